@@ -96,7 +96,7 @@ func validateDeckAgainstCardLibrary(cardLibrary []*zb_data.Card, deckCards []*zb
 	return nil
 }
 
-func validateDeck(isEditDeck bool, cardLibrary *zb_data.CardList, userCardCollection []*zb_data.CardCollectionCard, deck *zb_data.Deck, deckList []*zb_data.Deck, overlords []*zb_data.OverlordUserInstance) error {
+func validateDeck(isEditDeck bool, cardLibrary *zb_data.CardList, userCardCollection []*zb_data.CardCollectionCard, deck *zb_data.Deck, deckList []*zb_data.Deck, overlords []*zb_data.OverlordUserInstance, deckRules *zb_data.DeckRules) error {
 	if err := validateDeckAgainstUserCardCollection(userCardCollection, deck.Cards); err != nil {
 		return errors.Wrap(err, "error validating deck cards")
 	}
@@ -105,7 +105,9 @@ func validateDeck(isEditDeck bool, cardLibrary *zb_data.CardList, userCardCollec
 		return errors.Wrap(err, "error validating deck name")
 	}
 
-	// TODO: check for unlocked overlord skills
+	if err := validateDeckLegality(deckRules, cardLibrary.Cards, deck, overlords); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -0,0 +1,110 @@
+package battleground
+
+import (
+	"github.com/gogo/protobuf/proto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	"github.com/pkg/errors"
+)
+
+// GetCardHistory returns every change made to a card by card library updates
+func (z *ZombieBattleground) GetCardHistory(ctx contract.StaticContext, req *zb_calls.GetCardHistoryRequest) (*zb_calls.GetCardHistoryResponse, error) {
+	cardHistory, err := loadCardHistory(ctx, req.CardKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zb_calls.GetCardHistoryResponse{
+		Entries: cardHistory.Entries,
+	}, nil
+}
+
+// recordCardLibraryChanges appends the difference between the stored card library and the new one to card histories.
+// Nothing is recorded when the version has no card library yet.
+func recordCardLibraryChanges(ctx contract.Context, version string, newCards []*zb_data.Card) error {
+	if !ctx.Has(MakeVersionedKey(version, cardLibraryKey)) {
+		return nil
+	}
+
+	oldCardLibrary, err := loadCardLibraryRaw(ctx, version)
+	if err != nil {
+		return err
+	}
+
+	diff, err := DiffCardLibraries(oldCardLibrary.Cards, newCards)
+	if err != nil {
+		return errors.Wrap(err, "error comparing card libraries")
+	}
+
+	if diff.IsEmpty() {
+		return nil
+	}
+
+	entries := createCardHistoryEntries(diff)
+	for _, entry := range entries {
+		entry.Version = version
+		entry.BlockHeight = ctx.Block().Height
+		entry.ChangedBy = ctx.Message().Sender.Local.String()
+		entry.CreatedAt = ctx.Now().Unix()
+
+		cardHistory, err := loadCardHistory(ctx, entry.CardKey)
+		if err != nil {
+			return err
+		}
+
+		cardHistory.Entries = append(cardHistory.Entries, entry)
+		err = saveCardHistory(ctx, entry.CardKey, cardHistory)
+		if err != nil {
+			return err
+		}
+	}
+
+	emitMsg := zb_calls.CardHistoryEvent{
+		Entries: entries,
+	}
+
+	data, err := proto.Marshal(&emitMsg)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal CardHistoryEvent")
+	}
+	ctx.EmitTopics(data, TopicCardHistoryEvent)
+
+	return nil
+}
+
+func createCardHistoryEntries(diff *CardLibraryDiff) []*zb_data.CardHistoryEntry {
+	var entries []*zb_data.CardHistoryEntry
+	for _, card := range diff.Added {
+		entries = append(entries, &zb_data.CardHistoryEntry{
+			CardKey: card.CardKey,
+			Kind:    zb_data.CardHistoryEntry_Added,
+		})
+	}
+
+	for _, card := range diff.Removed {
+		entries = append(entries, &zb_data.CardHistoryEntry{
+			CardKey: card.CardKey,
+			Kind:    zb_data.CardHistoryEntry_Removed,
+		})
+	}
+
+	for _, cardDiff := range diff.Changed {
+		entry := &zb_data.CardHistoryEntry{
+			CardKey: cardDiff.CardKey,
+			Kind:    zb_data.CardHistoryEntry_Changed,
+		}
+
+		for _, field := range cardDiff.Fields {
+			entry.Changes = append(entry.Changes, &zb_data.CardFieldChange{
+				Field:    field.Path,
+				OldValue: field.From,
+				NewValue: field.To,
+			})
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
@@ -0,0 +1,95 @@
+package battleground
+
+import (
+	"github.com/gogo/protobuf/proto"
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"github.com/loomnetwork/go-loom"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	assert "github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+)
+
+func TestCardHistoryOperations(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "3866f776276246e4f9998aa90632931d89b0d3a5930e804e02299533f55b39e1"
+	var addr loom.Address
+	var ctx contract.Context
+
+	setup(c, pubKeyHexString, &addr, &ctx, t)
+
+	changedCardKey := battleground_proto.CardKey{MouldId: 2}
+	addedCardKey := battleground_proto.CardKey{MouldId: 9999}
+
+	t.Run("UpdateInit records card changes", func(t *testing.T) {
+		response, err := c.GetInit(ctx, &zb_calls.GetInitRequest{Version: "v1"})
+		assert.Nil(t, err)
+		initData := response.InitData
+
+		var oldDamage int32
+		for _, card := range initData.Cards {
+			if card.CardKey == changedCardKey {
+				addedCard := proto.Clone(card).(*zb_data.Card)
+				addedCard.CardKey = addedCardKey
+				initData.Cards = append(initData.Cards, addedCard)
+
+				oldDamage = card.Damage
+				card.Damage++
+				break
+			}
+		}
+
+		err = c.UpdateInit(ctx, &zb_calls.UpdateInitRequest{InitData: initData})
+		assert.Nil(t, err)
+
+		historyResponse, err := c.GetCardHistory(ctx, &zb_calls.GetCardHistoryRequest{CardKey: changedCardKey})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(historyResponse.Entries))
+
+		entry := historyResponse.Entries[0]
+		assert.Equal(t, zb_data.CardHistoryEntry_Changed, entry.Kind)
+		assert.Equal(t, "v1", entry.Version)
+		assert.Equal(t, addr.Local.String(), entry.ChangedBy)
+		assert.Equal(t, 1, len(entry.Changes))
+		assert.Equal(t, "damage", entry.Changes[0].Field)
+		assert.Equal(t, strconv.Itoa(int(oldDamage)), entry.Changes[0].OldValue)
+		assert.Equal(t, strconv.Itoa(int(oldDamage+1)), entry.Changes[0].NewValue)
+
+		historyResponse, err = c.GetCardHistory(ctx, &zb_calls.GetCardHistoryRequest{CardKey: addedCardKey})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(historyResponse.Entries))
+		assert.Equal(t, zb_data.CardHistoryEntry_Added, historyResponse.Entries[0].Kind)
+	})
+
+	t.Run("UpdateInit without changes records nothing", func(t *testing.T) {
+		response, err := c.GetInit(ctx, &zb_calls.GetInitRequest{Version: "v1"})
+		assert.Nil(t, err)
+
+		err = c.UpdateInit(ctx, &zb_calls.UpdateInitRequest{InitData: response.InitData})
+		assert.Nil(t, err)
+
+		historyResponse, err := c.GetCardHistory(ctx, &zb_calls.GetCardHistoryRequest{CardKey: changedCardKey})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(historyResponse.Entries))
+	})
+
+	t.Run("New version records nothing", func(t *testing.T) {
+		response, err := c.GetInit(ctx, &zb_calls.GetInitRequest{Version: "v1"})
+		assert.Nil(t, err)
+		response.InitData.Version = "v2"
+		for _, card := range response.InitData.Cards {
+			if card.CardKey == changedCardKey {
+				card.Damage++
+			}
+		}
+
+		err = c.UpdateInit(ctx, &zb_calls.UpdateInitRequest{InitData: response.InitData})
+		assert.Nil(t, err)
+
+		historyResponse, err := c.GetCardHistory(ctx, &zb_calls.GetCardHistoryRequest{CardKey: changedCardKey})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(historyResponse.Entries))
+	})
+}
@@ -0,0 +1,150 @@
+package battleground
+
+import (
+	"fmt"
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+)
+
+type cardKeyToCardKeyMigrationMap map[battleground_proto.CardKey]*zb_data.CardKeyMigration
+
+func validateCardKeyMigrations(migrations []*zb_data.CardKeyMigration, cardLibrary []*zb_data.Card) error {
+	cardKeyToCardMap, err := getCardKeyToCardMap(cardLibrary)
+	if err != nil {
+		return err
+	}
+
+	existingFromCardKeys := make(map[battleground_proto.CardKey]bool)
+	for _, migration := range migrations {
+		if existingFromCardKeys[migration.From] {
+			return fmt.Errorf("duplicate migration for card [%s]", migration.From.String())
+		}
+		existingFromCardKeys[migration.From] = true
+
+		// only cards removed from the card library can be migrated
+		if _, exists := cardKeyToCardMap[migration.From]; exists {
+			return fmt.Errorf("migrated card [%s] is still in card library", migration.From.String())
+		}
+
+		if migration.Retired {
+			continue
+		}
+
+		if _, exists := cardKeyToCardMap[migration.To]; !exists {
+			return fmt.Errorf("migration target card [%s] of card [%s] not found in card library", migration.To.String(), migration.From.String())
+		}
+	}
+
+	return nil
+}
+
+func getCardKeyToCardKeyMigrationMap(migrations []*zb_data.CardKeyMigration) cardKeyToCardKeyMigrationMap {
+	cardKeyToMigrationMap := make(cardKeyToCardKeyMigrationMap)
+	for _, migration := range migrations {
+		cardKeyToMigrationMap[migration.From] = migration
+	}
+
+	return cardKeyToMigrationMap
+}
+
+// splitCardKeyMigrations separates migrations to other cards from retirements
+func splitCardKeyMigrations(migrations []*zb_data.CardKeyMigration) (remapped cardKeyToCardKeyMigrationMap, retired cardKeyToCardKeyMigrationMap) {
+	remapped = make(cardKeyToCardKeyMigrationMap)
+	retired = make(cardKeyToCardKeyMigrationMap)
+	for _, migration := range migrations {
+		if migration.Retired {
+			retired[migration.From] = migration
+		} else {
+			remapped[migration.From] = migration
+		}
+	}
+
+	return remapped, retired
+}
+
+// migrateDeckCardKeys replaces remapped cards in the deck and removes retired ones
+func migrateDeckCardKeys(deck *zb_data.Deck, cardKeyToMigrationMap cardKeyToCardKeyMigrationMap) (changed bool) {
+	if len(cardKeyToMigrationMap) == 0 {
+		return false
+	}
+
+	var newDeckCards = make([]*zb_data.DeckCard, 0)
+	var cardKeyToDeckCard = make(map[battleground_proto.CardKey]*zb_data.DeckCard)
+	for _, deckCard := range deck.Cards {
+		if _, migrated := cardKeyToMigrationMap[deckCard.CardKey]; !migrated {
+			newDeckCards = append(newDeckCards, deckCard)
+			cardKeyToDeckCard[deckCard.CardKey] = deckCard
+		}
+	}
+
+	for _, deckCard := range deck.Cards {
+		migration, migrated := cardKeyToMigrationMap[deckCard.CardKey]
+		if !migrated {
+			continue
+		}
+
+		changed = true
+		if migration.Retired {
+			continue
+		}
+
+		targetDeckCard, exists := cardKeyToDeckCard[migration.To]
+		if exists {
+			targetDeckCard.Amount += deckCard.Amount
+		} else {
+			targetDeckCard = &zb_data.DeckCard{
+				CardKey: migration.To,
+				Amount:  deckCard.Amount,
+			}
+
+			newDeckCards = append(newDeckCards, targetDeckCard)
+			cardKeyToDeckCard[migration.To] = targetDeckCard
+		}
+	}
+
+	deck.Cards = newDeckCards
+	return changed
+}
+
+// migrateCollectionCardKeys moves the amounts of remapped cards to their new card keys and drops retired ones
+func migrateCollectionCardKeys(collectionCards []*zb_data.CardCollectionCard, cardKeyToMigrationMap cardKeyToCardKeyMigrationMap) []*zb_data.CardCollectionCard {
+	if len(cardKeyToMigrationMap) == 0 {
+		return collectionCards
+	}
+
+	var newCollectionCards = make([]*zb_data.CardCollectionCard, 0, len(collectionCards))
+	var cardKeyToCollectionCard = make(map[battleground_proto.CardKey]*zb_data.CardCollectionCard)
+	for _, collectionCard := range collectionCards {
+		if _, migrated := cardKeyToMigrationMap[collectionCard.CardKey]; !migrated {
+			// copy, the raw collection must stay as is
+			newCollectionCard := &zb_data.CardCollectionCard{
+				CardKey: collectionCard.CardKey,
+				Amount:  collectionCard.Amount,
+			}
+			newCollectionCards = append(newCollectionCards, newCollectionCard)
+			cardKeyToCollectionCard[collectionCard.CardKey] = newCollectionCard
+		}
+	}
+
+	for _, collectionCard := range collectionCards {
+		migration, migrated := cardKeyToMigrationMap[collectionCard.CardKey]
+		if !migrated || migration.Retired {
+			continue
+		}
+
+		targetCollectionCard, exists := cardKeyToCollectionCard[migration.To]
+		if exists {
+			targetCollectionCard.Amount += collectionCard.Amount
+		} else {
+			targetCollectionCard = &zb_data.CardCollectionCard{
+				CardKey: migration.To,
+				Amount:  collectionCard.Amount,
+			}
+
+			newCollectionCards = append(newCollectionCards, targetCollectionCard)
+			cardKeyToCollectionCard[migration.To] = targetCollectionCard
+		}
+	}
+
+	return newCollectionCards
+}
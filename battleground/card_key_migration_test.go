@@ -0,0 +1,175 @@
+package battleground
+
+import (
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/types/nullable/nullable_pb"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	"github.com/loomnetwork/go-loom"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	assert "github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDiffCardLibraries(t *testing.T) {
+	from := []*zb_data.Card{
+		{CardKey: battleground_proto.CardKey{MouldId: 1}, Name: "Removed"},
+		{CardKey: battleground_proto.CardKey{MouldId: 2}, Name: "Unchanged", Damage: 1},
+		{
+			CardKey: battleground_proto.CardKey{MouldId: 3},
+			Name:    "Changed",
+			Damage:  1,
+			Abilities: []*zb_data.AbilityData{
+				{Ability: zb_enums.AbilityType_ChangeStat, Value: 1},
+			},
+		},
+		{
+			CardKey: battleground_proto.CardKey{MouldId: 3, Variant: zb_enums.CardVariant_Backer},
+			Overrides: &zb_data.CardOverrides{
+				Damage: &nullable_pb.Int32Value{Value: 2},
+			},
+		},
+	}
+	to := []*zb_data.Card{
+		{CardKey: battleground_proto.CardKey{MouldId: 4}, Name: "Added"},
+		{CardKey: battleground_proto.CardKey{MouldId: 2}, Name: "Unchanged", Damage: 1},
+		{
+			CardKey: battleground_proto.CardKey{MouldId: 3},
+			Name:    "Changed",
+			Damage:  2,
+			Abilities: []*zb_data.AbilityData{
+				{Ability: zb_enums.AbilityType_ChangeStat, Value: 2},
+			},
+		},
+		{
+			CardKey: battleground_proto.CardKey{MouldId: 3, Variant: zb_enums.CardVariant_Backer},
+			Overrides: &zb_data.CardOverrides{
+				Damage: &nullable_pb.Int32Value{Value: 3},
+			},
+		},
+	}
+
+	diff, err := DiffCardLibraries(from, to)
+	assert.Nil(t, err)
+	assert.False(t, diff.IsEmpty())
+
+	assert.Equal(t, 1, len(diff.Added))
+	assert.Equal(t, int64(4), diff.Added[0].CardKey.MouldId)
+	assert.Equal(t, 1, len(diff.Removed))
+	assert.Equal(t, int64(1), diff.Removed[0].CardKey.MouldId)
+
+	assert.Equal(t, 2, len(diff.Changed))
+	assert.Equal(t, battleground_proto.CardKey{MouldId: 3}, diff.Changed[0].CardKey)
+	assert.Equal(t, 2, len(diff.Changed[0].Fields))
+	assert.Equal(t, "abilities[0].value", diff.Changed[0].Fields[0].Path)
+	assert.Equal(t, "1", diff.Changed[0].Fields[0].From)
+	assert.Equal(t, "2", diff.Changed[0].Fields[0].To)
+	assert.Equal(t, "damage", diff.Changed[0].Fields[1].Path)
+
+	assert.Equal(t, battleground_proto.CardKey{MouldId: 3, Variant: zb_enums.CardVariant_Backer}, diff.Changed[1].CardKey)
+	assert.Equal(t, 1, len(diff.Changed[1].Fields))
+	assert.Equal(t, "overrides.damage.value", diff.Changed[1].Fields[0].Path)
+
+	diff, err = DiffCardLibraries(to, to)
+	assert.Nil(t, err)
+	assert.True(t, diff.IsEmpty())
+}
+
+func TestValidateCardKeyMigrations(t *testing.T) {
+	cardLibrary := []*zb_data.Card{
+		{CardKey: battleground_proto.CardKey{MouldId: 2}},
+	}
+
+	assert.Nil(t, validateCardKeyMigrations([]*zb_data.CardKeyMigration{
+		{From: battleground_proto.CardKey{MouldId: 1}, To: battleground_proto.CardKey{MouldId: 2}},
+		{From: battleground_proto.CardKey{MouldId: 3}, Retired: true},
+	}, cardLibrary))
+
+	for _, migrations := range [][]*zb_data.CardKeyMigration{
+		// duplicate
+		{
+			{From: battleground_proto.CardKey{MouldId: 1}, To: battleground_proto.CardKey{MouldId: 2}},
+			{From: battleground_proto.CardKey{MouldId: 1}, Retired: true},
+		},
+		// still in card library
+		{{From: battleground_proto.CardKey{MouldId: 2}, Retired: true}},
+		// unknown target
+		{{From: battleground_proto.CardKey{MouldId: 1}, To: battleground_proto.CardKey{MouldId: 5}}},
+	} {
+		assert.NotNil(t, validateCardKeyMigrations(migrations, cardLibrary))
+	}
+}
+
+func TestCardKeyMigrationOperations(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "5bfbf5b3aa1b5b7b38f1b6d8fd8ed29bd4a1f4e7bd1b5de9f59c6e3e6d2a8c71"
+	var addr loom.Address
+	var ctx contract.Context
+
+	setup(c, pubKeyHexString, &addr, &ctx, t)
+	setupAccount(c, ctx, &zb_calls.UpsertAccountRequest{
+		UserId:  "MigrationUser",
+		Version: "v1",
+	}, t)
+
+	// v2 card library without mould ids 1 and 3
+	cardLibrary, err := loadCardLibraryRaw(ctx, "v1")
+	assert.Nil(t, err)
+
+	var cards []*zb_data.Card
+	for _, card := range cardLibrary.Cards {
+		if card.CardKey.MouldId != 1 && card.CardKey.MouldId != 3 {
+			cards = append(cards, card)
+		}
+	}
+	err = saveCardLibrary(ctx, "v2", &zb_data.CardList{Cards: cards})
+	assert.Nil(t, err)
+
+	err = saveCardKeyMigrations(ctx, "v2", &zb_data.CardKeyMigrationList{
+		Migrations: []*zb_data.CardKeyMigration{
+			{From: battleground_proto.CardKey{MouldId: 1}, To: battleground_proto.CardKey{MouldId: 2}},
+			{From: battleground_proto.CardKey{MouldId: 3}, Retired: true},
+		},
+	})
+	assert.Nil(t, err)
+
+	err = ctx.Set(DecksKey("MigrationUser"), &zb_data.DeckList{
+		Decks: []*zb_data.Deck{
+			{
+				Id:   1,
+				Name: "Migrated",
+				Cards: []*zb_data.DeckCard{
+					{CardKey: battleground_proto.CardKey{MouldId: 1}, Amount: 2},
+					{CardKey: battleground_proto.CardKey{MouldId: 2}, Amount: 1},
+					{CardKey: battleground_proto.CardKey{MouldId: 3}, Amount: 1},
+				},
+			},
+		},
+	})
+	assert.Nil(t, err)
+
+	t.Run("Collection cards are remapped", func(t *testing.T) {
+		collectionCards, err := loadUserCardCollection(ctx, "v2", "MigrationUser")
+		assert.Nil(t, err)
+
+		cardKeyToAmount := cardCollectionToCardKeyToAmountMap(collectionCards)
+		assert.Equal(t, int64(8), cardKeyToAmount[battleground_proto.CardKey{MouldId: 2}])
+		_, exists := cardKeyToAmount[battleground_proto.CardKey{MouldId: 1}]
+		assert.False(t, exists)
+
+		// raw collection is not changed
+		collection, err := loadUserCardCollectionRaw(ctx, "MigrationUser")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(4), cardCollectionToCardKeyToAmountMap(collection.Cards)[battleground_proto.CardKey{MouldId: 1}])
+	})
+
+	t.Run("Deck cards are remapped and retired cards removed", func(t *testing.T) {
+		deckList, err := loadDecks(ctx, "v2", "MigrationUser")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(deckList.Decks))
+		assert.Equal(t, 1, len(deckList.Decks[0].Cards))
+		assert.Equal(t, battleground_proto.CardKey{MouldId: 2}, deckList.Decks[0].Cards[0].CardKey)
+		assert.Equal(t, int64(3), deckList.Decks[0].Cards[0].Amount)
+	})
+}
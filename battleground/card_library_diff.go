@@ -0,0 +1,212 @@
+package battleground
+
+import (
+	"encoding/json"
+	"github.com/gogo/protobuf/jsonpb"
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"github.com/pkg/errors"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+type CardLibraryDiff struct {
+	Added   []*zb_data.Card
+	Removed []*zb_data.Card
+	Changed []*CardDiff
+}
+
+type CardDiff struct {
+	CardKey battleground_proto.CardKey
+	Name    string
+	Fields  []*CardFieldDiff
+}
+
+// CardFieldDiff is a single changed field, values are JSON encoded and "null" when the field is not set
+type CardFieldDiff struct {
+	Path string
+	From string
+	To   string
+}
+
+// DiffCardLibraries compares two card libraries field by field, including overrides and abilities
+func DiffCardLibraries(from []*zb_data.Card, to []*zb_data.Card) (*CardLibraryDiff, error) {
+	fromCardKeyToCardMap, err := getCardKeyToCardMap(from)
+	if err != nil {
+		return nil, errors.Wrap(err, "error in 'from' card library")
+	}
+
+	toCardKeyToCardMap, err := getCardKeyToCardMap(to)
+	if err != nil {
+		return nil, errors.Wrap(err, "error in 'to' card library")
+	}
+
+	diff := &CardLibraryDiff{}
+	for _, fromCard := range from {
+		toCard, exists := toCardKeyToCardMap[fromCard.CardKey]
+		if !exists {
+			diff.Removed = append(diff.Removed, fromCard)
+			continue
+		}
+
+		fields, err := diffCards(fromCard, toCard)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(fields) > 0 {
+			diff.Changed = append(diff.Changed, &CardDiff{
+				CardKey: toCard.CardKey,
+				Name:    toCard.Name,
+				Fields:  fields,
+			})
+		}
+	}
+
+	for _, toCard := range to {
+		if _, exists := fromCardKeyToCardMap[toCard.CardKey]; !exists {
+			diff.Added = append(diff.Added, toCard)
+		}
+	}
+
+	sortCardsByCardKey(diff.Added)
+	sortCardsByCardKey(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return diff.Changed[i].CardKey.Compare(&diff.Changed[j].CardKey) < 0
+	})
+
+	return diff, nil
+}
+
+func (diff *CardLibraryDiff) IsEmpty() bool {
+	return len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0
+}
+
+func sortCardsByCardKey(cards []*zb_data.Card) {
+	sort.Slice(cards, func(i, j int) bool {
+		return cards[i].CardKey.Compare(&cards[j].CardKey) < 0
+	})
+}
+
+func diffCards(from *zb_data.Card, to *zb_data.Card) ([]*CardFieldDiff, error) {
+	fromValue, err := cardToJsonValue(from)
+	if err != nil {
+		return nil, err
+	}
+
+	toValue, err := cardToJsonValue(to)
+	if err != nil {
+		return nil, err
+	}
+
+	// cards are matched by card key already
+	delete(fromValue, "cardKey")
+	delete(toValue, "cardKey")
+
+	var fields []*CardFieldDiff
+	err = diffJsonValues("", fromValue, toValue, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+func cardToJsonValue(card *zb_data.Card) (map[string]interface{}, error) {
+	m := jsonpb.Marshaler{
+		EmitDefaults: true,
+	}
+
+	cardJson, err := m.MarshalToString(card)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error marshaling card [%s]", card.CardKey.String())
+	}
+
+	var value map[string]interface{}
+	if err := json.Unmarshal([]byte(cardJson), &value); err != nil {
+		return nil, errors.Wrapf(err, "error unmarshaling card [%s]", card.CardKey.String())
+	}
+
+	return value, nil
+}
+
+func diffJsonValues(path string, from interface{}, to interface{}, fields *[]*CardFieldDiff) error {
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+	if fromIsMap && toIsMap {
+		keySet := make(map[string]bool)
+		for key := range fromMap {
+			keySet[key] = true
+		}
+		for key := range toMap {
+			keySet[key] = true
+		}
+
+		keys := make([]string, 0, len(keySet))
+		for key := range keySet {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+
+			if err := diffJsonValues(childPath, fromMap[key], toMap[key], fields); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	fromSlice, fromIsSlice := from.([]interface{})
+	toSlice, toIsSlice := to.([]interface{})
+	if fromIsSlice && toIsSlice {
+		length := len(fromSlice)
+		if len(toSlice) > length {
+			length = len(toSlice)
+		}
+
+		for i := 0; i < length; i++ {
+			var fromElement, toElement interface{}
+			if i < len(fromSlice) {
+				fromElement = fromSlice[i]
+			}
+			if i < len(toSlice) {
+				toElement = toSlice[i]
+			}
+
+			if err := diffJsonValues(path+"["+strconv.Itoa(i)+"]", fromElement, toElement, fields); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if reflect.DeepEqual(from, to) {
+		return nil
+	}
+
+	fromJson, err := json.Marshal(from)
+	if err != nil {
+		return errors.Wrapf(err, "error marshaling field %s", path)
+	}
+
+	toJson, err := json.Marshal(to)
+	if err != nil {
+		return errors.Wrapf(err, "error marshaling field %s", path)
+	}
+
+	*fields = append(*fields, &CardFieldDiff{
+		Path: path,
+		From: string(fromJson),
+		To:   string(toJson),
+	})
+
+	return nil
+}
@@ -452,3 +452,176 @@ func TestValidateDeckCardVariants(t *testing.T) {
 		assert.Equal(t, 0, len(deck.Cards))
 	})
 }
+
+func TestDeckLegality(t *testing.T) {
+	cardLibrary := []*zb_data.Card{
+		{CardKey: battleground_proto.CardKey{MouldId: 1}, Faction: zb_enums.Faction_Fire, Rank: zb_enums.CreatureRank_Minion},
+		{CardKey: battleground_proto.CardKey{MouldId: 1, Variant: zb_enums.CardVariant_Backer}, Faction: zb_enums.Faction_Fire, Rank: zb_enums.CreatureRank_Minion},
+		{CardKey: battleground_proto.CardKey{MouldId: 2}, Faction: zb_enums.Faction_Fire, Rank: zb_enums.CreatureRank_General},
+		{CardKey: battleground_proto.CardKey{MouldId: 3}, Faction: zb_enums.Faction_Water, Rank: zb_enums.CreatureRank_Minion},
+		{CardKey: battleground_proto.CardKey{MouldId: 4}, Faction: zb_enums.Faction_Item, Kind: zb_enums.CardKind_Item},
+		{CardKey: battleground_proto.CardKey{MouldId: 5}, Faction: zb_enums.Faction_Fire, Rank: zb_enums.CreatureRank_Minion, Hidden: true},
+	}
+
+	overlords := []*zb_data.OverlordUserInstance{
+		{
+			Prototype: &zb_data.OverlordPrototype{
+				Id:      1,
+				Faction: zb_enums.Faction_Fire,
+				Skills: []*zb_data.OverlordSkillPrototype{
+					{Id: 10, Skill: zb_enums.OverlordSkillType_Fireball},
+					{Id: 11, Skill: zb_enums.OverlordSkillType_FireBolt},
+				},
+			},
+			UserData: &zb_data.OverlordUserData{
+				PrototypeId:      1,
+				UnlockedSkillIds: []int64{10},
+			},
+		},
+	}
+
+	deckRules := &zb_data.DeckRules{
+		Name:                      DefaultDeckRulesName,
+		MinDeckSize:               2,
+		MaxDeckSize:               10,
+		EnforceCopyLimits:         true,
+		RestrictFactionToOverlord: true,
+		RequireUnlockedSkills:     true,
+	}
+
+	getReasons := func(deck *zb_data.Deck) []zb_data.DeckLegalityIssue_Reason {
+		issues, err := getDeckLegalityIssues(deckRules, cardLibrary, deck, overlords)
+		assert.Nil(t, err)
+
+		var reasons []zb_data.DeckLegalityIssue_Reason
+		for _, issue := range issues {
+			reasons = append(reasons, issue.Reason)
+		}
+		return reasons
+	}
+
+	t.Run("Legal deck", func(t *testing.T) {
+		deck := &zb_data.Deck{
+			OverlordId:   1,
+			PrimarySkill: zb_enums.OverlordSkillType_Fireball,
+			Cards: []*zb_data.DeckCard{
+				{CardKey: battleground_proto.CardKey{MouldId: 1}, Amount: 2},
+				{CardKey: battleground_proto.CardKey{MouldId: 1, Variant: zb_enums.CardVariant_Backer}, Amount: 2},
+				{CardKey: battleground_proto.CardKey{MouldId: 4}, Amount: 2},
+			},
+		}
+		assert.Empty(t, getReasons(deck))
+		assert.Nil(t, validateDeckLegality(deckRules, cardLibrary, deck, overlords))
+		assert.Nil(t, validateDeckLegality(nil, cardLibrary, &zb_data.Deck{}, nil))
+	})
+
+	t.Run("Copy limits count every variant", func(t *testing.T) {
+		deck := &zb_data.Deck{
+			OverlordId: 1,
+			Cards: []*zb_data.DeckCard{
+				{CardKey: battleground_proto.CardKey{MouldId: 1}, Amount: 3},
+				{CardKey: battleground_proto.CardKey{MouldId: 1, Variant: zb_enums.CardVariant_Backer}, Amount: 2},
+				{CardKey: battleground_proto.CardKey{MouldId: 2}, Amount: 2},
+			},
+		}
+		assert.Equal(t, []zb_data.DeckLegalityIssue_Reason{
+			zb_data.DeckLegalityIssue_CopyLimit,
+			zb_data.DeckLegalityIssue_CopyLimit,
+		}, getReasons(deck))
+	})
+
+	t.Run("Copy limit overrides", func(t *testing.T) {
+		deckRules.CopyLimits = []*zb_data.DeckRulesCopyLimit{
+			{Rank: zb_enums.CreatureRank_General, MaxCopies: 2},
+		}
+		defer func() { deckRules.CopyLimits = nil }()
+
+		deck := &zb_data.Deck{
+			OverlordId: 1,
+			Cards: []*zb_data.DeckCard{
+				{CardKey: battleground_proto.CardKey{MouldId: 2}, Amount: 2},
+			},
+		}
+		assert.Empty(t, getReasons(deck))
+	})
+
+	t.Run("Deck size, faction, hidden and unknown cards", func(t *testing.T) {
+		deck := &zb_data.Deck{
+			OverlordId: 1,
+			Cards: []*zb_data.DeckCard{
+				{CardKey: battleground_proto.CardKey{MouldId: 3}, Amount: 4},
+				{CardKey: battleground_proto.CardKey{MouldId: 5}, Amount: 4},
+				{CardKey: battleground_proto.CardKey{MouldId: 100}, Amount: 4},
+			},
+		}
+
+		issues, err := getDeckLegalityIssues(deckRules, cardLibrary, deck, overlords)
+		assert.Nil(t, err)
+		assert.Equal(t, 4, len(issues))
+		assert.Equal(t, zb_data.DeckLegalityIssue_Faction, issues[0].Reason)
+		assert.Equal(t, battleground_proto.CardKey{MouldId: 3}, issues[0].CardKey)
+		assert.Equal(t, zb_data.DeckLegalityIssue_HiddenCard, issues[1].Reason)
+		assert.Equal(t, battleground_proto.CardKey{MouldId: 5}, issues[1].CardKey)
+		assert.Equal(t, zb_data.DeckLegalityIssue_CardNotFound, issues[2].Reason)
+		assert.Equal(t, zb_data.DeckLegalityIssue_DeckSize, issues[3].Reason)
+
+		err = validateDeckLegality(deckRules, cardLibrary, deck, overlords)
+		assert.IsType(t, &DeckLegalityError{}, err)
+		assert.Equal(t, 4, len(err.(*DeckLegalityError).Issues))
+	})
+
+	t.Run("Banned cards", func(t *testing.T) {
+		deckRules.BannedCards = []battleground_proto.CardKey{{MouldId: 1}}
+		defer func() { deckRules.BannedCards = nil }()
+
+		deck := &zb_data.Deck{
+			OverlordId: 1,
+			Cards: []*zb_data.DeckCard{
+				{CardKey: battleground_proto.CardKey{MouldId: 1, Variant: zb_enums.CardVariant_Backer}, Amount: 2},
+			},
+		}
+		assert.Equal(t, []zb_data.DeckLegalityIssue_Reason{zb_data.DeckLegalityIssue_BannedCard}, getReasons(deck))
+	})
+
+	t.Run("Locked skills and unknown overlords", func(t *testing.T) {
+		deck := &zb_data.Deck{
+			OverlordId:     1,
+			PrimarySkill:   zb_enums.OverlordSkillType_Fireball,
+			SecondarySkill: zb_enums.OverlordSkillType_FireBolt,
+			Cards: []*zb_data.DeckCard{
+				{CardKey: battleground_proto.CardKey{MouldId: 4}, Amount: 2},
+			},
+		}
+		assert.Equal(t, []zb_data.DeckLegalityIssue_Reason{zb_data.DeckLegalityIssue_SkillLocked}, getReasons(deck))
+
+		deck.OverlordId = 2
+		assert.Equal(t, []zb_data.DeckLegalityIssue_Reason{zb_data.DeckLegalityIssue_OverlordNotFound}, getReasons(deck))
+	})
+
+	t.Run("Allowed sets and restricted cards", func(t *testing.T) {
+		deckRules.AllowedSets = []zb_enums.CardSet_Enum{zb_enums.CardSet_Season1}
+		deckRules.RestrictedCards = []battleground_proto.CardKey{{MouldId: 1}}
+		defer func() {
+			deckRules.AllowedSets = nil
+			deckRules.RestrictedCards = nil
+		}()
+
+		deck := &zb_data.Deck{
+			OverlordId: 1,
+			Cards: []*zb_data.DeckCard{
+				{CardKey: battleground_proto.CardKey{MouldId: 1}, Amount: 1},
+				{CardKey: battleground_proto.CardKey{MouldId: 1, Variant: zb_enums.CardVariant_Backer}, Amount: 1},
+			},
+		}
+		assert.Equal(t, []zb_data.DeckLegalityIssue_Reason{
+			zb_data.DeckLegalityIssue_CardSetNotAllowed,
+			zb_data.DeckLegalityIssue_CardSetNotAllowed,
+			zb_data.DeckLegalityIssue_RestrictedCard,
+		}, getReasons(deck))
+
+		cardLibrary[0].Set = zb_enums.CardSet_Season1
+		cardLibrary[1].Set = zb_enums.CardSet_Season1
+		deck.Cards = deck.Cards[:1]
+		assert.Empty(t, getReasons(deck))
+	})
+}
@@ -0,0 +1,263 @@
+package battleground
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	"github.com/loomnetwork/go-loom/types"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// ChallengeTimeout determines how long the challenged player has to accept the challenge
+const ChallengeTimeout = 300 * time.Second
+
+func (z *ZombieBattleground) CreateChallenge(ctx contract.Context, req *zb_calls.CreateChallengeRequest) (*zb_calls.CreateChallengeResponse, error) {
+	if !isOwner(ctx, req.UserId) {
+		return nil, ErrUserNotVerified
+	}
+
+	if req.Version == "" {
+		return nil, fmt.Errorf("version not specified")
+	}
+
+	if req.TargetUserId == req.UserId {
+		return nil, errors.New("can't challenge yourself")
+	}
+
+	if !ctx.Has(AccountKey(req.TargetUserId)) {
+		return nil, fmt.Errorf("user %s not found", req.TargetUserId)
+	}
+
+	registrationData := &zb_data.PlayerProfileRegistrationData{
+		UserId:  req.UserId,
+		DeckId:  req.DeckId,
+		Version: req.Version,
+	}
+	if _, err := getDeckWithRegistrationData(ctx, registrationData, req.Version); err != nil {
+		return nil, err
+	}
+
+	if _, err := getChallengeGameModeAddress(ctx, req.GameModeId); err != nil {
+		return nil, err
+	}
+
+	challengeID, err := nextChallengeID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := &zb_data.Challenge{
+		Code:             challengeCode(challengeID, req.UserId, req.TargetUserId),
+		ChallengerUserId: req.UserId,
+		TargetUserId:     req.TargetUserId,
+		ChallengerDeckId: req.DeckId,
+		GameModeId:       req.GameModeId,
+		Version:          req.Version,
+		CreatedAt:        ctx.Now().Unix(),
+		ExpiresAt:        ctx.Now().Add(ChallengeTimeout).Unix(),
+		Status:           zb_data.Challenge_Pending,
+	}
+
+	if ctx.Has(ChallengeKey(challenge.Code)) {
+		return nil, fmt.Errorf("challenge %s already exists", challenge.Code)
+	}
+
+	if err := saveChallenge(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	// notify the challenged player
+	notifications, err := loadUserNotifications(ctx, req.TargetUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	notification := createBaseNotification(ctx, notifications.Notifications, zb_data.NotificationType_Challenge)
+	notification.Notification = &zb_data.Notification_Challenge{
+		Challenge: &zb_data.NotificationChallenge{
+			ChallengeCode:    challenge.Code,
+			ChallengerUserId: challenge.ChallengerUserId,
+			GameModeId:       challenge.GameModeId,
+			ExpiresAt:        challenge.ExpiresAt,
+		},
+	}
+
+	notifications.Notifications = append(notifications.Notifications, notification)
+	if err := saveUserNotifications(ctx, req.TargetUserId, notifications); err != nil {
+		return nil, err
+	}
+
+	return &zb_calls.CreateChallengeResponse{
+		Challenge: challenge,
+	}, nil
+}
+
+// AcceptChallenge creates the match right away, the public player pool is not involved
+func (z *ZombieBattleground) AcceptChallenge(ctx contract.Context, req *zb_calls.AcceptChallengeRequest) (*zb_calls.AcceptChallengeResponse, error) {
+	if !isOwner(ctx, req.UserId) {
+		return nil, ErrUserNotVerified
+	}
+
+	if req.Version == "" {
+		return nil, fmt.Errorf("version not specified")
+	}
+
+	challenge, err := loadPendingChallenge(ctx, req.ChallengeCode, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// players waiting in a queue or playing can't start another match
+	for _, userID := range []string{challenge.ChallengerUserId, challenge.TargetUserId} {
+		if match, _ := loadUserCurrentMatch(ctx, userID); match != nil {
+			return nil, fmt.Errorf("player %s is already in a match", userID)
+		}
+
+		queueNames, err := loadUserMatchMakingQueues(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if len(queueNames) > 0 {
+			return nil, fmt.Errorf("player %s is in a matchmaking queue", userID)
+		}
+	}
+
+	configuration, err := loadContractConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matchVersion, compatible := getMatchVersion(configuration, challenge.Version, req.Version)
+	if !compatible {
+		return nil, fmt.Errorf("version %s is not compatible with challenge version %s", req.Version, challenge.Version)
+	}
+
+	customGame, err := getChallengeGameModeAddress(ctx, challenge.GameModeId)
+	if err != nil {
+		return nil, err
+	}
+
+	challengerRegistrationData := &zb_data.PlayerProfileRegistrationData{
+		UserId:     challenge.ChallengerUserId,
+		DeckId:     challenge.ChallengerDeckId,
+		Version:    challenge.Version,
+		CustomGame: customGame,
+	}
+	challengerDeck, err := getDeckWithRegistrationData(ctx, challengerRegistrationData, challengerRegistrationData.Version)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateDeckForMatchVersion(ctx, challengerRegistrationData, challengerDeck, matchVersion); err != nil {
+		return nil, err
+	}
+
+	registrationData := &zb_data.PlayerProfileRegistrationData{
+		UserId:     req.UserId,
+		DeckId:     req.DeckId,
+		Version:    req.Version,
+		CustomGame: customGame,
+	}
+	deck, err := getDeckWithRegistrationData(ctx, registrationData, registrationData.Version)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateDeckForMatchVersion(ctx, registrationData, deck, matchVersion); err != nil {
+		return nil, err
+	}
+
+	match, err := startMatch(ctx, challengerRegistrationData, challengerDeck, registrationData, deck, matchVersion, customGame)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge.Status = zb_data.Challenge_Accepted
+	challenge.MatchId = match.Id
+	if err := saveChallenge(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	return &zb_calls.AcceptChallengeResponse{
+		Match: match,
+	}, nil
+}
+
+func (z *ZombieBattleground) DeclineChallenge(ctx contract.Context, req *zb_calls.DeclineChallengeRequest) error {
+	if !isOwner(ctx, req.UserId) {
+		return ErrUserNotVerified
+	}
+
+	challenge, err := loadPendingChallenge(ctx, req.ChallengeCode, req.UserId)
+	if err != nil {
+		return err
+	}
+
+	challenge.Status = zb_data.Challenge_Declined
+	return saveChallenge(ctx, challenge)
+}
+
+func (z *ZombieBattleground) GetChallenge(ctx contract.StaticContext, req *zb_calls.GetChallengeRequest) (*zb_calls.GetChallengeResponse, error) {
+	challenge, err := loadChallenge(ctx, req.ChallengeCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if challenge.Status == zb_data.Challenge_Pending && ctx.Now().Unix() > challenge.ExpiresAt {
+		challenge.Status = zb_data.Challenge_Expired
+	}
+
+	return &zb_calls.GetChallengeResponse{
+		Challenge: challenge,
+	}, nil
+}
+
+// loadPendingChallenge returns the challenge if it is still waiting for the target player
+func loadPendingChallenge(ctx contract.StaticContext, code string, targetUserID string) (*zb_data.Challenge, error) {
+	challenge, err := loadChallenge(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if challenge.TargetUserId != targetUserID {
+		return nil, fmt.Errorf("challenge %s is not addressed to user %s", code, targetUserID)
+	}
+
+	// expired challenges are never stored as such, the status is derived from the expiration time
+	if challenge.Status == zb_data.Challenge_Pending && ctx.Now().Unix() > challenge.ExpiresAt {
+		challenge.Status = zb_data.Challenge_Expired
+	}
+
+	if challenge.Status != zb_data.Challenge_Pending {
+		return nil, fmt.Errorf("challenge %s is %s", code, challenge.Status.String())
+	}
+
+	return challenge, nil
+}
+
+// getChallengeGameModeAddress returns the custom game address of the game mode,
+// or nil if no game mode was requested
+func getChallengeGameModeAddress(ctx contract.StaticContext, gameModeID string) (*types.Address, error) {
+	if gameModeID == "" {
+		return nil, nil
+	}
+
+	gameModeList, err := loadGameModeList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gameMode := getGameModeFromList(gameModeList, gameModeID)
+	if gameMode == nil {
+		return nil, fmt.Errorf("game mode %s not found", gameModeID)
+	}
+
+	return gameMode.Address, nil
+}
+
+func challengeCode(challengeID int64, challengerUserID string, targetUserID string) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%d:%s:%s", challengeID, challengerUserID, targetUserID)))
+	return hex.EncodeToString(hash[:8])
+}
@@ -0,0 +1,163 @@
+package battleground
+
+import (
+	"fmt"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"github.com/loomnetwork/go-loom"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	assert "github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestChallengeOperations(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "3866f776276246e4f9998aa90632931d89b0d3a5930e804e02299533f55b39e1"
+	var addr loom.Address
+	var ctx contract.Context
+
+	fc := setup(c, pubKeyHexString, &addr, &ctx, t)
+	now := time.Now()
+	fc.SetTime(now)
+
+	for i := 1; i <= 3; i++ {
+		setupAccount(c, ctx, &zb_calls.UpsertAccountRequest{
+			UserId:  fmt.Sprintf("player-%d", i),
+			Version: "v1",
+		}, t)
+	}
+
+	createChallenge := func(targetUserID string) (*zb_data.Challenge, error) {
+		response, err := c.CreateChallenge(ctx, &zb_calls.CreateChallengeRequest{
+			UserId:       "player-1",
+			TargetUserId: targetUserID,
+			DeckId:       1,
+			Version:      "v1",
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return response.Challenge, nil
+	}
+
+	t.Run("CreateChallenge targeting yourself should fail", func(t *testing.T) {
+		_, err := createChallenge("player-1")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("CreateChallenge targeting unknown user should fail", func(t *testing.T) {
+		_, err := createChallenge("player-unknown")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("CreateChallenge with unknown game mode should fail", func(t *testing.T) {
+		_, err := c.CreateChallenge(ctx, &zb_calls.CreateChallengeRequest{
+			UserId:       "player-1",
+			TargetUserId: "player-2",
+			DeckId:       1,
+			GameModeId:   "unknown",
+			Version:      "v1",
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("CreateChallenge should notify the target", func(t *testing.T) {
+		challenge, err := createChallenge("player-2")
+		assert.Nil(t, err)
+		assert.NotEmpty(t, challenge.Code)
+		assert.Equal(t, zb_data.Challenge_Pending, challenge.Status)
+
+		response, err := c.GetNotifications(ctx, &zb_calls.GetNotificationsRequest{UserId: "player-2"})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(response.Notifications))
+		assert.Equal(t, zb_data.NotificationType_Challenge, response.Notifications[0].Type)
+		notificationChallenge := response.Notifications[0].Notification.(*zb_data.Notification_Challenge).Challenge
+		assert.Equal(t, challenge.Code, notificationChallenge.ChallengeCode)
+		assert.Equal(t, "player-1", notificationChallenge.ChallengerUserId)
+	})
+
+	t.Run("DeclineChallenge", func(t *testing.T) {
+		challenge, err := createChallenge("player-2")
+		assert.Nil(t, err)
+
+		err = c.DeclineChallenge(ctx, &zb_calls.DeclineChallengeRequest{
+			UserId:        "player-3",
+			ChallengeCode: challenge.Code,
+		})
+		assert.NotNil(t, err)
+
+		err = c.DeclineChallenge(ctx, &zb_calls.DeclineChallengeRequest{
+			UserId:        "player-2",
+			ChallengeCode: challenge.Code,
+		})
+		assert.Nil(t, err)
+
+		_, err = c.AcceptChallenge(ctx, &zb_calls.AcceptChallengeRequest{
+			UserId:        "player-2",
+			ChallengeCode: challenge.Code,
+			DeckId:        1,
+			Version:       "v1",
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("AcceptChallenge of expired challenge should fail", func(t *testing.T) {
+		challenge, err := createChallenge("player-3")
+		assert.Nil(t, err)
+
+		fc.SetTime(now.Add(ChallengeTimeout + time.Second))
+		defer fc.SetTime(now)
+
+		response, err := c.GetChallenge(ctx, &zb_calls.GetChallengeRequest{ChallengeCode: challenge.Code})
+		assert.Nil(t, err)
+		assert.Equal(t, zb_data.Challenge_Expired, response.Challenge.Status)
+
+		_, err = c.AcceptChallenge(ctx, &zb_calls.AcceptChallengeRequest{
+			UserId:        "player-3",
+			ChallengeCode: challenge.Code,
+			DeckId:        1,
+			Version:       "v1",
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("AcceptChallenge should create a match without the player pool", func(t *testing.T) {
+		challenge, err := createChallenge("player-2")
+		assert.Nil(t, err)
+
+		response, err := c.AcceptChallenge(ctx, &zb_calls.AcceptChallengeRequest{
+			UserId:        "player-2",
+			ChallengeCode: challenge.Code,
+			DeckId:        1,
+			Version:       "v1",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "player-1", response.Match.PlayerStates[0].Id)
+		assert.Equal(t, "player-2", response.Match.PlayerStates[1].Id)
+		assert.Equal(t, zb_data.Match_Matching, response.Match.Status)
+
+		getChallengeResponse, err := c.GetChallenge(ctx, &zb_calls.GetChallengeRequest{ChallengeCode: challenge.Code})
+		assert.Nil(t, err)
+		assert.Equal(t, zb_data.Challenge_Accepted, getChallengeResponse.Challenge.Status)
+		assert.Equal(t, response.Match.Id, getChallengeResponse.Challenge.MatchId)
+
+		playerPoolResponse, err := c.GetPlayerPool(ctx, &zb_calls.PlayerPoolRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(playerPoolResponse.Pool.PlayerProfiles))
+	})
+
+	t.Run("AcceptChallenge while in a match should fail", func(t *testing.T) {
+		challenge, err := createChallenge("player-3")
+		assert.Nil(t, err)
+
+		_, err = c.AcceptChallenge(ctx, &zb_calls.AcceptChallengeRequest{
+			UserId:        "player-3",
+			ChallengeCode: challenge.Code,
+			DeckId:        1,
+			Version:       "v1",
+		})
+		assert.NotNil(t, err)
+	})
+}
@@ -101,6 +101,19 @@ func (z *ZombieBattleground) processOracleCommandResponseGetUserFullCardCollecti
 		}
 	}
 
+	// Add cards crafted, disenchanted or opened from packs on gamechain, Plasmachain doesn't know about them
+	gamechainCardAmountChanges, err := loadGamechainCardAmountChanges(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	for _, gamechainCardAmountChange := range gamechainCardAmountChanges.CardAmountChanges {
+		cardAmountChanges = append(cardAmountChanges, CardAmountChangeItem{
+			CardKey:      gamechainCardAmountChange.CardKey,
+			AmountChange: gamechainCardAmountChange.AmountChange,
+		})
+	}
+
 	cardCollection.Cards, err =
 		z.syncAndSaveCardAmountChangesToCollectionAndUpdateDecks(
 			ctx,
@@ -169,9 +182,20 @@ func loadUserCardCollection(ctx contract.StaticContext, version string, userID s
 			return nil, err
 		}
 
+		cardKeyMigrations, err := loadCardKeyMigrations(ctx, version)
+		if err != nil {
+			return nil, err
+		}
+
+		// Move cards removed from the card library to their replacements
+		migratedCollectionCards := migrateCollectionCardKeys(
+			collectionCardsRaw.Cards,
+			getCardKeyToCardKeyMigrationMap(cardKeyMigrations.Migrations),
+		)
+
 		// Filter out cards not in card library
 		knownCollectionCards := make([]*zb_data.CardCollectionCard, 0)
-		for _, collectionCard := range collectionCardsRaw.Cards {
+		for _, collectionCard := range migratedCollectionCards {
 			_, exists := cardKeyToCardMap[collectionCard.CardKey]
 			if exists {
 				knownCollectionCards = append(knownCollectionCards, collectionCard)
@@ -445,6 +469,72 @@ func (z *ZombieBattleground) applyPendingCardAmountChanges(ctx contract.Context,
 	return userIdFound, nil
 }
 
+// applyGamechainCardAmountChanges applies card amount changes that originate on gamechain.
+// They go through the pending changes of the user address, like the changes coming from the oracle,
+// and are remembered so they survive a full card collection sync.
+func (z *ZombieBattleground) applyGamechainCardAmountChanges(ctx contract.Context, userID string, changes []CardAmountChangeItem) error {
+	userPersistentData, err := loadUserPersistentData(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if userPersistentData.Address == nil {
+		return fmt.Errorf("address of user %s is not known", userID)
+	}
+
+	userAddress := loom.UnmarshalAddressPB(userPersistentData.Address)
+	userIdFound, linkedUserID, err := loadUserIdByAddress(ctx, userAddress)
+	if err != nil {
+		return err
+	}
+
+	if !userIdFound || linkedUserID != userID {
+		return fmt.Errorf("address %s is not linked to user %s", userAddress.String(), userID)
+	}
+
+	pendingCardAmountChanges, err := loadPendingCardAmountChangesContainerByAddress(ctx, userAddress)
+	if err != nil {
+		return err
+	}
+
+	gamechainCardAmountChanges, err := loadGamechainCardAmountChanges(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		addCardAmountChangeItem(pendingCardAmountChanges, change.CardKey, change.AmountChange)
+		addCardAmountChangeItem(gamechainCardAmountChanges, change.CardKey, change.AmountChange)
+	}
+
+	err = savePendingCardAmountChangeItemsContainerByAddress(ctx, userAddress, pendingCardAmountChanges)
+	if err != nil {
+		return err
+	}
+
+	err = saveGamechainCardAmountChanges(ctx, userID, gamechainCardAmountChanges)
+	if err != nil {
+		return err
+	}
+
+	_, err = z.applyPendingCardAmountChanges(ctx, userAddress)
+	return err
+}
+
+func addCardAmountChangeItem(container *zb_data.CardAmountChangeItemsContainer, cardKey battleground_proto.CardKey, amountChange int64) {
+	for _, cardAmountChangeItem := range container.CardAmountChanges {
+		if cardAmountChangeItem.CardKey == cardKey {
+			cardAmountChangeItem.AmountChange += amountChange
+			return
+		}
+	}
+
+	container.CardAmountChanges = append(container.CardAmountChanges, &zb_data.CardAmountChangeItem{
+		CardKey:      cardKey,
+		AmountChange: amountChange,
+	})
+}
+
 func (z *ZombieBattleground) updateCardAmountChangeToAddressToCardAmountsChangeMap(
 	addressToCardAmountsChangeMap addressToCardKeyToAmountChangesMap,
 	from common.LocalAddress,
@@ -495,6 +585,12 @@ func fixDeckListCards(ctx contract.Context, deckList *zb_data.DeckList, version
 		return false, errors.Wrap(err, "error fixing deck list")
 	}
 
+	cardKeyMigrations, err := loadCardKeyMigrations(ctx, version)
+	if err != nil {
+		return false, errors.Wrap(err, "error fixing deck list")
+	}
+	cardKeyToRemapMigrationMap, cardKeyToRetireMigrationMap := splitCardKeyMigrations(cardKeyMigrations.Migrations)
+
 	collectionCards, err := loadUserCardCollection(ctx, version, userID)
 	if err != nil {
 		return false, errors.Wrap(err, "error fixing deck list")
@@ -502,12 +598,29 @@ func fixDeckListCards(ctx contract.Context, deckList *zb_data.DeckList, version
 
 	changed = false
 	for _, deck := range deckList.Decks {
-		if fixDeckCardVariants(deck, cardKeyToCardMap) {
-			changed = true
+		// Each fix is recorded as a deck revision, so players can see why their deck changed
+		fixes := []struct {
+			reason zb_data.DeckRevision_Reason
+			fix    func() bool
+		}{
+			// Migrations run first, otherwise remapped cards would be dropped as unknown
+			{zb_data.DeckRevision_CardRemapped, func() bool { return migrateDeckCardKeys(deck, cardKeyToRemapMigrationMap) }},
+			{zb_data.DeckRevision_CardRetired, func() bool { return migrateDeckCardKeys(deck, cardKeyToRetireMigrationMap) }},
+			{zb_data.DeckRevision_CardRetired, func() bool { return fixDeckCardVariants(deck, cardKeyToCardMap) }},
+			{zb_data.DeckRevision_CollectionShrank, func() bool { return limitDeckByCardCollection(deck, collectionCards) }},
 		}
 
-		if limitDeckByCardCollection(deck, collectionCards) {
+		for _, fix := range fixes {
+			previousDeck := proto.Clone(deck).(*zb_data.Deck)
+			if !fix.fix() {
+				continue
+			}
+
 			changed = true
+			err = addDeckRevision(ctx, userID, previousDeck, deck, fix.reason, 0)
+			if err != nil {
+				return false, errors.Wrap(err, "error fixing deck list")
+			}
 		}
 	}
 
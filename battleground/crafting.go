@@ -0,0 +1,278 @@
+package battleground
+
+import (
+	"fmt"
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	"github.com/pkg/errors"
+	"math"
+)
+
+var (
+	ErrCraftingDisabled = errors.New("crafting is disabled when the card library is used as user collection")
+	ErrNotEnoughDust    = errors.New("not enough dust")
+)
+
+// CraftCard spends dust to add cards to the user collection
+func (z *ZombieBattleground) CraftCard(ctx contract.Context, req *zb_calls.CraftCardRequest) (*zb_calls.CraftCardResponse, error) {
+	if req.Version == "" {
+		return nil, ErrVersionNotSet
+	}
+
+	if !isOwner(ctx, req.UserId) {
+		return nil, ErrUserNotVerified
+	}
+
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("invalid amount %d", req.Amount)
+	}
+
+	card, craftingRate, err := getCraftableCard(ctx, req.Version, req.CardKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// only the standard variant can be crafted, special variants are obtained from packs
+	if card.CardKey.Variant != zb_enums.CardVariant_Standard {
+		return nil, fmt.Errorf("card [%s] can't be crafted", card.CardKey.String())
+	}
+
+	if card.Hidden {
+		return nil, fmt.Errorf("card [%s] can't be crafted", card.CardKey.String())
+	}
+
+	// checked before multiplying, so a huge amount can't overflow the cost
+	userPersistentData, err := loadUserPersistentData(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Amount > userPersistentData.Dust/craftingRate.CraftCost {
+		return nil, ErrNotEnoughDust
+	}
+
+	dustBalance, err := z.applyCraftingChange(
+		ctx,
+		req.UserId,
+		zb_data.CraftingAuditEntry_Craft,
+		req.CardKey,
+		req.Amount,
+		-craftingRate.CraftCost*req.Amount,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zb_calls.CraftCardResponse{
+		Dust: dustBalance,
+	}, nil
+}
+
+// DisenchantCard converts the copies of a card above the deck limit into dust
+func (z *ZombieBattleground) DisenchantCard(ctx contract.Context, req *zb_calls.DisenchantCardRequest) (*zb_calls.DisenchantCardResponse, error) {
+	if req.Version == "" {
+		return nil, ErrVersionNotSet
+	}
+
+	if !isOwner(ctx, req.UserId) {
+		return nil, ErrUserNotVerified
+	}
+
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("invalid amount %d", req.Amount)
+	}
+
+	card, craftingRate, err := getCraftableCard(ctx, req.Version, req.CardKey)
+	if err != nil {
+		return nil, err
+	}
+
+	maxAmountOfCardInDeck, err := getMaxAmountOfCardInDeck(card)
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := loadUserCardCollectionRaw(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	amountInCollection := cardCollectionToCardKeyToAmountMap(collection.Cards)[req.CardKey]
+	if amountInCollection-req.Amount < int64(maxAmountOfCardInDeck) {
+		return nil, fmt.Errorf(
+			"only duplicates of card [%s] can be disenchanted, owned %d, max in deck %d",
+			req.CardKey.String(),
+			amountInCollection,
+			maxAmountOfCardInDeck,
+		)
+	}
+
+	if craftingRate.DisenchantValue > 0 && req.Amount > math.MaxInt64/craftingRate.DisenchantValue {
+		return nil, fmt.Errorf("invalid amount %d", req.Amount)
+	}
+
+	dustBalance, err := z.applyCraftingChange(
+		ctx,
+		req.UserId,
+		zb_data.CraftingAuditEntry_Disenchant,
+		req.CardKey,
+		-req.Amount,
+		craftingRate.DisenchantValue*req.Amount,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zb_calls.DisenchantCardResponse{
+		Dust: dustBalance,
+	}, nil
+}
+
+// GetCraftingAuditLog returns the dust balance and every craft and disenchant of the user
+func (z *ZombieBattleground) GetCraftingAuditLog(ctx contract.StaticContext, req *zb_calls.GetCraftingAuditLogRequest) (*zb_calls.GetCraftingAuditLogResponse, error) {
+	userPersistentData, err := loadUserPersistentData(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	auditLog, err := loadCraftingAuditLog(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zb_calls.GetCraftingAuditLogResponse{
+		Dust:    userPersistentData.Dust,
+		Entries: auditLog.Entries,
+	}, nil
+}
+
+// applyCraftingChange updates the dust balance and the collection, and records the change in the audit log
+func (z *ZombieBattleground) applyCraftingChange(
+	ctx contract.Context,
+	userID string,
+	action zb_data.CraftingAuditEntry_Action,
+	cardKey battleground_proto.CardKey,
+	amountChange int64,
+	dustChange int64,
+) (dustBalance int64, err error) {
+	configuration, err := loadContractConfiguration(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if configuration.UseCardLibraryAsUserCollection {
+		return 0, ErrCraftingDisabled
+	}
+
+	userPersistentData, err := loadUserPersistentData(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if userPersistentData.Dust+dustChange < 0 {
+		return 0, ErrNotEnoughDust
+	}
+
+	err = z.applyGamechainCardAmountChanges(ctx, userID, []CardAmountChangeItem{
+		{CardKey: cardKey, AmountChange: amountChange},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	userPersistentData.Dust += dustChange
+	err = saveUserPersistentData(ctx, userID, userPersistentData)
+	if err != nil {
+		return 0, err
+	}
+
+	// Audit log
+	auditLog, err := loadCraftingAuditLog(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	amount := amountChange
+	if amount < 0 {
+		amount = -amount
+	}
+
+	auditLog.Entries = append(auditLog.Entries, &zb_data.CraftingAuditEntry{
+		Action:      action,
+		CardKey:     cardKey,
+		Amount:      amount,
+		DustChange:  dustChange,
+		DustBalance: userPersistentData.Dust,
+		CreatedAt:   ctx.Now().Unix(),
+	})
+
+	err = saveCraftingAuditLog(ctx, userID, auditLog)
+	if err != nil {
+		return 0, err
+	}
+
+	return userPersistentData.Dust, nil
+}
+
+func getCraftableCard(ctx contract.StaticContext, version string, cardKey battleground_proto.CardKey) (*zb_data.Card, *zb_data.CraftingRate, error) {
+	cardLibrary, err := loadCardLibrary(ctx, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cardKeyToCardMap, err := getCardKeyToCardMap(cardLibrary.Cards)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	card, exists := cardKeyToCardMap[cardKey]
+	if !exists {
+		return nil, nil, fmt.Errorf("card [%s] not found in card library", cardKey.String())
+	}
+
+	craftingData, err := loadCraftingData(ctx, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	craftingRate := getCraftingRate(craftingData, card.Rank)
+	if craftingRate == nil {
+		return nil, nil, fmt.Errorf("no crafting rate for rank %s of card [%s]", card.Rank.String(), cardKey.String())
+	}
+
+	return card, craftingRate, nil
+}
+
+func getCraftingRate(craftingData *zb_data.CraftingData, rank zb_enums.CreatureRank_Enum) *zb_data.CraftingRate {
+	for _, craftingRate := range craftingData.Rates {
+		if craftingRate.Rank == rank {
+			return craftingRate
+		}
+	}
+
+	return nil
+}
+
+func validateCraftingData(craftingData *zb_data.CraftingData) error {
+	existingRanks := make(map[zb_enums.CreatureRank_Enum]bool)
+	for _, craftingRate := range craftingData.Rates {
+		if existingRanks[craftingRate.Rank] {
+			return fmt.Errorf("duplicate crafting rate for rank %s", craftingRate.Rank.String())
+		}
+		existingRanks[craftingRate.Rank] = true
+
+		if craftingRate.CraftCost <= 0 || craftingRate.DisenchantValue < 0 {
+			return fmt.Errorf("invalid crafting rate for rank %s", craftingRate.Rank.String())
+		}
+
+		// otherwise crafting and disenchanting in a loop would generate dust
+		if craftingRate.DisenchantValue > craftingRate.CraftCost {
+			return fmt.Errorf("disenchant value is higher than craft cost for rank %s", craftingRate.Rank.String())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package battleground
+
+import (
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	"github.com/loomnetwork/go-loom"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	assert "github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func TestValidateCraftingData(t *testing.T) {
+	assert.Nil(t, validateCraftingData(&zb_data.CraftingData{}))
+	assert.Nil(t, validateCraftingData(&zb_data.CraftingData{
+		Rates: []*zb_data.CraftingRate{
+			{Rank: zb_enums.CreatureRank_Minion, CraftCost: 40, DisenchantValue: 5},
+			{Rank: zb_enums.CreatureRank_General, CraftCost: 1600, DisenchantValue: 400},
+		},
+	}))
+
+	for _, craftingData := range []*zb_data.CraftingData{
+		{Rates: []*zb_data.CraftingRate{
+			{Rank: zb_enums.CreatureRank_Minion, CraftCost: 40, DisenchantValue: 5},
+			{Rank: zb_enums.CreatureRank_Minion, CraftCost: 50, DisenchantValue: 5},
+		}},
+		{Rates: []*zb_data.CraftingRate{{Rank: zb_enums.CreatureRank_Minion, CraftCost: 0}}},
+		{Rates: []*zb_data.CraftingRate{{Rank: zb_enums.CreatureRank_Minion, CraftCost: 40, DisenchantValue: -1}}},
+		{Rates: []*zb_data.CraftingRate{{Rank: zb_enums.CreatureRank_Minion, CraftCost: 40, DisenchantValue: 50}}},
+	} {
+		assert.NotNil(t, validateCraftingData(craftingData))
+	}
+}
+
+func TestCraftingOperations(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "8996b813617b283f81ea1747fbddbe73fe4b5fce0eac0728e47de51d8e506701"
+	var addr loom.Address
+	var ctx contract.Context
+
+	setup(c, pubKeyHexString, &addr, &ctx, t)
+	setupAccount(c, ctx, &zb_calls.UpsertAccountRequest{
+		UserId:  "CraftUser",
+		Version: "v1",
+	}, t)
+
+	cardKey := battleground_proto.CardKey{MouldId: 1}
+	getCardAmount := func(t *testing.T) int64 {
+		collection, err := loadUserCardCollectionRaw(ctx, "CraftUser")
+		assert.Nil(t, err)
+		return cardCollectionToCardKeyToAmountMap(collection.Cards)[cardKey]
+	}
+
+	t.Run("Crafting rates are part of init data", func(t *testing.T) {
+		response, err := c.GetInit(ctx, &zb_calls.GetInitRequest{Version: "v1"})
+		assert.Nil(t, err)
+		assert.Equal(t, 4, len(response.InitData.Crafting.Rates))
+	})
+
+	t.Run("Disenchanting cards within the deck limit should fail", func(t *testing.T) {
+		assert.Equal(t, int64(4), getCardAmount(t))
+		_, err := c.DisenchantCard(ctx, &zb_calls.DisenchantCardRequest{
+			UserId:  "CraftUser",
+			Version: "v1",
+			CardKey: cardKey,
+			Amount:  1,
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Crafting without dust should fail", func(t *testing.T) {
+		_, err := c.CraftCard(ctx, &zb_calls.CraftCardRequest{
+			UserId:  "CraftUser",
+			Version: "v1",
+			CardKey: cardKey,
+			Amount:  1,
+		})
+		assert.Equal(t, ErrNotEnoughDust, err)
+	})
+
+	t.Run("Crafting unknown card should fail", func(t *testing.T) {
+		_, err := c.CraftCard(ctx, &zb_calls.CraftCardRequest{
+			UserId:  "CraftUser",
+			Version: "v1",
+			CardKey: battleground_proto.CardKey{MouldId: 999999},
+			Amount:  1,
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("DisenchantCard", func(t *testing.T) {
+		collection, err := loadUserCardCollectionRaw(ctx, "CraftUser")
+		assert.Nil(t, err)
+		collection.Cards = c.syncCardAmountChangesToCollection(collection.Cards, []CardAmountChangeItem{
+			{CardKey: cardKey, AmountChange: 2},
+		})
+		err = saveUserCardCollection(ctx, "CraftUser", collection)
+		assert.Nil(t, err)
+
+		response, err := c.DisenchantCard(ctx, &zb_calls.DisenchantCardRequest{
+			UserId:  "CraftUser",
+			Version: "v1",
+			CardKey: cardKey,
+			Amount:  2,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(10), response.Dust)
+		assert.Equal(t, int64(4), getCardAmount(t))
+
+		pendingChanges, err := loadPendingCardAmountChangesContainerByAddress(ctx, addr)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(pendingChanges.CardAmountChanges))
+	})
+
+	t.Run("CraftCard", func(t *testing.T) {
+		userPersistentData, err := loadUserPersistentData(ctx, "CraftUser")
+		assert.Nil(t, err)
+		userPersistentData.Dust = 200
+		err = saveUserPersistentData(ctx, "CraftUser", userPersistentData)
+		assert.Nil(t, err)
+
+		response, err := c.CraftCard(ctx, &zb_calls.CraftCardRequest{
+			UserId:  "CraftUser",
+			Version: "v1",
+			CardKey: cardKey,
+			Amount:  3,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(80), response.Dust)
+		assert.Equal(t, int64(7), getCardAmount(t))
+	})
+
+	t.Run("Crafting an amount whose cost overflows should fail", func(t *testing.T) {
+		_, err := c.CraftCard(ctx, &zb_calls.CraftCardRequest{
+			UserId:  "CraftUser",
+			Version: "v1",
+			CardKey: cardKey,
+			Amount:  math.MaxInt64/40 + 1,
+		})
+		assert.Equal(t, ErrNotEnoughDust, err)
+	})
+
+	t.Run("GetCraftingAuditLog", func(t *testing.T) {
+		response, err := c.GetCraftingAuditLog(ctx, &zb_calls.GetCraftingAuditLogRequest{
+			UserId: "CraftUser",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(80), response.Dust)
+		assert.Equal(t, 2, len(response.Entries))
+
+		assert.Equal(t, zb_data.CraftingAuditEntry_Disenchant, response.Entries[0].Action)
+		assert.Equal(t, cardKey, response.Entries[0].CardKey)
+		assert.Equal(t, int64(2), response.Entries[0].Amount)
+		assert.Equal(t, int64(10), response.Entries[0].DustChange)
+		assert.Equal(t, int64(10), response.Entries[0].DustBalance)
+
+		assert.Equal(t, zb_data.CraftingAuditEntry_Craft, response.Entries[1].Action)
+		assert.Equal(t, int64(3), response.Entries[1].Amount)
+		assert.Equal(t, int64(-120), response.Entries[1].DustChange)
+		assert.Equal(t, int64(80), response.Entries[1].DustBalance)
+	})
+
+	t.Run("Crafted cards are kept after full card collection sync", func(t *testing.T) {
+		err := c.processOracleCommandResponseGetUserFullCardCollection(ctx, addr, nil, 1)
+		assert.Nil(t, err)
+
+		// default collection has 4 copies, 2 were disenchanted and 3 crafted
+		assert.Equal(t, int64(5), getCardAmount(t))
+	})
+
+	t.Run("Crafting is disabled when the card library is used as collection", func(t *testing.T) {
+		configuration, err := loadContractConfiguration(ctx)
+		assert.Nil(t, err)
+		configuration.UseCardLibraryAsUserCollection = true
+		err = saveContractConfiguration(ctx, configuration)
+		assert.Nil(t, err)
+
+		_, err = c.CraftCard(ctx, &zb_calls.CraftCardRequest{
+			UserId:  "CraftUser",
+			Version: "v1",
+			CardKey: cardKey,
+			Amount:  1,
+		})
+		assert.Equal(t, ErrCraftingDisabled, err)
+	})
+}
@@ -0,0 +1,165 @@
+package battleground
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	"github.com/pkg/errors"
+	"math"
+	"sort"
+)
+
+// Deck code layout, all integers are unsigned varints:
+// version byte, overlord id, primary skill, secondary skill, card count,
+// then mould id, variant and amount of each card, followed by the checksum.
+const (
+	DeckCodeVersion = 1
+
+	deckCodeChecksumLength = 4
+	// deckCodeMaxCards guards against huge allocations when decoding malicious codes
+	deckCodeMaxCards = 1000
+)
+
+var (
+	ErrInvalidDeckCode            = errors.New("invalid deck code")
+	ErrDeckCodeChecksum           = errors.New("deck code checksum mismatch")
+	ErrUnsupportedDeckCodeVersion = errors.New("unsupported deck code version")
+)
+
+// EncodeDeckCode returns the shareable code of the deck. Cards are sorted, so the same deck always has the same code.
+// The deck name and id are not part of the code.
+func EncodeDeckCode(deck *zb_data.Deck) (string, error) {
+	cards := make([]*zb_data.DeckCard, 0, len(deck.Cards))
+	for _, deckCard := range deck.Cards {
+		if deckCard.Amount <= 0 {
+			continue
+		}
+
+		if deckCard.CardKey.MouldId <= 0 {
+			return "", fmt.Errorf("mould id not set for card [%v]", deckCard.CardKey)
+		}
+
+		cards = append(cards, deckCard)
+	}
+
+	sort.SliceStable(cards, func(i, j int) bool {
+		return cards[i].CardKey.Compare(&cards[j].CardKey) < 0
+	})
+
+	if deck.OverlordId < 0 {
+		return "", fmt.Errorf("invalid overlord id %d", deck.OverlordId)
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteByte(DeckCodeVersion)
+	writeUvarint(&buffer, uint64(deck.OverlordId))
+	writeUvarint(&buffer, uint64(deck.PrimarySkill))
+	writeUvarint(&buffer, uint64(deck.SecondarySkill))
+	writeUvarint(&buffer, uint64(len(cards)))
+	for _, deckCard := range cards {
+		writeUvarint(&buffer, uint64(deckCard.CardKey.MouldId))
+		writeUvarint(&buffer, uint64(deckCard.CardKey.Variant))
+		writeUvarint(&buffer, uint64(deckCard.Amount))
+	}
+
+	checksum := deckCodeChecksum(buffer.Bytes())
+	buffer.Write(checksum)
+
+	return base64.RawURLEncoding.EncodeToString(buffer.Bytes()), nil
+}
+
+// DecodeDeckCode returns the deck encoded by EncodeDeckCode. The deck has no name and no id.
+func DecodeDeckCode(code string) (*zb_data.Deck, error) {
+	data, err := base64.RawURLEncoding.DecodeString(code)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidDeckCode, err.Error())
+	}
+
+	if len(data) < 1+deckCodeChecksumLength {
+		return nil, ErrInvalidDeckCode
+	}
+
+	payload := data[:len(data)-deckCodeChecksumLength]
+	if !bytes.Equal(deckCodeChecksum(payload), data[len(payload):]) {
+		return nil, ErrDeckCodeChecksum
+	}
+
+	if payload[0] != DeckCodeVersion {
+		return nil, errors.Wrapf(ErrUnsupportedDeckCodeVersion, "version %d", payload[0])
+	}
+
+	reader := bytes.NewReader(payload[1:])
+	var values [4]uint64
+	for i := range values {
+		values[i], err = binary.ReadUvarint(reader)
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidDeckCode, err.Error())
+		}
+	}
+
+	if values[0] > math.MaxInt64 || values[1] > math.MaxInt32 || values[2] > math.MaxInt32 {
+		return nil, errors.Wrap(ErrInvalidDeckCode, "overlord id or skill out of range")
+	}
+
+	cardCount := values[3]
+	if cardCount > deckCodeMaxCards {
+		return nil, errors.Wrapf(ErrInvalidDeckCode, "too many cards (%d)", cardCount)
+	}
+
+	deck := &zb_data.Deck{
+		OverlordId:     int64(values[0]),
+		PrimarySkill:   zb_enums.OverlordSkillType_Enum(values[1]),
+		SecondarySkill: zb_enums.OverlordSkillType_Enum(values[2]),
+		Cards:          make([]*zb_data.DeckCard, 0, cardCount),
+	}
+
+	for i := uint64(0); i < cardCount; i++ {
+		var cardValues [3]uint64
+		for j := range cardValues {
+			cardValues[j], err = binary.ReadUvarint(reader)
+			if err != nil {
+				return nil, errors.Wrap(ErrInvalidDeckCode, err.Error())
+			}
+		}
+
+		if cardValues[0] == 0 || cardValues[0] > math.MaxInt64 {
+			return nil, errors.Wrapf(ErrInvalidDeckCode, "invalid mould id %d", cardValues[0])
+		}
+		if cardValues[1] > math.MaxInt32 {
+			return nil, errors.Wrapf(ErrInvalidDeckCode, "invalid variant %d", cardValues[1])
+		}
+		if cardValues[2] == 0 || cardValues[2] > deckCodeMaxCards {
+			return nil, errors.Wrapf(ErrInvalidDeckCode, "invalid amount %d", cardValues[2])
+		}
+
+		deck.Cards = append(deck.Cards, &zb_data.DeckCard{
+			CardKey: battleground_proto.CardKey{
+				MouldId: int64(cardValues[0]),
+				Variant: zb_enums.CardVariant_Enum(cardValues[1]),
+			},
+			Amount: int64(cardValues[2]),
+		})
+	}
+
+	if reader.Len() > 0 {
+		return nil, errors.Wrap(ErrInvalidDeckCode, "unexpected trailing data")
+	}
+
+	return deck, nil
+}
+
+func deckCodeChecksum(payload []byte) []byte {
+	hash := sha256.Sum256(payload)
+	return hash[:deckCodeChecksumLength]
+}
+
+func writeUvarint(buffer *bytes.Buffer, value uint64) {
+	var encoded [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(encoded[:], value)
+	buffer.Write(encoded[:n])
+}
@@ -0,0 +1,196 @@
+package battleground
+
+import (
+	"bytes"
+	"encoding/base64"
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	"github.com/loomnetwork/go-loom"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	"github.com/pkg/errors"
+	assert "github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func TestDeckCode(t *testing.T) {
+	deck := &zb_data.Deck{
+		Id:             5,
+		Name:           "Shared",
+		OverlordId:     3,
+		PrimarySkill:   zb_enums.OverlordSkillType_Fireball,
+		SecondarySkill: zb_enums.OverlordSkillType_FireBolt,
+		Cards: []*zb_data.DeckCard{
+			{CardKey: battleground_proto.CardKey{MouldId: 300, Variant: zb_enums.CardVariant_Backer}, Amount: 1},
+			{CardKey: battleground_proto.CardKey{MouldId: 12}, Amount: 4},
+			{CardKey: battleground_proto.CardKey{MouldId: 300}, Amount: 2},
+			{CardKey: battleground_proto.CardKey{MouldId: 7}, Amount: 0},
+		},
+	}
+
+	code, err := EncodeDeckCode(deck)
+	assert.Nil(t, err)
+
+	t.Run("Round trip", func(t *testing.T) {
+		decodedDeck, err := DecodeDeckCode(code)
+		assert.Nil(t, err)
+		assert.Equal(t, "", decodedDeck.Name)
+		assert.Equal(t, int64(0), decodedDeck.Id)
+		assert.Equal(t, deck.OverlordId, decodedDeck.OverlordId)
+		assert.Equal(t, deck.PrimarySkill, decodedDeck.PrimarySkill)
+		assert.Equal(t, deck.SecondarySkill, decodedDeck.SecondarySkill)
+		assert.Equal(t, []*zb_data.DeckCard{
+			{CardKey: battleground_proto.CardKey{MouldId: 12}, Amount: 4},
+			{CardKey: battleground_proto.CardKey{MouldId: 300}, Amount: 2},
+			{CardKey: battleground_proto.CardKey{MouldId: 300, Variant: zb_enums.CardVariant_Backer}, Amount: 1},
+		}, decodedDeck.Cards)
+	})
+
+	t.Run("Card order doesn't change the code", func(t *testing.T) {
+		reversedDeck := *deck
+		reversedDeck.Cards = []*zb_data.DeckCard{deck.Cards[2], deck.Cards[1], deck.Cards[0]}
+		reversedCode, err := EncodeDeckCode(&reversedDeck)
+		assert.Nil(t, err)
+		assert.Equal(t, code, reversedCode)
+	})
+
+	t.Run("Tampered code should fail", func(t *testing.T) {
+		data, err := base64.RawURLEncoding.DecodeString(code)
+		assert.Nil(t, err)
+		data[3]++
+
+		_, err = DecodeDeckCode(base64.RawURLEncoding.EncodeToString(data))
+		assert.Equal(t, ErrDeckCodeChecksum, err)
+	})
+
+	t.Run("Unknown version should fail", func(t *testing.T) {
+		data, err := base64.RawURLEncoding.DecodeString(code)
+		assert.Nil(t, err)
+		payload := append([]byte{DeckCodeVersion + 1}, data[1:len(data)-deckCodeChecksumLength]...)
+		payload = append(payload, deckCodeChecksum(payload)...)
+
+		_, err = DecodeDeckCode(base64.RawURLEncoding.EncodeToString(payload))
+		assert.Equal(t, ErrUnsupportedDeckCodeVersion, errors.Cause(err))
+	})
+
+	t.Run("Out of range values should fail", func(t *testing.T) {
+		encode := func(values ...uint64) string {
+			var buffer bytes.Buffer
+			buffer.WriteByte(DeckCodeVersion)
+			for _, value := range values {
+				writeUvarint(&buffer, value)
+			}
+			buffer.Write(deckCodeChecksum(buffer.Bytes()))
+			return base64.RawURLEncoding.EncodeToString(buffer.Bytes())
+		}
+
+		_, err := DecodeDeckCode(encode(3, 0, 0, 1, 12, 0, 1))
+		assert.Nil(t, err)
+
+		for _, invalidCode := range []string{
+			encode(math.MaxUint64, 0, 0, 1, 12, 0, 1),
+			encode(3, 0, 0, 1, 0, 0, 1),
+			encode(3, 0, 0, 1, math.MaxUint64, 0, 1),
+			encode(3, 0, 0, 1, 12, math.MaxUint64, 1),
+			encode(3, 0, 0, 1, 12, 0, 0),
+			encode(3, 0, 0, 1, 12, 0, deckCodeMaxCards+1),
+			encode(3, 0, 0, 1, 12, 0, math.MaxUint64),
+		} {
+			_, err := DecodeDeckCode(invalidCode)
+			assert.Equal(t, ErrInvalidDeckCode, errors.Cause(err))
+		}
+	})
+
+	t.Run("Malformed code should fail", func(t *testing.T) {
+		for _, malformedCode := range []string{"", "!!!", "AQ", base64.RawURLEncoding.EncodeToString([]byte{DeckCodeVersion, 1, 2, 3, 4, 5})} {
+			_, err := DecodeDeckCode(malformedCode)
+			assert.NotNil(t, err)
+		}
+	})
+}
+
+func TestDeckCodeOperations(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "7796b813617b283f81ea1747fbddbe73fe4b5fce0eac0728e47de51d8e506701"
+	var addr loom.Address
+	var ctx contract.Context
+
+	setup(c, pubKeyHexString, &addr, &ctx, t)
+	setupAccount(c, ctx, &zb_calls.UpsertAccountRequest{
+		UserId:  "DeckUser",
+		Version: "v1",
+	}, t)
+
+	var deckCode string
+	t.Run("ExportDeck", func(t *testing.T) {
+		response, err := c.ExportDeck(ctx, &zb_calls.ExportDeckRequest{
+			UserId:  "DeckUser",
+			DeckId:  1,
+			Version: "v1",
+		})
+		assert.Nil(t, err)
+		assert.NotEmpty(t, response.DeckCode)
+		deckCode = response.DeckCode
+	})
+
+	t.Run("ExportDeck of unknown deck should fail", func(t *testing.T) {
+		_, err := c.ExportDeck(ctx, &zb_calls.ExportDeckRequest{
+			UserId:  "DeckUser",
+			DeckId:  100,
+			Version: "v1",
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ImportDeck", func(t *testing.T) {
+		response, err := c.ImportDeck(ctx, &zb_calls.ImportDeckRequest{
+			UserId:   "DeckUser",
+			DeckCode: deckCode,
+			Name:     "Imported",
+			Version:  "v1",
+		})
+		assert.Nil(t, err)
+
+		getDeckResponse, err := c.GetDeck(ctx, &zb_calls.GetDeckRequest{
+			UserId:  "DeckUser",
+			DeckId:  response.DeckId,
+			Version: "v1",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "Imported", getDeckResponse.Deck.Name)
+
+		importedDeckCode, err := EncodeDeckCode(getDeckResponse.Deck)
+		assert.Nil(t, err)
+		assert.Equal(t, deckCode, importedDeckCode)
+	})
+
+	t.Run("ImportDeck with cards missing from the collection should fail", func(t *testing.T) {
+		deckCode, err := EncodeDeckCode(&zb_data.Deck{
+			OverlordId: 1,
+			Cards: []*zb_data.DeckCard{
+				{CardKey: battleground_proto.CardKey{MouldId: 4}, Amount: 100},
+			},
+		})
+		assert.Nil(t, err)
+
+		_, err = c.ImportDeck(ctx, &zb_calls.ImportDeckRequest{
+			UserId:   "DeckUser",
+			DeckCode: deckCode,
+			Name:     "TooMany",
+			Version:  "v1",
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ImportDeck with invalid code should fail", func(t *testing.T) {
+		_, err := c.ImportDeck(ctx, &zb_calls.ImportDeckRequest{
+			UserId:   "DeckUser",
+			DeckCode: "invalid",
+			Name:     "Invalid",
+			Version:  "v1",
+		})
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,237 @@
+package battleground
+
+import (
+	"fmt"
+	"github.com/gogo/protobuf/proto"
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	"github.com/pkg/errors"
+	"sort"
+)
+
+// MaxDeckRevisions is the number of revisions kept for each deck, older revisions are dropped
+const MaxDeckRevisions = 20
+
+// ListDeckRevisions returns the kept revisions of a deck, oldest first
+func (z *ZombieBattleground) ListDeckRevisions(ctx contract.StaticContext, req *zb_calls.ListDeckRevisionsRequest) (*zb_calls.ListDeckRevisionsResponse, error) {
+	deckRevisionHistoryList, err := loadDeckRevisionHistoryList(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	deckRevisionHistory := getDeckRevisionHistoryByID(deckRevisionHistoryList, req.DeckId)
+	if deckRevisionHistory == nil {
+		return &zb_calls.ListDeckRevisionsResponse{
+			Revisions: []*zb_data.DeckRevision{},
+		}, nil
+	}
+
+	return &zb_calls.ListDeckRevisionsResponse{
+		Revisions: deckRevisionHistory.Revisions,
+	}, nil
+}
+
+// RestoreDeckRevision replaces the deck with the contents of an earlier revision
+func (z *ZombieBattleground) RestoreDeckRevision(ctx contract.Context, req *zb_calls.RestoreDeckRevisionRequest) (*zb_calls.RestoreDeckRevisionResponse, error) {
+	if req.Version == "" {
+		return nil, ErrVersionNotSet
+	}
+	if !isOwner(ctx, req.UserId) {
+		return nil, ErrUserNotVerified
+	}
+
+	deckRevisionHistoryList, err := loadDeckRevisionHistoryList(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	deckRevisionHistory := getDeckRevisionHistoryByID(deckRevisionHistoryList, req.DeckId)
+	if deckRevisionHistory == nil {
+		return nil, fmt.Errorf("deck %d has no revisions", req.DeckId)
+	}
+
+	var deckRevision *zb_data.DeckRevision
+	for _, existingDeckRevision := range deckRevisionHistory.Revisions {
+		if existingDeckRevision.Revision == req.Revision {
+			deckRevision = existingDeckRevision
+			break
+		}
+	}
+
+	if deckRevision == nil {
+		return nil, fmt.Errorf("revision %d of deck %d not found", req.Revision, req.DeckId)
+	}
+
+	overlords, err := loadOverlordUserInstances(ctx, req.Version, req.UserId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get overlord instances for userId: %s", req.UserId)
+	}
+
+	deckList, err := loadDecks(ctx, req.Version, req.UserId)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load decks")
+	}
+
+	existingDeck := getDeckByID(deckList.Decks, req.DeckId)
+	if existingDeck == nil {
+		return nil, ErrNotFound
+	}
+
+	cardLibrary, err := loadCardLibrary(ctx, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	userCardCollection, err := loadUserCardCollection(ctx, req.Version, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	configuration, err := loadContractConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	restoredDeck := proto.Clone(deckRevision.Deck).(*zb_data.Deck)
+	restoredDeck.Id = req.DeckId
+
+	err = validateDeck(true, cardLibrary, userCardCollection, restoredDeck, deckList.Decks, overlords, getDeckRules(configuration, DefaultDeckRulesName))
+	if err != nil {
+		return nil, errors.Wrapf(err, "revision %d can't be restored", req.Revision)
+	}
+
+	restoredDeck.LegalFormats, err = getDeckLegalFormats(configuration, cardLibrary.Cards, restoredDeck, overlords)
+	if err != nil {
+		return nil, err
+	}
+
+	previousDeck := proto.Clone(existingDeck).(*zb_data.Deck)
+	existingDeck.Reset()
+	proto.Merge(existingDeck, restoredDeck)
+
+	if err := saveDecks(ctx, req.Version, req.UserId, deckList); err != nil {
+		return nil, errors.Wrap(err, "error saving decks")
+	}
+
+	err = addDeckRevision(ctx, req.UserId, previousDeck, existingDeck, zb_data.DeckRevision_Restored, req.Revision)
+	if err != nil {
+		return nil, err
+	}
+
+	emitMsg := zb_calls.EditDeckEvent{
+		UserId:        req.UserId,
+		SenderAddress: ctx.Message().Sender.Local.String(),
+		Deck:          existingDeck,
+		Version:       req.Version,
+	}
+
+	data, err := proto.Marshal(&emitMsg)
+	if err != nil {
+		return nil, err
+	}
+	ctx.EmitTopics(data, TopicEditDeckEvent)
+
+	return &zb_calls.RestoreDeckRevisionResponse{
+		Deck: existingDeck,
+	}, nil
+}
+
+// addDeckRevision records the new state of a deck. previousDeck is nil for new decks,
+// otherwise it is recorded as a baseline if the deck has no revisions yet.
+func addDeckRevision(
+	ctx contract.Context,
+	userID string,
+	previousDeck *zb_data.Deck,
+	deck *zb_data.Deck,
+	reason zb_data.DeckRevision_Reason,
+	restoredRevision int64,
+) error {
+	deckRevisionHistoryList, err := loadDeckRevisionHistoryList(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	deckRevisionHistory := getDeckRevisionHistoryByID(deckRevisionHistoryList, deck.Id)
+	if deckRevisionHistory == nil {
+		deckRevisionHistory = &zb_data.DeckRevisionHistory{
+			DeckId: deck.Id,
+		}
+		deckRevisionHistoryList.Decks = append(deckRevisionHistoryList.Decks, deckRevisionHistory)
+	}
+
+	appendRevision := func(revision *zb_data.DeckRevision) {
+		deckRevisionHistory.LastRevision++
+		revision.Revision = deckRevisionHistory.LastRevision
+		revision.CreatedAt = ctx.Now().Unix()
+		deckRevisionHistory.Revisions = append(deckRevisionHistory.Revisions, revision)
+	}
+
+	if previousDeck != nil && len(deckRevisionHistory.Revisions) == 0 {
+		appendRevision(&zb_data.DeckRevision{
+			Reason: zb_data.DeckRevision_Baseline,
+			Deck:   proto.Clone(previousDeck).(*zb_data.Deck),
+		})
+	}
+
+	revision := &zb_data.DeckRevision{
+		Reason:           reason,
+		Deck:             proto.Clone(deck).(*zb_data.Deck),
+		RestoredRevision: restoredRevision,
+	}
+	if previousDeck != nil {
+		revision.Changes = getDeckCardChanges(previousDeck, deck)
+	}
+	appendRevision(revision)
+
+	if len(deckRevisionHistory.Revisions) > MaxDeckRevisions {
+		deckRevisionHistory.Revisions = deckRevisionHistory.Revisions[len(deckRevisionHistory.Revisions)-MaxDeckRevisions:]
+	}
+
+	return saveDeckRevisionHistoryList(ctx, userID, deckRevisionHistoryList)
+}
+
+func getDeckCardChanges(previousDeck *zb_data.Deck, deck *zb_data.Deck) []*zb_data.DeckCardChange {
+	cardKeyToChange := make(map[battleground_proto.CardKey]*zb_data.DeckCardChange)
+	for _, deckCard := range previousDeck.Cards {
+		cardKeyToChange[deckCard.CardKey] = &zb_data.DeckCardChange{
+			CardKey:   deckCard.CardKey,
+			OldAmount: deckCard.Amount,
+		}
+	}
+
+	for _, deckCard := range deck.Cards {
+		change, exists := cardKeyToChange[deckCard.CardKey]
+		if !exists {
+			change = &zb_data.DeckCardChange{
+				CardKey: deckCard.CardKey,
+			}
+			cardKeyToChange[deckCard.CardKey] = change
+		}
+		change.NewAmount = deckCard.Amount
+	}
+
+	changes := make([]*zb_data.DeckCardChange, 0)
+	for _, change := range cardKeyToChange {
+		if change.OldAmount != change.NewAmount {
+			changes = append(changes, change)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].CardKey.Compare(&changes[j].CardKey) < 0
+	})
+
+	return changes
+}
+
+func getDeckRevisionHistoryByID(deckRevisionHistoryList *zb_data.DeckRevisionHistoryList, deckID int64) *zb_data.DeckRevisionHistory {
+	for _, deckRevisionHistory := range deckRevisionHistoryList.Decks {
+		if deckRevisionHistory.DeckId == deckID {
+			return deckRevisionHistory
+		}
+	}
+
+	return nil
+}
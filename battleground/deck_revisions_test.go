@@ -0,0 +1,207 @@
+package battleground
+
+import (
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"github.com/loomnetwork/go-loom"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	assert "github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGetDeckCardChanges(t *testing.T) {
+	previousDeck := &zb_data.Deck{
+		Cards: []*zb_data.DeckCard{
+			{CardKey: battleground_proto.CardKey{MouldId: 3}, Amount: 2},
+			{CardKey: battleground_proto.CardKey{MouldId: 1}, Amount: 2},
+			{CardKey: battleground_proto.CardKey{MouldId: 2}, Amount: 1},
+		},
+	}
+	deck := &zb_data.Deck{
+		Cards: []*zb_data.DeckCard{
+			{CardKey: battleground_proto.CardKey{MouldId: 1}, Amount: 2},
+			{CardKey: battleground_proto.CardKey{MouldId: 2}, Amount: 3},
+			{CardKey: battleground_proto.CardKey{MouldId: 4}, Amount: 1},
+		},
+	}
+
+	changes := getDeckCardChanges(previousDeck, deck)
+	assert.Equal(t, []*zb_data.DeckCardChange{
+		{CardKey: battleground_proto.CardKey{MouldId: 2}, OldAmount: 1, NewAmount: 3},
+		{CardKey: battleground_proto.CardKey{MouldId: 3}, OldAmount: 2, NewAmount: 0},
+		{CardKey: battleground_proto.CardKey{MouldId: 4}, OldAmount: 0, NewAmount: 1},
+	}, changes)
+}
+
+func TestDeckRevisionOperations(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "a3b1c0f5d9e4e8f7c2b6a1d0e9f8c7b6a5d4c3b2a1f0e9d8c7b6a5f4e3d2c1b0"
+	var addr loom.Address
+	var ctx contract.Context
+
+	setup(c, pubKeyHexString, &addr, &ctx, t)
+	setupAccount(c, ctx, &zb_calls.UpsertAccountRequest{
+		UserId:  "RevisionUser",
+		Version: "v1",
+	}, t)
+
+	listRevisions := func(t *testing.T, deckID int64) []*zb_data.DeckRevision {
+		response, err := c.ListDeckRevisions(ctx, &zb_calls.ListDeckRevisionsRequest{
+			UserId: "RevisionUser",
+			DeckId: deckID,
+		})
+		assert.Nil(t, err)
+		return response.Revisions
+	}
+
+	newDeck := func(amount int64) *zb_data.Deck {
+		return &zb_data.Deck{
+			Name:       "RevisionDeck",
+			OverlordId: 1,
+			Cards: []*zb_data.DeckCard{
+				{CardKey: battleground_proto.CardKey{MouldId: 1}, Amount: amount},
+				{CardKey: battleground_proto.CardKey{MouldId: 48}, Amount: 1},
+			},
+		}
+	}
+
+	var deckID int64
+	t.Run("CreateDeck records a revision", func(t *testing.T) {
+		response, err := c.CreateDeck(ctx, &zb_calls.CreateDeckRequest{
+			UserId:  "RevisionUser",
+			Version: "v1",
+			Deck:    newDeck(2),
+		})
+		assert.Nil(t, err)
+		deckID = response.DeckId
+
+		revisions := listRevisions(t, deckID)
+		assert.Equal(t, 1, len(revisions))
+		assert.Equal(t, int64(1), revisions[0].Revision)
+		assert.Equal(t, zb_data.DeckRevision_Created, revisions[0].Reason)
+		assert.Equal(t, 0, len(revisions[0].Changes))
+	})
+
+	t.Run("Editing a deck without revisions records a baseline", func(t *testing.T) {
+		assert.Equal(t, 0, len(listRevisions(t, 1)))
+
+		deck := newDeck(1)
+		deck.Id = 1
+		deck.Name = "Default"
+		_, err := c.EditDeck(ctx, &zb_calls.EditDeckRequest{
+			UserId:  "RevisionUser",
+			Version: "v1",
+			Deck:    deck,
+		})
+		assert.Nil(t, err)
+
+		revisions := listRevisions(t, 1)
+		assert.Equal(t, 2, len(revisions))
+		assert.Equal(t, zb_data.DeckRevision_Baseline, revisions[0].Reason)
+		assert.Equal(t, zb_data.DeckRevision_Edited, revisions[1].Reason)
+	})
+
+	t.Run("EditDeck records a revision", func(t *testing.T) {
+		deck := newDeck(3)
+		deck.Id = deckID
+		_, err := c.EditDeck(ctx, &zb_calls.EditDeckRequest{
+			UserId:  "RevisionUser",
+			Version: "v1",
+			Deck:    deck,
+		})
+		assert.Nil(t, err)
+
+		revisions := listRevisions(t, deckID)
+		assert.Equal(t, 2, len(revisions))
+		assert.Equal(t, zb_data.DeckRevision_Edited, revisions[1].Reason)
+		assert.Equal(t, []*zb_data.DeckCardChange{
+			{CardKey: battleground_proto.CardKey{MouldId: 1}, OldAmount: 2, NewAmount: 3},
+		}, revisions[1].Changes)
+	})
+
+	t.Run("RestoreDeckRevision", func(t *testing.T) {
+		response, err := c.RestoreDeckRevision(ctx, &zb_calls.RestoreDeckRevisionRequest{
+			UserId:   "RevisionUser",
+			Version:  "v1",
+			DeckId:   deckID,
+			Revision: 1,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, deckID, response.Deck.Id)
+		assert.Equal(t, int64(2), response.Deck.Cards[0].Amount)
+
+		revisions := listRevisions(t, deckID)
+		assert.Equal(t, 3, len(revisions))
+		assert.Equal(t, zb_data.DeckRevision_Restored, revisions[2].Reason)
+		assert.Equal(t, int64(1), revisions[2].RestoredRevision)
+
+		_, err = c.RestoreDeckRevision(ctx, &zb_calls.RestoreDeckRevisionRequest{
+			UserId:   "RevisionUser",
+			Version:  "v1",
+			DeckId:   deckID,
+			Revision: 100,
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Automated removal is recorded with a reason", func(t *testing.T) {
+		collection, err := loadUserCardCollectionRaw(ctx, "RevisionUser")
+		assert.Nil(t, err)
+		for _, collectionCard := range collection.Cards {
+			if collectionCard.CardKey.MouldId == 1 {
+				collectionCard.Amount = 1
+			}
+		}
+		err = saveUserCardCollection(ctx, "RevisionUser", collection)
+		assert.Nil(t, err)
+
+		deckList, err := loadDecks(ctx, "v1", "RevisionUser")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), getDeckByID(deckList.Decks, deckID).Cards[0].Amount)
+
+		revisions := listRevisions(t, deckID)
+		assert.Equal(t, 4, len(revisions))
+		assert.Equal(t, zb_data.DeckRevision_CollectionShrank, revisions[3].Reason)
+		assert.Equal(t, []*zb_data.DeckCardChange{
+			{CardKey: battleground_proto.CardKey{MouldId: 1}, OldAmount: 2, NewAmount: 1},
+		}, revisions[3].Changes)
+
+		// not enough cards in collection anymore
+		_, err = c.RestoreDeckRevision(ctx, &zb_calls.RestoreDeckRevisionRequest{
+			UserId:   "RevisionUser",
+			Version:  "v1",
+			DeckId:   deckID,
+			Revision: 2,
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Only the latest revisions are kept", func(t *testing.T) {
+		for i := 0; i < MaxDeckRevisions; i++ {
+			deck := newDeck(1)
+			deck.Id = deckID
+			deck.Cards[1].Amount = int64(i%2) + 2
+			_, err := c.EditDeck(ctx, &zb_calls.EditDeckRequest{
+				UserId:  "RevisionUser",
+				Version: "v1",
+				Deck:    deck,
+			})
+			assert.Nil(t, err)
+		}
+
+		revisions := listRevisions(t, deckID)
+		assert.Equal(t, MaxDeckRevisions, len(revisions))
+		assert.Equal(t, int64(4+MaxDeckRevisions), revisions[MaxDeckRevisions-1].Revision)
+	})
+
+	t.Run("DeleteDeck removes revisions", func(t *testing.T) {
+		err := c.DeleteDeck(ctx, &zb_calls.DeleteDeckRequest{
+			UserId:  "RevisionUser",
+			Version: "v1",
+			DeckId:  deckID,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(listRevisions(t, deckID)))
+	})
+}
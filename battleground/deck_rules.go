@@ -0,0 +1,368 @@
+package battleground
+
+import (
+	"fmt"
+	"github.com/gogo/protobuf/proto"
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	"github.com/loomnetwork/go-loom"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// DefaultDeckRulesName is the name of the rules every deck has to follow
+const DefaultDeckRulesName = "default"
+
+// DeckLegalityError lists every rule violated by a deck
+type DeckLegalityError struct {
+	Issues []*zb_data.DeckLegalityIssue
+}
+
+func (e *DeckLegalityError) Error() string {
+	messages := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		messages = append(messages, issue.Message)
+	}
+
+	return "deck is not legal: " + strings.Join(messages, "; ")
+}
+
+func getDeckRules(configuration *zb_data.ContractConfiguration, name string) *zb_data.DeckRules {
+	if configuration == nil {
+		return nil
+	}
+
+	return getDeckRulesFromList(configuration.DeckRules, name)
+}
+
+func getFormat(configuration *zb_data.ContractConfiguration, name string) *zb_data.DeckRules {
+	if configuration == nil {
+		return nil
+	}
+
+	return getDeckRulesFromList(configuration.Formats, name)
+}
+
+func getDeckRulesFromList(deckRulesList []*zb_data.DeckRules, name string) *zb_data.DeckRules {
+	for _, deckRules := range deckRulesList {
+		if deckRules.Name == name {
+			return deckRules
+		}
+	}
+
+	return nil
+}
+
+// getGameModeDeckRules returns the rules of the custom game mode, or nil if the game mode has none
+func getGameModeDeckRules(ctx contract.StaticContext, configuration *zb_data.ContractConfiguration, customGameAddress loom.Address) (*zb_data.DeckRules, error) {
+	gameModeList, err := loadGameModeList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gameMode := getGameModeFromListByAddress(gameModeList, customGameAddress)
+	if gameMode == nil {
+		return nil, fmt.Errorf("custom game mode %s not found", customGameAddress.Local.String())
+	}
+
+	return getDeckRules(configuration, gameMode.ID), nil
+}
+
+func validateDeckRulesConfiguration(deckRules *zb_data.DeckRules) error {
+	if deckRules == nil {
+		return fmt.Errorf("DeckRules == nil")
+	}
+
+	if deckRules.Name == "" {
+		return fmt.Errorf("deck rules name is not set")
+	}
+
+	for _, copyLimit := range deckRules.CopyLimits {
+		if copyLimit.MaxCopies < 0 {
+			return fmt.Errorf("copy limit of rank %s can't be negative", copyLimit.Rank.String())
+		}
+	}
+
+	return nil
+}
+
+// setDeckRulesInList replaces the rules with the same name, or appends the rules if there are none
+func setDeckRulesInList(deckRulesList []*zb_data.DeckRules, deckRules *zb_data.DeckRules) []*zb_data.DeckRules {
+	for _, existingDeckRules := range deckRulesList {
+		if existingDeckRules.Name == deckRules.Name {
+			existingDeckRules.Reset()
+			proto.Merge(existingDeckRules, deckRules)
+			return deckRulesList
+		}
+	}
+
+	return append(deckRulesList, deckRules)
+}
+
+func getDeckRulesMaxCopies(deckRules *zb_data.DeckRules, card *zb_data.Card) (int, error) {
+	if card.Faction != zb_enums.Faction_Item {
+		for _, copyLimit := range deckRules.CopyLimits {
+			if copyLimit.Rank == card.Rank {
+				return int(copyLimit.MaxCopies), nil
+			}
+		}
+	}
+
+	return getMaxAmountOfCardInDeck(card)
+}
+
+// getDeckLegalityIssues checks the deck against the rules and returns every violation found
+func getDeckLegalityIssues(deckRules *zb_data.DeckRules, cardLibrary []*zb_data.Card, deck *zb_data.Deck, overlords []*zb_data.OverlordUserInstance) ([]*zb_data.DeckLegalityIssue, error) {
+	var issues []*zb_data.DeckLegalityIssue
+	addIssue := func(reason zb_data.DeckLegalityIssue_Reason, cardKey battleground_proto.CardKey, format string, args ...interface{}) {
+		issues = append(issues, &zb_data.DeckLegalityIssue{
+			Reason:  reason,
+			CardKey: cardKey,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	cardKeyToCard, err := getCardKeyToCardMap(cardLibrary)
+	if err != nil {
+		return nil, err
+	}
+
+	var overlord *zb_data.OverlordUserInstance
+	for _, overlordUserInstance := range overlords {
+		if overlordUserInstance.Prototype.Id == deck.OverlordId {
+			overlord = overlordUserInstance
+			break
+		}
+	}
+
+	if overlord == nil && (deckRules.RestrictFactionToOverlord || deckRules.RequireUnlockedSkills) {
+		addIssue(zb_data.DeckLegalityIssue_OverlordNotFound, battleground_proto.CardKey{}, "overlord %d not found", deck.OverlordId)
+	}
+
+	bannedCards := make(map[battleground_proto.CardKey]bool)
+	for _, cardKey := range deckRules.BannedCards {
+		bannedCards[cardKey] = true
+	}
+
+	restrictedCards := make(map[battleground_proto.CardKey]bool)
+	for _, cardKey := range deckRules.RestrictedCards {
+		restrictedCards[cardKey] = true
+	}
+
+	allowedSets := make(map[zb_enums.CardSet_Enum]bool)
+	for _, set := range deckRules.AllowedSets {
+		allowedSets[set] = true
+	}
+
+	// variants of the same card count towards the same copy limit
+	mouldIdToAmount := make(map[int64]int64)
+	var deckSize int64
+	for _, deckCard := range deck.Cards {
+		deckSize += deckCard.Amount
+		mouldIdToAmount[deckCard.CardKey.MouldId] += deckCard.Amount
+
+		card, exists := cardKeyToCard[deckCard.CardKey]
+		if !exists {
+			addIssue(zb_data.DeckLegalityIssue_CardNotFound, deckCard.CardKey, "card [%s] not found in card library", deckCard.CardKey.String())
+			continue
+		}
+
+		if card.Hidden && !deckRules.AllowHiddenCards {
+			addIssue(zb_data.DeckLegalityIssue_HiddenCard, deckCard.CardKey, "card [%s] is hidden", deckCard.CardKey.String())
+		}
+
+		if isCardKeyListed(bannedCards, deckCard.CardKey) {
+			addIssue(zb_data.DeckLegalityIssue_BannedCard, deckCard.CardKey, "card [%s] is banned", deckCard.CardKey.String())
+		}
+
+		if len(allowedSets) > 0 && !allowedSets[card.Set] {
+			addIssue(zb_data.DeckLegalityIssue_CardSetNotAllowed, deckCard.CardKey, "card [%s] of set %s is not allowed", deckCard.CardKey.String(), card.Set.String())
+		}
+
+		if deckRules.RestrictFactionToOverlord && overlord != nil && card.Faction != zb_enums.Faction_Item && card.Faction != overlord.Prototype.Faction {
+			addIssue(
+				zb_data.DeckLegalityIssue_Faction,
+				deckCard.CardKey,
+				"card [%s] of faction %s can't be used by overlord %d of faction %s",
+				deckCard.CardKey.String(),
+				card.Faction.String(),
+				overlord.Prototype.Id,
+				overlord.Prototype.Faction.String(),
+			)
+		}
+	}
+
+	if len(restrictedCards) > 0 {
+		reportedMouldIds := make(map[int64]bool)
+		for _, deckCard := range deck.Cards {
+			amount := mouldIdToAmount[deckCard.CardKey.MouldId]
+			if amount <= 1 || reportedMouldIds[deckCard.CardKey.MouldId] || !isCardKeyListed(restrictedCards, deckCard.CardKey) {
+				continue
+			}
+
+			addIssue(zb_data.DeckLegalityIssue_RestrictedCard, deckCard.CardKey, "card [%s] is restricted: %d copies in deck, at most 1 allowed", deckCard.CardKey.String(), amount)
+			reportedMouldIds[deckCard.CardKey.MouldId] = true
+		}
+	}
+
+	if deckRules.EnforceCopyLimits {
+		for _, deckCard := range deck.Cards {
+			card, exists := cardKeyToCard[deckCard.CardKey]
+			if !exists || mouldIdToAmount[deckCard.CardKey.MouldId] == 0 {
+				continue
+			}
+
+			maxCopies, err := getDeckRulesMaxCopies(deckRules, card)
+			if err != nil {
+				return nil, err
+			}
+
+			amount := mouldIdToAmount[deckCard.CardKey.MouldId]
+			if amount > int64(maxCopies) {
+				addIssue(zb_data.DeckLegalityIssue_CopyLimit, deckCard.CardKey, "card [%s]: %d copies in deck, at most %d allowed", deckCard.CardKey.String(), amount, maxCopies)
+			}
+
+			// only report a card once, even when several variants are in the deck
+			mouldIdToAmount[deckCard.CardKey.MouldId] = 0
+		}
+	}
+
+	if deckRules.MinDeckSize > 0 && deckSize < int64(deckRules.MinDeckSize) {
+		addIssue(zb_data.DeckLegalityIssue_DeckSize, battleground_proto.CardKey{}, "deck has %d cards, at least %d required", deckSize, deckRules.MinDeckSize)
+	}
+
+	if deckRules.MaxDeckSize > 0 && deckSize > int64(deckRules.MaxDeckSize) {
+		addIssue(zb_data.DeckLegalityIssue_DeckSize, battleground_proto.CardKey{}, "deck has %d cards, at most %d allowed", deckSize, deckRules.MaxDeckSize)
+	}
+
+	if deckRules.RequireUnlockedSkills && overlord != nil {
+		for _, skill := range []zb_enums.OverlordSkillType_Enum{deck.PrimarySkill, deck.SecondarySkill} {
+			if skill == zb_enums.OverlordSkillType_None {
+				continue
+			}
+
+			if !isOverlordSkillUnlocked(overlord, skill) {
+				addIssue(zb_data.DeckLegalityIssue_SkillLocked, battleground_proto.CardKey{}, "skill %s is not unlocked for overlord %d", skill.String(), overlord.Prototype.Id)
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+// isCardKeyListed returns whether the card, or the standard variant of the card, is in the list
+func isCardKeyListed(cardKeys map[battleground_proto.CardKey]bool, cardKey battleground_proto.CardKey) bool {
+	return cardKeys[cardKey] || cardKeys[battleground_proto.CardKey{MouldId: cardKey.MouldId}]
+}
+
+func isOverlordSkillUnlocked(overlord *zb_data.OverlordUserInstance, skill zb_enums.OverlordSkillType_Enum) bool {
+	for _, skillPrototype := range overlord.Prototype.Skills {
+		if skillPrototype.Skill != skill {
+			continue
+		}
+
+		for _, unlockedSkillID := range overlord.UserData.UnlockedSkillIds {
+			if unlockedSkillID == skillPrototype.Id {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// validateDeckLegality returns a DeckLegalityError if the deck doesn't follow the rules.
+// Nil rules accept any deck.
+func validateDeckLegality(deckRules *zb_data.DeckRules, cardLibrary []*zb_data.Card, deck *zb_data.Deck, overlords []*zb_data.OverlordUserInstance) error {
+	if deckRules == nil {
+		return nil
+	}
+
+	issues, err := getDeckLegalityIssues(deckRules, cardLibrary, deck, overlords)
+	if err != nil {
+		return err
+	}
+
+	if len(issues) > 0 {
+		return &DeckLegalityError{Issues: issues}
+	}
+
+	return nil
+}
+
+// getDeckLegalFormats returns the names of the formats the deck is legal in
+func getDeckLegalFormats(configuration *zb_data.ContractConfiguration, cardLibrary []*zb_data.Card, deck *zb_data.Deck, overlords []*zb_data.OverlordUserInstance) ([]string, error) {
+	var legalFormats []string
+	for _, format := range configuration.Formats {
+		issues, err := getDeckLegalityIssues(format, cardLibrary, deck, overlords)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(issues) == 0 {
+			legalFormats = append(legalFormats, format.Name)
+		}
+	}
+
+	return legalFormats, nil
+}
+
+// updateDecksLegalFormats recomputes the legal formats of the decks, since formats can change after a deck is saved
+func updateDecksLegalFormats(ctx contract.StaticContext, version string, userID string, decks []*zb_data.Deck) error {
+	configuration, err := loadContractConfiguration(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(configuration.Formats) == 0 {
+		for _, deck := range decks {
+			deck.LegalFormats = nil
+		}
+		return nil
+	}
+
+	cardLibrary, err := loadCardLibrary(ctx, version)
+	if err != nil {
+		return err
+	}
+
+	overlords, err := loadOverlordUserInstances(ctx, version, userID)
+	if err != nil {
+		return errors.Wrapf(err, "unable to get overlord instances for userId: %s", userID)
+	}
+
+	for _, deck := range decks {
+		deck.LegalFormats, err = getDeckLegalFormats(configuration, cardLibrary.Cards, deck, overlords)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateDeckForFormat returns an error if the deck isn't legal in the format
+func validateDeckForFormat(ctx contract.StaticContext, configuration *zb_data.ContractConfiguration, formatName string, version string, userID string, deck *zb_data.Deck) error {
+	format := getFormat(configuration, formatName)
+	if format == nil {
+		return fmt.Errorf("format %s not found", formatName)
+	}
+
+	cardLibrary, err := loadCardLibrary(ctx, version)
+	if err != nil {
+		return err
+	}
+
+	overlords, err := loadOverlordUserInstances(ctx, version, userID)
+	if err != nil {
+		return errors.Wrapf(err, "unable to get overlord instances for userId: %s", userID)
+	}
+
+	if err := validateDeckLegality(format, cardLibrary.Cards, deck, overlords); err != nil {
+		return errors.Wrapf(err, "deck %d is not legal in format %s", deck.Id, formatName)
+	}
+
+	return nil
+}
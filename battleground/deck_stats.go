@@ -0,0 +1,243 @@
+package battleground
+
+import (
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	"sort"
+)
+
+// DefaultMostPlayedCardCount is the number of most played cards returned by GetDeckStats if not specified
+const DefaultMostPlayedCardCount = 5
+
+// GetDeckStats returns the statistics of a deck, the goo curve is computed from the current deck contents
+func (z *ZombieBattleground) GetDeckStats(ctx contract.StaticContext, req *zb_calls.GetDeckStatsRequest) (*zb_calls.GetDeckStatsResponse, error) {
+	if req.Version == "" {
+		return nil, ErrVersionNotSet
+	}
+
+	deckList, err := loadDecksRaw(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	deck := getDeckByID(deckList.Decks, req.DeckId)
+	if deck == nil {
+		return nil, contract.ErrNotFound
+	}
+
+	cardLibrary, err := loadCardLibrary(ctx, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	gooCurve, err := getDeckGooCurve(cardLibrary.Cards, deck)
+	if err != nil {
+		return nil, err
+	}
+
+	deckStatsList, err := loadDeckStatsList(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	deckStats := getDeckStatsByID(deckStatsList, req.DeckId)
+	if deckStats == nil {
+		deckStats = &zb_data.DeckStats{DeckId: req.DeckId}
+	}
+
+	mostPlayedCardCount := int(req.MostPlayedCardCount)
+	if mostPlayedCardCount <= 0 {
+		mostPlayedCardCount = DefaultMostPlayedCardCount
+	}
+
+	response := &zb_calls.GetDeckStatsResponse{
+		DeckId:          deckStats.DeckId,
+		GamesPlayed:     deckStats.GamesPlayed,
+		Wins:            deckStats.Wins,
+		Losses:          deckStats.Losses,
+		MostPlayedCards: getMostPlayedCards(deckStats, mostPlayedCardCount),
+		GooCurve:        gooCurve,
+	}
+
+	if deckStats.GamesPlayed > 0 {
+		response.WinRate = float32(deckStats.Wins) / float32(deckStats.GamesPlayed)
+		response.AverageMatchDuration = deckStats.TotalMatchDuration / deckStats.GamesPlayed
+	}
+
+	return response, nil
+}
+
+// updateDeckStatsAtMatchEnd records the match result in the stats of the decks of both players
+func updateDeckStatsAtMatchEnd(ctx contract.Context, match *zb_data.Match, gameState *zb_data.GameState, winnerID string) error {
+	matchDuration := ctx.Now().Unix() - gameState.CreatedAt
+	if matchDuration < 0 {
+		matchDuration = 0
+	}
+
+	playedCards := getPlayedCardsByPlayer(gameState)
+	for index, playerState := range match.PlayerStates {
+		// custom debug decks are not saved decks
+		if index < len(match.PlayerDebugCheats) {
+			debugCheats := match.PlayerDebugCheats[index]
+			if debugCheats != nil && debugCheats.Enabled && debugCheats.UseCustomDeck {
+				continue
+			}
+		}
+
+		deckStatsList, err := loadDeckStatsList(ctx, playerState.Id)
+		if err != nil {
+			return err
+		}
+
+		deckStats := getDeckStatsByID(deckStatsList, playerState.Deck.Id)
+		if deckStats == nil {
+			deckStats = &zb_data.DeckStats{DeckId: playerState.Deck.Id}
+			deckStatsList.DeckStats = append(deckStatsList.DeckStats, deckStats)
+		}
+
+		deckStats.GamesPlayed++
+		deckStats.TotalMatchDuration += matchDuration
+		// matches without a winner are draws
+		if winnerID == playerState.Id {
+			deckStats.Wins++
+		} else if winnerID != "" {
+			deckStats.Losses++
+		}
+
+		addDeckCardPlayCounts(deckStats, playedCards[playerState.Id])
+
+		if err := saveDeckStatsList(ctx, playerState.Id, deckStatsList); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getPlayedCardsByPlayer counts the cards played by each player during the match
+func getPlayedCardsByPlayer(gameState *zb_data.GameState) map[string]map[battleground_proto.CardKey]int64 {
+	instanceIDToCardKey := make(map[int32]battleground_proto.CardKey)
+	for _, playerState := range gameState.PlayerStates {
+		for _, cardInstances := range [][]*zb_data.CardInstance{
+			playerState.CardsInHand,
+			playerState.CardsInPlay,
+			playerState.CardsInDeck,
+			playerState.CardsInGraveyard,
+			playerState.MulliganCards,
+		} {
+			for _, cardInstance := range cardInstances {
+				if cardInstance.InstanceId != nil && cardInstance.Prototype != nil {
+					instanceIDToCardKey[cardInstance.InstanceId.Id] = cardInstance.Prototype.CardKey
+				}
+			}
+		}
+	}
+
+	playedCards := make(map[string]map[battleground_proto.CardKey]int64)
+	for _, playerAction := range gameState.PlayerActions {
+		cardPlay := playerAction.GetCardPlay()
+		if cardPlay == nil || cardPlay.Card == nil {
+			continue
+		}
+
+		cardKey, exists := instanceIDToCardKey[cardPlay.Card.Id]
+		if !exists {
+			continue
+		}
+
+		if playedCards[playerAction.PlayerId] == nil {
+			playedCards[playerAction.PlayerId] = make(map[battleground_proto.CardKey]int64)
+		}
+		playedCards[playerAction.PlayerId][cardKey]++
+	}
+
+	return playedCards
+}
+
+func addDeckCardPlayCounts(deckStats *zb_data.DeckStats, playedCards map[battleground_proto.CardKey]int64) {
+	cardKeyToPlayCount := make(map[battleground_proto.CardKey]*zb_data.DeckCardPlayCount)
+	for _, cardPlayCount := range deckStats.CardPlayCounts {
+		cardKeyToPlayCount[cardPlayCount.CardKey] = cardPlayCount
+	}
+
+	for cardKey, playCount := range playedCards {
+		if cardPlayCount, exists := cardKeyToPlayCount[cardKey]; exists {
+			cardPlayCount.PlayCount += playCount
+		} else {
+			deckStats.CardPlayCounts = append(deckStats.CardPlayCounts, &zb_data.DeckCardPlayCount{
+				CardKey:   cardKey,
+				PlayCount: playCount,
+			})
+		}
+	}
+
+	// map iteration order is random, keep the stored order deterministic
+	sortDeckCardPlayCounts(deckStats.CardPlayCounts)
+}
+
+func getMostPlayedCards(deckStats *zb_data.DeckStats, count int) []*zb_data.DeckCardPlayCount {
+	cardPlayCounts := make([]*zb_data.DeckCardPlayCount, len(deckStats.CardPlayCounts))
+	copy(cardPlayCounts, deckStats.CardPlayCounts)
+	sortDeckCardPlayCounts(cardPlayCounts)
+
+	if len(cardPlayCounts) > count {
+		cardPlayCounts = cardPlayCounts[:count]
+	}
+
+	return cardPlayCounts
+}
+
+// sortDeckCardPlayCounts sorts by descending play count, then by card key
+func sortDeckCardPlayCounts(cardPlayCounts []*zb_data.DeckCardPlayCount) {
+	sort.SliceStable(cardPlayCounts, func(i, j int) bool {
+		if cardPlayCounts[i].PlayCount != cardPlayCounts[j].PlayCount {
+			return cardPlayCounts[i].PlayCount > cardPlayCounts[j].PlayCount
+		}
+
+		return cardPlayCounts[i].CardKey.Compare(&cardPlayCounts[j].CardKey) < 0
+	})
+}
+
+// getDeckGooCurve returns the number of cards in the deck for each goo cost, cards missing from the library are skipped
+func getDeckGooCurve(cardLibrary []*zb_data.Card, deck *zb_data.Deck) ([]*zb_data.DeckGooCurveEntry, error) {
+	cardKeyToCard, err := getCardKeyToCardMap(cardLibrary)
+	if err != nil {
+		return nil, err
+	}
+
+	gooToCardCount := make(map[int32]int64)
+	for _, deckCard := range deck.Cards {
+		card, exists := cardKeyToCard[deckCard.CardKey]
+		if !exists {
+			continue
+		}
+
+		gooToCardCount[card.Cost] += deckCard.Amount
+	}
+
+	gooCurve := make([]*zb_data.DeckGooCurveEntry, 0, len(gooToCardCount))
+	for goo, cardCount := range gooToCardCount {
+		gooCurve = append(gooCurve, &zb_data.DeckGooCurveEntry{
+			Goo:       goo,
+			CardCount: cardCount,
+		})
+	}
+
+	sort.Slice(gooCurve, func(i, j int) bool {
+		return gooCurve[i].Goo < gooCurve[j].Goo
+	})
+
+	return gooCurve, nil
+}
+
+func getDeckStatsByID(deckStatsList *zb_data.DeckStatsList, deckID int64) *zb_data.DeckStats {
+	for _, deckStats := range deckStatsList.DeckStats {
+		if deckStats.DeckId == deckID {
+			return deckStats
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,232 @@
+package battleground
+
+import (
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	"github.com/loomnetwork/go-loom"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	assert "github.com/stretchr/testify/require"
+	"testing"
+)
+
+func newCardPlayAction(playerID string, instanceID int32) *zb_data.PlayerAction {
+	return &zb_data.PlayerAction{
+		ActionType: zb_enums.PlayerActionType_CardPlay,
+		PlayerId:   playerID,
+		Action: &zb_data.PlayerAction_CardPlay{
+			CardPlay: &zb_data.PlayerActionCardPlay{
+				Card: &zb_data.InstanceId{Id: instanceID},
+			},
+		},
+	}
+}
+
+func newDeckStatsTestGameState(createdAt int64) *zb_data.GameState {
+	return &zb_data.GameState{
+		CreatedAt: createdAt,
+		PlayerStates: []*zb_data.PlayerState{
+			{
+				Id: "player-1",
+				CardsInGraveyard: []*zb_data.CardInstance{
+					{InstanceId: &zb_data.InstanceId{Id: 1}, Prototype: &zb_data.Card{CardKey: battleground_proto.CardKey{MouldId: 10}}},
+					{InstanceId: &zb_data.InstanceId{Id: 2}, Prototype: &zb_data.Card{CardKey: battleground_proto.CardKey{MouldId: 11}}},
+				},
+				CardsInPlay: []*zb_data.CardInstance{
+					{InstanceId: &zb_data.InstanceId{Id: 3}, Prototype: &zb_data.Card{CardKey: battleground_proto.CardKey{MouldId: 10}}},
+				},
+			},
+			{
+				Id: "player-2",
+				CardsInHand: []*zb_data.CardInstance{
+					{InstanceId: &zb_data.InstanceId{Id: 4}, Prototype: &zb_data.Card{CardKey: battleground_proto.CardKey{MouldId: 20}}},
+				},
+			},
+		},
+		PlayerActions: []*zb_data.PlayerAction{
+			newCardPlayAction("player-1", 1),
+			newCardPlayAction("player-1", 2),
+			newCardPlayAction("player-1", 3),
+			newCardPlayAction("player-2", 4),
+			// unknown instances are ignored
+			newCardPlayAction("player-2", 100),
+			{ActionType: zb_enums.PlayerActionType_EndTurn, PlayerId: "player-1"},
+		},
+	}
+}
+
+func TestDeckStatsHelpers(t *testing.T) {
+	t.Run("Played cards are counted per player", func(t *testing.T) {
+		playedCards := getPlayedCardsByPlayer(newDeckStatsTestGameState(0))
+		assert.Equal(t, map[string]map[battleground_proto.CardKey]int64{
+			"player-1": {
+				battleground_proto.CardKey{MouldId: 10}: 2,
+				battleground_proto.CardKey{MouldId: 11}: 1,
+			},
+			"player-2": {
+				battleground_proto.CardKey{MouldId: 20}: 1,
+			},
+		}, playedCards)
+	})
+
+	t.Run("Play counts are merged and sorted", func(t *testing.T) {
+		deckStats := &zb_data.DeckStats{
+			CardPlayCounts: []*zb_data.DeckCardPlayCount{
+				{CardKey: battleground_proto.CardKey{MouldId: 11}, PlayCount: 2},
+			},
+		}
+
+		addDeckCardPlayCounts(deckStats, map[battleground_proto.CardKey]int64{
+			battleground_proto.CardKey{MouldId: 10}: 2,
+			battleground_proto.CardKey{MouldId: 11}: 1,
+			battleground_proto.CardKey{MouldId: 12}: 1,
+		})
+
+		assert.Equal(t, []*zb_data.DeckCardPlayCount{
+			{CardKey: battleground_proto.CardKey{MouldId: 11}, PlayCount: 3},
+			{CardKey: battleground_proto.CardKey{MouldId: 10}, PlayCount: 2},
+			{CardKey: battleground_proto.CardKey{MouldId: 12}, PlayCount: 1},
+		}, deckStats.CardPlayCounts)
+
+		mostPlayedCards := getMostPlayedCards(deckStats, 2)
+		assert.Equal(t, 2, len(mostPlayedCards))
+		assert.Equal(t, int64(11), mostPlayedCards[0].CardKey.MouldId)
+		assert.Equal(t, int64(10), mostPlayedCards[1].CardKey.MouldId)
+	})
+
+	t.Run("Goo curve", func(t *testing.T) {
+		cardLibrary := []*zb_data.Card{
+			{CardKey: battleground_proto.CardKey{MouldId: 1}, Cost: 1},
+			{CardKey: battleground_proto.CardKey{MouldId: 2}, Cost: 3},
+			{CardKey: battleground_proto.CardKey{MouldId: 3}, Cost: 1},
+		}
+
+		gooCurve, err := getDeckGooCurve(cardLibrary, &zb_data.Deck{
+			Cards: []*zb_data.DeckCard{
+				{CardKey: battleground_proto.CardKey{MouldId: 2}, Amount: 2},
+				{CardKey: battleground_proto.CardKey{MouldId: 1}, Amount: 3},
+				{CardKey: battleground_proto.CardKey{MouldId: 3}, Amount: 1},
+				{CardKey: battleground_proto.CardKey{MouldId: 100}, Amount: 4},
+			},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []*zb_data.DeckGooCurveEntry{
+			{Goo: 1, CardCount: 4},
+			{Goo: 3, CardCount: 2},
+		}, gooCurve)
+	})
+}
+
+func TestDeckStatsOperations(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "7796b813617b283f81ea1747fbddbe73fe4b5fce0eac0728e47de51d8e506701"
+	var addr loom.Address
+	var ctx contract.Context
+
+	setup(c, pubKeyHexString, &addr, &ctx, t)
+	setupAccount(c, ctx, &zb_calls.UpsertAccountRequest{
+		UserId:  "player-1",
+		Version: "v1",
+	}, t)
+	setupAccount(c, ctx, &zb_calls.UpsertAccountRequest{
+		UserId:  "player-2",
+		Version: "v1",
+	}, t)
+
+	match := &zb_data.Match{
+		PlayerStates: []*zb_data.InitialPlayerState{
+			{Id: "player-1", Deck: &zb_data.Deck{Id: 1}},
+			{Id: "player-2", Deck: &zb_data.Deck{Id: 1}},
+		},
+	}
+
+	t.Run("GetDeckStats without games", func(t *testing.T) {
+		response, err := c.GetDeckStats(ctx, &zb_calls.GetDeckStatsRequest{
+			UserId:  "player-1",
+			DeckId:  1,
+			Version: "v1",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), response.GamesPlayed)
+		assert.Equal(t, float32(0), response.WinRate)
+		assert.Equal(t, 0, len(response.MostPlayedCards))
+		assert.NotEmpty(t, response.GooCurve)
+	})
+
+	t.Run("GetDeckStats of unknown deck should fail", func(t *testing.T) {
+		_, err := c.GetDeckStats(ctx, &zb_calls.GetDeckStatsRequest{
+			UserId:  "player-1",
+			DeckId:  100,
+			Version: "v1",
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Stats are updated at match end", func(t *testing.T) {
+		now := ctx.Now().Unix()
+		err := updateDeckStatsAtMatchEnd(ctx, match, newDeckStatsTestGameState(now-100), "player-1")
+		assert.Nil(t, err)
+		err = updateDeckStatsAtMatchEnd(ctx, match, newDeckStatsTestGameState(now-200), "player-2")
+		assert.Nil(t, err)
+		err = updateDeckStatsAtMatchEnd(ctx, match, newDeckStatsTestGameState(now-300), "")
+		assert.Nil(t, err)
+
+		response, err := c.GetDeckStats(ctx, &zb_calls.GetDeckStatsRequest{
+			UserId:              "player-1",
+			DeckId:              1,
+			Version:             "v1",
+			MostPlayedCardCount: 1,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), response.GamesPlayed)
+		assert.Equal(t, int64(1), response.Wins)
+		assert.Equal(t, int64(1), response.Losses)
+		assert.InDelta(t, 1.0/3.0, response.WinRate, 0.001)
+		assert.Equal(t, int64(200), response.AverageMatchDuration)
+		assert.Equal(t, []*zb_data.DeckCardPlayCount{
+			{CardKey: battleground_proto.CardKey{MouldId: 10}, PlayCount: 6},
+		}, response.MostPlayedCards)
+	})
+
+	t.Run("Custom debug decks are not counted", func(t *testing.T) {
+		debugMatch := *match
+		debugMatch.PlayerDebugCheats = []*zb_data.DebugCheatsConfiguration{
+			{Enabled: true, UseCustomDeck: true},
+			{},
+		}
+		err := updateDeckStatsAtMatchEnd(ctx, &debugMatch, newDeckStatsTestGameState(ctx.Now().Unix()), "player-1")
+		assert.Nil(t, err)
+
+		response, err := c.GetDeckStats(ctx, &zb_calls.GetDeckStatsRequest{
+			UserId:  "player-1",
+			DeckId:  1,
+			Version: "v1",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), response.GamesPlayed)
+
+		response, err = c.GetDeckStats(ctx, &zb_calls.GetDeckStatsRequest{
+			UserId:  "player-2",
+			DeckId:  1,
+			Version: "v1",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(4), response.GamesPlayed)
+		assert.Equal(t, int64(1), response.Wins)
+		assert.Equal(t, int64(2), response.Losses)
+	})
+
+	t.Run("DeleteDeck removes the stats", func(t *testing.T) {
+		err := c.DeleteDeck(ctx, &zb_calls.DeleteDeckRequest{
+			UserId:  "player-1",
+			DeckId:  1,
+			Version: "v1",
+		})
+		assert.Nil(t, err)
+
+		deckStatsList, err := loadDeckStatsList(ctx, "player-1")
+		assert.Nil(t, err)
+		assert.Nil(t, getDeckStatsByID(deckStatsList, 1))
+	})
+}
@@ -0,0 +1,313 @@
+package battleground
+
+import (
+	"fmt"
+	"github.com/gogo/protobuf/proto"
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	"github.com/pkg/errors"
+	"sort"
+)
+
+// DefaultSuggestedDeckSize is the size suggested decks are filled to when the deck rules have no minimum size
+const DefaultSuggestedDeckSize = 30
+
+// suggestedGooCurve is the share of a suggested deck for each goo cost, the last entry covers every higher cost
+var suggestedGooCurve = []int{2, 4, 5, 5, 5, 4, 3, 2}
+
+type deckSuggestionCandidate struct {
+	card      *zb_data.Card
+	available int64
+	maxCopies int64
+	affinity  int
+}
+
+// SuggestDeckCompletion fills a partial deck to legal size with cards from the user collection.
+// Cards of the overlord faction are preferred, then items, and the goo curve is kept close to suggestedGooCurve.
+func (z *ZombieBattleground) SuggestDeckCompletion(ctx contract.StaticContext, req *zb_calls.SuggestDeckCompletionRequest) (*zb_calls.SuggestDeckCompletionResponse, error) {
+	if req.Version == "" {
+		return nil, ErrVersionNotSet
+	}
+
+	deck := &zb_data.Deck{}
+	if req.Deck != nil {
+		deck = proto.Clone(req.Deck).(*zb_data.Deck)
+	}
+	deck.OverlordId = req.OverlordId
+
+	overlords, err := loadOverlordUserInstances(ctx, req.Version, req.UserId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get overlord instances for userId: %s", req.UserId)
+	}
+
+	overlord, exists := getOverlordUserInstanceByPrototypeId(overlords, req.OverlordId)
+	if !exists {
+		return nil, fmt.Errorf("overlord %d not found", req.OverlordId)
+	}
+
+	cardLibrary, err := loadCardLibrary(ctx, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	userCardCollection, err := loadUserCardCollection(ctx, req.Version, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	configuration, err := loadContractConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deckRules := getDeckRules(configuration, DefaultDeckRulesName)
+	deckSize := getSuggestedDeckSize(deckRules)
+
+	addedCards, err := suggestDeckCompletion(deck, cardLibrary.Cards, userCardCollection, overlord.Prototype.Faction, deckRules, deckSize)
+	if err != nil {
+		return nil, err
+	}
+
+	deck.LegalFormats, err = getDeckLegalFormats(configuration, cardLibrary.Cards, deck, overlords)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zb_calls.SuggestDeckCompletionResponse{
+		Deck:       deck,
+		AddedCards: addedCards,
+		Complete:   getDeckSize(deck) >= int64(deckSize),
+	}, nil
+}
+
+func getSuggestedDeckSize(deckRules *zb_data.DeckRules) int {
+	if deckRules == nil {
+		return DefaultSuggestedDeckSize
+	}
+
+	deckSize := DefaultSuggestedDeckSize
+	if deckRules.MinDeckSize > 0 {
+		deckSize = int(deckRules.MinDeckSize)
+	}
+	if deckRules.MaxDeckSize > 0 && deckSize > int(deckRules.MaxDeckSize) {
+		deckSize = int(deckRules.MaxDeckSize)
+	}
+
+	return deckSize
+}
+
+func getDeckSize(deck *zb_data.Deck) int64 {
+	var deckSize int64
+	for _, deckCard := range deck.Cards {
+		deckSize += deckCard.Amount
+	}
+
+	return deckSize
+}
+
+func getGooCurveBucket(card *zb_data.Card) int {
+	if card.Cost < 0 {
+		return 0
+	}
+	if int(card.Cost) >= len(suggestedGooCurve) {
+		return len(suggestedGooCurve) - 1
+	}
+
+	return int(card.Cost)
+}
+
+// suggestDeckCompletion adds cards to the deck one at a time until it reaches deckSize or no card can be added,
+// and returns the added cards. The result only depends on the arguments.
+func suggestDeckCompletion(
+	deck *zb_data.Deck,
+	cardLibrary []*zb_data.Card,
+	userCardCollection []*zb_data.CardCollectionCard,
+	overlordFaction zb_enums.Faction_Enum,
+	deckRules *zb_data.DeckRules,
+	deckSize int,
+) ([]*zb_data.DeckCard, error) {
+	cardKeyToCard, err := getCardKeyToCardMap(cardLibrary)
+	if err != nil {
+		return nil, err
+	}
+
+	cardKeyToDeckCard := make(map[battleground_proto.CardKey]*zb_data.DeckCard)
+	mouldIdToAmount := make(map[int64]int64)
+	gooCurve := make([]int64, len(suggestedGooCurve))
+	currentDeckSize := getDeckSize(deck)
+	for _, deckCard := range deck.Cards {
+		cardKeyToDeckCard[deckCard.CardKey] = deckCard
+		mouldIdToAmount[deckCard.CardKey.MouldId] += deckCard.Amount
+
+		card, exists := cardKeyToCard[deckCard.CardKey]
+		if exists {
+			gooCurve[getGooCurveBucket(card)] += deckCard.Amount
+		}
+	}
+
+	var candidates []*deckSuggestionCandidate
+	for _, collectionCard := range userCardCollection {
+		card, exists := cardKeyToCard[collectionCard.CardKey]
+		if !exists || !isCardSuggestable(card, deckRules, overlordFaction) {
+			continue
+		}
+
+		available := collectionCard.Amount
+		if deckCard, exists := cardKeyToDeckCard[card.CardKey]; exists {
+			available -= deckCard.Amount
+		}
+		if available <= 0 {
+			continue
+		}
+
+		maxCopies, err := getSuggestedCardMaxCopies(deckRules, card)
+		if err != nil {
+			return nil, err
+		}
+
+		candidate := &deckSuggestionCandidate{
+			card:      card,
+			available: available,
+			maxCopies: maxCopies,
+		}
+		if card.Faction == zb_enums.Faction_Item {
+			candidate.affinity = 1
+		} else if overlordFaction != zb_enums.Faction_None && card.Faction == overlordFaction {
+			candidate.affinity = 2
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	gooCurveWeightSum := 0
+	for _, weight := range suggestedGooCurve {
+		gooCurveWeightSum += weight
+	}
+	gooCurveDeficit := func(card *zb_data.Card) float64 {
+		bucket := getGooCurveBucket(card)
+		target := float64(suggestedGooCurve[bucket]*deckSize) / float64(gooCurveWeightSum)
+		return target - float64(gooCurve[bucket])
+	}
+
+	isBetterCandidate := func(candidate *deckSuggestionCandidate, best *deckSuggestionCandidate) bool {
+		if candidate.affinity != best.affinity {
+			return candidate.affinity > best.affinity
+		}
+
+		candidateDeficit := gooCurveDeficit(candidate.card)
+		bestDeficit := gooCurveDeficit(best.card)
+		if candidateDeficit != bestDeficit {
+			return candidateDeficit > bestDeficit
+		}
+
+		if candidate.card.Cost != best.card.Cost {
+			return candidate.card.Cost < best.card.Cost
+		}
+
+		return candidate.card.CardKey.Compare(&best.card.CardKey) < 0
+	}
+
+	addedCardKeyToAmount := make(map[battleground_proto.CardKey]int64)
+	for currentDeckSize < int64(deckSize) {
+		var best *deckSuggestionCandidate
+		for _, candidate := range candidates {
+			if candidate.available <= 0 || mouldIdToAmount[candidate.card.CardKey.MouldId] >= candidate.maxCopies {
+				continue
+			}
+
+			if best == nil || isBetterCandidate(candidate, best) {
+				best = candidate
+			}
+		}
+
+		if best == nil {
+			break
+		}
+
+		deckCard, exists := cardKeyToDeckCard[best.card.CardKey]
+		if !exists {
+			deckCard = &zb_data.DeckCard{
+				CardKey: best.card.CardKey,
+			}
+			deck.Cards = append(deck.Cards, deckCard)
+			cardKeyToDeckCard[best.card.CardKey] = deckCard
+		}
+
+		deckCard.Amount++
+		best.available--
+		mouldIdToAmount[best.card.CardKey.MouldId]++
+		gooCurve[getGooCurveBucket(best.card)]++
+		addedCardKeyToAmount[best.card.CardKey]++
+		currentDeckSize++
+	}
+
+	addedCards := make([]*zb_data.DeckCard, 0, len(addedCardKeyToAmount))
+	for cardKey, amount := range addedCardKeyToAmount {
+		addedCards = append(addedCards, &zb_data.DeckCard{
+			CardKey: cardKey,
+			Amount:  amount,
+		})
+	}
+
+	sort.Slice(addedCards, func(i, j int) bool {
+		return addedCards[i].CardKey.Compare(&addedCards[j].CardKey) < 0
+	})
+
+	return addedCards, nil
+}
+
+// isCardSuggestable returns whether the card can be added to a deck without breaking the deck rules
+func isCardSuggestable(card *zb_data.Card, deckRules *zb_data.DeckRules, overlordFaction zb_enums.Faction_Enum) bool {
+	if deckRules == nil {
+		return !card.Hidden
+	}
+
+	if card.Hidden && !deckRules.AllowHiddenCards {
+		return false
+	}
+
+	for _, cardKey := range deckRules.BannedCards {
+		if cardKey == card.CardKey || cardKey == (battleground_proto.CardKey{MouldId: card.CardKey.MouldId}) {
+			return false
+		}
+	}
+
+	if len(deckRules.AllowedSets) > 0 {
+		allowed := false
+		for _, set := range deckRules.AllowedSets {
+			if set == card.Set {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return false
+		}
+	}
+
+	if deckRules.RestrictFactionToOverlord && card.Faction != zb_enums.Faction_Item && card.Faction != overlordFaction {
+		return false
+	}
+
+	return true
+}
+
+// getSuggestedCardMaxCopies returns the number of copies of the card a suggested deck can have, restricted cards are limited to one
+func getSuggestedCardMaxCopies(deckRules *zb_data.DeckRules, card *zb_data.Card) (int64, error) {
+	if deckRules == nil {
+		maxCopies, err := getMaxAmountOfCardInDeck(card)
+		return int64(maxCopies), err
+	}
+
+	for _, cardKey := range deckRules.RestrictedCards {
+		if cardKey == card.CardKey || cardKey == (battleground_proto.CardKey{MouldId: card.CardKey.MouldId}) {
+			return 1, nil
+		}
+	}
+
+	maxCopies, err := getDeckRulesMaxCopies(deckRules, card)
+	return int64(maxCopies), err
+}
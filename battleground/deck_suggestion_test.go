@@ -0,0 +1,159 @@
+package battleground
+
+import (
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	"github.com/loomnetwork/go-loom"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	assert "github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSuggestDeckCompletion(t *testing.T) {
+	cardLibrary := []*zb_data.Card{
+		{CardKey: battleground_proto.CardKey{MouldId: 1}, Faction: zb_enums.Faction_Fire, Rank: zb_enums.CreatureRank_Minion, Cost: 1},
+		{CardKey: battleground_proto.CardKey{MouldId: 2}, Faction: zb_enums.Faction_Fire, Rank: zb_enums.CreatureRank_Minion, Cost: 2},
+		{CardKey: battleground_proto.CardKey{MouldId: 3}, Faction: zb_enums.Faction_Fire, Rank: zb_enums.CreatureRank_General, Cost: 8},
+		{CardKey: battleground_proto.CardKey{MouldId: 4}, Faction: zb_enums.Faction_Water, Rank: zb_enums.CreatureRank_Minion, Cost: 1},
+		{CardKey: battleground_proto.CardKey{MouldId: 5}, Faction: zb_enums.Faction_Item, Cost: 2},
+		{CardKey: battleground_proto.CardKey{MouldId: 6}, Faction: zb_enums.Faction_Fire, Rank: zb_enums.CreatureRank_Minion, Cost: 3, Hidden: true},
+	}
+	userCardCollection := []*zb_data.CardCollectionCard{
+		{CardKey: battleground_proto.CardKey{MouldId: 1}, Amount: 5},
+		{CardKey: battleground_proto.CardKey{MouldId: 2}, Amount: 4},
+		{CardKey: battleground_proto.CardKey{MouldId: 3}, Amount: 2},
+		{CardKey: battleground_proto.CardKey{MouldId: 4}, Amount: 4},
+		{CardKey: battleground_proto.CardKey{MouldId: 5}, Amount: 4},
+		{CardKey: battleground_proto.CardKey{MouldId: 6}, Amount: 4},
+	}
+	newPartialDeck := func() *zb_data.Deck {
+		return &zb_data.Deck{
+			Cards: []*zb_data.DeckCard{
+				{CardKey: battleground_proto.CardKey{MouldId: 1}, Amount: 1},
+			},
+		}
+	}
+
+	t.Run("Overlord faction is preferred, then items", func(t *testing.T) {
+		deck := newPartialDeck()
+		addedCards, err := suggestDeckCompletion(deck, cardLibrary, userCardCollection, zb_enums.Faction_Fire, nil, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, []*zb_data.DeckCard{
+			{CardKey: battleground_proto.CardKey{MouldId: 1}, Amount: 3},
+			{CardKey: battleground_proto.CardKey{MouldId: 2}, Amount: 4},
+			{CardKey: battleground_proto.CardKey{MouldId: 3}, Amount: 1},
+			{CardKey: battleground_proto.CardKey{MouldId: 5}, Amount: 1},
+		}, addedCards)
+		assert.Equal(t, int64(10), getDeckSize(deck))
+		assert.Equal(t, int64(4), deck.Cards[0].Amount)
+
+		// same input gives the same deck
+		otherDeck := newPartialDeck()
+		_, err = suggestDeckCompletion(otherDeck, cardLibrary, userCardCollection, zb_enums.Faction_Fire, nil, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, deck, otherDeck)
+	})
+
+	t.Run("Deck rules are followed", func(t *testing.T) {
+		deckRules := &zb_data.DeckRules{
+			Name:                      DefaultDeckRulesName,
+			RestrictFactionToOverlord: true,
+			BannedCards:               []battleground_proto.CardKey{{MouldId: 5}},
+			RestrictedCards:           []battleground_proto.CardKey{{MouldId: 2}},
+		}
+
+		deck := newPartialDeck()
+		addedCards, err := suggestDeckCompletion(deck, cardLibrary, userCardCollection, zb_enums.Faction_Fire, deckRules, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, []*zb_data.DeckCard{
+			{CardKey: battleground_proto.CardKey{MouldId: 1}, Amount: 3},
+			{CardKey: battleground_proto.CardKey{MouldId: 2}, Amount: 1},
+			{CardKey: battleground_proto.CardKey{MouldId: 3}, Amount: 1},
+		}, addedCards)
+		assert.Equal(t, int64(6), getDeckSize(deck))
+	})
+
+	t.Run("Goo curve is followed", func(t *testing.T) {
+		cardLibrary := []*zb_data.Card{
+			{CardKey: battleground_proto.CardKey{MouldId: 10}, Faction: zb_enums.Faction_Fire, Rank: zb_enums.CreatureRank_Minion, Cost: 0},
+			{CardKey: battleground_proto.CardKey{MouldId: 11}, Faction: zb_enums.Faction_Fire, Rank: zb_enums.CreatureRank_Minion, Cost: 3},
+			{CardKey: battleground_proto.CardKey{MouldId: 12}, Faction: zb_enums.Faction_Fire, Rank: zb_enums.CreatureRank_Minion, Cost: 9},
+		}
+		userCardCollection := []*zb_data.CardCollectionCard{
+			{CardKey: battleground_proto.CardKey{MouldId: 12}, Amount: 4},
+			{CardKey: battleground_proto.CardKey{MouldId: 11}, Amount: 4},
+			{CardKey: battleground_proto.CardKey{MouldId: 10}, Amount: 4},
+		}
+
+		deck := &zb_data.Deck{}
+		addedCards, err := suggestDeckCompletion(deck, cardLibrary, userCardCollection, zb_enums.Faction_Fire, nil, 3)
+		assert.Nil(t, err)
+		assert.Equal(t, []*zb_data.DeckCard{
+			{CardKey: battleground_proto.CardKey{MouldId: 10}, Amount: 1},
+			{CardKey: battleground_proto.CardKey{MouldId: 11}, Amount: 1},
+			{CardKey: battleground_proto.CardKey{MouldId: 12}, Amount: 1},
+		}, addedCards)
+		assert.Equal(t, battleground_proto.CardKey{MouldId: 11}, deck.Cards[0].CardKey)
+	})
+
+	t.Run("Suggested deck size", func(t *testing.T) {
+		assert.Equal(t, DefaultSuggestedDeckSize, getSuggestedDeckSize(nil))
+		assert.Equal(t, 20, getSuggestedDeckSize(&zb_data.DeckRules{MinDeckSize: 20}))
+		assert.Equal(t, 25, getSuggestedDeckSize(&zb_data.DeckRules{MaxDeckSize: 25}))
+	})
+}
+
+func TestSuggestDeckCompletionOperations(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "7c1f0e3d2b4a59687f6e5d4c3b2a19080f1e2d3c4b5a69788796a5b4c3d2e1f0"
+	var addr loom.Address
+	var ctx contract.Context
+
+	setup(c, pubKeyHexString, &addr, &ctx, t)
+	setupAccount(c, ctx, &zb_calls.UpsertAccountRequest{
+		UserId:  "SuggestionUser",
+		Version: "v1",
+	}, t)
+
+	t.Run("Suggested deck can be created", func(t *testing.T) {
+		response, err := c.SuggestDeckCompletion(ctx, &zb_calls.SuggestDeckCompletionRequest{
+			UserId:     "SuggestionUser",
+			Version:    "v1",
+			OverlordId: 1,
+			Deck: &zb_data.Deck{
+				Name: "Suggested",
+				Cards: []*zb_data.DeckCard{
+					{CardKey: battleground_proto.CardKey{MouldId: 43}, Amount: 1},
+				},
+			},
+		})
+		assert.Nil(t, err)
+		assert.True(t, response.Complete)
+		assert.Equal(t, int64(DefaultSuggestedDeckSize), getDeckSize(response.Deck))
+		assert.Equal(t, int64(1), response.Deck.OverlordId)
+
+		var addedCardCount int64
+		for _, deckCard := range response.AddedCards {
+			addedCardCount += deckCard.Amount
+		}
+		assert.Equal(t, int64(DefaultSuggestedDeckSize-1), addedCardCount)
+
+		_, err = c.CreateDeck(ctx, &zb_calls.CreateDeckRequest{
+			UserId:  "SuggestionUser",
+			Version: "v1",
+			Deck:    response.Deck,
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("Unknown overlord", func(t *testing.T) {
+		_, err := c.SuggestDeckCompletion(ctx, &zb_calls.SuggestDeckCompletionRequest{
+			UserId:     "SuggestionUser",
+			Version:    "v1",
+			OverlordId: 999,
+		})
+		assert.NotNil(t, err)
+	})
+}
@@ -1,8 +1,16 @@
 package battleground
 
 import (
+	"fmt"
+	"github.com/gogo/protobuf/proto"
 	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"github.com/loomnetwork/go-loom"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	"github.com/loomnetwork/go-loom/types"
+	"github.com/pkg/errors"
+	"math"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -15,24 +23,45 @@ const (
 	MMTimeout = 120 * time.Second
 )
 
+const (
+	MatchMakingQueueCasual     = "casual"
+	MatchMakingQueueRanked     = "ranked"
+	MatchMakingQueueTournament = "tournament"
+
+	// custom game mode queues are named after the game mode contract address, e.g. custom:0x...
+	matchMakingQueueCustomGamePrefix = "custom:"
+	// legacy tagged registrations are put into a queue named after the sorted tags, e.g. tags:tag1,tag2
+	matchMakingQueueTagsPrefix = "tags:"
+)
+
 // MatchMakingFunc calculates the score based on the given profile target and candidate
 type MatchMakingFunc func(target *zb_data.PlayerProfile, candidate *zb_data.PlayerProfile) float64
 
-// mmf is the gobal match making function that calculates the match making score
+// MatchMakingQueue is a named player pool with its own match making function and registration rules
+type MatchMakingQueue struct {
+	Name            string
+	MatchMakingFunc MatchMakingFunc
+	Rules           MatchMakingQueueRules
+}
+
+// MatchMakingQueueRules restricts which registrations are accepted by a queue
+type MatchMakingQueueRules struct {
+	// AllowedVersions lists the data versions accepted by the queue, any version is accepted if empty
+	AllowedVersions         []string
+	RequireBackendGameLogic bool
+	ForbidDebugCheats       bool
+	// RequiredFormat is the name of the format the decks must be legal in, any deck is accepted if empty
+	RequiredFormat string
+}
+
+// mmf is the gobal match making function that calculates the match making score.
+// Version compatibility depends on the contract configuration and is checked by FindMatch.
 var mmf MatchMakingFunc = func(target *zb_data.PlayerProfile, candidate *zb_data.PlayerProfile) float64 {
 	targetData := target.RegistrationData
 	candidateData := candidate.RegistrationData
-	// map tag to the same tag group
-	if len(targetData.Tags) > 0 || len(candidateData.Tags) > 0 {
-		if compareTags(targetData.Tags, candidateData.Tags) {
-			return 1
-		} else {
-			return 0
-		}
-	}
 
-	// version
-	if targetData.Version != candidateData.Version {
+	// custom game mode, both players must request the same one
+	if !customGameAddressesEqual(targetData.CustomGame, candidateData.CustomGame) {
 		return 0
 	}
 
@@ -49,23 +78,499 @@ var mmf MatchMakingFunc = func(target *zb_data.PlayerProfile, candidate *zb_data
 	return 1
 }
 
-// compareTags compares string slices so order of string matters
-func compareTags(tag1, tag2 []string) bool {
-	if len(tag1) != len(tag2) {
-		return false
+// rankedMMF prefers the candidates with the closest elo score
+var rankedMMF MatchMakingFunc = func(target *zb_data.PlayerProfile, candidate *zb_data.PlayerProfile) float64 {
+	score := mmf(target, candidate)
+	if score <= 0 {
+		return score
 	}
-	for i, v := range tag1 {
-		if v != tag2[i] {
-			return false
+
+	eloDifference := math.Abs(float64(target.EloScore - candidate.EloScore))
+	return score / (1 + eloDifference/100)
+}
+
+var builtinMatchMakingQueues = map[string]*MatchMakingQueue{
+	MatchMakingQueueCasual: {
+		Name:            MatchMakingQueueCasual,
+		MatchMakingFunc: mmf,
+	},
+	MatchMakingQueueRanked: {
+		Name:            MatchMakingQueueRanked,
+		MatchMakingFunc: rankedMMF,
+		Rules: MatchMakingQueueRules{
+			ForbidDebugCheats: true,
+		},
+	},
+	MatchMakingQueueTournament: {
+		Name:            MatchMakingQueueTournament,
+		MatchMakingFunc: rankedMMF,
+		Rules: MatchMakingQueueRules{
+			RequireBackendGameLogic: true,
+			ForbidDebugCheats:       true,
+		},
+	},
+}
+
+// getMatchMakingQueue returns the queue with the given name.
+// The contract configuration can add rules to the built-in queues, but can't relax the built-in ones.
+func getMatchMakingQueue(configuration *zb_data.ContractConfiguration, name string) (*MatchMakingQueue, error) {
+	var queue MatchMakingQueue
+	if builtinQueue, exists := builtinMatchMakingQueues[name]; exists {
+		queue = *builtinQueue
+	} else if strings.HasPrefix(name, matchMakingQueueCustomGamePrefix) || strings.HasPrefix(name, matchMakingQueueTagsPrefix) {
+		queue = MatchMakingQueue{
+			Name:            name,
+			MatchMakingFunc: mmf,
+		}
+	} else {
+		return nil, fmt.Errorf("unknown matchmaking queue %s", name)
+	}
+
+	if configuration != nil {
+		for _, queueConfiguration := range configuration.MatchMakingQueueConfiguration {
+			if queueConfiguration.Name == name {
+				if len(queueConfiguration.AllowedVersions) > 0 {
+					queue.Rules.AllowedVersions = queueConfiguration.AllowedVersions
+				}
+				if queueConfiguration.RequiredFormat != "" {
+					queue.Rules.RequiredFormat = queueConfiguration.RequiredFormat
+				}
+				queue.Rules.RequireBackendGameLogic = queue.Rules.RequireBackendGameLogic || queueConfiguration.RequireBackendGameLogic
+				queue.Rules.ForbidDebugCheats = queue.Rules.ForbidDebugCheats || queueConfiguration.ForbidDebugCheats
+				break
+			}
+		}
+	}
+
+	return &queue, nil
+}
+
+func (queue *MatchMakingQueue) validateRegistrationData(registrationData *zb_data.PlayerProfileRegistrationData) error {
+	if len(queue.Rules.AllowedVersions) > 0 {
+		if !containsString(queue.Rules.AllowedVersions, registrationData.Version) {
+			return fmt.Errorf("version %s is not allowed in matchmaking queue %s", registrationData.Version, queue.Name)
 		}
 	}
-	return true
+
+	if queue.Rules.RequireBackendGameLogic && !registrationData.UseBackendGameLogic {
+		return fmt.Errorf("matchmaking queue %s requires backend game logic", queue.Name)
+	}
+
+	if queue.Rules.ForbidDebugCheats && registrationData.DebugCheats.Enabled {
+		return fmt.Errorf("debug cheats are not allowed in matchmaking queue %s", queue.Name)
+	}
+
+	return nil
+}
+
+// getMatchVersion returns the card library version the match between two client versions is played on.
+// Identical versions always play together, other pairs must be listed in the compatibility table.
+func getMatchVersion(configuration *zb_data.ContractConfiguration, version1 string, version2 string) (string, bool) {
+	if version1 == version2 {
+		return version1, true
+	}
+
+	if configuration == nil {
+		return "", false
+	}
+
+	for _, compatibility := range configuration.MatchMakingVersionCompatibility {
+		if containsString(compatibility.Versions, version1) && containsString(compatibility.Versions, version2) {
+			return compatibility.MatchVersion, true
+		}
+	}
+
+	return "", false
+}
+
+// validateDeckForMatchVersion makes sure the deck can be played on the card library of the match version
+func validateDeckForMatchVersion(ctx contract.StaticContext, registrationData *zb_data.PlayerProfileRegistrationData, deck *zb_data.Deck, matchVersion string) error {
+	cardLibrary, err := loadCardLibrary(ctx, matchVersion)
+	if err != nil {
+		return err
+	}
+
+	if err := validateDeckAgainstCardLibrary(cardLibrary.Cards, deck.Cards); err != nil {
+		return errors.Wrapf(err, "deck %d of user %s is not valid for version %s", deck.Id, registrationData.UserId, matchVersion)
+	}
+
+	// custom debug decks are not backed by the collection
+	if registrationData.DebugCheats.Enabled && registrationData.DebugCheats.UseCustomDeck {
+		return nil
+	}
+
+	if registrationData.CustomGame != nil {
+		configuration, err := loadContractConfiguration(ctx)
+		if err != nil {
+			return err
+		}
+
+		deckRules, err := getGameModeDeckRules(ctx, configuration, loom.UnmarshalAddressPB(registrationData.CustomGame))
+		if err != nil {
+			return err
+		}
+
+		if deckRules != nil {
+			overlords, err := loadOverlordUserInstances(ctx, matchVersion, registrationData.UserId)
+			if err != nil {
+				return err
+			}
+
+			if err := validateDeckLegality(deckRules, cardLibrary.Cards, deck, overlords); err != nil {
+				return errors.Wrapf(err, "deck %d of user %s is not valid for the custom game", deck.Id, registrationData.UserId)
+			}
+		}
+	}
+
+	userCardCollection, err := loadUserCardCollection(ctx, matchVersion, registrationData.UserId)
+	if err != nil {
+		return err
+	}
+
+	if err := validateDeckAgainstUserCardCollection(userCardCollection, deck.Cards); err != nil {
+		return errors.Wrapf(err, "deck %d of user %s is not valid for version %s", deck.Id, registrationData.UserId, matchVersion)
+	}
+
+	return nil
+}
+
+// startMatch creates a match between two players and sets it as the current match of both players
+func startMatch(
+	ctx contract.Context,
+	registrationData *zb_data.PlayerProfileRegistrationData,
+	deck *zb_data.Deck,
+	opponentRegistrationData *zb_data.PlayerProfileRegistrationData,
+	opponentDeck *zb_data.Deck,
+	version string,
+	customGame *types.Address,
+) (*zb_data.Match, error) {
+	match := &zb_data.Match{
+		Status: zb_data.Match_Matching,
+		PlayerStates: []*zb_data.InitialPlayerState{
+			&zb_data.InitialPlayerState{
+				Id:            registrationData.UserId,
+				Deck:          deck,
+				MatchAccepted: false,
+			},
+			&zb_data.InitialPlayerState{
+				Id:            opponentRegistrationData.UserId,
+				Deck:          opponentDeck,
+				MatchAccepted: false,
+			},
+		},
+		Version: version,
+		PlayerLastSeens: []*zb_data.PlayerTimestamp{
+			&zb_data.PlayerTimestamp{
+				Id:        registrationData.UserId,
+				UpdatedAt: ctx.Now().Unix(),
+			},
+			&zb_data.PlayerTimestamp{
+				Id:        opponentRegistrationData.UserId,
+				UpdatedAt: ctx.Now().Unix(),
+			},
+		},
+		PlayerDebugCheats: []*zb_data.DebugCheatsConfiguration{
+			&registrationData.DebugCheats,
+			&opponentRegistrationData.DebugCheats,
+		},
+		CustomGameAddr: customGame,
+	}
+
+	if registrationData.DebugCheats.Enabled && registrationData.DebugCheats.UseCustomRandomSeed {
+		match.RandomSeed = registrationData.DebugCheats.CustomRandomSeed
+	} else {
+		match.RandomSeed = ctx.Now().Unix()
+	}
+
+	if err := createMatch(ctx, match, registrationData.UseBackendGameLogic); err != nil {
+		return nil, err
+	}
+
+	// save user match
+	if err := saveUserCurrentMatch(ctx, registrationData.UserId, match); err != nil {
+		return nil, err
+	}
+	if err := saveUserCurrentMatch(ctx, opponentRegistrationData.UserId, match); err != nil {
+		return nil, err
+	}
+
+	emitMsg := zb_data.PlayerActionEvent{
+		Match: match,
+	}
+	data, err := proto.Marshal(&emitMsg)
+	if err != nil {
+		return nil, err
+	}
+	topics := append(match.Topics, TopicFindMatchEvent)
+	ctx.EmitTopics(data, topics...)
+
+	return match, nil
+}
+
+// validateRegistrationCustomGame makes sure the requested custom game mode is listed in the game mode list,
+// and that the registration only goes into the queue of that game mode. Tags queues can hold registrations
+// of any game mode, the custom game is compared when the players are matched.
+func validateRegistrationCustomGame(ctx contract.StaticContext, registrationData *zb_data.PlayerProfileRegistrationData, queueNames []string) error {
+	var customGameQueueName string
+	if registrationData.CustomGame != nil {
+		gameModeList, err := loadGameModeList(ctx)
+		if err != nil {
+			return err
+		}
+
+		customGameAddress := loom.UnmarshalAddressPB(registrationData.CustomGame)
+		if getGameModeFromListByAddress(gameModeList, customGameAddress) == nil {
+			return fmt.Errorf("custom game mode %s not found", customGameAddress.Local.String())
+		}
+
+		customGameQueueName = customGameMatchMakingQueueName(customGameAddress)
+	}
+
+	for _, queueName := range queueNames {
+		if isTagsMatchMakingQueueName(queueName) {
+			continue
+		}
+
+		if strings.HasPrefix(queueName, matchMakingQueueCustomGamePrefix) || customGameQueueName != "" {
+			if queueName != customGameQueueName {
+				return fmt.Errorf("registration custom game doesn't match matchmaking queue %s", queueName)
+			}
+		}
+	}
+
+	return nil
+}
+
+func customGameAddressesEqual(address1 *types.Address, address2 *types.Address) bool {
+	if address1 == nil || address2 == nil {
+		return address1 == address2
+	}
+
+	return address1.Local.Compare(address2.Local) == 0
+}
+
+func customGameMatchMakingQueueName(customGameAddress loom.Address) string {
+	return matchMakingQueueCustomGamePrefix + customGameAddress.Local.String()
+}
+
+func tagsMatchMakingQueueName(tags []string) string {
+	sortedTags := make([]string, len(tags))
+	copy(sortedTags, tags)
+	sort.Strings(sortedTags)
+	return matchMakingQueueTagsPrefix + strings.Join(sortedTags, ",")
+}
+
+func isTagsMatchMakingQueueName(name string) bool {
+	return strings.HasPrefix(name, matchMakingQueueTagsPrefix)
+}
+
+// getRegistrationMatchMakingQueueNames returns the queues the registration must be put in.
+// Registrations that don't name any queue fall back to the custom game, tags, or casual queue.
+func getRegistrationMatchMakingQueueNames(registrationData *zb_data.PlayerProfileRegistrationData) []string {
+	if len(registrationData.Queues) > 0 {
+		return uniqueStrings(registrationData.Queues)
+	}
+
+	if len(registrationData.Tags) > 0 {
+		return []string{tagsMatchMakingQueueName(registrationData.Tags)}
+	}
+
+	if registrationData.CustomGame != nil {
+		return []string{customGameMatchMakingQueueName(loom.UnmarshalAddressPB(registrationData.CustomGame))}
+	}
+
+	return []string{MatchMakingQueueCasual}
+}
+
+// getRequestedMatchMakingQueueNames returns the queues to search in for FindMatch.
+// Tagged queues are only searched when the tags are explicitly requested.
+func getRequestedMatchMakingQueueNames(queues []string, tags []string, registeredQueues []string) []string {
+	if len(queues) > 0 {
+		return uniqueStrings(queues)
+	}
+
+	if len(tags) > 0 {
+		return []string{tagsMatchMakingQueueName(tags)}
+	}
+
+	// registrations made before the queues were introduced only exist in the casual queue
+	if len(registeredQueues) == 0 {
+		return []string{MatchMakingQueueCasual}
+	}
+
+	var queueNames []string
+	for _, queueName := range registeredQueues {
+		if !isTagsMatchMakingQueueName(queueName) {
+			queueNames = append(queueNames, queueName)
+		}
+	}
+
+	return queueNames
+}
+
+// removePlayerFromMatchMakingQueues removes the player from every queue the player is registered in
+func removePlayerFromMatchMakingQueues(ctx contract.Context, userID string) error {
+	queueNames, err := loadUserMatchMakingQueues(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// registrations made before the queues were introduced only exist in the casual queue
+	if len(queueNames) == 0 {
+		queueNames = []string{MatchMakingQueueCasual}
+	}
+
+	for _, queueName := range queueNames {
+		pool, err := loadPlayerPool(ctx, queueName)
+		if err != nil {
+			return err
+		}
+
+		if findPlayerProfileByID(pool, userID) == nil {
+			continue
+		}
+
+		pool = removePlayerFromPool(pool, userID)
+		if err := savePlayerPool(ctx, queueName, pool); err != nil {
+			return err
+		}
+	}
+
+	return saveUserMatchMakingQueues(ctx, userID, nil)
+}
+
+func getPlayerPoolEntryTimeToLive(configuration *zb_data.ContractConfiguration) time.Duration {
+	if configuration != nil && configuration.PlayerPoolEntryTimeToLive > 0 {
+		return time.Duration(configuration.PlayerPoolEntryTimeToLive) * time.Second
+	}
+
+	return MMTimeout
+}
+
+// evictTimedOutPlayerPoolEntries removes the players whose entry in any of the given queues is older than the TTL
+// from all the queues they are registered in. Returns the number of evicted entries of every given queue.
+func evictTimedOutPlayerPoolEntries(
+	ctx contract.Context,
+	configuration *zb_data.ContractConfiguration,
+	queueNames []string,
+	excludedUserID string,
+) (map[string]int64, error) {
+	timeToLive := getPlayerPoolEntryTimeToLive(configuration)
+	evictedEntryCounts := make(map[string]int64)
+	var timedOutUserIDs []string
+	for _, queueName := range queueNames {
+		pool, err := loadPlayerPool(ctx, queueName)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pp := range pool.PlayerProfiles {
+			if pp.RegistrationData.UserId == excludedUserID {
+				continue
+			}
+
+			updatedAt := time.Unix(pp.UpdatedAt, 0)
+			if updatedAt.Add(timeToLive).Before(ctx.Now()) {
+				evictedEntryCounts[queueName]++
+				timedOutUserIDs = append(timedOutUserIDs, pp.RegistrationData.UserId)
+			}
+		}
+	}
+
+	if len(timedOutUserIDs) == 0 {
+		return evictedEntryCounts, nil
+	}
+
+	for _, userID := range uniqueStrings(timedOutUserIDs) {
+		ctx.Logger().Info(fmt.Sprintf("Player profile %s timedout", userID))
+		if err := removePlayerFromMatchMakingQueues(ctx, userID); err != nil {
+			return nil, err
+		}
+
+		if err := timeOutCurrentMatchOfEvictedPlayer(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	state, err := loadContractState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, evictedEntryCount := range evictedEntryCounts {
+		state.PlayerPoolEvictedEntryCount += uint64(evictedEntryCount)
+	}
+
+	if err := saveContractState(ctx, state); err != nil {
+		return nil, err
+	}
+
+	return evictedEntryCounts, nil
+}
+
+func timeOutCurrentMatchOfEvictedPlayer(ctx contract.Context, userID string) error {
+	match, _ := loadUserCurrentMatch(ctx, userID)
+	if match == nil {
+		return nil
+	}
+
+	ctx.Delete(MatchKey(match.Id))
+	match.Status = zb_data.Match_Timedout
+	// remove player's match if existing
+	ctx.Delete(UserMatchKey(userID))
+	// notify player
+	emitMsg := zb_data.PlayerActionEvent{
+		Match:            match,
+		CreatedByBackend: true,
+	}
+	data, err := proto.Marshal(&emitMsg)
+	if err != nil {
+		return err
+	}
+	ctx.EmitTopics(data, match.Topics...)
+
+	return nil
+}
+
+func loadUserEloScore(ctx contract.StaticContext, userID string) int64 {
+	var account zb_data.Account
+	if err := ctx.Get(AccountKey(userID), &account); err != nil {
+		return 0
+	}
+
+	return account.EloScore
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, value := range values {
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		result = append(result, value)
+	}
+
+	return result
 }
 
 // PlayerScore simply maintains the player id and score tuple
 type PlayerScore struct {
-	score float64
-	id    string
+	score        float64
+	id           string
+	profile      *zb_data.PlayerProfile
+	matchVersion string
 }
 
 type byPlayersScore []*PlayerScore
@@ -79,7 +584,7 @@ func (p byPlayersScore) Less(i, j int) bool {
 }
 
 func sortByPlayerScore(ps []*PlayerScore) []*PlayerScore {
-	sort.Sort(byPlayersScore(ps))
+	sort.Stable(byPlayersScore(ps))
 	return ps
 }
 
@@ -0,0 +1,262 @@
+package battleground
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	"github.com/pkg/errors"
+)
+
+const (
+	// expired receipts are still tracked in the history in case they are redeemed later
+	defaultMintingReceiptTimeToLive = 30 * 24 * time.Hour
+	// minimum time between two redemption checks of the same receipt
+	mintingReceiptCheckInterval = time.Hour
+	// expired receipts are rarely redeemed, they are checked less often
+	expiredMintingReceiptCheckInterval = 24 * time.Hour
+	// maximum number of watched receipts a sweep goes through
+	maxMintingReceiptSweepCount = 100
+)
+
+// addPendingMintingReceipt adds a newly minted receipt to the pending receipts of the user,
+// to the receipt history and to the list of receipts the oracle checks for redemption
+func addPendingMintingReceipt(ctx contract.Context, userId string, receipt *zb_data.MintingTransactionReceipt) error {
+	receiptCollection, err := loadPendingMintingTransactionReceipts(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	receiptCollection.Receipts = append(receiptCollection.Receipts, receipt)
+	err = savePendingMintingTransactionReceipts(ctx, userId, receiptCollection)
+	if err != nil {
+		return err
+	}
+
+	history, err := loadMintingTransactionReceiptHistory(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	history.Entries = append(history.Entries, &zb_data.MintingTransactionReceiptHistoryEntry{
+		Receipt:   receipt,
+		Status:    zb_enums.MintingTransactionReceiptStatus_Pending,
+		CreatedAt: ctx.Now().Unix(),
+		UpdatedAt: ctx.Now().Unix(),
+	})
+	err = saveMintingTransactionReceiptHistory(ctx, userId, history)
+	if err != nil {
+		return err
+	}
+
+	watchList, err := loadMintingReceiptWatchList(ctx)
+	if err != nil {
+		return err
+	}
+
+	watchList.Entries = append(watchList.Entries, &zb_data.MintingReceiptWatchList_Entry{
+		UserId:    userId,
+		TxId:      receipt.TxId,
+		CreatedAt: ctx.Now().Unix(),
+	})
+	return saveMintingReceiptWatchList(ctx, watchList)
+}
+
+// resolveMintingReceipt removes the receipt from the pending receipts of the user and from the watch list,
+// and records the final status in the receipt history. Returns the removed receipt, or nil if it wasn't pending,
+// and whether the receipt was found in the pending receipts or in the history.
+func resolveMintingReceipt(
+	ctx contract.Context,
+	userId string,
+	txId *big.Int,
+	status zb_enums.MintingTransactionReceiptStatus_Enum,
+) (*zb_data.MintingTransactionReceipt, bool, error) {
+	receipt, found, err := resolvePendingMintingReceipt(ctx, userId, txId, status)
+	if err != nil {
+		return nil, false, err
+	}
+
+	watchList, err := loadMintingReceiptWatchList(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	entries := make([]*zb_data.MintingReceiptWatchList_Entry, 0, len(watchList.Entries))
+	for _, entry := range watchList.Entries {
+		if entry.UserId != userId || entry.TxId.Value.Int.Cmp(txId) != 0 {
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(entries) != len(watchList.Entries) {
+		watchList.Entries = entries
+		err = saveMintingReceiptWatchList(ctx, watchList)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	return receipt, found, nil
+}
+
+// resolvePendingMintingReceipt is resolveMintingReceipt without the watch list update
+func resolvePendingMintingReceipt(
+	ctx contract.Context,
+	userId string,
+	txId *big.Int,
+	status zb_enums.MintingTransactionReceiptStatus_Enum,
+) (*zb_data.MintingTransactionReceipt, bool, error) {
+	receiptCollection, err := loadPendingMintingTransactionReceipts(ctx, userId)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var receipt *zb_data.MintingTransactionReceipt
+	for i, pendingReceipt := range receiptCollection.Receipts {
+		if pendingReceipt.TxId.Value.Int.Cmp(txId) == 0 {
+			receipt = pendingReceipt
+			receiptCollection.Receipts = append(receiptCollection.Receipts[:i], receiptCollection.Receipts[i+1:]...)
+			break
+		}
+	}
+
+	if receipt != nil {
+		err = savePendingMintingTransactionReceipts(ctx, userId, receiptCollection)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	// an expired receipt can still be redeemed on Plasmachain, so the history is updated even if it wasn't pending
+	history, err := loadMintingTransactionReceiptHistory(ctx, userId)
+	if err != nil {
+		return nil, false, err
+	}
+
+	found := false
+	for _, entry := range history.Entries {
+		if entry.Receipt.TxId.Value.Int.Cmp(txId) == 0 {
+			entry.Status = status
+			entry.UpdatedAt = ctx.Now().Unix()
+			found = true
+			break
+		}
+	}
+
+	// receipts minted before the history was kept
+	if !found && receipt != nil {
+		history.Entries = append(history.Entries, &zb_data.MintingTransactionReceiptHistoryEntry{
+			Receipt:   receipt,
+			Status:    status,
+			UpdatedAt: ctx.Now().Unix(),
+		})
+		found = true
+	}
+
+	if found {
+		err = saveMintingTransactionReceiptHistory(ctx, userId, history)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	return receipt, found, nil
+}
+
+func getMintingReceiptTimeToLive(configuration *zb_data.ContractConfiguration) time.Duration {
+	if configuration != nil && configuration.MintingReceiptTimeToLive > 0 {
+		return time.Duration(configuration.MintingReceiptTimeToLive) * time.Second
+	}
+
+	return defaultMintingReceiptTimeToLive
+}
+
+// SweepMintingReceipts expires the pending receipts that are older than the configured TTL,
+// and asks the oracle to check whether the watched receipts were redeemed on Plasmachain.
+// Expired receipts stay watched, since they can still be redeemed, but are checked less often.
+// Goes through at most maxMintingReceiptSweepCount receipts, the next sweep continues where it stopped.
+// Meant to be called periodically by the oracle.
+func (z *ZombieBattleground) SweepMintingReceipts(ctx contract.Context, req *zb_calls.SweepMintingReceiptsRequest) (*zb_calls.SweepMintingReceiptsResponse, error) {
+	err := z.validateOracle(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	configuration, err := loadContractConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	timeToLive := getMintingReceiptTimeToLive(configuration)
+
+	watchList, err := loadMintingReceiptWatchList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	start := int(watchList.NextSweepIndex)
+	if start >= len(watchList.Entries) {
+		start = 0
+	}
+	end := start + maxMintingReceiptSweepCount
+	if end > len(watchList.Entries) {
+		end = len(watchList.Entries)
+	}
+
+	response := &zb_calls.SweepMintingReceiptsResponse{}
+	entries := make([]*zb_data.MintingReceiptWatchList_Entry, 0, len(watchList.Entries))
+	entries = append(entries, watchList.Entries[:start]...)
+	for _, entry := range watchList.Entries[start:end] {
+		if !entry.Expired && ctx.Now().Sub(time.Unix(entry.CreatedAt, 0)) >= timeToLive {
+			_, _, err = resolvePendingMintingReceipt(ctx, entry.UserId, entry.TxId.Value.Int, zb_enums.MintingTransactionReceiptStatus_Expired)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to expire minting receipt")
+			}
+
+			ctx.Logger().Info("minting receipt expired", "userId", entry.UserId, "txId", entry.TxId.Value.String())
+			entry.Expired = true
+			response.ExpiredReceiptCount++
+		}
+
+		entries = append(entries, entry)
+		checkInterval := mintingReceiptCheckInterval
+		if entry.Expired {
+			checkInterval = expiredMintingReceiptCheckInterval
+		}
+		if entry.LastCheckedAt > 0 && ctx.Now().Sub(time.Unix(entry.LastCheckedAt, 0)) < checkInterval {
+			continue
+		}
+
+		err = z.addVerifyMintingReceiptConsumedOracleCommand(ctx, entry.UserId, entry.TxId.Value.Int)
+		if err != nil {
+			return nil, err
+		}
+		entry.LastCheckedAt = ctx.Now().Unix()
+		response.VerificationRequestCount++
+	}
+
+	watchList.NextSweepIndex = uint64(len(entries))
+	if end == len(watchList.Entries) {
+		watchList.NextSweepIndex = 0
+	}
+	watchList.Entries = append(entries, watchList.Entries[end:]...)
+	err = saveMintingReceiptWatchList(ctx, watchList)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (z *ZombieBattleground) GetMintingTransactionReceiptHistory(ctx contract.StaticContext, req *zb_calls.GetMintingTransactionReceiptHistoryRequest) (*zb_calls.GetMintingTransactionReceiptHistoryResponse, error) {
+	history, err := loadMintingTransactionReceiptHistory(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zb_calls.GetMintingTransactionReceiptHistoryResponse{
+		History: history,
+	}, nil
+}
@@ -0,0 +1,226 @@
+package battleground
+
+import (
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	orctype "github.com/loomnetwork/gamechain/types/oracle"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	"github.com/loomnetwork/go-loom"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	assert "github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestMintingReceiptLifecycle(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "3866f776276246e4f9998aa90632931d89b0d3a5930e804e02299533f55b39e1"
+	var addr loom.Address
+	var ctx contract.Context
+
+	fc := setup(c, pubKeyHexString, &addr, &ctx, t)
+	now := time.Now()
+	fc.SetTime(now)
+
+	userId := "ReceiptUser"
+	setupAccount(c, ctx, &zb_calls.UpsertAccountRequest{
+		UserId:  userId,
+		Version: "v1",
+	}, t)
+
+	err := c.UpdateContractConfiguration(ctx, &zb_calls.UpdateContractConfigurationRequest{
+		SetMintingReceiptTimeToLive: true,
+		MintingReceiptTimeToLive:    3600,
+	})
+	assert.Nil(t, err)
+
+	getHistoryStatuses := func(t *testing.T) map[string]zb_enums.MintingTransactionReceiptStatus_Enum {
+		response, err := c.GetMintingTransactionReceiptHistory(ctx, &zb_calls.GetMintingTransactionReceiptHistoryRequest{
+			UserId: userId,
+		})
+		assert.Nil(t, err)
+
+		statuses := make(map[string]zb_enums.MintingTransactionReceiptStatus_Enum)
+		for _, entry := range response.History.Entries {
+			statuses[entry.Receipt.TxId.Value.String()] = entry.Status
+		}
+		return statuses
+	}
+
+	getPendingReceiptCount := func(t *testing.T) int {
+		response, err := c.GetPendingMintingTransactionReceipts(ctx, &zb_calls.GetPendingMintingTransactionReceiptsRequest{
+			UserId: userId,
+		})
+		assert.Nil(t, err)
+		return len(response.ReceiptCollection.Receipts)
+	}
+
+	var redeemedTxId, confirmedTxId, expiredTxId *big.Int
+	t.Run("Minted receipts are pending", func(t *testing.T) {
+		for _, txId := range []**big.Int{&redeemedTxId, &confirmedTxId, &expiredTxId} {
+			receipt, err := mintBoosterPacksAndSave(ctx, userId, nil, 1)
+			assert.Nil(t, err)
+			*txId = receipt.TxID
+		}
+
+		statuses := getHistoryStatuses(t)
+		assert.Equal(t, 3, len(statuses))
+		for _, status := range statuses {
+			assert.Equal(t, zb_enums.MintingTransactionReceiptStatus_Pending, status)
+		}
+		assert.Equal(t, 3, getPendingReceiptCount(t))
+	})
+
+	t.Run("Client confirmation redeems the receipt", func(t *testing.T) {
+		err := c.ConfirmPendingMintingTransactionReceipt(ctx, &zb_calls.ConfirmPendingMintingTransactionReceiptRequest{
+			UserId: userId,
+			TxId:   battleground_utility.MarshalBigIntProto(confirmedTxId),
+		})
+		assert.Nil(t, err)
+
+		assert.Equal(t, zb_enums.MintingTransactionReceiptStatus_Redeemed, getHistoryStatuses(t)[confirmedTxId.String()])
+		assert.Equal(t, 2, getPendingReceiptCount(t))
+	})
+
+	t.Run("Sweep asks the oracle to verify the pending receipts", func(t *testing.T) {
+		response, err := c.SweepMintingReceipts(ctx, &zb_calls.SweepMintingReceiptsRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), response.ExpiredReceiptCount)
+		assert.Equal(t, int64(2), response.VerificationRequestCount)
+
+		commandResponse, err := c.GetOracleCommandRequestList(ctx, &orctype.GetOracleCommandRequestListRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(commandResponse.CommandRequests))
+
+		var commandResponses []*orctype.OracleCommandResponse
+		for _, commandRequest := range commandResponse.CommandRequests {
+			request := commandRequest.GetVerifyMintingReceiptConsumed()
+			assert.NotNil(t, request)
+			commandResponses = append(commandResponses, &orctype.OracleCommandResponse{
+				CommandId: commandRequest.CommandId,
+				Command: &orctype.OracleCommandResponse_VerifyMintingReceiptConsumed{
+					VerifyMintingReceiptConsumed: &orctype.OracleCommandResponse_VerifyMintingReceiptConsumedCommandResponse{
+						UserId:      request.UserId,
+						TxId:        request.TxId,
+						Consumed:    request.TxId.Value.Int.Cmp(redeemedTxId) == 0,
+						BlockHeight: 10,
+					},
+				},
+			})
+		}
+
+		_, err = c.ProcessOracleCommandResponseBatch(ctx, &orctype.ProcessOracleCommandResponseBatchRequest{
+			CommandResponses: commandResponses,
+		})
+		assert.Nil(t, err)
+
+		statuses := getHistoryStatuses(t)
+		assert.Equal(t, zb_enums.MintingTransactionReceiptStatus_Redeemed, statuses[redeemedTxId.String()])
+		assert.Equal(t, zb_enums.MintingTransactionReceiptStatus_Pending, statuses[expiredTxId.String()])
+		assert.Equal(t, 1, getPendingReceiptCount(t))
+	})
+
+	t.Run("Sweep doesn't check receipts again before the interval", func(t *testing.T) {
+		response, err := c.SweepMintingReceipts(ctx, &zb_calls.SweepMintingReceiptsRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), response.ExpiredReceiptCount)
+		assert.Equal(t, int64(0), response.VerificationRequestCount)
+	})
+
+	t.Run("Sweep expires old receipts", func(t *testing.T) {
+		fc.SetTime(now.Add(2 * time.Hour))
+
+		response, err := c.SweepMintingReceipts(ctx, &zb_calls.SweepMintingReceiptsRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), response.ExpiredReceiptCount)
+		assert.Equal(t, int64(0), response.VerificationRequestCount)
+
+		assert.Equal(t, zb_enums.MintingTransactionReceiptStatus_Expired, getHistoryStatuses(t)[expiredTxId.String()])
+		assert.Equal(t, 0, getPendingReceiptCount(t))
+
+		// expired receipts can still be redeemed on Plasmachain
+		watchList, err := loadMintingReceiptWatchList(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(watchList.Entries))
+		assert.True(t, watchList.Entries[0].Expired)
+	})
+
+	t.Run("History entries keep the receipt", func(t *testing.T) {
+		response, err := c.GetMintingTransactionReceiptHistory(ctx, &zb_calls.GetMintingTransactionReceiptHistoryRequest{
+			UserId: userId,
+		})
+		assert.Nil(t, err)
+
+		var entry *zb_data.MintingTransactionReceiptHistoryEntry
+		for _, historyEntry := range response.History.Entries {
+			if historyEntry.Receipt.TxId.Value.Int.Cmp(expiredTxId) == 0 {
+				entry = historyEntry
+			}
+		}
+		assert.NotNil(t, entry)
+		assert.Equal(t, uint64(1), entry.Receipt.Booster)
+		assert.Equal(t, now.Unix(), entry.CreatedAt)
+		assert.Equal(t, now.Add(2*time.Hour).Unix(), entry.UpdatedAt)
+	})
+
+	t.Run("Expired receipts are checked less often", func(t *testing.T) {
+		fc.SetTime(now.Add(23 * time.Hour))
+
+		response, err := c.SweepMintingReceipts(ctx, &zb_calls.SweepMintingReceiptsRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), response.ExpiredReceiptCount)
+		assert.Equal(t, int64(0), response.VerificationRequestCount)
+
+		fc.SetTime(now.Add(25 * time.Hour))
+
+		response, err = c.SweepMintingReceipts(ctx, &zb_calls.SweepMintingReceiptsRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), response.ExpiredReceiptCount)
+		assert.Equal(t, int64(1), response.VerificationRequestCount)
+	})
+
+	t.Run("Client confirmation redeems an expired receipt", func(t *testing.T) {
+		err := c.ConfirmPendingMintingTransactionReceipt(ctx, &zb_calls.ConfirmPendingMintingTransactionReceiptRequest{
+			UserId: userId,
+			TxId:   battleground_utility.MarshalBigIntProto(expiredTxId),
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, zb_enums.MintingTransactionReceiptStatus_Redeemed, getHistoryStatuses(t)[expiredTxId.String()])
+
+		watchList, err := loadMintingReceiptWatchList(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(watchList.Entries))
+
+		// unknown receipts are still rejected
+		err = c.ConfirmPendingMintingTransactionReceipt(ctx, &zb_calls.ConfirmPendingMintingTransactionReceiptRequest{
+			UserId: userId,
+			TxId:   battleground_utility.MarshalBigIntProto(big.NewInt(1 << 40)),
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Sweep goes through the watch list in pages", func(t *testing.T) {
+		for i := 0; i < maxMintingReceiptSweepCount+1; i++ {
+			_, err := mintBoosterPacksAndSave(ctx, userId, nil, 1)
+			assert.Nil(t, err)
+		}
+
+		response, err := c.SweepMintingReceipts(ctx, &zb_calls.SweepMintingReceiptsRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(maxMintingReceiptSweepCount), response.VerificationRequestCount)
+
+		response, err = c.SweepMintingReceipts(ctx, &zb_calls.SweepMintingReceiptsRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), response.VerificationRequestCount)
+
+		response, err = c.SweepMintingReceipts(ctx, &zb_calls.SweepMintingReceiptsRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), response.VerificationRequestCount)
+	})
+
+	t.Run("Receipts expire by default", func(t *testing.T) {
+		assert.Equal(t, defaultMintingReceiptTimeToLive, getMintingReceiptTimeToLive(&zb_data.ContractConfiguration{}))
+	})
+}
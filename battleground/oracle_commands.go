@@ -2,12 +2,83 @@ package battleground
 
 import (
 	"fmt"
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
 	orctype "github.com/loomnetwork/gamechain/types/oracle"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
 	"github.com/loomnetwork/go-loom"
 	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
 	"github.com/pkg/errors"
+	"math/big"
+	"reflect"
+	"time"
 )
 
+// oracleCommandHandler describes how the contract handles a type of oracle command
+type oracleCommandHandler struct {
+	// time the oracle has to execute the command before it's dropped, 0 if the command doesn't expire
+	ttl time.Duration
+	// number of failures reported by the oracle before the command is dropped, 0 if unlimited
+	maxAttempts int32
+	// type of the successful responses to the command
+	responseType reflect.Type
+	// processes a successful response, an error keeps the command in the list
+	processResponse func(z *ZombieBattleground, ctx contract.Context, response *orctype.OracleCommandResponse) error
+}
+
+// oracleCommandHandlers maps the command request types to their handlers
+var oracleCommandHandlers = map[reflect.Type]*oracleCommandHandler{
+	reflect.TypeOf(&orctype.OracleCommandRequest_GetUserFullCardCollection{}): {
+		ttl:          24 * time.Hour,
+		maxAttempts:  10,
+		responseType: reflect.TypeOf(&orctype.OracleCommandResponse_GetUserFullCardCollection{}),
+		processResponse: func(z *ZombieBattleground, ctx contract.Context, response *orctype.OracleCommandResponse) error {
+			commandResponse := response.GetGetUserFullCardCollection()
+			return z.processOracleCommandResponseGetUserFullCardCollection(
+				ctx,
+				loom.UnmarshalAddressPB(commandResponse.UserAddress),
+				commandResponse.OwnedCards,
+				commandResponse.BlockHeight,
+			)
+		},
+	},
+	reflect.TypeOf(&orctype.OracleCommandRequest_VerifyMintingReceiptConsumed{}): {
+		ttl:          24 * time.Hour,
+		maxAttempts:  10,
+		responseType: reflect.TypeOf(&orctype.OracleCommandResponse_VerifyMintingReceiptConsumed{}),
+		processResponse: func(z *ZombieBattleground, ctx contract.Context, response *orctype.OracleCommandResponse) error {
+			return z.processOracleCommandResponseVerifyMintingReceiptConsumed(ctx, response.GetVerifyMintingReceiptConsumed())
+		},
+	},
+	reflect.TypeOf(&orctype.OracleCommandRequest_GetPackBalances{}): {
+		ttl:          time.Hour,
+		maxAttempts:  5,
+		responseType: reflect.TypeOf(&orctype.OracleCommandResponse_GetPackBalances{}),
+		processResponse: func(z *ZombieBattleground, ctx contract.Context, response *orctype.OracleCommandResponse) error {
+			return z.processOracleCommandResponseGetPackBalances(ctx, response.GetGetPackBalances())
+		},
+	},
+}
+
+func isOracleCommandExpired(request *orctype.OracleCommandRequest, now time.Time) bool {
+	return request.ExpiresAt > 0 && now.Unix() >= request.ExpiresAt
+}
+
+// filterExpiredOracleCommands returns the commands that haven't expired yet
+func filterExpiredOracleCommands(ctx contract.StaticContext, requests []*orctype.OracleCommandRequest) []*orctype.OracleCommandRequest {
+	filteredRequests := make([]*orctype.OracleCommandRequest, 0, len(requests))
+	for _, request := range requests {
+		if isOracleCommandExpired(request, ctx.Now()) {
+			ctx.Logger().Info("oracle command expired", "commandId", request.CommandId, "commandType", fmt.Sprint(reflect.TypeOf(request.Command)))
+			continue
+		}
+
+		filteredRequests = append(filteredRequests, request)
+	}
+
+	return filteredRequests
+}
+
 func createBaseOracleCommand(ctx contract.StaticContext) (*orctype.OracleCommandRequest, error) {
 	contractState, err := loadContractState(ctx)
 	if err != nil {
@@ -52,52 +123,144 @@ func (z *ZombieBattleground) addGetUserFullCardCollectionOracleCommand(ctx contr
 	return nil
 }
 
+func (z *ZombieBattleground) addVerifyMintingReceiptConsumedOracleCommand(ctx contract.Context, userId string, txId *big.Int) error {
+	command, err := createBaseOracleCommand(ctx)
+	if err != nil {
+		return errors.Wrap(err, "addVerifyMintingReceiptConsumedOracleCommand")
+	}
+
+	command.Command = &orctype.OracleCommandRequest_VerifyMintingReceiptConsumed{
+		VerifyMintingReceiptConsumed: &orctype.OracleCommandRequest_VerifyMintingReceiptConsumedCommandRequest{
+			UserId: userId,
+			TxId:   battleground_utility.MarshalBigIntProto(txId),
+		},
+	}
+
+	err = z.saveOracleCommandRequestToList(ctx, command, func(request *orctype.OracleCommandRequest) (mustRemove bool) {
+		// Remove any other request for the same receipt
+		commandRequest := request.GetVerifyMintingReceiptConsumed()
+		return commandRequest != nil && commandRequest.UserId == userId && commandRequest.TxId.Value.Int.Cmp(txId) == 0
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "addVerifyMintingReceiptConsumedOracleCommand")
+	}
+
+	return nil
+}
+
+func (z *ZombieBattleground) addGetPackBalancesOracleCommand(ctx contract.Context, address loom.Address) error {
+	command, err := createBaseOracleCommand(ctx)
+	if err != nil {
+		return errors.Wrap(err, "addGetPackBalancesOracleCommand")
+	}
+
+	command.Command = &orctype.OracleCommandRequest_GetPackBalances{
+		GetPackBalances: &orctype.OracleCommandRequest_GetPackBalancesCommandRequest{
+			UserAddress: address.MarshalPB(),
+		},
+	}
+
+	err = z.saveOracleCommandRequestToList(ctx, command, func(request *orctype.OracleCommandRequest) (mustRemove bool) {
+		// Remove any other request for the same address
+		return request.GetGetPackBalances() != nil && loom.UnmarshalAddressPB(request.GetGetPackBalances().UserAddress).Compare(address) == 0
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "addGetPackBalancesOracleCommand")
+	}
+
+	return nil
+}
+
+func (z *ZombieBattleground) processOracleCommandResponseVerifyMintingReceiptConsumed(
+	ctx contract.Context,
+	response *orctype.OracleCommandResponse_VerifyMintingReceiptConsumedCommandResponse,
+) error {
+	ctx.Logger().Debug(
+		"processOracleCommandResponseVerifyMintingReceiptConsumed",
+		"userId", response.UserId,
+		"txId", response.TxId.Value.String(),
+		"consumed", response.Consumed,
+	)
+
+	if !response.Consumed {
+		return nil
+	}
+
+	_, _, err := resolveMintingReceipt(ctx, response.UserId, response.TxId.Value.Int, zb_enums.MintingTransactionReceiptStatus_Redeemed)
+	return err
+}
+
+func (z *ZombieBattleground) processOracleCommandResponseGetPackBalances(
+	ctx contract.Context,
+	response *orctype.OracleCommandResponse_GetPackBalancesCommandResponse,
+) error {
+	userAddress := loom.UnmarshalAddressPB(response.UserAddress)
+	ctx.Logger().Debug(
+		"processOracleCommandResponseGetPackBalances",
+		"userAddress", userAddress.String(),
+		"len(packBalances)", len(response.PackBalances),
+		"plasmachainBlockHeight", response.BlockHeight,
+	)
+
+	if response.BlockHeight == 0 {
+		return errors.Wrap(errors.New("plasmachainBlockHeight == 0"), "processOracleCommandResponseGetPackBalances")
+	}
+
+	found, userId, err := loadUserIdByAddress(ctx, userAddress)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		ctx.Logger().Info("failed to get user id by address", "address", userAddress.String())
+		return nil
+	}
+
+	packBalanceList := &zb_data.PackBalanceList{
+		PlasmachainBlockHeight: response.BlockHeight,
+	}
+	for _, rawPackBalance := range response.PackBalances {
+		packBalanceList.PackBalances = append(packBalanceList.PackBalances, &zb_data.PackBalance{
+			Type:   rawPackBalance.PackType,
+			Amount: rawPackBalance.Amount.Value.Uint64(),
+		})
+	}
+
+	return savePackBalances(ctx, userId, packBalanceList)
+}
+
 func (z *ZombieBattleground) processOracleCommandResponseBatchInternal(ctx contract.Context, commandResponses []*orctype.OracleCommandResponse) error {
 	commandRequestList, err := loadOracleCommandRequestList(ctx)
 	if err != nil {
 		return errors.Wrap(err, "processOracleCommandResponseBatchInternal")
 	}
 
-	unhandledCommandRequests := make([]*orctype.OracleCommandRequest, 0)
-	for _, commandResponseOneOf := range commandResponses {
-		// check if a command with such id even exists
-		var matchingCommandRequest *orctype.OracleCommandRequest = nil
-		for _, existingCommandRequest := range commandRequestList.Commands {
-			if existingCommandRequest.CommandId == commandResponseOneOf.CommandId {
-				matchingCommandRequest = existingCommandRequest
-				break
-			}
-		}
+	commandIdToResponse := make(map[uint64]*orctype.OracleCommandResponse)
+	for _, commandResponse := range commandResponses {
+		commandIdToResponse[commandResponse.CommandId] = commandResponse
+	}
 
-		if matchingCommandRequest == nil {
-			ctx.Logger().Warn(fmt.Errorf("unknown oracle command with id %d", commandResponseOneOf.CommandId).Error())
+	unhandledCommandRequests := make([]*orctype.OracleCommandRequest, 0)
+	for _, commandRequest := range commandRequestList.Commands {
+		commandResponse, exists := commandIdToResponse[commandRequest.CommandId]
+		if !exists {
+			unhandledCommandRequests = append(unhandledCommandRequests, commandRequest)
 			continue
 		}
+		delete(commandIdToResponse, commandRequest.CommandId)
 
-		err = nil
-		switch commandResponse := commandResponseOneOf.Command.(type) {
-		case *orctype.OracleCommandResponse_GetUserFullCardCollection:
-			err = z.processOracleCommandResponseGetUserFullCardCollection(
-				ctx,
-				loom.UnmarshalAddressPB(commandResponse.GetUserFullCardCollection.UserAddress),
-				commandResponse.GetUserFullCardCollection.OwnedCards,
-				commandResponse.GetUserFullCardCollection.BlockHeight,
-			)
-			if err != nil {
-				err = errors.Wrap(err, "processOracleCommandResponseGetUserFullCardCollection failed")
-			}
-			break
+		if z.processOracleCommandResponse(ctx, commandRequest, commandResponse) {
+			unhandledCommandRequests = append(unhandledCommandRequests, commandRequest)
 		}
+	}
 
-		// We allow single commands to fail
-		// Just keep them unconfirmed until something is fixed for it to become processed and confirmed.
-		if err != nil {
-			unhandledCommandRequests = append(unhandledCommandRequests, matchingCommandRequest)
-			ctx.Logger().Error(errors.Wrap(err, "error processing oracle command response").Error())
-		}
+	for commandId := range commandIdToResponse {
+		ctx.Logger().Warn(fmt.Errorf("unknown oracle command with id %d", commandId).Error())
 	}
 
-	commandRequestList.Commands = unhandledCommandRequests
+	commandRequestList.Commands = filterExpiredOracleCommands(ctx, unhandledCommandRequests)
 	err = saveOracleCommandRequestList(ctx, commandRequestList)
 	if err != nil {
 		return errors.Wrap(err, "processOracleCommandResponseBatchInternal")
@@ -106,6 +269,59 @@ func (z *ZombieBattleground) processOracleCommandResponseBatchInternal(ctx contr
 	return nil
 }
 
+// processOracleCommandResponse handles a response to the command and returns whether the command has to stay in the list
+func (z *ZombieBattleground) processOracleCommandResponse(
+	ctx contract.Context,
+	commandRequest *orctype.OracleCommandRequest,
+	commandResponse *orctype.OracleCommandResponse,
+) (keep bool) {
+	commandType := reflect.TypeOf(commandRequest.Command)
+	handler, exists := oracleCommandHandlers[commandType]
+	if !exists {
+		ctx.Logger().Error("no handler for oracle command, dropping it", "commandId", commandRequest.CommandId, "commandType", fmt.Sprint(commandType))
+		return false
+	}
+
+	if failure := commandResponse.GetFailure(); failure != nil {
+		commandRequest.AttemptCount++
+		ctx.Logger().Warn(
+			"oracle failed to execute command",
+			"commandId", commandRequest.CommandId,
+			"commandType", fmt.Sprint(commandType),
+			"attemptCount", commandRequest.AttemptCount,
+			"permanent", failure.Permanent,
+			"err", failure.Error,
+		)
+
+		if failure.Permanent || (commandRequest.MaxAttempts > 0 && commandRequest.AttemptCount >= commandRequest.MaxAttempts) {
+			ctx.Logger().Error("dropping oracle command after failure", "commandId", commandRequest.CommandId, "commandType", fmt.Sprint(commandType))
+			return false
+		}
+
+		return true
+	}
+
+	if responseType := reflect.TypeOf(commandResponse.Command); responseType != handler.responseType {
+		ctx.Logger().Error(
+			"oracle command response doesn't match the request",
+			"commandId", commandRequest.CommandId,
+			"commandType", fmt.Sprint(commandType),
+			"responseType", fmt.Sprint(responseType),
+		)
+		return true
+	}
+
+	// We allow single commands to fail
+	// Just keep them unconfirmed until something is fixed for it to become processed and confirmed.
+	err := handler.processResponse(z, ctx, commandResponse)
+	if err != nil {
+		ctx.Logger().Error(errors.Wrapf(err, "error processing oracle command response of type %v", commandType).Error())
+		return true
+	}
+
+	return false
+}
+
 func (z *ZombieBattleground) saveOracleCommandRequestToList(
 	ctx contract.Context,
 	commandRequest *orctype.OracleCommandRequest,
@@ -122,9 +338,17 @@ func (z *ZombieBattleground) saveOracleCommandRequestToList(
 		return err
 	}
 
+	commandRequest.CreatedAt = ctx.Now().Unix()
+	if handler, exists := oracleCommandHandlers[reflect.TypeOf(commandRequest.Command)]; exists {
+		if handler.ttl > 0 {
+			commandRequest.ExpiresAt = ctx.Now().Add(handler.ttl).Unix()
+		}
+		commandRequest.MaxAttempts = handler.maxAttempts
+	}
+
 	// filter out commands. Useful for commands that must be unique in list
 	filteredCommandsRequests := make([]*orctype.OracleCommandRequest, 0)
-	for _, commandRequest := range commandRequestList.Commands {
+	for _, commandRequest := range filterExpiredOracleCommands(ctx, commandRequestList.Commands) {
 		if !filterSameTypePredicate(commandRequest) {
 			filteredCommandsRequests = append(filteredCommandsRequests, commandRequest)
 		}
@@ -3,10 +3,14 @@ package battleground
 import (
 	"github.com/loomnetwork/gamechain/tools/battleground_utility"
 	orctype "github.com/loomnetwork/gamechain/types/oracle"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
 	"github.com/loomnetwork/go-loom"
 	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	"github.com/loomnetwork/go-loom/types"
 	assert "github.com/stretchr/testify/require"
 	"math/big"
+	"strings"
 	"testing"
 )
 
@@ -61,6 +65,267 @@ func TestProcessEventBatch(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestRollbackOracleEventBatch(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "3866f776276246e4f9998aa90632931d89b0d3a5930e804e02299533f55b39e1"
+	var addr loom.Address
+	var ctx contract.Context
+
+	setup(c, pubKeyHexString, &addr, &ctx, t)
+
+	zbgCardContractAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000099")
+	userAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000002")
+	userPendingAddress := loom.Address{
+		ChainID: ctx.Message().Sender.ChainID,
+		Local:   userAddress.Local,
+	}
+
+	err := c.ProcessOracleEventBatch(ctx, &orctype.ProcessOracleEventBatchRequest{
+		LastPlasmachainBlockNumber: 123,
+		ZbgCardContractAddress:     zbgCardContractAddress.MarshalPB(),
+		Events: []*orctype.PlasmachainEvent{
+			{
+				EthBlock: 121,
+				Payload: &orctype.PlasmachainEvent_TransferWithQuantity{
+					TransferWithQuantity: &orctype.PlasmachainEventTransferWithQuantity{
+						Amount:  battleground_utility.MarshalBigIntProto(big.NewInt(3)),
+						TokenId: battleground_utility.MarshalBigIntProto(big.NewInt(100)),
+						From:    zbgCardContractAddress.MarshalPB(),
+						To:      userAddress.MarshalPB(),
+					},
+				},
+			},
+		},
+	})
+	assert.Nil(t, err)
+
+	container, err := loadPendingCardAmountChangesContainerByAddress(ctx, userPendingAddress)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(container.CardAmountChanges))
+	assert.Equal(t, int64(3), container.CardAmountChanges[0].AmountChange)
+
+	t.Run("Rollback undoes card amount changes", func(t *testing.T) {
+		_, err := c.RollbackOracleEventBatch(ctx, &orctype.RollbackOracleEventBatchRequest{
+			LastPlasmachainBlockNumber: 120,
+			CardAmountChanges: []*orctype.PlasmachainCardAmountChange{
+				{
+					Address:      userAddress.MarshalPB(),
+					CardTokenId:  battleground_utility.MarshalBigIntProto(big.NewInt(100)),
+					AmountChange: -3,
+				},
+			},
+		})
+		assert.Nil(t, err)
+
+		container, err := loadPendingCardAmountChangesContainerByAddress(ctx, userPendingAddress)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(container.CardAmountChanges))
+		assert.Equal(t, int64(0), container.CardAmountChanges[0].AmountChange)
+
+		state, err := loadContractState(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(120), state.LastPlasmachainBlockNumber)
+	})
+
+	t.Run("Rollback to an unprocessed block fails", func(t *testing.T) {
+		_, err := c.RollbackOracleEventBatch(ctx, &orctype.RollbackOracleEventBatchRequest{
+			LastPlasmachainBlockNumber: 120,
+		})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestProcessOracleEventBatchRejectsAppliedEvents(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "3866f776276246e4f9998aa90632931d89b0d3a5930e804e02299533f55b39e1"
+	var addr loom.Address
+	var ctx contract.Context
+
+	setup(c, pubKeyHexString, &addr, &ctx, t)
+
+	zbgCardContractAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000099")
+	userAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000002")
+	userPendingAddress := loom.Address{
+		ChainID: ctx.Message().Sender.ChainID,
+		Local:   userAddress.Local,
+	}
+	newMintEvent := func(txIndex uint64, logIndex uint64, amount int64) *orctype.PlasmachainEvent {
+		return &orctype.PlasmachainEvent{
+			EthBlock: 121,
+			TxIndex:  txIndex,
+			LogIndex: logIndex,
+			Payload: &orctype.PlasmachainEvent_TransferWithQuantity{
+				TransferWithQuantity: &orctype.PlasmachainEventTransferWithQuantity{
+					Amount:  battleground_utility.MarshalBigIntProto(big.NewInt(amount)),
+					TokenId: battleground_utility.MarshalBigIntProto(big.NewInt(100)),
+					From:    zbgCardContractAddress.MarshalPB(),
+					To:      userAddress.MarshalPB(),
+				},
+			},
+		}
+	}
+	requireAmountChange := func(t *testing.T, amountChange int64) {
+		container, err := loadPendingCardAmountChangesContainerByAddress(ctx, userPendingAddress)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(container.CardAmountChanges))
+		assert.Equal(t, amountChange, container.CardAmountChanges[0].AmountChange)
+	}
+	moveLastBlockBack := func(t *testing.T) {
+		err := c.SetLastPlasmaBlockNumber(ctx, &zb_calls.SetLastPlasmaBlockNumberRequest{
+			LastPlasmachainBlockNumber: 120,
+		})
+		assert.Nil(t, err)
+	}
+
+	err := c.ProcessOracleEventBatch(ctx, &orctype.ProcessOracleEventBatchRequest{
+		LastPlasmachainBlockNumber: 123,
+		ZbgCardContractAddress:     zbgCardContractAddress.MarshalPB(),
+		Events:                     []*orctype.PlasmachainEvent{newMintEvent(0, 0, 3)},
+	})
+	assert.Nil(t, err)
+	requireAmountChange(t, 3)
+
+	t.Run("Applied events are skipped after the last block is moved back", func(t *testing.T) {
+		moveLastBlockBack(t)
+
+		err := c.ProcessOracleEventBatch(ctx, &orctype.ProcessOracleEventBatchRequest{
+			LastPlasmachainBlockNumber: 123,
+			ZbgCardContractAddress:     zbgCardContractAddress.MarshalPB(),
+			Events:                     []*orctype.PlasmachainEvent{newMintEvent(0, 0, 3)},
+		})
+		assert.Nil(t, err)
+		requireAmountChange(t, 3)
+
+		state, err := loadContractState(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(123), state.LastPlasmachainBlockNumber)
+	})
+
+	t.Run("Other events of the same block are applied", func(t *testing.T) {
+		moveLastBlockBack(t)
+
+		err := c.ProcessOracleEventBatch(ctx, &orctype.ProcessOracleEventBatchRequest{
+			LastPlasmachainBlockNumber: 123,
+			ZbgCardContractAddress:     zbgCardContractAddress.MarshalPB(),
+			Events:                     []*orctype.PlasmachainEvent{newMintEvent(0, 0, 3), newMintEvent(1, 2, 2)},
+		})
+		assert.Nil(t, err)
+		requireAmountChange(t, 5)
+	})
+
+	t.Run("Rolled back events are applied again", func(t *testing.T) {
+		_, err := c.RollbackOracleEventBatch(ctx, &orctype.RollbackOracleEventBatchRequest{
+			LastPlasmachainBlockNumber: 120,
+			CardAmountChanges: []*orctype.PlasmachainCardAmountChange{
+				{
+					Address:      userAddress.MarshalPB(),
+					CardTokenId:  battleground_utility.MarshalBigIntProto(big.NewInt(100)),
+					AmountChange: -5,
+				},
+			},
+		})
+		assert.Nil(t, err)
+		requireAmountChange(t, 0)
+
+		err = c.ProcessOracleEventBatch(ctx, &orctype.ProcessOracleEventBatchRequest{
+			LastPlasmachainBlockNumber: 123,
+			ZbgCardContractAddress:     zbgCardContractAddress.MarshalPB(),
+			Events:                     []*orctype.PlasmachainEvent{newMintEvent(0, 0, 3), newMintEvent(1, 2, 2)},
+		})
+		assert.Nil(t, err)
+		requireAmountChange(t, 5)
+	})
+
+	t.Run("Old events are dropped from the window", func(t *testing.T) {
+		window := &orctype.ProcessedOracleEventWindow{
+			EventIds: []*orctype.PlasmachainEventId{{EthBlock: 100}, {EthBlock: 1100}, {EthBlock: 1200}},
+		}
+		pruneProcessedOracleEventWindow(window, 1100+processedOracleEventWindowBlockCount-1)
+		assert.Equal(t, 2, len(window.EventIds))
+		assert.Equal(t, uint64(1100), window.EventIds[0].EthBlock)
+	})
+}
+
+func TestQuarantineOracleEvents(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "3866f776276246e4f9998aa90632931d89b0d3a5930e804e02299533f55b39e1"
+	var addr loom.Address
+	var ctx contract.Context
+
+	setup(c, pubKeyHexString, &addr, &ctx, t)
+
+	zbgCardContractAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000099")
+	userAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000002")
+	userPendingAddress := loom.Address{
+		ChainID: ctx.Message().Sender.ChainID,
+		Local:   userAddress.Local,
+	}
+
+	err := c.SetLastPlasmaBlockNumber(ctx, &zb_calls.SetLastPlasmaBlockNumberRequest{
+		LastPlasmachainBlockNumber: 120,
+	})
+	assert.Nil(t, err)
+
+	event := &orctype.PlasmachainEvent{
+		EthBlock: 122,
+		Payload: &orctype.PlasmachainEvent_TransferWithQuantity{
+			TransferWithQuantity: &orctype.PlasmachainEventTransferWithQuantity{
+				Amount:  battleground_utility.MarshalBigIntProto(big.NewInt(3)),
+				TokenId: battleground_utility.MarshalBigIntProto(big.NewInt(100)),
+				From:    zbgCardContractAddress.MarshalPB(),
+				To:      userAddress.MarshalPB(),
+			},
+		},
+	}
+
+	t.Run("Quarantined events are recorded but not applied", func(t *testing.T) {
+		err := c.QuarantineOracleEvents(ctx, &orctype.QuarantineOracleEventsRequest{
+			Events:                     []*orctype.PlasmachainEvent{event},
+			LastPlasmachainBlockNumber: 122,
+			Error:                      "out of gas",
+			ZbgCardContractAddress:     zbgCardContractAddress.MarshalPB(),
+		})
+		assert.Nil(t, err)
+
+		state, err := loadContractState(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(122), state.LastPlasmachainBlockNumber)
+
+		container, err := loadPendingCardAmountChangesContainerByAddress(ctx, userPendingAddress)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(container.CardAmountChanges))
+
+		response, err := c.GetQuarantinedOracleEvents(ctx, &orctype.GetQuarantinedOracleEventsRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(response.Batches))
+		assert.Equal(t, "out of gas", response.Batches[0].Error)
+		assert.Equal(t, uint64(122), response.Batches[0].LastPlasmachainBlockNumber)
+		assert.Equal(t, 1, len(response.Batches[0].Events))
+	})
+
+	t.Run("Quarantined events queue collection syncs", func(t *testing.T) {
+		response, err := c.GetOracleCommandRequestList(ctx, &orctype.GetOracleCommandRequestListRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(response.CommandRequests))
+
+		commandRequest := response.CommandRequests[0].GetGetUserFullCardCollection()
+		assert.NotNil(t, commandRequest)
+		assert.Equal(t, 0, loom.UnmarshalAddressPB(commandRequest.UserAddress).Compare(userAddress))
+	})
+
+	t.Run("Processed blocks can't be quarantined", func(t *testing.T) {
+		err := c.QuarantineOracleEvents(ctx, &orctype.QuarantineOracleEventsRequest{
+			Events:                     []*orctype.PlasmachainEvent{event},
+			LastPlasmachainBlockNumber: 123,
+		})
+		assert.NotNil(t, err)
+
+		response, err := c.GetQuarantinedOracleEvents(ctx, &orctype.GetQuarantinedOracleEventsRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(response.Batches))
+	})
+}
+
 func TestCreateOracleCommandRequest(t *testing.T) {
 	c := &ZombieBattleground{}
 	var pubKeyHexString = "3866f776276246e4f9998aa90632931d89b0d3a5930e804e02299533f55b39e1"
@@ -206,4 +471,218 @@ func TestGetUserFullCardCollectionCommandRequest(t *testing.T) {
 		assert.NotNil(t, state)
 		assert.Equal(t, uint64(3), state.CurrentOracleCommandId)
 	})
+
+	t.Run("RequestFullCardCollectionSyncBatch queues a sync per address", func(t *testing.T) {
+		user3Address := loom.MustParseAddress("default:0x0000000000000000000000000000000000000005")
+		err := c.RequestFullCardCollectionSyncBatch(ctx, &orctype.RequestFullCardCollectionSyncBatchRequest{
+			Addresses: []*types.Address{user1Address.MarshalPB(), user3Address.MarshalPB()},
+		})
+		assert.Nil(t, err)
+
+		commandRequestList, err := loadOracleCommandRequestList(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(commandRequestList.Commands))
+		assert.Equal(t, user2Address.String(), loom.UnmarshalAddressPB(commandRequestList.Commands[0].GetGetUserFullCardCollection().UserAddress).String())
+		assert.Equal(t, user1Address.String(), loom.UnmarshalAddressPB(commandRequestList.Commands[1].GetGetUserFullCardCollection().UserAddress).String())
+		assert.Equal(t, user3Address.String(), loom.UnmarshalAddressPB(commandRequestList.Commands[2].GetGetUserFullCardCollection().UserAddress).String())
+	})
+}
+
+func TestOracleCommandHandlers(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "5f2a6c3e9d1b4a7f8e0c2d4b6a8f1e3c5d7b9a0f2e4c6d8b1a3f5e7c9d0b2a4f"
+	var addr loom.Address
+	var ctx contract.Context
+
+	setup(c, pubKeyHexString, &addr, &ctx, t)
+	setupAccount(c, ctx, &zb_calls.UpsertAccountRequest{
+		UserId:  "OracleCommandUser",
+		Version: "v1",
+	}, t)
+
+	getCommandRequests := func(t *testing.T) []*orctype.OracleCommandRequest {
+		response, err := c.GetOracleCommandRequestList(ctx, &orctype.GetOracleCommandRequestListRequest{})
+		assert.Nil(t, err)
+		return response.CommandRequests
+	}
+
+	requestPackBalancesSync := func(t *testing.T) *orctype.OracleCommandRequest {
+		_, err := c.RequestPackBalancesSync(ctx, &zb_calls.RequestPackBalancesSyncRequest{
+			UserId: "OracleCommandUser",
+		})
+		assert.Nil(t, err)
+
+		commandRequests := getCommandRequests(t)
+		assert.Equal(t, 1, len(commandRequests))
+		return commandRequests[0]
+	}
+
+	newFailureResponse := func(commandId uint64, permanent bool) *orctype.OracleCommandResponse {
+		return &orctype.OracleCommandResponse{
+			CommandId: commandId,
+			Command: &orctype.OracleCommandResponse_Failure{
+				Failure: &orctype.OracleCommandResponse_CommandFailure{
+					Error:     "failed",
+					Permanent: permanent,
+				},
+			},
+		}
+	}
+
+	t.Run("Handlers expect the response of their command", func(t *testing.T) {
+		assert.Equal(t, 3, len(oracleCommandHandlers))
+		for requestType, handler := range oracleCommandHandlers {
+			assert.Equal(
+				t,
+				strings.TrimPrefix(requestType.Elem().Name(), "OracleCommandRequest_"),
+				strings.TrimPrefix(handler.responseType.Elem().Name(), "OracleCommandResponse_"),
+			)
+		}
+	})
+
+	t.Run("Commands without handler are dropped", func(t *testing.T) {
+		keep := c.processOracleCommandResponse(ctx, &orctype.OracleCommandRequest{CommandId: 1000}, newFailureResponse(1000, false))
+		assert.False(t, keep)
+	})
+
+	t.Run("GetPackBalances response is saved", func(t *testing.T) {
+		commandRequest := requestPackBalancesSync(t)
+		assert.NotNil(t, commandRequest.GetGetPackBalances())
+		assert.Equal(t, addr.String(), loom.UnmarshalAddressPB(commandRequest.GetGetPackBalances().UserAddress).String())
+		assert.Equal(t, ctx.Now().Unix(), commandRequest.CreatedAt)
+		assert.True(t, commandRequest.ExpiresAt > 0)
+		assert.Equal(t, int32(5), commandRequest.MaxAttempts)
+
+		err := c.processOracleCommandResponseBatchInternal(ctx, []*orctype.OracleCommandResponse{
+			{
+				CommandId: commandRequest.CommandId,
+				Command: &orctype.OracleCommandResponse_GetPackBalances{
+					GetPackBalances: &orctype.OracleCommandResponse_GetPackBalancesCommandResponse{
+						UserAddress: addr.MarshalPB(),
+						BlockHeight: 10,
+						PackBalances: []*orctype.RawPackBalance{
+							{PackType: zb_enums.PackType_Booster, Amount: battleground_utility.MarshalBigIntProto(big.NewInt(3))},
+						},
+					},
+				},
+			},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(getCommandRequests(t)))
+
+		response, err := c.GetPackBalances(ctx, &zb_calls.GetPackBalancesRequest{
+			UserId: "OracleCommandUser",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(10), response.PackBalanceList.PlasmachainBlockHeight)
+		assert.Equal(t, 1, len(response.PackBalanceList.PackBalances))
+		assert.Equal(t, zb_enums.PackType_Booster, response.PackBalanceList.PackBalances[0].Type)
+		assert.Equal(t, uint64(3), response.PackBalanceList.PackBalances[0].Amount)
+	})
+
+	t.Run("Mismatched response keeps the command", func(t *testing.T) {
+		commandRequest := requestPackBalancesSync(t)
+		err := c.processOracleCommandResponseBatchInternal(ctx, []*orctype.OracleCommandResponse{
+			{
+				CommandId: commandRequest.CommandId,
+				Command: &orctype.OracleCommandResponse_GetUserFullCardCollection{
+					GetUserFullCardCollection: &orctype.OracleCommandResponse_GetUserFullCardCollectionCommandResponse{
+						UserAddress: addr.MarshalPB(),
+						BlockHeight: 10,
+					},
+				},
+			},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(getCommandRequests(t)))
+	})
+
+	t.Run("Failed command is retried until max attempts", func(t *testing.T) {
+		commandRequest := requestPackBalancesSync(t)
+		for attempt := int32(1); attempt < commandRequest.MaxAttempts; attempt++ {
+			err := c.processOracleCommandResponseBatchInternal(ctx, []*orctype.OracleCommandResponse{
+				newFailureResponse(commandRequest.CommandId, false),
+			})
+			assert.Nil(t, err)
+
+			commandRequests := getCommandRequests(t)
+			assert.Equal(t, 1, len(commandRequests))
+			assert.Equal(t, attempt, commandRequests[0].AttemptCount)
+		}
+
+		err := c.processOracleCommandResponseBatchInternal(ctx, []*orctype.OracleCommandResponse{
+			newFailureResponse(commandRequest.CommandId, false),
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(getCommandRequests(t)))
+	})
+
+	t.Run("Permanent failure drops the command", func(t *testing.T) {
+		commandRequest := requestPackBalancesSync(t)
+		err := c.processOracleCommandResponseBatchInternal(ctx, []*orctype.OracleCommandResponse{
+			newFailureResponse(commandRequest.CommandId, true),
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(getCommandRequests(t)))
+	})
+
+	t.Run("Expired commands are not returned", func(t *testing.T) {
+		commandRequest := requestPackBalancesSync(t)
+		commandRequestList, err := loadOracleCommandRequestList(ctx)
+		assert.Nil(t, err)
+		commandRequestList.Commands[0].ExpiresAt = ctx.Now().Unix() - 1
+		err = saveOracleCommandRequestList(ctx, commandRequestList)
+		assert.Nil(t, err)
+
+		assert.Equal(t, 0, len(getCommandRequests(t)))
+
+		// a late response is ignored and the command is removed
+		err = c.processOracleCommandResponseBatchInternal(ctx, []*orctype.OracleCommandResponse{
+			newFailureResponse(commandRequest.CommandId, false),
+		})
+		assert.Nil(t, err)
+		commandRequestList, err = loadOracleCommandRequestList(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(commandRequestList.Commands))
+	})
+
+	t.Run("VerifyMintingReceiptConsumed removes consumed receipts", func(t *testing.T) {
+		consumedReceipt, err := mintBoosterPacksAndSave(ctx, "OracleCommandUser", nil, 1)
+		assert.Nil(t, err)
+		pendingReceipt, err := mintBoosterPacksAndSave(ctx, "OracleCommandUser", nil, 1)
+		assert.Nil(t, err)
+
+		_, err = c.RequestPendingMintingTransactionReceiptsVerification(ctx, &zb_calls.RequestPendingMintingTransactionReceiptsVerificationRequest{
+			UserId: "OracleCommandUser",
+		})
+		assert.Nil(t, err)
+
+		commandRequests := getCommandRequests(t)
+		assert.Equal(t, 2, len(commandRequests))
+
+		var commandResponses []*orctype.OracleCommandResponse
+		for _, commandRequest := range commandRequests {
+			txId := commandRequest.GetVerifyMintingReceiptConsumed().TxId
+			commandResponses = append(commandResponses, &orctype.OracleCommandResponse{
+				CommandId: commandRequest.CommandId,
+				Command: &orctype.OracleCommandResponse_VerifyMintingReceiptConsumed{
+					VerifyMintingReceiptConsumed: &orctype.OracleCommandResponse_VerifyMintingReceiptConsumedCommandResponse{
+						UserId:      "OracleCommandUser",
+						TxId:        txId,
+						Consumed:    txId.Value.Int.Cmp(consumedReceipt.TxID) == 0,
+						BlockHeight: 10,
+					},
+				},
+			})
+		}
+
+		err = c.processOracleCommandResponseBatchInternal(ctx, commandResponses)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(getCommandRequests(t)))
+
+		receiptCollection, err := loadPendingMintingTransactionReceipts(ctx, "OracleCommandUser")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(receiptCollection.Receipts))
+		assert.Equal(t, 0, receiptCollection.Receipts[0].TxId.Value.Int.Cmp(pendingReceipt.TxID))
+	})
 }
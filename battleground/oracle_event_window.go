@@ -0,0 +1,59 @@
+package battleground
+
+import (
+	"fmt"
+	orctype "github.com/loomnetwork/gamechain/types/oracle"
+)
+
+// Number of Plasmachain blocks, counting back from the last processed one, whose applied events are remembered,
+// so they aren't applied again even if the last processed block is moved back.
+const processedOracleEventWindowBlockCount = 1000
+
+func plasmachainEventIdKey(ethBlock uint64, txIndex uint64, logIndex uint64) string {
+	return fmt.Sprintf("%d:%d:%d", ethBlock, txIndex, logIndex)
+}
+
+func newPlasmachainEventId(event *orctype.PlasmachainEvent) *orctype.PlasmachainEventId {
+	return &orctype.PlasmachainEventId{
+		EthBlock: event.EthBlock,
+		TxIndex:  event.TxIndex,
+		LogIndex: event.LogIndex,
+	}
+}
+
+// getProcessedOracleEventIdSet returns the keys of the event ids in the window
+func getProcessedOracleEventIdSet(window *orctype.ProcessedOracleEventWindow) map[string]bool {
+	eventIdSet := make(map[string]bool, len(window.EventIds))
+	for _, eventId := range window.EventIds {
+		eventIdSet[plasmachainEventIdKey(eventId.EthBlock, eventId.TxIndex, eventId.LogIndex)] = true
+	}
+	return eventIdSet
+}
+
+// pruneProcessedOracleEventWindow drops the events from blocks that are too old to be submitted again
+func pruneProcessedOracleEventWindow(window *orctype.ProcessedOracleEventWindow, lastPlasmachainBlockNumber uint64) {
+	if lastPlasmachainBlockNumber < processedOracleEventWindowBlockCount {
+		return
+	}
+
+	firstBlock := lastPlasmachainBlockNumber - processedOracleEventWindowBlockCount + 1
+	eventIds := window.EventIds[:0]
+	for _, eventId := range window.EventIds {
+		if eventId.EthBlock >= firstBlock {
+			eventIds = append(eventIds, eventId)
+		}
+	}
+	window.EventIds = eventIds
+}
+
+// removeProcessedOracleEventsAfter drops the events from blocks after lastPlasmachainBlockNumber,
+// so the events of blocks invalidated by a reorg can be applied again
+func removeProcessedOracleEventsAfter(window *orctype.ProcessedOracleEventWindow, lastPlasmachainBlockNumber uint64) {
+	eventIds := window.EventIds[:0]
+	for _, eventId := range window.EventIds {
+		if eventId.EthBlock <= lastPlasmachainBlockNumber {
+			eventIds = append(eventIds, eventId)
+		}
+	}
+	window.EventIds = eventIds
+}
@@ -0,0 +1,215 @@
+package battleground
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"github.com/gogo/protobuf/proto"
+	orctype "github.com/loomnetwork/gamechain/types/oracle"
+	"github.com/loomnetwork/go-loom"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	"github.com/loomnetwork/go-loom/types"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// Votes that didn't reach the threshold within this time are dropped
+const oracleCallVoteTimeToLive = time.Hour
+
+func isOracleSetEnabled(oracleSet *orctype.OracleSet) bool {
+	return oracleSet.Threshold > 0
+}
+
+func findAddress(addresses []*types.Address, address loom.Address) int {
+	for i, addressPB := range addresses {
+		if loom.UnmarshalAddressPB(addressPB).Compare(address) == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// UpdateOracleSet adds and removes members of the oracle set and changes its threshold. With a threshold of 0
+// the set is disabled, and the Plasmachain events are applied as soon as the oracle submits them.
+func (z *ZombieBattleground) UpdateOracleSet(ctx contract.Context, req *orctype.UpdateOracleSetRequest) error {
+	err := z.validateOracle(ctx)
+	if err != nil {
+		return err
+	}
+
+	oracleSet, err := loadOracleSet(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range req.RemoveMembers {
+		index := findAddress(oracleSet.Members, loom.UnmarshalAddressPB(member))
+		if index < 0 {
+			return fmt.Errorf("%s is not a member of the oracle set", loom.UnmarshalAddressPB(member).String())
+		}
+		oracleSet.Members = append(oracleSet.Members[:index], oracleSet.Members[index+1:]...)
+	}
+
+	for _, member := range req.AddMembers {
+		if findAddress(oracleSet.Members, loom.UnmarshalAddressPB(member)) < 0 {
+			oracleSet.Members = append(oracleSet.Members, member)
+		}
+	}
+
+	if int(req.Threshold) > len(oracleSet.Members) {
+		return fmt.Errorf("threshold %d is higher than the number of members %d", req.Threshold, len(oracleSet.Members))
+	}
+	oracleSet.Threshold = req.Threshold
+
+	err = saveOracleSet(ctx, oracleSet)
+	if err != nil {
+		return err
+	}
+
+	// the votes of removed members don't count anymore
+	voteList, err := loadOracleCallVotes(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, vote := range voteList.Votes {
+		var voters []*types.Address
+		for _, voter := range vote.Voters {
+			if findAddress(oracleSet.Members, loom.UnmarshalAddressPB(voter)) >= 0 {
+				voters = append(voters, voter)
+			}
+		}
+		vote.Voters = voters
+	}
+
+	err = saveOracleCallVotes(ctx, voteList)
+	if err != nil {
+		return err
+	}
+
+	ctx.Logger().Info("updated oracle set", "memberCount", len(oracleSet.Members), "threshold", oracleSet.Threshold)
+	return nil
+}
+
+func (z *ZombieBattleground) GetOracleSet(ctx contract.StaticContext, req *orctype.GetOracleSetRequest) (*orctype.GetOracleSetResponse, error) {
+	oracleSet, err := loadOracleSet(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	voteList, err := loadOracleCallVotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &orctype.GetOracleSetResponse{
+		OracleSet: oracleSet,
+		Votes:     voteList.Votes,
+	}, nil
+}
+
+// approveOracleCall checks whether an oracle call that changes card collections can be executed.
+// Without an oracle set the sender has to be the oracle. Otherwise the sender has to be a member of the set,
+// the call is only approved once enough distinct members submitted an identical request.
+func (z *ZombieBattleground) approveOracleCall(ctx contract.Context, method string, req proto.Message) (bool, error) {
+	oracleSet, err := loadOracleSet(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if !isOracleSetEnabled(oracleSet) {
+		if err := z.validateOracle(ctx); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	return voteOracleCall(ctx, oracleSet, method, req)
+}
+
+// validateOracleWithoutOracleSet checks that the sender is the oracle, for the oracle calls that would let
+// the oracle key alone skip Plasmachain blocks or overwrite card collections, so they are rejected while the set is enabled
+func (z *ZombieBattleground) validateOracleWithoutOracleSet(ctx contract.Context) error {
+	oracleSet, err := loadOracleSet(ctx)
+	if err != nil {
+		return err
+	}
+
+	if isOracleSetEnabled(oracleSet) {
+		return ErrOracleSetEnabled
+	}
+
+	return z.validateOracle(ctx)
+}
+
+// voteOracleCall records the vote of the sender for the call, and returns whether the call reached the threshold.
+// The votes for a call are dropped once it reaches the threshold.
+func voteOracleCall(ctx contract.Context, oracleSet *orctype.OracleSet, method string, req proto.Message) (bool, error) {
+	sender := ctx.Message().Sender
+	if findAddress(oracleSet.Members, sender) < 0 {
+		return false, ErrNotOracleSetMember
+	}
+
+	reqBytes, err := proto.Marshal(req)
+	if err != nil {
+		return false, errors.Wrap(err, "error hashing oracle call")
+	}
+	requestHash := sha256.Sum256(reqBytes)
+
+	voteList, err := loadOracleCallVotes(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	// drop the votes that never reached the threshold
+	var votes []*orctype.OracleCallVote
+	var currentVote *orctype.OracleCallVote
+	for _, vote := range voteList.Votes {
+		if ctx.Now().Sub(time.Unix(vote.CreatedAt, 0)) > oracleCallVoteTimeToLive {
+			continue
+		}
+
+		if vote.Method == method && bytes.Equal(vote.RequestHash, requestHash[:]) {
+			currentVote = vote
+		}
+		votes = append(votes, vote)
+	}
+
+	if currentVote == nil {
+		currentVote = &orctype.OracleCallVote{
+			Method:      method,
+			RequestHash: requestHash[:],
+			CreatedAt:   ctx.Now().Unix(),
+		}
+		votes = append(votes, currentVote)
+	}
+
+	if findAddress(currentVote.Voters, sender) < 0 {
+		currentVote.Voters = append(currentVote.Voters, sender.MarshalPB())
+	}
+
+	approved := len(currentVote.Voters) >= int(oracleSet.Threshold)
+	if approved {
+		remainingVotes := votes[:0]
+		for _, vote := range votes {
+			if vote != currentVote {
+				remainingVotes = append(remainingVotes, vote)
+			}
+		}
+		votes = remainingVotes
+	}
+
+	voteList.Votes = votes
+	if err := saveOracleCallVotes(ctx, voteList); err != nil {
+		return false, err
+	}
+
+	ctx.Logger().Info("oracle call vote",
+		"method", method,
+		"voter", sender.String(),
+		"voteCount", len(currentVote.Voters),
+		"threshold", oracleSet.Threshold,
+		"approved", approved,
+	)
+	return approved, nil
+}
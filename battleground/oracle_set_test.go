@@ -0,0 +1,189 @@
+package battleground
+
+import (
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	orctype "github.com/loomnetwork/gamechain/types/oracle"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/go-loom"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	"github.com/loomnetwork/go-loom/types"
+	assert "github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestOracleSet(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "3866f776276246e4f9998aa90632931d89b0d3a5930e804e02299533f55b39e1"
+	var addr loom.Address
+	var ctx contract.Context
+
+	fc := setup(c, pubKeyHexString, &addr, &ctx, t)
+
+	zbgCardContractAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000099")
+	userAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000002")
+	userPendingAddress := loom.Address{
+		ChainID: ctx.Message().Sender.ChainID,
+		Local:   userAddress.Local,
+	}
+	member1Address := loom.MustParseAddress("default:0x0000000000000000000000000000000000000011")
+	member2Address := loom.MustParseAddress("default:0x0000000000000000000000000000000000000012")
+	member3Address := loom.MustParseAddress("default:0x0000000000000000000000000000000000000013")
+	member1Ctx := contract.WrapPluginContext(fc.WithSender(member1Address))
+	member2Ctx := contract.WrapPluginContext(fc.WithSender(member2Address))
+	member3Ctx := contract.WrapPluginContext(fc.WithSender(member3Address))
+
+	newBatch := func(firstBlock uint64, lastBlock uint64, amount int64) *orctype.ProcessOracleEventBatchRequest {
+		return &orctype.ProcessOracleEventBatchRequest{
+			FirstPlasmachainBlockNumber: firstBlock,
+			LastPlasmachainBlockNumber:  lastBlock,
+			ZbgCardContractAddress:      zbgCardContractAddress.MarshalPB(),
+			Events: []*orctype.PlasmachainEvent{
+				{
+					EthBlock: lastBlock,
+					Payload: &orctype.PlasmachainEvent_TransferWithQuantity{
+						TransferWithQuantity: &orctype.PlasmachainEventTransferWithQuantity{
+							Amount:  battleground_utility.MarshalBigIntProto(big.NewInt(amount)),
+							TokenId: battleground_utility.MarshalBigIntProto(big.NewInt(100)),
+							From:    zbgCardContractAddress.MarshalPB(),
+							To:      userAddress.MarshalPB(),
+						},
+					},
+				},
+			},
+		}
+	}
+	requireState := func(t *testing.T, lastBlock uint64, amountChange int64) {
+		state, err := loadContractState(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, lastBlock, state.LastPlasmachainBlockNumber)
+
+		container, err := loadPendingCardAmountChangesContainerByAddress(ctx, userPendingAddress)
+		assert.Nil(t, err)
+		totalAmountChange := int64(0)
+		for _, cardAmountChange := range container.CardAmountChanges {
+			totalAmountChange += cardAmountChange.AmountChange
+		}
+		assert.Equal(t, amountChange, totalAmountChange)
+	}
+
+	err := c.SetLastPlasmaBlockNumber(ctx, &zb_calls.SetLastPlasmaBlockNumberRequest{
+		LastPlasmachainBlockNumber: 120,
+	})
+	assert.Nil(t, err)
+
+	err = c.UpdateOracleSet(ctx, &orctype.UpdateOracleSetRequest{
+		AddMembers: []*types.Address{member1Address.MarshalPB(), member2Address.MarshalPB(), member3Address.MarshalPB()},
+		Threshold:  2,
+	})
+	assert.Nil(t, err)
+
+	t.Run("Threshold can't be higher than the member count", func(t *testing.T) {
+		err := c.UpdateOracleSet(ctx, &orctype.UpdateOracleSetRequest{
+			Threshold: 4,
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Non-members can't submit batches", func(t *testing.T) {
+		err := c.ProcessOracleEventBatch(ctx, newBatch(121, 121, 3))
+		assert.Equal(t, ErrNotOracleSetMember, err)
+		requireState(t, 120, 0)
+	})
+
+	t.Run("Batch is applied once enough members submitted it", func(t *testing.T) {
+		err := c.ProcessOracleEventBatch(member1Ctx, newBatch(121, 121, 3))
+		assert.Nil(t, err)
+		err = c.ProcessOracleEventBatch(member1Ctx, newBatch(121, 121, 3))
+		assert.Nil(t, err)
+		requireState(t, 120, 0)
+
+		err = c.ProcessOracleEventBatch(member2Ctx, newBatch(121, 121, 4))
+		assert.Nil(t, err)
+		requireState(t, 120, 0)
+
+		err = c.ProcessOracleEventBatch(member2Ctx, newBatch(121, 121, 3))
+		assert.Nil(t, err)
+		requireState(t, 121, 3)
+
+		response, err := c.GetOracleSet(ctx, &orctype.GetOracleSetRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(response.OracleSet.Members))
+		assert.Equal(t, 1, len(response.Votes), "only the disputed batch should be left")
+	})
+
+	t.Run("Late submissions of an applied batch are ignored", func(t *testing.T) {
+		err := c.ProcessOracleEventBatch(member3Ctx, newBatch(121, 121, 3))
+		assert.Nil(t, err)
+		requireState(t, 121, 3)
+	})
+
+	t.Run("Approved batch has to follow the last processed block", func(t *testing.T) {
+		err := c.ProcessOracleEventBatch(member1Ctx, newBatch(125, 125, 1))
+		assert.Nil(t, err)
+		err = c.ProcessOracleEventBatch(member2Ctx, newBatch(125, 125, 1))
+		assert.NotNil(t, err)
+		requireState(t, 121, 3)
+	})
+
+	t.Run("Rollback needs the votes of the members", func(t *testing.T) {
+		rollbackRequest := &orctype.RollbackOracleEventBatchRequest{
+			LastPlasmachainBlockNumber: 120,
+			CardAmountChanges: []*orctype.PlasmachainCardAmountChange{
+				{
+					Address:      userAddress.MarshalPB(),
+					CardTokenId:  battleground_utility.MarshalBigIntProto(big.NewInt(100)),
+					AmountChange: -3,
+				},
+			},
+		}
+
+		_, err := c.RollbackOracleEventBatch(ctx, rollbackRequest)
+		assert.Equal(t, ErrNotOracleSetMember, err)
+
+		response, err := c.RollbackOracleEventBatch(member1Ctx, rollbackRequest)
+		assert.Nil(t, err)
+		assert.False(t, response.Applied)
+		requireState(t, 121, 3)
+
+		response, err = c.RollbackOracleEventBatch(member3Ctx, rollbackRequest)
+		assert.Nil(t, err)
+		assert.True(t, response.Applied)
+		requireState(t, 120, 0)
+	})
+
+	t.Run("Removed members are rotated out", func(t *testing.T) {
+		err := c.UpdateOracleSet(ctx, &orctype.UpdateOracleSetRequest{
+			RemoveMembers: []*types.Address{member2Address.MarshalPB(), member3Address.MarshalPB()},
+			Threshold:     1,
+		})
+		assert.Nil(t, err)
+
+		err = c.ProcessOracleEventBatch(member2Ctx, newBatch(121, 121, 3))
+		assert.Equal(t, ErrNotOracleSetMember, err)
+
+		err = c.ProcessOracleEventBatch(member1Ctx, newBatch(121, 122, 2))
+		assert.Nil(t, err)
+		requireState(t, 122, 2)
+	})
+
+	t.Run("Oracle key alone can't move the last processed block or process commands", func(t *testing.T) {
+		err := c.SetLastPlasmaBlockNumber(ctx, &zb_calls.SetLastPlasmaBlockNumberRequest{
+			LastPlasmachainBlockNumber: 200,
+		})
+		assert.Equal(t, ErrOracleSetEnabled, err)
+		requireState(t, 122, 2)
+
+		_, err = c.ProcessOracleCommandResponseBatch(ctx, &orctype.ProcessOracleCommandResponseBatchRequest{})
+		assert.Equal(t, ErrOracleSetEnabled, err)
+	})
+
+	t.Run("Disabled set lets the oracle submit on its own", func(t *testing.T) {
+		err := c.UpdateOracleSet(ctx, &orctype.UpdateOracleSetRequest{})
+		assert.Nil(t, err)
+
+		err = c.ProcessOracleEventBatch(ctx, newBatch(0, 123, 1))
+		assert.Nil(t, err)
+		requireState(t, 123, 3)
+	})
+}
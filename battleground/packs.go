@@ -0,0 +1,582 @@
+package battleground
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	orctype "github.com/loomnetwork/gamechain/types/oracle"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	"github.com/loomnetwork/go-loom"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	"github.com/pkg/errors"
+	"math/rand"
+	"sort"
+)
+
+// packSlotPool holds the cards that can be drawn in a pack slot
+type packSlotPool struct {
+	rankWeights        []*zb_data.PackRankWeight
+	totalRankWeight    int64
+	rankToCards        map[zb_enums.CreatureRank_Enum][]*zb_data.Card
+	variantWeights     []*zb_data.PackVariantWeight
+	totalVariantWeight int64
+	cardKeyToCard      map[battleground_proto.CardKey]*zb_data.Card
+}
+
+// maxOpenedPackCount caps the packs opened in a single call
+const maxOpenedPackCount = 100
+
+// OpenPack opens packs the user burned on Plasmachain and adds the cards to the collection.
+// Minting receipts can't be opened directly, since they stay redeemable on Plasmachain,
+// so packs are only opened once the oracle has reported the burn of the pack tokens.
+// The packs are taken from the oldest burns first, with one opening for each burn.
+func (z *ZombieBattleground) OpenPack(ctx contract.Context, req *zb_calls.OpenPackRequest) (*zb_calls.OpenPackResponse, error) {
+	if req.Version == "" {
+		return nil, ErrVersionNotSet
+	}
+
+	if !isOwner(ctx, req.UserId) {
+		return nil, ErrUserNotVerified
+	}
+
+	if req.Amount == 0 || req.Amount > maxOpenedPackCount {
+		return nil, fmt.Errorf("invalid amount %d, up to %d packs can be opened at once", req.Amount, maxOpenedPackCount)
+	}
+
+	userPersistentData, err := loadUserPersistentData(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if userPersistentData.Address == nil {
+		return nil, fmt.Errorf("user %s has no Plasmachain address", req.UserId)
+	}
+	address := loom.UnmarshalAddressPB(userPersistentData.Address)
+
+	unopenedPackList, err := loadUnopenedPacksByAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if getUnopenedPackAmount(unopenedPackList.Burns, req.Type) < req.Amount {
+		return nil, fmt.Errorf("not enough burned %s packs to open %d", req.Type.String(), req.Amount)
+	}
+
+	packDefinitions, err := loadPackDefinitions(ctx, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	cardLibrary, err := loadCardLibrary(ctx, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	packOpenings, err := loadPackOpenings(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	var openings []*zb_data.PackOpening
+	remainingAmount := req.Amount
+	for _, burn := range unopenedPackList.Burns {
+		if remainingAmount == 0 {
+			break
+		}
+
+		if burn.Type != req.Type || burn.OpenedAmount >= burn.Amount {
+			continue
+		}
+
+		amount := burn.Amount - burn.OpenedAmount
+		if amount > remainingAmount {
+			amount = remainingAmount
+		}
+
+		opening, err := openPacks(req.Type, burn.Seed, burn.OpenedAmount, amount, packDefinitions.Packs, cardLibrary.Cards)
+		if err != nil {
+			return nil, err
+		}
+
+		opening.Index = uint64(len(packOpenings.Openings))
+		opening.BlockHeight = ctx.Block().Height
+		opening.CreatedAt = ctx.Now().Unix()
+		opening.BurnEthBlock = burn.EthBlock
+		opening.BurnTxIndex = burn.TxIndex
+		opening.BurnLogIndex = burn.LogIndex
+		opening.FirstPackIndex = burn.OpenedAmount
+		packOpenings.Openings = append(packOpenings.Openings, opening)
+		openings = append(openings, opening)
+
+		burn.OpenedAmount += amount
+		remainingAmount -= amount
+	}
+
+	unopenedPackList.Burns = removeOpenedPackBurns(unopenedPackList.Burns)
+	err = saveUnopenedPacksByAddress(ctx, address, unopenedPackList)
+	if err != nil {
+		return nil, err
+	}
+
+	cardKeyToAmountChange := cardKeyToAmountChangeMap{}
+	for _, opening := range openings {
+		for _, openedPack := range opening.Packs {
+			for _, cardKey := range openedPack.Cards {
+				cardKeyToAmountChange[cardKey]++
+			}
+		}
+	}
+
+	cardAmountChanges := make([]CardAmountChangeItem, 0, len(cardKeyToAmountChange))
+	for cardKey, amountChange := range cardKeyToAmountChange {
+		cardAmountChanges = append(cardAmountChanges, CardAmountChangeItem{
+			CardKey:      cardKey,
+			AmountChange: amountChange,
+		})
+	}
+	sort.SliceStable(cardAmountChanges, func(i, j int) bool {
+		return cardAmountChanges[i].CardKey.Compare(&cardAmountChanges[j].CardKey) < 0
+	})
+
+	err = z.applyGamechainCardAmountChanges(ctx, req.UserId, cardAmountChanges)
+	if err != nil {
+		return nil, err
+	}
+
+	err = savePackOpenings(ctx, req.UserId, packOpenings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zb_calls.OpenPackResponse{
+		Openings: openings,
+	}, nil
+}
+
+// GetPackOdds returns the definition of a pack type and the probability of every card in each slot
+func (z *ZombieBattleground) GetPackOdds(ctx contract.StaticContext, req *zb_calls.GetPackOddsRequest) (*zb_calls.GetPackOddsResponse, error) {
+	if req.Version == "" {
+		return nil, ErrVersionNotSet
+	}
+
+	packDefinitions, err := loadPackDefinitions(ctx, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	packDefinition := getPackDefinition(packDefinitions.Packs, req.Type)
+	if packDefinition == nil {
+		return nil, fmt.Errorf("no definition for pack type %s", req.Type.String())
+	}
+
+	cardLibrary, err := loadCardLibrary(ctx, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	slotPools, err := newPackSlotPools(packDefinition, cardLibrary.Cards)
+	if err != nil {
+		return nil, err
+	}
+
+	slotOdds := make([]*zb_data.PackSlotOdds, len(slotPools))
+	for index, slotPool := range slotPools {
+		slotOdds[index] = slotPool.getOdds()
+	}
+
+	return &zb_calls.GetPackOddsResponse{
+		Definition: packDefinition,
+		Slots:      slotOdds,
+	}, nil
+}
+
+// ListPackOpenings returns the packs opened by the user, with the seeds used to open them, and the packs left to open
+func (z *ZombieBattleground) ListPackOpenings(ctx contract.StaticContext, req *zb_calls.ListPackOpeningsRequest) (*zb_calls.ListPackOpeningsResponse, error) {
+	packOpenings, err := loadPackOpenings(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	userPersistentData, err := loadUserPersistentData(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	var unopenedPacks []*zb_data.PackBalance
+	if userPersistentData.Address != nil {
+		unopenedPackList, err := loadUnopenedPacksByAddress(ctx, loom.UnmarshalAddressPB(userPersistentData.Address))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, burn := range unopenedPackList.Burns {
+			unopenedPack := getPackBalance(unopenedPacks, burn.Type)
+			if unopenedPack == nil {
+				unopenedPack = &zb_data.PackBalance{Type: burn.Type}
+				unopenedPacks = append(unopenedPacks, unopenedPack)
+			}
+			unopenedPack.Amount += burn.Amount - burn.OpenedAmount
+		}
+
+		sort.SliceStable(unopenedPacks, func(i, j int) bool {
+			return unopenedPacks[i].Type < unopenedPacks[j].Type
+		})
+	}
+
+	return &zb_calls.ListPackOpeningsResponse{
+		Openings:      packOpenings.Openings,
+		UnopenedPacks: unopenedPacks,
+	}, nil
+}
+
+// addPackBurn adds the packs burned on Plasmachain to the packs the address can open.
+// The seed of the burn is derived from its event and the current block, before the user can open the packs.
+func addPackBurn(ctx contract.Context, address loom.Address, event *orctype.PlasmachainEvent) error {
+	packBurn := event.GetPackBurn()
+	unopenedPackList, err := loadUnopenedPacksByAddress(ctx, address)
+	if err != nil {
+		return err
+	}
+
+	block := ctx.Block()
+	unopenedPackList.Burns = append(unopenedPackList.Burns, &zb_data.PackBurn{
+		EthBlock:    event.EthBlock,
+		TxIndex:     event.TxIndex,
+		LogIndex:    event.LogIndex,
+		Type:        packBurn.PackType,
+		Amount:      packBurn.Amount.Value.Uint64(),
+		Seed:        getPackBurnSeed(event.EthBlock, event.TxIndex, event.LogIndex, block.Height, block.CurrentHash),
+		BlockHeight: block.Height,
+	})
+
+	return saveUnopenedPacksByAddress(ctx, address, unopenedPackList)
+}
+
+// removeBurnedPacks undoes burns invalidated by a reorg, newest first.
+// Packs that were already opened can't be taken back.
+func removeBurnedPacks(ctx contract.Context, address loom.Address, packType zb_enums.PackType_Enum, amount uint64) error {
+	unopenedPackList, err := loadUnopenedPacksByAddress(ctx, address)
+	if err != nil {
+		return err
+	}
+
+	remainingAmount := amount
+	for i := len(unopenedPackList.Burns) - 1; i >= 0 && remainingAmount > 0; i-- {
+		burn := unopenedPackList.Burns[i]
+		if burn.Type != packType {
+			continue
+		}
+
+		removedAmount := burn.Amount - burn.OpenedAmount
+		if removedAmount > remainingAmount {
+			removedAmount = remainingAmount
+		}
+		burn.Amount -= removedAmount
+		remainingAmount -= removedAmount
+	}
+
+	if remainingAmount > 0 {
+		ctx.Logger().Warn(
+			"burned packs were already opened, can't undo the burn",
+			"address", address.String(),
+			"packType", packType.String(),
+			"amount", amount,
+			"openedAmount", remainingAmount,
+		)
+	}
+
+	unopenedPackList.Burns = removeOpenedPackBurns(unopenedPackList.Burns)
+	return saveUnopenedPacksByAddress(ctx, address, unopenedPackList)
+}
+
+// removeOpenedPackBurns drops the burns without packs left to open
+func removeOpenedPackBurns(burns []*zb_data.PackBurn) []*zb_data.PackBurn {
+	remainingBurns := make([]*zb_data.PackBurn, 0, len(burns))
+	for _, burn := range burns {
+		if burn.OpenedAmount < burn.Amount {
+			remainingBurns = append(remainingBurns, burn)
+		}
+	}
+
+	return remainingBurns
+}
+
+func getUnopenedPackAmount(burns []*zb_data.PackBurn, packType zb_enums.PackType_Enum) uint64 {
+	amount := uint64(0)
+	for _, burn := range burns {
+		if burn.Type == packType {
+			amount += burn.Amount - burn.OpenedAmount
+		}
+	}
+
+	return amount
+}
+
+func getPackBalance(packBalances []*zb_data.PackBalance, packType zb_enums.PackType_Enum) *zb_data.PackBalance {
+	for _, packBalance := range packBalances {
+		if packBalance.Type == packType {
+			return packBalance
+		}
+	}
+
+	return nil
+}
+
+// getPackBurnSeed derives the seed of a burn from the position of its event on Plasmachain
+// and the block the oracle reported it in
+func getPackBurnSeed(ethBlock uint64, txIndex uint64, logIndex uint64, blockHeight int64, blockHash []byte) []byte {
+	hash := sha256.New()
+	for _, value := range []uint64{ethBlock, txIndex, logIndex, uint64(blockHeight)} {
+		var bytes [8]byte
+		binary.BigEndian.PutUint64(bytes[:], value)
+		hash.Write(bytes[:])
+	}
+	hash.Write(blockHash)
+	return hash.Sum(nil)
+}
+
+// getPackSeed derives the seed of a pack from the seed of its burn, so the cards don't depend on
+// how many packs of the burn are opened at once
+func getPackSeed(burnSeed []byte, packIndex uint64) int64 {
+	var index [8]byte
+	binary.BigEndian.PutUint64(index[:], packIndex)
+
+	hash := sha256.New()
+	hash.Write(burnSeed)
+	hash.Write(index[:])
+	return int64(binary.BigEndian.Uint64(hash.Sum(nil)[:8]))
+}
+
+// openPacks opens the packs of a burn starting at firstPackIndex,
+// the result only depends on the seed, the definitions and the card library
+func openPacks(
+	packType zb_enums.PackType_Enum,
+	seed []byte,
+	firstPackIndex uint64,
+	amount uint64,
+	packDefinitions []*zb_data.PackDefinition,
+	cardLibrary []*zb_data.Card,
+) (*zb_data.PackOpening, error) {
+	packDefinition := getPackDefinition(packDefinitions, packType)
+	if packDefinition == nil {
+		return nil, fmt.Errorf("no definition for pack type %s", packType.String())
+	}
+
+	slotPools, err := newPackSlotPools(packDefinition, cardLibrary)
+	if err != nil {
+		return nil, err
+	}
+
+	opening := &zb_data.PackOpening{
+		Seed: seed,
+	}
+
+	for i := uint64(0); i < amount; i++ {
+		r := rand.New(rand.NewSource(getPackSeed(seed, firstPackIndex+i)))
+		openedPack := &zb_data.OpenedPack{
+			Type:  packType,
+			Cards: make([]battleground_proto.CardKey, 0, len(slotPools)),
+		}
+
+		for _, slotPool := range slotPools {
+			openedPack.Cards = append(openedPack.Cards, slotPool.draw(r))
+		}
+
+		opening.Packs = append(opening.Packs, openedPack)
+	}
+
+	return opening, nil
+}
+
+func getPackDefinition(packDefinitions []*zb_data.PackDefinition, packType zb_enums.PackType_Enum) *zb_data.PackDefinition {
+	for _, packDefinition := range packDefinitions {
+		if packDefinition.Type == packType {
+			return packDefinition
+		}
+	}
+
+	return nil
+}
+
+func validatePackDefinitions(packDefinitions []*zb_data.PackDefinition, cardLibrary []*zb_data.Card) error {
+	existingTypes := make(map[zb_enums.PackType_Enum]bool)
+	for _, packDefinition := range packDefinitions {
+		if existingTypes[packDefinition.Type] {
+			return fmt.Errorf("duplicate definition for pack type %s", packDefinition.Type.String())
+		}
+		existingTypes[packDefinition.Type] = true
+
+		if len(packDefinition.Slots) == 0 {
+			return fmt.Errorf("pack type %s has no slots", packDefinition.Type.String())
+		}
+
+		if _, err := newPackSlotPools(packDefinition, cardLibrary); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newPackSlotPools(packDefinition *zb_data.PackDefinition, cardLibrary []*zb_data.Card) ([]*packSlotPool, error) {
+	cardKeyToCard, err := getCardKeyToCardMap(cardLibrary)
+	if err != nil {
+		return nil, err
+	}
+
+	slotPools := make([]*packSlotPool, len(packDefinition.Slots))
+	for index, slot := range packDefinition.Slots {
+		slotPools[index], err = newPackSlotPool(slot, cardLibrary, cardKeyToCard)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid slot %d of pack type %s", index, packDefinition.Type.String())
+		}
+	}
+
+	return slotPools, nil
+}
+
+func newPackSlotPool(
+	slot *zb_data.PackSlot,
+	cardLibrary []*zb_data.Card,
+	cardKeyToCard map[battleground_proto.CardKey]*zb_data.Card,
+) (*packSlotPool, error) {
+	var cards []*zb_data.Card
+	if len(slot.CardPool) == 0 {
+		for _, card := range cardLibrary {
+			if !card.Hidden && card.CardKey.Variant == zb_enums.CardVariant_Standard {
+				cards = append(cards, card)
+			}
+		}
+	} else {
+		for _, cardKey := range slot.CardPool {
+			if cardKey.Variant != zb_enums.CardVariant_Standard {
+				return nil, fmt.Errorf("card pool must only contain standard cards, got [%s]", cardKey.String())
+			}
+
+			card, exists := cardKeyToCard[cardKey]
+			if !exists {
+				return nil, fmt.Errorf("card [%s] not found in card library", cardKey.String())
+			}
+
+			cards = append(cards, card)
+		}
+	}
+
+	// card library order shouldn't change the results
+	sort.SliceStable(cards, func(i, j int) bool {
+		return cards[i].CardKey.Compare(&cards[j].CardKey) < 0
+	})
+
+	slotPool := &packSlotPool{
+		rankToCards:   make(map[zb_enums.CreatureRank_Enum][]*zb_data.Card),
+		cardKeyToCard: cardKeyToCard,
+	}
+	for _, card := range cards {
+		slotPool.rankToCards[card.Rank] = append(slotPool.rankToCards[card.Rank], card)
+	}
+
+	// ranks without cards can't be drawn
+	for _, rankWeight := range slot.RankWeights {
+		if rankWeight.Weight == 0 || len(slotPool.rankToCards[rankWeight.Rank]) == 0 {
+			continue
+		}
+
+		slotPool.rankWeights = append(slotPool.rankWeights, rankWeight)
+		slotPool.totalRankWeight += int64(rankWeight.Weight)
+	}
+
+	if slotPool.totalRankWeight == 0 {
+		return nil, fmt.Errorf("no card can be drawn")
+	}
+
+	for _, variantWeight := range slot.VariantWeights {
+		if variantWeight.Weight == 0 {
+			continue
+		}
+
+		slotPool.variantWeights = append(slotPool.variantWeights, variantWeight)
+		slotPool.totalVariantWeight += int64(variantWeight.Weight)
+	}
+
+	return slotPool, nil
+}
+
+func (p *packSlotPool) draw(r *rand.Rand) battleground_proto.CardKey {
+	var rank zb_enums.CreatureRank_Enum
+	roll := r.Int63n(p.totalRankWeight)
+	for _, rankWeight := range p.rankWeights {
+		rank = rankWeight.Rank
+		roll -= int64(rankWeight.Weight)
+		if roll < 0 {
+			break
+		}
+	}
+
+	cards := p.rankToCards[rank]
+	card := cards[r.Intn(len(cards))]
+
+	if p.totalVariantWeight == 0 {
+		return card.CardKey
+	}
+
+	var variant zb_enums.CardVariant_Enum
+	roll = r.Int63n(p.totalVariantWeight)
+	for _, variantWeight := range p.variantWeights {
+		variant = variantWeight.Variant
+		roll -= int64(variantWeight.Weight)
+		if roll < 0 {
+			break
+		}
+	}
+
+	return p.getVariantCardKey(card, variant)
+}
+
+func (p *packSlotPool) getVariantCardKey(card *zb_data.Card, variant zb_enums.CardVariant_Enum) battleground_proto.CardKey {
+	variantCardKey := battleground_proto.CardKey{
+		MouldId: card.CardKey.MouldId,
+		Variant: variant,
+	}
+
+	if _, exists := p.cardKeyToCard[variantCardKey]; !exists {
+		return card.CardKey
+	}
+
+	return variantCardKey
+}
+
+func (p *packSlotPool) getOdds() *zb_data.PackSlotOdds {
+	cardKeyToProbability := make(map[battleground_proto.CardKey]float64)
+	for _, rankWeight := range p.rankWeights {
+		cards := p.rankToCards[rankWeight.Rank]
+		cardProbability := float64(rankWeight.Weight) / float64(p.totalRankWeight) / float64(len(cards))
+		for _, card := range cards {
+			if p.totalVariantWeight == 0 {
+				cardKeyToProbability[card.CardKey] += cardProbability
+				continue
+			}
+
+			for _, variantWeight := range p.variantWeights {
+				variantCardKey := p.getVariantCardKey(card, variantWeight.Variant)
+				cardKeyToProbability[variantCardKey] += cardProbability * float64(variantWeight.Weight) / float64(p.totalVariantWeight)
+			}
+		}
+	}
+
+	slotOdds := &zb_data.PackSlotOdds{}
+	for cardKey, probability := range cardKeyToProbability {
+		slotOdds.Cards = append(slotOdds.Cards, &zb_data.PackCardOdds{
+			CardKey:     cardKey,
+			Probability: probability,
+		})
+	}
+
+	sort.SliceStable(slotOdds.Cards, func(i, j int) bool {
+		return slotOdds.Cards[i].CardKey.Compare(&slotOdds.Cards[j].CardKey) < 0
+	})
+
+	return slotOdds
+}
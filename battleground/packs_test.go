@@ -0,0 +1,297 @@
+package battleground
+
+import (
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	orctype "github.com/loomnetwork/gamechain/types/oracle"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	"github.com/loomnetwork/go-loom"
+	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	assert "github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func newPackTestCardLibrary() []*zb_data.Card {
+	return []*zb_data.Card{
+		{CardKey: battleground_proto.CardKey{MouldId: 3}, Rank: zb_enums.CreatureRank_Officer},
+		{CardKey: battleground_proto.CardKey{MouldId: 3, Variant: zb_enums.CardVariant_Backer}, Rank: zb_enums.CreatureRank_Officer},
+		{CardKey: battleground_proto.CardKey{MouldId: 2}, Rank: zb_enums.CreatureRank_Minion},
+		{CardKey: battleground_proto.CardKey{MouldId: 1}, Rank: zb_enums.CreatureRank_Minion},
+		{CardKey: battleground_proto.CardKey{MouldId: 4}, Rank: zb_enums.CreatureRank_Minion, Hidden: true},
+	}
+}
+
+func newPackTestDefinition() *zb_data.PackDefinition {
+	return &zb_data.PackDefinition{
+		Type: zb_enums.PackType_Booster,
+		Slots: []*zb_data.PackSlot{
+			{
+				RankWeights: []*zb_data.PackRankWeight{
+					{Rank: zb_enums.CreatureRank_Minion, Weight: 3},
+					{Rank: zb_enums.CreatureRank_Officer, Weight: 1},
+					// no general in the library, can't be drawn
+					{Rank: zb_enums.CreatureRank_General, Weight: 5},
+				},
+				VariantWeights: []*zb_data.PackVariantWeight{
+					{Variant: zb_enums.CardVariant_Standard, Weight: 3},
+					{Variant: zb_enums.CardVariant_Backer, Weight: 1},
+				},
+			},
+		},
+	}
+}
+
+func TestPackOdds(t *testing.T) {
+	cardLibrary := newPackTestCardLibrary()
+	slotPools, err := newPackSlotPools(newPackTestDefinition(), cardLibrary)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(slotPools))
+
+	slotOdds := slotPools[0].getOdds()
+	assert.Equal(t, 4, len(slotOdds.Cards))
+
+	expectedOdds := []struct {
+		cardKey     battleground_proto.CardKey
+		probability float64
+	}{
+		// minions have no Backer variant, so they are always Standard
+		{battleground_proto.CardKey{MouldId: 1}, 0.375},
+		{battleground_proto.CardKey{MouldId: 2}, 0.375},
+		{battleground_proto.CardKey{MouldId: 3}, 0.1875},
+		{battleground_proto.CardKey{MouldId: 3, Variant: zb_enums.CardVariant_Backer}, 0.0625},
+	}
+	for index, expected := range expectedOdds {
+		assert.Equal(t, expected.cardKey, slotOdds.Cards[index].CardKey)
+		assert.InDelta(t, expected.probability, slotOdds.Cards[index].Probability, 0.000001)
+	}
+
+	t.Run("Draws follow the odds", func(t *testing.T) {
+		seed := getPackBurnSeed(10, 0, 0, 20, []byte{1, 2, 3})
+		opening, err := openPacks(zb_enums.PackType_Booster, seed, 0, 4000, []*zb_data.PackDefinition{newPackTestDefinition()}, cardLibrary)
+		assert.Nil(t, err)
+		assert.Equal(t, 4000, len(opening.Packs))
+
+		cardKeyToCount := map[battleground_proto.CardKey]int{}
+		for _, openedPack := range opening.Packs {
+			assert.Equal(t, 1, len(openedPack.Cards))
+			cardKeyToCount[openedPack.Cards[0]]++
+		}
+
+		for _, expected := range expectedOdds {
+			assert.InDelta(t, expected.probability, float64(cardKeyToCount[expected.cardKey])/4000, 0.03)
+		}
+
+		// same seed, same cards
+		sameOpening, err := openPacks(zb_enums.PackType_Booster, seed, 0, 4000, []*zb_data.PackDefinition{newPackTestDefinition()}, cardLibrary)
+		assert.Nil(t, err)
+		assert.Equal(t, opening, sameOpening)
+	})
+
+	t.Run("Split openings draw the same packs", func(t *testing.T) {
+		seed := getPackBurnSeed(10, 0, 0, 20, nil)
+		opening, err := openPacks(zb_enums.PackType_Booster, seed, 0, 10, []*zb_data.PackDefinition{newPackTestDefinition()}, cardLibrary)
+		assert.Nil(t, err)
+
+		firstOpening, err := openPacks(zb_enums.PackType_Booster, seed, 0, 4, []*zb_data.PackDefinition{newPackTestDefinition()}, cardLibrary)
+		assert.Nil(t, err)
+		secondOpening, err := openPacks(zb_enums.PackType_Booster, seed, 4, 6, []*zb_data.PackDefinition{newPackTestDefinition()}, cardLibrary)
+		assert.Nil(t, err)
+		assert.Equal(t, opening.Packs, append(firstOpening.Packs, secondOpening.Packs...))
+	})
+
+	t.Run("Packs without definition should fail", func(t *testing.T) {
+		seed := getPackBurnSeed(10, 0, 0, 20, nil)
+		_, err := openPacks(zb_enums.PackType_Air, seed, 0, 1, []*zb_data.PackDefinition{newPackTestDefinition()}, cardLibrary)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Seed depends on the burn event", func(t *testing.T) {
+		assert.NotEqual(t, getPackBurnSeed(10, 0, 0, 20, nil), getPackBurnSeed(10, 0, 1, 20, nil))
+		assert.NotEqual(t, getPackBurnSeed(10, 0, 0, 20, nil), getPackBurnSeed(10, 1, 0, 20, nil))
+		assert.NotEqual(t, getPackBurnSeed(10, 0, 0, 20, nil), getPackBurnSeed(11, 0, 0, 20, nil))
+	})
+}
+
+func TestValidatePackDefinitions(t *testing.T) {
+	cardLibrary := newPackTestCardLibrary()
+	assert.Nil(t, validatePackDefinitions([]*zb_data.PackDefinition{newPackTestDefinition()}, cardLibrary))
+
+	minionSlot := &zb_data.PackSlot{
+		RankWeights: []*zb_data.PackRankWeight{{Rank: zb_enums.CreatureRank_Minion, Weight: 1}},
+	}
+	for _, packDefinitions := range [][]*zb_data.PackDefinition{
+		// duplicate type
+		{newPackTestDefinition(), newPackTestDefinition()},
+		// no slots
+		{{Type: zb_enums.PackType_Air}},
+		// no card can be drawn
+		{{Type: zb_enums.PackType_Air, Slots: []*zb_data.PackSlot{{
+			RankWeights: []*zb_data.PackRankWeight{{Rank: zb_enums.CreatureRank_General, Weight: 1}},
+		}}}},
+		// unknown card in pool
+		{{Type: zb_enums.PackType_Air, Slots: []*zb_data.PackSlot{{
+			CardPool:    []battleground_proto.CardKey{{MouldId: 100}},
+			RankWeights: minionSlot.RankWeights,
+		}}}},
+		// non standard card in pool
+		{{Type: zb_enums.PackType_Air, Slots: []*zb_data.PackSlot{{
+			CardPool:    []battleground_proto.CardKey{{MouldId: 3, Variant: zb_enums.CardVariant_Backer}},
+			RankWeights: []*zb_data.PackRankWeight{{Rank: zb_enums.CreatureRank_Officer, Weight: 1}},
+		}}}},
+	} {
+		assert.NotNil(t, validatePackDefinitions(packDefinitions, cardLibrary))
+	}
+}
+
+func TestPackOperations(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "e4008e26428a9bca87465e8de3a8d0e9c37a56ca619d3d6202b0567528786618"
+	var addr loom.Address
+	var ctx contract.Context
+
+	userId := defaultUserIdPrefix + "373"
+	setup(c, pubKeyHexString, &addr, &ctx, t)
+	setupAccount(c, ctx, &zb_calls.UpsertAccountRequest{
+		UserId:  userId,
+		Version: "v1",
+	}, t)
+
+	err := c.UpdateContractConfiguration(ctx, &zb_calls.UpdateContractConfigurationRequest{
+		SetFiatPurchaseContractVersion: true,
+		FiatPurchaseContractVersion:    3,
+		SetInitialFiatPurchaseTxId:     true,
+		InitialFiatPurchaseTxId:        battleground_utility.MarshalBigIntProto(big.NewInt(100)),
+	})
+	assert.Nil(t, err)
+
+	getCollectionCardCount := func(t *testing.T) int64 {
+		collection, err := loadUserCardCollectionRaw(ctx, userId)
+		assert.Nil(t, err)
+
+		var count int64
+		for _, collectionCard := range collection.Cards {
+			count += collectionCard.Amount
+		}
+		return count
+	}
+
+	t.Run("GetPackOdds", func(t *testing.T) {
+		response, err := c.GetPackOdds(ctx, &zb_calls.GetPackOddsRequest{
+			Version: "v1",
+			Type:    zb_enums.PackType_Minion,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(response.Slots))
+		assert.Equal(t, 2, len(response.Slots[0].Cards))
+		assert.InDelta(t, 0.5, response.Slots[0].Cards[0].Probability, 0.000001)
+		assert.InDelta(t, 0.5, response.Slots[0].Cards[1].Probability, 0.000001)
+
+		_, err = c.GetPackOdds(ctx, &zb_calls.GetPackOddsRequest{
+			Version: "v1",
+			Type:    zb_enums.PackType_Air,
+		})
+		assert.NotNil(t, err)
+	})
+
+	burnPacks := func(t *testing.T, block uint64, amount int64) {
+		err := c.ProcessOracleEventBatch(ctx, &orctype.ProcessOracleEventBatchRequest{
+			LastPlasmachainBlockNumber: block,
+			ZbgCardContractAddress:     loom.MustParseAddress("default:0x0000000000000000000000000000000000000099").MarshalPB(),
+			Events: []*orctype.PlasmachainEvent{
+				{
+					EthBlock: block,
+					Payload: &orctype.PlasmachainEvent_PackBurn{
+						PackBurn: &orctype.PlasmachainEventPackBurn{
+							From:     addr.MarshalPB(),
+							PackType: zb_enums.PackType_Booster,
+							Amount:   battleground_utility.MarshalBigIntProto(big.NewInt(amount)),
+						},
+					},
+				},
+			},
+		})
+		assert.Nil(t, err)
+	}
+
+	t.Run("Opening a minting receipt without burning the packs should fail", func(t *testing.T) {
+		_, err := mintBoosterPacksAndSave(ctx, userId, nil, 2)
+		assert.Nil(t, err)
+
+		_, err = c.OpenPack(ctx, &zb_calls.OpenPackRequest{
+			UserId:  userId,
+			Version: "v1",
+			Type:    zb_enums.PackType_Booster,
+			Amount:  2,
+		})
+		assert.NotNil(t, err)
+
+		// the receipt is still redeemable on Plasmachain
+		receiptCollection, err := loadPendingMintingTransactionReceipts(ctx, userId)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(receiptCollection.Receipts))
+	})
+
+	t.Run("OpenPack", func(t *testing.T) {
+		burnPacks(t, 10, 3)
+
+		cardCountBefore := getCollectionCardCount(t)
+		response, err := c.OpenPack(ctx, &zb_calls.OpenPackRequest{
+			UserId:  userId,
+			Version: "v1",
+			Type:    zb_enums.PackType_Booster,
+			Amount:  2,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(response.Openings))
+		assert.Equal(t, uint64(10), response.Openings[0].BurnEthBlock)
+		assert.Equal(t, uint64(0), response.Openings[0].FirstPackIndex)
+		assert.Equal(t, 2, len(response.Openings[0].Packs))
+		assert.Equal(t, 2, len(response.Openings[0].Packs[0].Cards))
+		assert.Equal(t, cardCountBefore+4, getCollectionCardCount(t))
+	})
+
+	t.Run("Opening more packs than were burned should fail", func(t *testing.T) {
+		_, err := c.OpenPack(ctx, &zb_calls.OpenPackRequest{
+			UserId:  userId,
+			Version: "v1",
+			Type:    zb_enums.PackType_Booster,
+			Amount:  2,
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ListPackOpenings", func(t *testing.T) {
+		response, err := c.ListPackOpenings(ctx, &zb_calls.ListPackOpeningsRequest{
+			UserId: userId,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(response.Openings))
+		assert.Equal(t, uint64(0), response.Openings[0].Index)
+		assert.NotEmpty(t, response.Openings[0].Seed)
+		assert.Equal(t, []*zb_data.PackBalance{{Type: zb_enums.PackType_Booster, Amount: 1}}, response.UnopenedPacks)
+	})
+
+	t.Run("Rollback can't take back opened packs", func(t *testing.T) {
+		_, err := c.RollbackOracleEventBatch(ctx, &orctype.RollbackOracleEventBatchRequest{
+			LastPlasmachainBlockNumber: 9,
+			PackAmountChanges: []*orctype.PlasmachainPackAmountChange{
+				{Address: addr.MarshalPB(), PackType: zb_enums.PackType_Booster, AmountChange: -3},
+			},
+		})
+		assert.Nil(t, err)
+
+		unopenedPackList, err := loadUnopenedPacksByAddress(ctx, addr)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(unopenedPackList.Burns))
+	})
+
+	t.Run("Opened cards are kept after full card collection sync", func(t *testing.T) {
+		cardCount := getCollectionCardCount(t)
+		err := c.processOracleCommandResponseGetUserFullCardCollection(ctx, addr, nil, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, cardCount, getCollectionCardCount(t))
+	})
+}
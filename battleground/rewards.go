@@ -162,14 +162,7 @@ func mintGenericPacksAndSave(
 		return nil, err
 	}
 
-	// Add to the list of pending receipts
-	receiptCollection, err := loadPendingMintingTransactionReceipts(ctx, userId)
-	if err != nil {
-		return nil, err
-	}
-
-	receiptCollection.Receipts = append(receiptCollection.Receipts, receipt.MarshalPB())
-	err = savePendingMintingTransactionReceipts(ctx, userId, receiptCollection)
+	err = addPendingMintingReceipt(ctx, userId, receipt.MarshalPB())
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +43,17 @@ var (
 	nonceKey                    = []byte("nonce")
 	currentUserIDUIntKey        = []byte("current-user-id")
 	overlordLevelingDataKey     = []byte("overlord-leveling")
+	craftingDataKey             = []byte("crafting")
+	packDefinitionsKey          = []byte("pack-definitions")
+	cardKeyMigrationsKey        = []byte("card-key-migrations")
 	oracleCommandRequestListKey = []byte("oracle-command-request-list")
+	challengeCountKey           = []byte("challenge-count")
+	matchMakingQueueListKey     = []byte("matchmaking-queue-list")
+	mintingReceiptWatchListKey  = []byte("minting-receipt-watch-list")
+	quarantinedOracleEventsKey  = []byte("quarantined-oracle-events")
+	processedOracleEventsKey    = []byte("processed-oracle-events")
+	oracleSetKey                = []byte("oracle-set")
+	oracleCallVotesKey          = []byte("oracle-call-votes")
 )
 
 var (
@@ -70,10 +80,47 @@ func DecksKey(userID string) []byte {
 	return []byte("user:" + userID + ":decks")
 }
 
+func DeckStatsKey(userID string) []byte {
+	return []byte("user:" + userID + ":deck-stats")
+}
+
+func DeckRevisionsKey(userID string) []byte {
+	return []byte("user:" + userID + ":deck-revisions")
+}
+
+func CraftingAuditLogKey(userID string) []byte {
+	return []byte("user:" + userID + ":crafting-audit-log")
+}
+
+func PackOpeningsKey(userID string) []byte {
+	return []byte("user:" + userID + ":pack-openings")
+}
+
+func PackBalancesKey(userID string) []byte {
+	return []byte("user:" + userID + ":pack-balances")
+}
+
+// packs can be burned before the address is linked to a user, so they are kept by address
+func AddressToUnopenedPacksKey(address loom.Address) []byte {
+	return []byte("address" + string(address.Local) + ":address-to-unopened-packs")
+}
+
+func GamechainCardAmountChangesKey(userID string) []byte {
+	return []byte("user:" + userID + ":gamechain-card-amount-changes")
+}
+
+func CardHistoryKey(cardKey battleground_proto.CardKey) []byte {
+	return []byte(fmt.Sprintf("card-history:%d:%d", cardKey.MouldId, cardKey.Variant))
+}
+
 func PendingMintingTransactionReceiptCollectionKey(userID string) []byte {
 	return []byte("user:" + userID + ":pending-minting-transaction-receipts")
 }
 
+func MintingTransactionReceiptHistoryKey(userID string) []byte {
+	return []byte("user:" + userID + ":minting-transaction-receipt-history")
+}
+
 func AddressToPendingCardAmountChangeItemsKey(address loom.Address) []byte {
 	return []byte("address" + string(address.Local) + ":address-to-pending-card-amount-change-items")
 }
@@ -90,6 +137,19 @@ func UserNotificationsKey(userID string) []byte {
 	return []byte("user:" + userID + ":notifications")
 }
 
+func UserMatchMakingQueuesKey(userID string) []byte {
+	return []byte("user:" + userID + ":matchmaking-queues")
+}
+
+func MatchMakingQueuePlayerPoolKey(queueName string) []byte {
+	// the casual queue keeps using the original player pool key
+	if queueName == MatchMakingQueueCasual {
+		return playerPoolKey
+	}
+
+	return []byte("matchmaking-queue:" + queueName + ":playerpool")
+}
+
 func MatchKey(matchID int64) []byte {
 	return []byte(fmt.Sprintf("match:%d", matchID))
 }
@@ -102,6 +162,10 @@ func InitialGameStateKey(gameStateID int64) []byte {
 	return []byte(fmt.Sprintf("initial-gamestate:%d", gameStateID))
 }
 
+func ChallengeKey(code string) []byte {
+	return []byte("challenge:" + code)
+}
+
 func UserMatchKey(userID string) []byte {
 	return []byte("user:" + userID + ":match")
 }
@@ -180,6 +244,74 @@ func loadOracleCommandRequestList(ctx contract.StaticContext) (*oracle.OracleCom
 	return &commandRequestList, nil
 }
 
+func loadQuarantinedOracleEvents(ctx contract.StaticContext) (*oracle.QuarantinedOracleEventBatchList, error) {
+	var batchList oracle.QuarantinedOracleEventBatchList
+	if err := ctx.Get(quarantinedOracleEventsKey, &batchList); err != nil {
+		if err != contract.ErrNotFound {
+			return nil, errors.Wrap(err, "error loading quarantined oracle events")
+		}
+	}
+	return &batchList, nil
+}
+
+func saveQuarantinedOracleEvents(ctx contract.Context, batchList *oracle.QuarantinedOracleEventBatchList) error {
+	if err := ctx.Set(quarantinedOracleEventsKey, batchList); err != nil {
+		return errors.Wrap(err, "error saving quarantined oracle events")
+	}
+	return nil
+}
+
+func loadProcessedOracleEventWindow(ctx contract.StaticContext) (*oracle.ProcessedOracleEventWindow, error) {
+	var window oracle.ProcessedOracleEventWindow
+	if err := ctx.Get(processedOracleEventsKey, &window); err != nil {
+		if err != contract.ErrNotFound {
+			return nil, errors.Wrap(err, "error loading processed oracle event window")
+		}
+	}
+	return &window, nil
+}
+
+func saveProcessedOracleEventWindow(ctx contract.Context, window *oracle.ProcessedOracleEventWindow) error {
+	if err := ctx.Set(processedOracleEventsKey, window); err != nil {
+		return errors.Wrap(err, "error saving processed oracle event window")
+	}
+	return nil
+}
+
+func loadOracleSet(ctx contract.StaticContext) (*oracle.OracleSet, error) {
+	var oracleSet oracle.OracleSet
+	if err := ctx.Get(oracleSetKey, &oracleSet); err != nil {
+		if err != contract.ErrNotFound {
+			return nil, errors.Wrap(err, "error loading oracle set")
+		}
+	}
+	return &oracleSet, nil
+}
+
+func saveOracleSet(ctx contract.Context, oracleSet *oracle.OracleSet) error {
+	if err := ctx.Set(oracleSetKey, oracleSet); err != nil {
+		return errors.Wrap(err, "error saving oracle set")
+	}
+	return nil
+}
+
+func loadOracleCallVotes(ctx contract.StaticContext) (*oracle.OracleCallVoteList, error) {
+	var voteList oracle.OracleCallVoteList
+	if err := ctx.Get(oracleCallVotesKey, &voteList); err != nil {
+		if err != contract.ErrNotFound {
+			return nil, errors.Wrap(err, "error loading oracle call votes")
+		}
+	}
+	return &voteList, nil
+}
+
+func saveOracleCallVotes(ctx contract.Context, voteList *oracle.OracleCallVoteList) error {
+	if err := ctx.Set(oracleCallVotesKey, voteList); err != nil {
+		return errors.Wrap(err, "error saving oracle call votes")
+	}
+	return nil
+}
+
 func saveContractState(ctx contract.Context, state *zb_data.ContractState) error {
 	if err := ctx.Set(contractStateKey, state); err != nil {
 		return errors.Wrap(err, "error saving contract state")
@@ -272,6 +404,44 @@ func savePendingMintingTransactionReceipts(ctx contract.Context, userID string,
 	return nil
 }
 
+func loadMintingTransactionReceiptHistory(ctx contract.StaticContext, userID string) (*zb_data.MintingTransactionReceiptHistory, error) {
+	var history zb_data.MintingTransactionReceiptHistory
+	if err := ctx.Get(MintingTransactionReceiptHistoryKey(userID), &history); err != nil {
+		if err != contract.ErrNotFound {
+			return nil, errors.Wrap(err, "failed to load minting transaction receipt history")
+		}
+	}
+
+	return &history, nil
+}
+
+func saveMintingTransactionReceiptHistory(ctx contract.Context, userID string, history *zb_data.MintingTransactionReceiptHistory) error {
+	if err := ctx.Set(MintingTransactionReceiptHistoryKey(userID), history); err != nil {
+		return errors.Wrap(err, "failed to save minting transaction receipt history")
+	}
+
+	return nil
+}
+
+func loadMintingReceiptWatchList(ctx contract.StaticContext) (*zb_data.MintingReceiptWatchList, error) {
+	var watchList zb_data.MintingReceiptWatchList
+	if err := ctx.Get(mintingReceiptWatchListKey, &watchList); err != nil {
+		if err != contract.ErrNotFound {
+			return nil, errors.Wrap(err, "failed to load minting receipt watch list")
+		}
+	}
+
+	return &watchList, nil
+}
+
+func saveMintingReceiptWatchList(ctx contract.Context, watchList *zb_data.MintingReceiptWatchList) error {
+	if err := ctx.Set(mintingReceiptWatchListKey, watchList); err != nil {
+		return errors.Wrap(err, "failed to save minting receipt watch list")
+	}
+
+	return nil
+}
+
 func saveDecks(ctx contract.Context, version string, userID string, decks *zb_data.DeckList) error {
 	_, err := fixDeckListCards(ctx, decks, version, userID)
 	if err != nil {
@@ -305,7 +475,7 @@ func loadDecks(ctx contract.Context, version string, userID string) (*zb_data.De
 	return deckList, nil
 }
 
-func loadDecksRaw(ctx contract.Context, userID string) (*zb_data.DeckList, error) {
+func loadDecksRaw(ctx contract.StaticContext, userID string) (*zb_data.DeckList, error) {
 	var deckList zb_data.DeckList
 	if err := ctx.Get(DecksKey(userID), &deckList); err != nil {
 		if err == contract.ErrNotFound {
@@ -318,6 +488,86 @@ func loadDecksRaw(ctx contract.Context, userID string) (*zb_data.DeckList, error
 	return &deckList, nil
 }
 
+func loadDeckStatsList(ctx contract.StaticContext, userID string) (*zb_data.DeckStatsList, error) {
+	var deckStatsList zb_data.DeckStatsList
+	if err := ctx.Get(DeckStatsKey(userID), &deckStatsList); err != nil {
+		if err != contract.ErrNotFound {
+			return nil, errors.Wrap(err, "error loading deck stats")
+		}
+	}
+
+	return &deckStatsList, nil
+}
+
+func saveDeckStatsList(ctx contract.Context, userID string, deckStatsList *zb_data.DeckStatsList) error {
+	if err := ctx.Set(DeckStatsKey(userID), deckStatsList); err != nil {
+		return errors.Wrap(err, "error saving deck stats")
+	}
+	return nil
+}
+
+// deleteDeckStats removes the stats of a deleted deck, since deck ids can be reused
+func deleteDeckStats(ctx contract.Context, userID string, deckID int64) error {
+	deckStatsList, err := loadDeckStatsList(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var deckStats []*zb_data.DeckStats
+	for _, existingDeckStats := range deckStatsList.DeckStats {
+		if existingDeckStats.DeckId != deckID {
+			deckStats = append(deckStats, existingDeckStats)
+		}
+	}
+
+	if len(deckStats) == len(deckStatsList.DeckStats) {
+		return nil
+	}
+
+	deckStatsList.DeckStats = deckStats
+	return saveDeckStatsList(ctx, userID, deckStatsList)
+}
+
+func loadDeckRevisionHistoryList(ctx contract.StaticContext, userID string) (*zb_data.DeckRevisionHistoryList, error) {
+	var deckRevisionHistoryList zb_data.DeckRevisionHistoryList
+	if err := ctx.Get(DeckRevisionsKey(userID), &deckRevisionHistoryList); err != nil {
+		if err != contract.ErrNotFound {
+			return nil, errors.Wrap(err, "error loading deck revisions")
+		}
+	}
+
+	return &deckRevisionHistoryList, nil
+}
+
+func saveDeckRevisionHistoryList(ctx contract.Context, userID string, deckRevisionHistoryList *zb_data.DeckRevisionHistoryList) error {
+	if err := ctx.Set(DeckRevisionsKey(userID), deckRevisionHistoryList); err != nil {
+		return errors.Wrap(err, "error saving deck revisions")
+	}
+	return nil
+}
+
+// deleteDeckRevisions removes the revisions of a deleted deck, since deck ids can be reused
+func deleteDeckRevisions(ctx contract.Context, userID string, deckID int64) error {
+	deckRevisionHistoryList, err := loadDeckRevisionHistoryList(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var deckRevisionHistories []*zb_data.DeckRevisionHistory
+	for _, deckRevisionHistory := range deckRevisionHistoryList.Decks {
+		if deckRevisionHistory.DeckId != deckID {
+			deckRevisionHistories = append(deckRevisionHistories, deckRevisionHistory)
+		}
+	}
+
+	if len(deckRevisionHistories) == len(deckRevisionHistoryList.Decks) {
+		return nil
+	}
+
+	deckRevisionHistoryList.Decks = deckRevisionHistories
+	return saveDeckRevisionHistoryList(ctx, userID, deckRevisionHistoryList)
+}
+
 func saveAIDecks(ctx contract.Context, version string, decks *zb_data.AIDeckList) error {
 	if err := ctx.Set(MakeVersionedKey(version, aiDecksKey), decks); err != nil {
 		return errors.Wrap(err, "error saving AI decks")
@@ -428,6 +678,177 @@ func saveOverlordLevelingData(ctx contract.Context, version string, overlordLeve
 	return nil
 }
 
+func loadCraftingData(ctx contract.StaticContext, version string) (*zb_data.CraftingData, error) {
+	var craftingData zb_data.CraftingData
+	if err := ctx.Get(MakeVersionedKey(version, craftingDataKey), &craftingData); err != nil {
+		if err != contract.ErrNotFound {
+			return nil, errors.Wrap(err, "error getting crafting data")
+		}
+	}
+
+	return &craftingData, nil
+}
+
+func saveCraftingData(ctx contract.Context, version string, craftingData *zb_data.CraftingData) error {
+	if err := ctx.Set(MakeVersionedKey(version, craftingDataKey), craftingData); err != nil {
+		return errors.Wrap(err, "error setting crafting data")
+	}
+
+	return nil
+}
+
+func loadPackDefinitions(ctx contract.StaticContext, version string) (*zb_data.PackDefinitionList, error) {
+	var packDefinitionList zb_data.PackDefinitionList
+	if err := ctx.Get(MakeVersionedKey(version, packDefinitionsKey), &packDefinitionList); err != nil {
+		if err != contract.ErrNotFound {
+			return nil, errors.Wrap(err, "error getting pack definitions")
+		}
+	}
+
+	return &packDefinitionList, nil
+}
+
+func savePackDefinitions(ctx contract.Context, version string, packDefinitionList *zb_data.PackDefinitionList) error {
+	if err := ctx.Set(MakeVersionedKey(version, packDefinitionsKey), packDefinitionList); err != nil {
+		return errors.Wrap(err, "error setting pack definitions")
+	}
+
+	return nil
+}
+
+func loadCardKeyMigrations(ctx contract.StaticContext, version string) (*zb_data.CardKeyMigrationList, error) {
+	var cardKeyMigrationList zb_data.CardKeyMigrationList
+	if err := ctx.Get(MakeVersionedKey(version, cardKeyMigrationsKey), &cardKeyMigrationList); err != nil {
+		if err != contract.ErrNotFound {
+			return nil, errors.Wrap(err, "error getting card key migrations")
+		}
+	}
+
+	return &cardKeyMigrationList, nil
+}
+
+func saveCardKeyMigrations(ctx contract.Context, version string, cardKeyMigrationList *zb_data.CardKeyMigrationList) error {
+	if err := ctx.Set(MakeVersionedKey(version, cardKeyMigrationsKey), cardKeyMigrationList); err != nil {
+		return errors.Wrap(err, "error setting card key migrations")
+	}
+
+	return nil
+}
+
+func loadCardHistory(ctx contract.StaticContext, cardKey battleground_proto.CardKey) (*zb_data.CardHistory, error) {
+	var cardHistory zb_data.CardHistory
+	if err := ctx.Get(CardHistoryKey(cardKey), &cardHistory); err != nil {
+		if err != contract.ErrNotFound {
+			return nil, errors.Wrap(err, "error loading card history")
+		}
+	}
+
+	return &cardHistory, nil
+}
+
+func saveCardHistory(ctx contract.Context, cardKey battleground_proto.CardKey, cardHistory *zb_data.CardHistory) error {
+	if err := ctx.Set(CardHistoryKey(cardKey), cardHistory); err != nil {
+		return errors.Wrap(err, "error saving card history")
+	}
+
+	return nil
+}
+
+func loadPackOpenings(ctx contract.StaticContext, userID string) (*zb_data.PackOpeningList, error) {
+	var packOpeningList zb_data.PackOpeningList
+	if err := ctx.Get(PackOpeningsKey(userID), &packOpeningList); err != nil {
+		if err != contract.ErrNotFound {
+			return nil, errors.Wrap(err, "error loading pack openings")
+		}
+	}
+
+	return &packOpeningList, nil
+}
+
+func savePackOpenings(ctx contract.Context, userID string, packOpeningList *zb_data.PackOpeningList) error {
+	if err := ctx.Set(PackOpeningsKey(userID), packOpeningList); err != nil {
+		return errors.Wrap(err, "error saving pack openings")
+	}
+
+	return nil
+}
+
+func loadUnopenedPacksByAddress(ctx contract.StaticContext, address loom.Address) (*zb_data.UnopenedPackList, error) {
+	var unopenedPackList zb_data.UnopenedPackList
+	if err := ctx.Get(AddressToUnopenedPacksKey(address), &unopenedPackList); err != nil {
+		if err != contract.ErrNotFound {
+			return nil, errors.Wrap(err, "error loading unopened packs")
+		}
+	}
+
+	return &unopenedPackList, nil
+}
+
+func saveUnopenedPacksByAddress(ctx contract.Context, address loom.Address, unopenedPackList *zb_data.UnopenedPackList) error {
+	if err := ctx.Set(AddressToUnopenedPacksKey(address), unopenedPackList); err != nil {
+		return errors.Wrap(err, "error saving unopened packs")
+	}
+
+	return nil
+}
+
+func loadPackBalances(ctx contract.StaticContext, userID string) (*zb_data.PackBalanceList, error) {
+	var packBalanceList zb_data.PackBalanceList
+	if err := ctx.Get(PackBalancesKey(userID), &packBalanceList); err != nil {
+		if err != contract.ErrNotFound {
+			return nil, errors.Wrap(err, "error loading pack balances")
+		}
+	}
+
+	return &packBalanceList, nil
+}
+
+func savePackBalances(ctx contract.Context, userID string, packBalanceList *zb_data.PackBalanceList) error {
+	if err := ctx.Set(PackBalancesKey(userID), packBalanceList); err != nil {
+		return errors.Wrap(err, "error saving pack balances")
+	}
+
+	return nil
+}
+
+func loadCraftingAuditLog(ctx contract.StaticContext, userID string) (*zb_data.CraftingAuditLog, error) {
+	var auditLog zb_data.CraftingAuditLog
+	if err := ctx.Get(CraftingAuditLogKey(userID), &auditLog); err != nil {
+		if err != contract.ErrNotFound {
+			return nil, errors.Wrap(err, "error loading crafting audit log")
+		}
+	}
+
+	return &auditLog, nil
+}
+
+func saveCraftingAuditLog(ctx contract.Context, userID string, auditLog *zb_data.CraftingAuditLog) error {
+	if err := ctx.Set(CraftingAuditLogKey(userID), auditLog); err != nil {
+		return errors.Wrap(err, "error saving crafting audit log")
+	}
+
+	return nil
+}
+
+func loadGamechainCardAmountChanges(ctx contract.StaticContext, userID string) (*zb_data.CardAmountChangeItemsContainer, error) {
+	var container zb_data.CardAmountChangeItemsContainer
+	if err := ctx.Get(GamechainCardAmountChangesKey(userID), &container); err != nil {
+		if err != contract.ErrNotFound {
+			return nil, errors.Wrap(err, "error loading gamechain card amount changes")
+		}
+	}
+
+	return &container, nil
+}
+
+func saveGamechainCardAmountChanges(ctx contract.Context, userID string, container *zb_data.CardAmountChangeItemsContainer) error {
+	if err := ctx.Set(GamechainCardAmountChangesKey(userID), container); err != nil {
+		return errors.Wrap(err, "error saving gamechain card amount changes")
+	}
+
+	return nil
+}
+
 func prepareEmitMsgJSON(address []byte, owner, method string) ([]byte, error) {
 	emitMsg := struct {
 		Owner  string
@@ -491,20 +912,81 @@ func copyAccountInfo(account *zb_data.Account, req *zb_calls.UpsertAccountReques
 	account.GameMembershipTier = req.GameMembershipTier
 }
 
-func savePlayerPool(ctx contract.Context, pool *zb_data.PlayerPool) error {
-	return ctx.Set(playerPoolKey, pool)
+func savePlayerPool(ctx contract.Context, queueName string, pool *zb_data.PlayerPool) error {
+	if err := ctx.Set(MatchMakingQueuePlayerPoolKey(queueName), pool); err != nil {
+		return err
+	}
+
+	return updateActiveMatchMakingQueues(ctx, queueName, len(pool.PlayerProfiles) > 0)
+}
+
+// updateActiveMatchMakingQueues keeps track of the queues that have players waiting,
+// so dynamically named queues can be swept too
+func updateActiveMatchMakingQueues(ctx contract.Context, queueName string, active bool) error {
+	queueNames, err := loadActiveMatchMakingQueues(ctx)
+	if err != nil {
+		return err
+	}
+
+	if containsString(queueNames, queueName) == active {
+		return nil
+	}
+
+	if active {
+		queueNames = append(queueNames, queueName)
+	} else {
+		var newQueueNames []string
+		for _, name := range queueNames {
+			if name != queueName {
+				newQueueNames = append(newQueueNames, name)
+			}
+		}
+		queueNames = newQueueNames
+	}
+
+	if err := ctx.Set(matchMakingQueueListKey, &zb_data.MatchMakingQueueNameList{Names: queueNames}); err != nil {
+		return errors.Wrap(err, "error saving matchmaking queue list")
+	}
+	return nil
 }
 
-func loadPlayerPool(ctx contract.Context) (*zb_data.PlayerPool, error) {
-	return loadPlayerPoolInternal(ctx, playerPoolKey)
+func loadActiveMatchMakingQueues(ctx contract.StaticContext) ([]string, error) {
+	var queueNameList zb_data.MatchMakingQueueNameList
+	if err := ctx.Get(matchMakingQueueListKey, &queueNameList); err != nil {
+		if err == contract.ErrNotFound {
+			return []string{}, nil
+		}
+		return nil, errors.Wrap(err, "error loading matchmaking queue list")
+	}
+
+	return queueNameList.Names, nil
 }
 
-func saveTaggedPlayerPool(ctx contract.Context, pool *zb_data.PlayerPool) error {
-	return ctx.Set(taggedPlayerPoolKey, pool)
+func loadPlayerPool(ctx contract.Context, queueName string) (*zb_data.PlayerPool, error) {
+	return loadPlayerPoolInternal(ctx, MatchMakingQueuePlayerPoolKey(queueName))
 }
 
-func loadTaggedPlayerPool(ctx contract.Context) (*zb_data.PlayerPool, error) {
-	return loadPlayerPoolInternal(ctx, taggedPlayerPoolKey)
+func saveUserMatchMakingQueues(ctx contract.Context, userID string, queueNames []string) error {
+	if len(queueNames) == 0 {
+		ctx.Delete(UserMatchMakingQueuesKey(userID))
+		return nil
+	}
+
+	if err := ctx.Set(UserMatchMakingQueuesKey(userID), &zb_data.MatchMakingQueueNameList{Names: queueNames}); err != nil {
+		return errors.Wrap(err, "error saving user matchmaking queues")
+	}
+	return nil
+}
+
+func loadUserMatchMakingQueues(ctx contract.StaticContext, userID string) ([]string, error) {
+	var queueNameList zb_data.MatchMakingQueueNameList
+	if err := ctx.Get(UserMatchMakingQueuesKey(userID), &queueNameList); err != nil {
+		if err == contract.ErrNotFound {
+			return []string{}, nil
+		}
+		return nil, errors.Wrap(err, "error loading user matchmaking queues")
+	}
+	return queueNameList.Names, nil
 }
 
 func loadPlayerPoolInternal(ctx contract.Context, poolKey []byte) (*zb_data.PlayerPool, error) {
@@ -568,6 +1050,39 @@ func nextMatchID(ctx contract.Context) (int64, error) {
 	return count.CurrentId, nil
 }
 
+func nextChallengeID(ctx contract.Context) (int64, error) {
+	var count zb_data.ChallengeCount
+	err := ctx.Get(challengeCountKey, &count)
+	if err != nil && err != contract.ErrNotFound {
+		return 0, err
+	}
+	count.CurrentId++
+	if err := ctx.Set(challengeCountKey, &zb_data.ChallengeCount{CurrentId: count.CurrentId}); err != nil {
+		return 0, err
+	}
+	return count.CurrentId, nil
+}
+
+func saveChallenge(ctx contract.Context, challenge *zb_data.Challenge) error {
+	if err := ctx.Set(ChallengeKey(challenge.Code), challenge); err != nil {
+		return errors.Wrap(err, "error saving challenge")
+	}
+
+	return nil
+}
+
+func loadChallenge(ctx contract.StaticContext, code string) (*zb_data.Challenge, error) {
+	var challenge zb_data.Challenge
+	if err := ctx.Get(ChallengeKey(code), &challenge); err != nil {
+		if err == contract.ErrNotFound {
+			return nil, fmt.Errorf("challenge %s not found", code)
+		}
+		return nil, errors.Wrap(err, "error loading challenge")
+	}
+
+	return &challenge, nil
+}
+
 func saveGameState(ctx contract.Context, gs *zb_data.GameState) error {
 	if err := ctx.Set(GameStateKey(gs.Id), gs); err != nil {
 		return err
@@ -766,6 +1281,16 @@ func getGameModeFromListByName(gameModeList *zb_data.GameModeList, name string)
 	return nil
 }
 
+func getGameModeFromListByAddress(gameModeList *zb_data.GameModeList, address loom.Address) *zb_data.GameMode {
+	for _, gameMode := range gameModeList.GameModes {
+		if gameMode.Address != nil && gameMode.Address.Local.Compare(address.Local) == 0 {
+			return gameMode
+		}
+	}
+
+	return nil
+}
+
 func deleteGameMode(gameModeList *zb_data.GameModeList, ID string) (*zb_data.GameModeList, bool) {
 	newList := make([]*zb_data.GameMode, 0)
 	for _, gameMode := range gameModeList.GameModes {
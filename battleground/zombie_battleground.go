@@ -43,6 +43,7 @@ const (
 	TopicRegisterPlayerPoolEvent = "registerplayerpool"
 	TopicFindMatchEvent          = "findmatch"
 	TopicAcceptMatchEvent        = "acceptmatch"
+	TopicCardHistoryEvent        = "cardhistory"
 	// match pattern match:id e.g. match:1, match:2, ...
 	TopicMatchEventPrefix = "match:"
 	TopicUserEventPrefix  = "user:"
@@ -61,6 +62,8 @@ var (
 	// Error list
 	ErrOracleNotSpecified = errors.New("oracle not specified")
 	ErrOracleNotVerified  = errors.New("oracle not verified")
+	ErrNotOracleSetMember = errors.New("sender is not a member of the oracle set")
+	ErrOracleSetEnabled   = errors.New("not allowed while the oracle set is enabled")
 	ErrInvalidEventBatch  = errors.New("invalid event batch")
 	ErrVersionNotSet      = errors.New("data version not set")
 	ErrDebugNotEnabled    = errors.New("debug mode not enabled")
@@ -140,6 +143,26 @@ func (z *ZombieBattleground) Init(ctx contract.Context, req *zb_calls.InitReques
 		return err
 	}
 
+	// initialize crafting
+	craftingData := req.Crafting
+	if craftingData == nil {
+		craftingData = &zb_data.CraftingData{}
+	}
+	if err := validateCraftingData(craftingData); err != nil {
+		return errors.Wrap(err, "error while validating crafting data")
+	}
+	if err := saveCraftingData(ctx, req.Version, craftingData); err != nil {
+		return err
+	}
+
+	// initialize pack definitions
+	if err := validatePackDefinitions(req.Packs, cardLibrary.Cards); err != nil {
+		return errors.Wrap(err, "error while validating pack definitions")
+	}
+	if err := savePackDefinitions(ctx, req.Version, &zb_data.PackDefinitionList{Packs: req.Packs}); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -152,6 +175,9 @@ func (z *ZombieBattleground) UpdateInit(ctx contract.Context, req *zb_calls.Upda
 	var defaultDecks zb_data.DeckList
 	var aiDeckList zb_data.AIDeckList
 	var overlordLevelingData *zb_data.OverlordLevelingData
+	var craftingData *zb_data.CraftingData
+	var packDefinitionList zb_data.PackDefinitionList
+	var cardKeyMigrationList zb_data.CardKeyMigrationList
 
 	// load data
 	// card library
@@ -195,6 +221,18 @@ func (z *ZombieBattleground) UpdateInit(ctx contract.Context, req *zb_calls.Upda
 		return fmt.Errorf("'overlordLeveling' key missing")
 	}
 
+	// crafting rates, optional
+	craftingData = initData.Crafting
+	if craftingData == nil {
+		craftingData = &zb_data.CraftingData{}
+	}
+
+	// pack definitions, optional
+	packDefinitionList.Packs = initData.Packs
+
+	// card key migrations, optional
+	cardKeyMigrationList.Migrations = initData.CardKeyMigrations
+
 	// validate data
 	// card library
 	err := validateCardLibraryCards(cardList.Cards)
@@ -216,6 +254,26 @@ func (z *ZombieBattleground) UpdateInit(ctx contract.Context, req *zb_calls.Upda
 		}
 	}
 
+	// crafting
+	if err := validateCraftingData(craftingData); err != nil {
+		return errors.Wrap(err, "error while validating crafting data")
+	}
+
+	// packs
+	if err := validatePackDefinitions(packDefinitionList.Packs, cardList.Cards); err != nil {
+		return errors.Wrap(err, "error while validating pack definitions")
+	}
+
+	// card key migrations
+	if err := validateCardKeyMigrations(cardKeyMigrationList.Migrations, cardList.Cards); err != nil {
+		return errors.Wrap(err, "error while validating card key migrations")
+	}
+
+	// record card changes before the previous card library is replaced
+	if err := recordCardLibraryChanges(ctx, initData.Version, cardList.Cards); err != nil {
+		return errors.Wrap(err, "error recording card history")
+	}
+
 	// initialize card library
 	if err := saveCardLibrary(ctx, initData.Version, &cardList); err != nil {
 		return err
@@ -246,6 +304,21 @@ func (z *ZombieBattleground) UpdateInit(ctx contract.Context, req *zb_calls.Upda
 		return err
 	}
 
+	// initialize crafting
+	if err := saveCraftingData(ctx, initData.Version, craftingData); err != nil {
+		return err
+	}
+
+	// initialize pack definitions
+	if err := savePackDefinitions(ctx, initData.Version, &packDefinitionList); err != nil {
+		return err
+	}
+
+	// initialize card key migrations
+	if err := saveCardKeyMigrations(ctx, initData.Version, &cardKeyMigrationList); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -256,6 +329,9 @@ func (z *ZombieBattleground) GetInit(ctx contract.StaticContext, req *zb_calls.G
 	var defaultDecks *zb_data.DeckList
 	var aiDeckList *zb_data.AIDeckList
 	var overlordLevelingData *zb_data.OverlordLevelingData
+	var craftingData *zb_data.CraftingData
+	var packDefinitionList *zb_data.PackDefinitionList
+	var cardKeyMigrationList *zb_data.CardKeyMigrationList
 
 	cardLibrary, err := loadCardLibraryRaw(ctx, req.Version)
 	if err != nil {
@@ -287,6 +363,21 @@ func (z *ZombieBattleground) GetInit(ctx contract.StaticContext, req *zb_calls.G
 		return nil, err
 	}
 
+	craftingData, err = loadCraftingData(ctx, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	packDefinitionList, err = loadPackDefinitions(ctx, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	cardKeyMigrationList, err = loadCardKeyMigrations(ctx, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
 	var oracleAddress types.Address
 	err = ctx.Get(oracleKey, &oracleAddress)
 	if err != nil {
@@ -301,6 +392,9 @@ func (z *ZombieBattleground) GetInit(ctx contract.StaticContext, req *zb_calls.G
 			DefaultCollection: defaultCardCollection.Cards,
 			AiDecks:           aiDeckList.Decks,
 			OverlordLeveling:  overlordLevelingData,
+			Crafting:          craftingData,
+			Packs:             packDefinitionList.Packs,
+			CardKeyMigrations: cardKeyMigrationList.Migrations,
 			Version:           req.Version,
 			Oracle:            &oracleAddress,
 		},
@@ -554,11 +648,21 @@ func (z *ZombieBattleground) CreateDeck(ctx contract.Context, req *zb_calls.Crea
 		return nil, err
 	}
 
-	err = validateDeck(false, cardLibrary, userCardCollection, req.Deck, deckList.Decks, overlords)
+	configuration, err := loadContractConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateDeck(false, cardLibrary, userCardCollection, req.Deck, deckList.Decks, overlords, getDeckRules(configuration, DefaultDeckRulesName))
 	if err != nil {
 		return nil, errors.Wrap(err, "error validating deck")
 	}
 
+	req.Deck.LegalFormats, err = getDeckLegalFormats(configuration, cardLibrary.Cards, req.Deck, overlords)
+	if err != nil {
+		return nil, err
+	}
+
 	// allocate new deck id starting from 1
 	var newDeckID int64
 	if len(deckList.Decks) != 0 {
@@ -575,6 +679,10 @@ func (z *ZombieBattleground) CreateDeck(ctx contract.Context, req *zb_calls.Crea
 		return nil, err
 	}
 
+	if err := addDeckRevision(ctx, req.UserId, nil, req.Deck, zb_data.DeckRevision_Created, 0); err != nil {
+		return nil, err
+	}
+
 	senderAddress := ctx.Message().Sender.Local.String()
 	emitMsg := zb_calls.CreateDeckEvent{
 		UserId:        req.UserId,
@@ -589,58 +697,76 @@ func (z *ZombieBattleground) CreateDeck(ctx contract.Context, req *zb_calls.Crea
 	}
 	ctx.EmitTopics(data, TopicCreateDeckEvent)
 
-	return &zb_calls.CreateDeckResponse{DeckId: newDeckID}, nil
+	return &zb_calls.CreateDeckResponse{
+		DeckId:       newDeckID,
+		LegalFormats: req.Deck.LegalFormats,
+	}, nil
 }
 
 // EditDeck edits the deck by id
-func (z *ZombieBattleground) EditDeck(ctx contract.Context, req *zb_calls.EditDeckRequest) error {
+func (z *ZombieBattleground) EditDeck(ctx contract.Context, req *zb_calls.EditDeckRequest) (*zb_calls.EditDeckResponse, error) {
 	if req.Version == "" {
-		return ErrVersionNotSet
+		return nil, ErrVersionNotSet
 	}
 	if req.Deck == nil {
-		return fmt.Errorf("deck must not be nil")
+		return nil, fmt.Errorf("deck must not be nil")
 	}
 	if !isOwner(ctx, req.UserId) {
-		return ErrUserNotVerified
+		return nil, ErrUserNotVerified
 	}
 
 	overlords, err := loadOverlordUserInstances(ctx, req.Version, req.UserId)
 	if err != nil {
-		return errors.Wrapf(err, "unable to get overlord instances for userId: %s", req.UserId)
+		return nil, errors.Wrapf(err, "unable to get overlord instances for userId: %s", req.UserId)
 	}
 
 	deckList, err := loadDecks(ctx, req.Version, req.UserId)
 	if err != nil {
-		return errors.Wrap(err, "unable to load decks")
+		return nil, errors.Wrap(err, "unable to load decks")
 	}
 
 	cardLibrary, err := loadCardLibrary(ctx, req.Version)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	userCardCollection, err := loadUserCardCollection(ctx, req.Version, req.UserId)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	configuration, err := loadContractConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateDeck(true, cardLibrary, userCardCollection, req.Deck, deckList.Decks, overlords, getDeckRules(configuration, DefaultDeckRulesName))
+	if err != nil {
+		return nil, errors.Wrap(err, "error validating deck")
 	}
 
-	err = validateDeck(true, cardLibrary, userCardCollection, req.Deck, deckList.Decks, overlords)
+	req.Deck.LegalFormats, err = getDeckLegalFormats(configuration, cardLibrary.Cards, req.Deck, overlords)
 	if err != nil {
-		return errors.Wrap(err, "error validating deck")
+		return nil, err
 	}
 
 	existingDeck := getDeckByID(deckList.Decks, req.Deck.Id)
 	if existingDeck == nil {
-		return ErrNotFound
+		return nil, ErrNotFound
 	}
 
 	// update deck
+	previousDeck := proto.Clone(existingDeck).(*zb_data.Deck)
 	existingDeck.Reset()
 	proto.Merge(existingDeck, req.Deck)
 
 	// update decklist
 	if err := saveDecks(ctx, req.Version, req.UserId, deckList); err != nil {
-		return errors.Wrap(err, "error saving decks")
+		return nil, errors.Wrap(err, "error saving decks")
+	}
+
+	if err := addDeckRevision(ctx, req.UserId, previousDeck, existingDeck, zb_data.DeckRevision_Edited, 0); err != nil {
+		return nil, err
 	}
 
 	senderAddress := ctx.Message().Sender.Local.String()
@@ -653,11 +779,115 @@ func (z *ZombieBattleground) EditDeck(ctx contract.Context, req *zb_calls.EditDe
 
 	data, err := proto.Marshal(&emitMsg)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	ctx.EmitTopics(data, TopicEditDeckEvent)
 
-	return nil
+	return &zb_calls.EditDeckResponse{
+		LegalFormats: req.Deck.LegalFormats,
+	}, nil
+}
+
+// ValidateDeck checks the deck against the deck rules or format and reports every violation,
+// the default rules are used if no rules name is specified
+func (z *ZombieBattleground) ValidateDeck(ctx contract.StaticContext, req *zb_calls.ValidateDeckRequest) (*zb_calls.ValidateDeckResponse, error) {
+	if req.Version == "" {
+		return nil, ErrVersionNotSet
+	}
+	if req.Deck == nil {
+		return nil, ErrDeckMustNotNil
+	}
+
+	configuration, err := loadContractConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deckRulesName := req.DeckRulesName
+	if deckRulesName == "" {
+		deckRulesName = DefaultDeckRulesName
+	}
+
+	deckRules := getDeckRules(configuration, deckRulesName)
+	if deckRules == nil {
+		deckRules = getFormat(configuration, deckRulesName)
+	}
+	if deckRules == nil {
+		if req.DeckRulesName != "" {
+			return nil, fmt.Errorf("deck rules %s not found", req.DeckRulesName)
+		}
+
+		return &zb_calls.ValidateDeckResponse{Legal: true}, nil
+	}
+
+	overlords, err := loadOverlordUserInstances(ctx, req.Version, req.UserId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get overlord instances for userId: %s", req.UserId)
+	}
+
+	cardLibrary, err := loadCardLibrary(ctx, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	issues, err := getDeckLegalityIssues(deckRules, cardLibrary.Cards, req.Deck, overlords)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zb_calls.ValidateDeckResponse{
+		Legal:  len(issues) == 0,
+		Issues: issues,
+	}, nil
+}
+
+// ExportDeck returns the shareable code of the deck
+func (z *ZombieBattleground) ExportDeck(ctx contract.Context, req *zb_calls.ExportDeckRequest) (*zb_calls.ExportDeckResponse, error) {
+	if req.Version == "" {
+		return nil, ErrVersionNotSet
+	}
+
+	deckList, err := loadDecks(ctx, req.Version, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	deck := getDeckByID(deckList.Decks, req.DeckId)
+	if deck == nil {
+		return nil, contract.ErrNotFound
+	}
+
+	deckCode, err := EncodeDeckCode(deck)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zb_calls.ExportDeckResponse{
+		DeckCode: deckCode,
+	}, nil
+}
+
+// ImportDeck creates a new deck from a deck code, the cards must be in the user's collection
+func (z *ZombieBattleground) ImportDeck(ctx contract.Context, req *zb_calls.ImportDeckRequest) (*zb_calls.ImportDeckResponse, error) {
+	deck, err := DecodeDeckCode(req.DeckCode)
+	if err != nil {
+		return nil, err
+	}
+	deck.Name = req.Name
+
+	createDeckResponse, err := z.CreateDeck(ctx, &zb_calls.CreateDeckRequest{
+		UserId:  req.UserId,
+		Deck:    deck,
+		Version: req.Version,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error importing deck")
+	}
+
+	return &zb_calls.ImportDeckResponse{
+		DeckId:       createDeckResponse.DeckId,
+		LegalFormats: createDeckResponse.LegalFormats,
+	}, nil
 }
 
 // DeleteDeck deletes a user's deck by id
@@ -685,6 +915,14 @@ func (z *ZombieBattleground) DeleteDeck(ctx contract.Context, req *zb_calls.Dele
 		return err
 	}
 
+	if err := deleteDeckStats(ctx, req.UserId, req.DeckId); err != nil {
+		return err
+	}
+
+	if err := deleteDeckRevisions(ctx, req.UserId, req.DeckId); err != nil {
+		return err
+	}
+
 	senderAddress := ctx.Message().Sender.Local.String()
 	emitMsg := zb_calls.DeleteDeckEvent{
 		UserId:        req.UserId,
@@ -711,6 +949,11 @@ func (z *ZombieBattleground) ListDecks(ctx contract.Context, req *zb_calls.ListD
 	if err != nil {
 		return nil, err
 	}
+
+	if err := updateDecksLegalFormats(ctx, req.Version, req.UserId, deckList.Decks); err != nil {
+		return nil, err
+	}
+
 	return &zb_calls.ListDecksResponse{
 		Decks: deckList.Decks,
 	}, nil
@@ -730,6 +973,11 @@ func (z *ZombieBattleground) GetDeck(ctx contract.Context, req *zb_calls.GetDeck
 	if deck == nil {
 		return nil, contract.ErrNotFound
 	}
+
+	if err := updateDecksLegalFormats(ctx, req.Version, req.UserId, []*zb_data.Deck{deck}); err != nil {
+		return nil, err
+	}
+
 	return &zb_calls.GetDeckResponse{Deck: deck}, nil
 }
 
@@ -817,7 +1065,7 @@ func (z *ZombieBattleground) GetOverlordUserInstance(ctx contract.StaticContext,
 
 func (z *ZombieBattleground) RegisterPlayerPool(ctx contract.Context, req *zb_calls.RegisterPlayerPoolRequest) (*zb_calls.RegisterPlayerPoolResponse, error) {
 	// preparing user profile consisting of deck, score, ...
-	_, err := getDeckWithRegistrationData(ctx, req.RegistrationData, req.RegistrationData.Version)
+	deck, err := getDeckWithRegistrationData(ctx, req.RegistrationData, req.RegistrationData.Version)
 	if err != nil {
 		return nil, err
 	}
@@ -831,74 +1079,74 @@ func (z *ZombieBattleground) RegisterPlayerPool(ctx contract.Context, req *zb_ca
 		sort.Strings(req.RegistrationData.Tags)
 	}
 
-	profile := zb_data.PlayerProfile{
-		RegistrationData: req.RegistrationData,
-		UpdatedAt:        ctx.Now().Unix(),
+	configuration, err := loadContractConfiguration(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	var loadPlayerPoolFn func(contract.Context) (*zb_data.PlayerPool, error)
-	var savePlayerPoolFn func(contract.Context, *zb_data.PlayerPool) error
-	// if the tags is set, use tagged playerpool
-	if len(profile.RegistrationData.Tags) > 0 {
-		loadPlayerPoolFn = loadTaggedPlayerPool
-		savePlayerPoolFn = saveTaggedPlayerPool
-	} else {
-		loadPlayerPoolFn = loadPlayerPool
-		savePlayerPoolFn = savePlayerPool
+	// check the registration against the rules of every requested queue
+	queueNames := getRegistrationMatchMakingQueueNames(req.RegistrationData)
+	for _, queueName := range queueNames {
+		queue, err := getMatchMakingQueue(configuration, queueName)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := queue.validateRegistrationData(req.RegistrationData); err != nil {
+			return nil, err
+		}
+
+		if queue.Rules.RequiredFormat != "" {
+			err := validateDeckForFormat(ctx, configuration, queue.Rules.RequiredFormat, req.RegistrationData.Version, req.RegistrationData.UserId, deck)
+			if err != nil {
+				return nil, errors.Wrapf(err, "matchmaking queue %s requires format %s", queueName, queue.Rules.RequiredFormat)
+			}
+		}
 	}
 
-	// load player pool
-	pool, err := loadPlayerPoolFn(ctx)
-	if err != nil {
+	if err := validateRegistrationCustomGame(ctx, req.RegistrationData, queueNames); err != nil {
 		return nil, err
 	}
+	req.RegistrationData.Queues = queueNames
+
+	profile := zb_data.PlayerProfile{
+		RegistrationData: req.RegistrationData,
+		UpdatedAt:        ctx.Now().Unix(),
+		EloScore:         loadUserEloScore(ctx, req.RegistrationData.UserId),
+	}
 
 	match, _ := loadUserCurrentMatch(ctx, req.RegistrationData.UserId)
 	if match != nil {
 		return nil, errors.New("Player is already in a match")
 	}
 
-	targetProfile := findPlayerProfileByID(pool, req.RegistrationData.UserId)
-	// if player is in the pool, remove the player from the pool first. otherwise, the profile won't get updated
-	if targetProfile != nil {
-		pool = removePlayerFromPool(pool, req.RegistrationData.UserId)
+	// if player is in any queue, remove the player from the queues first. otherwise, the profile won't get updated
+	if err := removePlayerFromMatchMakingQueues(ctx, req.RegistrationData.UserId); err != nil {
+		return nil, err
 	}
-	pool.PlayerProfiles = append(pool.PlayerProfiles, &profile)
-	if err := savePlayerPoolFn(ctx, pool); err != nil {
+
+	// evict the stale registrations of the queues
+	if _, err := evictTimedOutPlayerPoolEntries(ctx, configuration, queueNames, req.RegistrationData.UserId); err != nil {
 		return nil, err
 	}
 
-	// prune the timed out player profile
-	for _, pp := range pool.PlayerProfiles {
-		updatedAt := time.Unix(pp.UpdatedAt, 0)
-		if updatedAt.Add(MMTimeout).Before(ctx.Now()) {
-			ctx.Logger().Info(fmt.Sprintf("Player profile %s timedout", pp.RegistrationData.UserId))
-			// remove player from the pool
-			pool = removePlayerFromPool(pool, pp.RegistrationData.UserId)
-			if err := savePlayerPoolFn(ctx, pool); err != nil {
-				return nil, err
-			}
-			// remove match
-			match, _ := loadUserCurrentMatch(ctx, pp.RegistrationData.UserId)
-			if match != nil {
-				ctx.Delete(MatchKey(match.Id))
-				match.Status = zb_data.Match_Timedout
-				// remove player's match if existing
-				ctx.Delete(UserMatchKey(pp.RegistrationData.UserId))
-				// notify player
-				emitMsg := zb_data.PlayerActionEvent{
-					Match:            match,
-					CreatedByBackend: true,
-				}
-				data, err := proto.Marshal(&emitMsg)
-				if err != nil {
-					return nil, err
-				}
-				ctx.EmitTopics(data, match.Topics...)
-			}
+	for _, queueName := range queueNames {
+		pool, err := loadPlayerPool(ctx, queueName)
+		if err != nil {
+			return nil, err
+		}
+
+		pool.PlayerProfiles = append(pool.PlayerProfiles, &profile)
+
+		if err := savePlayerPool(ctx, queueName, pool); err != nil {
+			return nil, err
 		}
 	}
 
+	if err := saveUserMatchMakingQueues(ctx, req.RegistrationData.UserId, queueNames); err != nil {
+		return nil, err
+	}
+
 	senderAddress := []byte(ctx.Message().Sender.Local)
 	emitMsgJSON, err := prepareEmitMsgJSON(senderAddress, req.RegistrationData.UserId, "registerplayerpool")
 	if err == nil {
@@ -909,21 +1157,6 @@ func (z *ZombieBattleground) RegisterPlayerPool(ctx contract.Context, req *zb_ca
 }
 
 func (z *ZombieBattleground) FindMatch(ctx contract.Context, req *zb_calls.FindMatchRequest) (*zb_calls.FindMatchResponse, error) {
-	var loadPlayerPoolFn func(contract.Context) (*zb_data.PlayerPool, error)
-	var savePlayerPoolFn func(contract.Context, *zb_data.PlayerPool) error
-	// if the tags is set, use tagged playerpool
-	if len(req.Tags) > 0 {
-		loadPlayerPoolFn = loadTaggedPlayerPool
-		savePlayerPoolFn = saveTaggedPlayerPool
-	} else {
-		loadPlayerPoolFn = loadPlayerPool
-		savePlayerPoolFn = savePlayerPool
-	}
-
-	pool, err := loadPlayerPoolFn(ctx)
-	if err != nil {
-		return nil, err
-	}
 	match, _ := loadUserCurrentMatch(ctx, req.UserId)
 	if match != nil {
 		// timeout for matchmaking
@@ -961,45 +1194,117 @@ func (z *ZombieBattleground) FindMatch(ctx contract.Context, req *zb_calls.FindM
 			MatchFound: true,
 		}, nil
 	}
-	playerProfile := findPlayerProfileByID(pool, req.UserId)
-	if playerProfile == nil {
-		return nil, errors.New("Player not found in player pool")
+
+	configuration, err := loadContractConfiguration(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	deck, err := getDeckWithRegistrationData(ctx, playerProfile.RegistrationData, playerProfile.RegistrationData.Version)
+	registeredQueueNames, err := loadUserMatchMakingQueues(ctx, req.UserId)
 	if err != nil {
 		return nil, err
 	}
 
-	// perform matchmaking function to calculate scores
+	queueNames := getRequestedMatchMakingQueueNames(req.Queues, req.Tags, registeredQueueNames)
+
+	// evict the stale registrations, so only live players are matched
+	if _, err := evictTimedOutPlayerPoolEntries(ctx, configuration, queueNames, req.UserId); err != nil {
+		return nil, err
+	}
+
+	// perform matchmaking function of every queue to calculate scores
 	// steps:
-	// 1. list all the candidates that has similar profiles TODO: match versions
-	// 2. pick the most highest score
+	// 1. list all the candidates that has similar profiles and compatible versions in every queue the player is in
+	// 2. pick the most highest score whose deck is valid for the match version, along with the player's own deck
 	// 3. if there is no candidate, sleep for MMWaitTime seconds
 	retries := 0
+	var playerProfile *zb_data.PlayerProfile
 	var matchedPlayerProfile *zb_data.PlayerProfile
+	var deck *zb_data.Deck
+	var matchedDeck *zb_data.Deck
+	var matchVersion string
 	for retries < MMRetries {
 		var playerScores []*PlayerScore
-		for _, pp := range pool.PlayerProfiles {
-			// skip the requesting player
-			if pp.RegistrationData.UserId == req.UserId {
+		for _, queueName := range queueNames {
+			queue, err := getMatchMakingQueue(configuration, queueName)
+			if err != nil {
+				return nil, err
+			}
+
+			pool, err := loadPlayerPool(ctx, queueName)
+			if err != nil {
+				return nil, err
+			}
+
+			queuePlayerProfile := findPlayerProfileByID(pool, req.UserId)
+			if queuePlayerProfile == nil {
 				continue
 			}
-			score := mmf(playerProfile, pp)
-			// only non-negative score will be added
-			if score > 0 {
-				playerScores = append(playerScores, &PlayerScore{score: score, id: pp.RegistrationData.UserId})
+			playerProfile = queuePlayerProfile
+
+			for _, pp := range pool.PlayerProfiles {
+				// skip the requesting player
+				if pp.RegistrationData.UserId == req.UserId {
+					continue
+				}
+				candidateMatchVersion, compatible := getMatchVersion(configuration, playerProfile.RegistrationData.Version, pp.RegistrationData.Version)
+				if !compatible {
+					continue
+				}
+				score := queue.MatchMakingFunc(playerProfile, pp)
+				// only non-negative score will be added
+				if score > 0 {
+					playerScores = append(playerScores, &PlayerScore{
+						score:        score,
+						id:           pp.RegistrationData.UserId,
+						profile:      pp,
+						matchVersion: candidateMatchVersion,
+					})
+				}
+			}
+		}
+
+		if playerProfile == nil {
+			return nil, errors.New("Player not found in player pool")
+		}
+
+		if deck == nil {
+			deck, err = getDeckWithRegistrationData(ctx, playerProfile.RegistrationData, playerProfile.RegistrationData.Version)
+			if err != nil {
+				return nil, err
 			}
 		}
 
 		sortedPlayerScores := sortByPlayerScore(playerScores)
-		if len(sortedPlayerScores) > 0 {
-			matchedPlayerID := sortedPlayerScores[0].id
-			matchedPlayerProfile = findPlayerProfileByID(pool, matchedPlayerID)
-			// remove the match players from the pool
-			pool = removePlayerFromPool(pool, matchedPlayerID)
-			pool = removePlayerFromPool(pool, req.UserId)
-			if err := savePlayerPoolFn(ctx, pool); err != nil {
+		for _, playerScore := range sortedPlayerScores {
+			candidateDeck, err := getDeckWithRegistrationData(ctx, playerScore.profile.RegistrationData, playerScore.profile.RegistrationData.Version)
+			if err != nil {
+				ctx.Logger().Info("skipping matchmaking candidate", "userId", playerScore.id, "err", err)
+				continue
+			}
+
+			if err := validateDeckForMatchVersion(ctx, playerProfile.RegistrationData, deck, playerScore.matchVersion); err != nil {
+				ctx.Logger().Info("skipping matchmaking candidate", "userId", playerScore.id, "err", err)
+				continue
+			}
+
+			if err := validateDeckForMatchVersion(ctx, playerScore.profile.RegistrationData, candidateDeck, playerScore.matchVersion); err != nil {
+				ctx.Logger().Info("skipping matchmaking candidate", "userId", playerScore.id, "err", err)
+				continue
+			}
+
+			matchedPlayerProfile = playerScore.profile
+			matchedDeck = candidateDeck
+			matchVersion = playerScore.matchVersion
+			break
+		}
+
+		if matchedPlayerProfile != nil {
+			// remove the match players from all the queues they are in
+			if err := removePlayerFromMatchMakingQueues(ctx, matchedPlayerProfile.RegistrationData.UserId); err != nil {
+				return nil, err
+			}
+			if err := removePlayerFromMatchMakingQueues(ctx, req.UserId); err != nil {
 				return nil, err
 			}
 			break
@@ -1013,78 +1318,12 @@ func (z *ZombieBattleground) FindMatch(ctx contract.Context, req *zb_calls.FindM
 		}, nil
 	}
 
-	// get matched player deck
-	matchedDeck, err := getDeckWithRegistrationData(ctx, matchedPlayerProfile.RegistrationData, matchedPlayerProfile.RegistrationData.Version)
+	// the match making function guarantees both players requested the same custom game
+	match, err = startMatch(ctx, playerProfile.RegistrationData, deck, matchedPlayerProfile.RegistrationData, matchedDeck, matchVersion, playerProfile.RegistrationData.CustomGame)
 	if err != nil {
 		return nil, err
 	}
 
-	// create match
-	match = &zb_data.Match{
-		Status: zb_data.Match_Matching,
-		PlayerStates: []*zb_data.InitialPlayerState{
-			&zb_data.InitialPlayerState{
-				Id:            playerProfile.RegistrationData.UserId,
-				Deck:          deck,
-				MatchAccepted: false,
-			},
-			&zb_data.InitialPlayerState{
-				Id:            matchedPlayerProfile.RegistrationData.UserId,
-				Deck:          matchedDeck,
-				MatchAccepted: false,
-			},
-		},
-		Version: playerProfile.RegistrationData.Version, // TODO: match version of both players
-		PlayerLastSeens: []*zb_data.PlayerTimestamp{
-			&zb_data.PlayerTimestamp{
-				Id:        playerProfile.RegistrationData.UserId,
-				UpdatedAt: ctx.Now().Unix(),
-			},
-			&zb_data.PlayerTimestamp{
-				Id:        matchedPlayerProfile.RegistrationData.UserId,
-				UpdatedAt: ctx.Now().Unix(),
-			},
-		},
-		PlayerDebugCheats: []*zb_data.DebugCheatsConfiguration{
-			&playerProfile.RegistrationData.DebugCheats,
-			&matchedPlayerProfile.RegistrationData.DebugCheats,
-		},
-	}
-
-	if playerProfile.RegistrationData.DebugCheats.Enabled && playerProfile.RegistrationData.DebugCheats.UseCustomRandomSeed {
-		match.RandomSeed = playerProfile.RegistrationData.DebugCheats.CustomRandomSeed
-	} else {
-		match.RandomSeed = ctx.Now().Unix()
-	}
-
-	match.CustomGameAddr = playerProfile.RegistrationData.CustomGame // TODO: make sure both players request same custom game?
-
-	if err := createMatch(ctx, match, playerProfile.RegistrationData.UseBackendGameLogic); err != nil {
-		return nil, err
-	}
-
-	// save user match
-	if err := saveUserCurrentMatch(ctx, playerProfile.RegistrationData.UserId, match); err != nil {
-		return nil, err
-	}
-	if err := saveUserCurrentMatch(ctx, matchedPlayerProfile.RegistrationData.UserId, match); err != nil {
-		return nil, err
-	}
-	// save match
-	// if err := saveMatch(ctx, match); err != nil {
-	// 	return nil, err
-	// }
-
-	emitMsg := zb_data.PlayerActionEvent{
-		Match: match,
-	}
-	data, err := proto.Marshal(&emitMsg)
-	if err != nil {
-		return nil, err
-	}
-	topics := append(match.Topics, TopicFindMatchEvent)
-	ctx.EmitTopics(data, topics...)
-
 	return &zb_calls.FindMatchResponse{
 		Match:      match,
 		MatchFound: true,
@@ -1199,7 +1438,12 @@ func (z *ZombieBattleground) AcceptMatch(ctx contract.Context, req *zb_calls.Acc
 
 // TODO remove this
 func (z *ZombieBattleground) GetPlayerPool(ctx contract.Context, req *zb_calls.PlayerPoolRequest) (*zb_calls.PlayerPoolResponse, error) {
-	pool, err := loadPlayerPool(ctx)
+	queueName := req.Queue
+	if queueName == "" {
+		queueName = MatchMakingQueueCasual
+	}
+
+	pool, err := loadPlayerPool(ctx, queueName)
 	if err != nil {
 		return nil, err
 	}
@@ -1209,18 +1453,80 @@ func (z *ZombieBattleground) GetPlayerPool(ctx contract.Context, req *zb_calls.P
 	}, nil
 }
 
-// TODO remove this
+// GetTaggedPlayerPool returns the players of every tags queue, along with the registrations left in the
+// legacy tagged player pool.
+// Deprecated: use GetPlayerPool with the name of the tags queue.
 func (z *ZombieBattleground) GetTaggedPlayerPool(ctx contract.Context, req *zb_calls.PlayerPoolRequest) (*zb_calls.PlayerPoolResponse, error) {
-	pool, err := loadTaggedPlayerPool(ctx)
+	pool, err := loadPlayerPoolInternal(ctx, taggedPlayerPoolKey)
+	if err != nil {
+		return nil, err
+	}
+
+	activeQueueNames, err := loadActiveMatchMakingQueues(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	for _, queueName := range activeQueueNames {
+		if !isTagsMatchMakingQueueName(queueName) {
+			continue
+		}
+
+		queuePool, err := loadPlayerPool(ctx, queueName)
+		if err != nil {
+			return nil, err
+		}
+		pool.PlayerProfiles = append(pool.PlayerProfiles, queuePool.PlayerProfiles...)
+	}
+
 	return &zb_calls.PlayerPoolResponse{
 		Pool: pool,
 	}, nil
 }
 
+// SweepPlayerPools evicts the stale entries of every matchmaking queue. Meant to be called periodically by the oracle.
+func (z *ZombieBattleground) SweepPlayerPools(ctx contract.Context, req *zb_calls.SweepPlayerPoolsRequest) (*zb_calls.SweepPlayerPoolsResponse, error) {
+	err := z.validateOracle(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	configuration, err := loadContractConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	activeQueueNames, err := loadActiveMatchMakingQueues(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// legacy registrations are only in the casual queue, which is not necessarily in the active list
+	queueNames := []string{MatchMakingQueueCasual, MatchMakingQueueRanked, MatchMakingQueueTournament}
+	queueNames = uniqueStrings(append(queueNames, activeQueueNames...))
+
+	evictedEntryCounts, err := evictTimedOutPlayerPoolEntries(ctx, configuration, queueNames, "")
+	if err != nil {
+		return nil, err
+	}
+
+	response := &zb_calls.SweepPlayerPoolsResponse{}
+	for _, queueName := range queueNames {
+		evictedEntryCount := evictedEntryCounts[queueName]
+		if evictedEntryCount == 0 {
+			continue
+		}
+
+		response.EvictedEntryCount += evictedEntryCount
+		response.QueueEvictionCounts = append(response.QueueEvictionCounts, &zb_calls.SweepPlayerPoolsResponse_MatchMakingQueueEvictionCount{
+			Queue:             queueName,
+			EvictedEntryCount: evictedEntryCount,
+		})
+	}
+
+	return response, nil
+}
+
 func (z *ZombieBattleground) CancelFindMatch(ctx contract.Context, req *zb_calls.CancelFindMatchRequest) (*zb_calls.CancelFindMatchResponse, error) {
 	match, _ := loadUserCurrentMatch(ctx, req.UserId)
 
@@ -1247,24 +1553,8 @@ func (z *ZombieBattleground) CancelFindMatch(ctx contract.Context, req *zb_calls
 		}
 	}
 
-	var loadPlayerPoolFn func(contract.Context) (*zb_data.PlayerPool, error)
-	var savePlayerPoolFn func(contract.Context, *zb_data.PlayerPool) error
-	// if the tags is set, use tagged playerpool
-	if len(req.Tags) > 0 {
-		loadPlayerPoolFn = loadTaggedPlayerPool
-		savePlayerPoolFn = saveTaggedPlayerPool
-	} else {
-		loadPlayerPoolFn = loadPlayerPool
-		savePlayerPoolFn = savePlayerPool
-	}
-
-	// remove player from the player pool
-	pool, err := loadPlayerPoolFn(ctx)
-	if err != nil {
-		return nil, err
-	}
-	pool = removePlayerFromPool(pool, req.UserId)
-	if err := savePlayerPoolFn(ctx, pool); err != nil {
+	// remove player from all the matchmaking queues
+	if err := removePlayerFromMatchMakingQueues(ctx, req.UserId); err != nil {
 		return nil, err
 	}
 
@@ -1327,6 +1617,11 @@ func (z *ZombieBattleground) EndMatch(ctx contract.Context, req *zb_calls.EndMat
 		return nil, err
 	}
 
+	// experience and deck stats must only be counted once per match
+	if match.Status == zb_data.Match_Ended {
+		return nil, fmt.Errorf("match %d already ended", match.Id)
+	}
+
 	match.Status = zb_data.Match_Ended
 	if err := saveMatch(ctx, match); err != nil {
 		return nil, err
@@ -1360,6 +1655,10 @@ func (z *ZombieBattleground) EndMatch(ctx contract.Context, req *zb_calls.EndMat
 		}
 	}
 
+	if err := updateDeckStatsAtMatchEnd(ctx, match, gameState, req.WinnerId); err != nil {
+		return nil, err
+	}
+
 	// delete user match for both users
 	for _, playerState := range match.PlayerStates {
 		ctx.Delete(UserMatchKey(playerState.Id))
@@ -1828,6 +2127,105 @@ func (z *ZombieBattleground) UpdateContractConfiguration(ctx contract.Context, r
 		configuration.CardCollectionSyncDataVersion = req.CardCollectionSyncDataVersion
 	}
 
+	if req.SetMatchMakingQueueConfiguration {
+		changed = true
+		if req.MatchMakingQueueConfiguration == nil {
+			return fmt.Errorf("MatchMakingQueueConfiguration == nil")
+		}
+
+		if _, err := getMatchMakingQueue(nil, req.MatchMakingQueueConfiguration.Name); err != nil {
+			return err
+		}
+
+		found := false
+		for _, existingQueueConfiguration := range configuration.MatchMakingQueueConfiguration {
+			if existingQueueConfiguration.Name == req.MatchMakingQueueConfiguration.Name {
+				existingQueueConfiguration.Reset()
+				proto.Merge(existingQueueConfiguration, req.MatchMakingQueueConfiguration)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			configuration.MatchMakingQueueConfiguration = append(configuration.MatchMakingQueueConfiguration, req.MatchMakingQueueConfiguration)
+		}
+	}
+
+	if req.SetMatchMakingVersionCompatibility {
+		changed = true
+		if req.MatchMakingVersionCompatibility == nil {
+			return fmt.Errorf("MatchMakingVersionCompatibility == nil")
+		}
+
+		if req.MatchMakingVersionCompatibility.MatchVersion == "" {
+			return fmt.Errorf("match version is not set")
+		}
+
+		// an entry without versions removes the compatibility for the match version
+		var versionCompatibility []*zb_data.MatchMakingVersionCompatibility
+		for _, existingVersionCompatibility := range configuration.MatchMakingVersionCompatibility {
+			if existingVersionCompatibility.MatchVersion != req.MatchMakingVersionCompatibility.MatchVersion {
+				versionCompatibility = append(versionCompatibility, existingVersionCompatibility)
+			}
+		}
+
+		if len(req.MatchMakingVersionCompatibility.Versions) > 0 {
+			versionCompatibility = append(versionCompatibility, req.MatchMakingVersionCompatibility)
+		}
+
+		configuration.MatchMakingVersionCompatibility = versionCompatibility
+	}
+
+	if req.SetPlayerPoolEntryTimeToLive {
+		changed = true
+		if req.PlayerPoolEntryTimeToLive < 0 {
+			return fmt.Errorf("player pool entry time to live can't be negative")
+		}
+		configuration.PlayerPoolEntryTimeToLive = req.PlayerPoolEntryTimeToLive
+	}
+
+	if req.SetMintingReceiptTimeToLive {
+		changed = true
+		if req.MintingReceiptTimeToLive < 0 {
+			return fmt.Errorf("minting receipt time to live can't be negative")
+		}
+		configuration.MintingReceiptTimeToLive = req.MintingReceiptTimeToLive
+	}
+
+	if req.SetDeckRules {
+		changed = true
+		if err := validateDeckRulesConfiguration(req.DeckRules); err != nil {
+			return err
+		}
+
+		configuration.DeckRules = setDeckRulesInList(configuration.DeckRules, req.DeckRules)
+	}
+
+	if req.SetFormat {
+		changed = true
+		if err := validateDeckRulesConfiguration(req.Format); err != nil {
+			return err
+		}
+
+		configuration.Formats = setDeckRulesInList(configuration.Formats, req.Format)
+	}
+
+	if req.DeleteFormat {
+		changed = true
+		if getFormat(configuration, req.DeleteFormatName) == nil {
+			return fmt.Errorf("format %s not found", req.DeleteFormatName)
+		}
+
+		var formats []*zb_data.DeckRules
+		for _, format := range configuration.Formats {
+			if format.Name != req.DeleteFormatName {
+				formats = append(formats, format)
+			}
+		}
+		configuration.Formats = formats
+	}
+
 	if !changed {
 		return fmt.Errorf("no configuration changes specified")
 	}
@@ -2086,15 +2484,62 @@ func (z *ZombieBattleground) DeleteGameMode(ctx contract.Context, req *zb_calls.
 }
 
 func (z *ZombieBattleground) ProcessOracleEventBatch(ctx contract.Context, req *orctype.ProcessOracleEventBatchRequest) error {
+	oracleSet, err := loadOracleSet(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !isOracleSetEnabled(oracleSet) {
+		return z.applyOracleEventBatch(ctx, req, false)
+	}
+
+	state, err := loadContractState(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the batch was already applied with the votes of other members
+	if req.LastPlasmachainBlockNumber <= state.LastPlasmachainBlockNumber {
+		return nil
+	}
+
+	approved, err := voteOracleCall(ctx, oracleSet, "ProcessOracleEventBatch", req)
+	if err != nil || !approved {
+		return err
+	}
+
+	// each member submits the batches in order, so an approved batch that leaves a gap means the members disagree
+	if req.FirstPlasmachainBlockNumber != state.LastPlasmachainBlockNumber+1 {
+		return fmt.Errorf(
+			"event batch starts at Plasmachain block %d, last processed block is %d",
+			req.FirstPlasmachainBlockNumber,
+			state.LastPlasmachainBlockNumber,
+		)
+	}
+
+	// the members submit batches without new events too, so they agree on the last processed block
+	return z.applyOracleEventBatch(ctx, req, true)
+}
+
+// applyOracleEventBatch applies the card amount changes of the events from blocks that weren't processed yet
+func (z *ZombieBattleground) applyOracleEventBatch(ctx contract.Context, req *orctype.ProcessOracleEventBatchRequest, allowEmpty bool) error {
 	state, err := loadContractState(ctx)
 	if err != nil {
 		return err
 	}
 
+	window, err := loadProcessedOracleEventWindow(ctx)
+	if err != nil {
+		return err
+	}
+	processedEventIdSet := getProcessedOracleEventIdSet(window)
+
 	eventCount := 0           // number of blocks that were actually processed in this batch
+	duplicateEventCount := 0  // number of events that were already applied by an earlier batch
 	lastEthBlock := uint64(0) // the last block processed in this batch
 
 	addressToCardKeyToAmountChangesMap := addressToCardKeyToAmountChangesMap{}
+	var packBurnEvents []*orctype.PlasmachainEvent
 
 	for _, ev := range req.Events {
 		// Events in the batch are expected to be ordered by block, so a batch should contain
@@ -2112,6 +2557,14 @@ func (z *ZombieBattleground) ProcessOracleEventBatch(ctx contract.Context, req *
 			continue
 		}
 
+		// Events applied by an earlier batch are rejected, even if the last processed block was moved back since
+		if processedEventIdSet[plasmachainEventIdKey(ev.EthBlock, ev.TxIndex, ev.LogIndex)] {
+			ctx.Logger().Warn("Oracle event has already been applied",
+				"block", ev.EthBlock, "txIndex", ev.TxIndex, "logIndex", ev.LogIndex)
+			duplicateEventCount++
+			continue
+		}
+
 		switch payload := ev.Payload.(type) {
 		case *orctype.PlasmachainEvent_Transfer:
 			ctx.Logger().Info("got Transfer event")
@@ -2165,6 +2618,14 @@ func (z *ZombieBattleground) ProcessOracleEventBatch(ctx contract.Context, req *
 					return err
 				}
 			}
+		case *orctype.PlasmachainEvent_PackBurn:
+			ctx.Logger().Info("got PackBurn event")
+			if payload.PackBurn.From == nil || payload.PackBurn.Amount == nil {
+				ctx.Logger().Error("Oracle PackBurn event is missing address or amount")
+				return ErrInvalidEventBatch
+			}
+
+			packBurnEvents = append(packBurnEvents, ev)
 		case nil:
 			ctx.Logger().Error("Oracle missing event payload")
 			continue
@@ -2177,6 +2638,7 @@ func (z *ZombieBattleground) ProcessOracleEventBatch(ctx contract.Context, req *
 			lastEthBlock = ev.EthBlock
 		}
 
+		window.EventIds = append(window.EventIds, newPlasmachainEventId(ev))
 		eventCount++
 	}
 
@@ -2190,8 +2652,8 @@ func (z *ZombieBattleground) ProcessOracleEventBatch(ctx contract.Context, req *
 	}
 
 	// If there are no new events in this batch return an error so that the batch tx isn't
-	// propagated to the other nodes.
-	if eventCount == 0 {
+	// propagated to the other nodes. A batch of already applied events still moves the last block forward.
+	if eventCount == 0 && duplicateEventCount == 0 && !allowEmpty {
 		return fmt.Errorf("no new events found in the batch")
 	}
 
@@ -2200,6 +2662,19 @@ func (z *ZombieBattleground) ProcessOracleEventBatch(ctx contract.Context, req *
 		return errors.Wrap(err, "failed to apply address to card amounts change map")
 	}
 
+	for _, packBurnEvent := range packBurnEvents {
+		err = addPackBurn(ctx, loom.UnmarshalAddressPB(packBurnEvent.GetPackBurn().From), packBurnEvent)
+		if err != nil {
+			return errors.Wrap(err, "failed to add burned packs")
+		}
+	}
+
+	pruneProcessedOracleEventWindow(window, req.LastPlasmachainBlockNumber)
+	err = saveProcessedOracleEventWindow(ctx, window)
+	if err != nil {
+		return err
+	}
+
 	ctx.Logger().Debug("setting last Plasmachain block", "LastPlasmachainBlockNumber", req.LastPlasmachainBlockNumber)
 
 	state.LastPlasmachainBlockNumber = req.LastPlasmachainBlockNumber
@@ -2207,7 +2682,7 @@ func (z *ZombieBattleground) ProcessOracleEventBatch(ctx contract.Context, req *
 }
 
 func (z *ZombieBattleground) SetLastPlasmaBlockNumber(ctx contract.Context, req *zb_calls.SetLastPlasmaBlockNumberRequest) error {
-	err := z.validateOracle(ctx)
+	err := z.validateOracleWithoutOracleSet(ctx)
 	if err != nil {
 		return err
 	}
@@ -2222,6 +2697,232 @@ func (z *ZombieBattleground) SetLastPlasmaBlockNumber(ctx contract.Context, req
 	return saveContractState(ctx, state)
 }
 
+// RollbackOracleEventBatch undoes the card amount changes and pack burns of events from Plasmachain blocks invalidated by a reorg,
+// and moves the last processed Plasmachain block back so the blocks are processed again.
+func (z *ZombieBattleground) RollbackOracleEventBatch(ctx contract.Context, req *orctype.RollbackOracleEventBatchRequest) (*orctype.RollbackOracleEventBatchResponse, error) {
+	state, err := loadContractState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.LastPlasmachainBlockNumber >= state.LastPlasmachainBlockNumber {
+		return nil, fmt.Errorf(
+			"can't roll back to Plasmachain block %d, last processed block is %d",
+			req.LastPlasmachainBlockNumber,
+			state.LastPlasmachainBlockNumber,
+		)
+	}
+
+	approved, err := z.approveOracleCall(ctx, "RollbackOracleEventBatch", req)
+	if err != nil {
+		return nil, err
+	}
+	if !approved {
+		return &orctype.RollbackOracleEventBatchResponse{}, nil
+	}
+
+	addressToCardKeyToAmountChangesMap := addressToCardKeyToAmountChangesMap{}
+	for _, cardAmountChange := range req.CardAmountChanges {
+		if cardAmountChange.Address == nil || cardAmountChange.CardTokenId == nil {
+			return nil, fmt.Errorf("card amount change is missing address or card token id")
+		}
+
+		addressHex := hex.EncodeToString(cardAmountChange.Address.Local)
+		cardKeyToAmountChanges, exists := addressToCardKeyToAmountChangesMap[addressHex]
+		if !exists {
+			cardKeyToAmountChanges = cardKeyToAmountChangeMap{}
+			addressToCardKeyToAmountChangesMap[addressHex] = cardKeyToAmountChanges
+		}
+
+		cardKey := cardKeyFromCardTokenId(cardAmountChange.CardTokenId.Value.Int64())
+		cardKeyToAmountChanges[cardKey] += cardAmountChange.AmountChange
+	}
+
+	err = z.applyAddressToCardAmountsChangeMapDelta(ctx, addressToCardKeyToAmountChangesMap)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to apply address to card amounts change map")
+	}
+
+	for _, packAmountChange := range req.PackAmountChanges {
+		if packAmountChange.Address == nil {
+			return nil, fmt.Errorf("pack amount change is missing address")
+		}
+
+		if packAmountChange.AmountChange > 0 {
+			return nil, fmt.Errorf("pack amount change %d can't add packs", packAmountChange.AmountChange)
+		}
+
+		err = removeBurnedPacks(ctx, loom.UnmarshalAddressPB(packAmountChange.Address), packAmountChange.PackType, uint64(-packAmountChange.AmountChange))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to undo burned packs")
+		}
+	}
+
+	// the events of the invalidated blocks were undone, so they have to be accepted again
+	window, err := loadProcessedOracleEventWindow(ctx)
+	if err != nil {
+		return nil, err
+	}
+	removeProcessedOracleEventsAfter(window, req.LastPlasmachainBlockNumber)
+	err = saveProcessedOracleEventWindow(ctx, window)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Logger().Info(
+		"rolled back Plasmachain blocks",
+		"LastPlasmachainBlockNumber", req.LastPlasmachainBlockNumber,
+		"previousLastPlasmachainBlockNumber", state.LastPlasmachainBlockNumber,
+	)
+
+	state.LastPlasmachainBlockNumber = req.LastPlasmachainBlockNumber
+	err = saveContractState(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &orctype.RollbackOracleEventBatchResponse{
+		Applied: true,
+	}, nil
+}
+
+// QuarantineOracleEvents records Plasmachain events that keep failing in ProcessOracleEventBatch without applying them,
+// and skips their blocks so the oracle can process the next ones.
+func (z *ZombieBattleground) QuarantineOracleEvents(ctx contract.Context, req *orctype.QuarantineOracleEventsRequest) error {
+	state, err := loadContractState(ctx)
+	if err != nil {
+		return err
+	}
+
+	if req.LastPlasmachainBlockNumber <= state.LastPlasmachainBlockNumber {
+		return fmt.Errorf(
+			"Plasmachain block %d has already been processed, last processed block is %d",
+			req.LastPlasmachainBlockNumber,
+			state.LastPlasmachainBlockNumber,
+		)
+	}
+
+	for _, event := range req.Events {
+		if event.EthBlock <= state.LastPlasmachainBlockNumber || event.EthBlock > req.LastPlasmachainBlockNumber {
+			return fmt.Errorf("event from Plasmachain block %d is outside of the quarantined blocks", event.EthBlock)
+		}
+	}
+
+	approved, err := z.approveOracleCall(ctx, "QuarantineOracleEvents", req)
+	if err != nil || !approved {
+		return err
+	}
+
+	batchList, err := loadQuarantinedOracleEvents(ctx)
+	if err != nil {
+		return err
+	}
+
+	batchList.Batches = append(batchList.Batches, &orctype.QuarantinedOracleEventBatch{
+		Events:                     req.Events,
+		LastPlasmachainBlockNumber: req.LastPlasmachainBlockNumber,
+		Error:                      req.Error,
+		QuarantinedAt:              ctx.Now().Unix(),
+	})
+	err = saveQuarantinedOracleEvents(ctx, batchList)
+	if err != nil {
+		return err
+	}
+
+	// the events aren't applied, so the collections they change are synced from Plasmachain instead
+	syncedAddresses := make(map[string]bool)
+	for _, event := range req.Events {
+		for _, addressPB := range getOracleEventAddresses(event) {
+			if addressPB == nil || isZeroLocalAddress(addressPB.Local) {
+				continue
+			}
+			if req.ZbgCardContractAddress != nil && addressPB.Local.Compare(req.ZbgCardContractAddress.Local) == 0 {
+				continue
+			}
+
+			address := loom.UnmarshalAddressPB(addressPB)
+			if syncedAddresses[address.String()] {
+				continue
+			}
+			syncedAddresses[address.String()] = true
+
+			err = z.addGetUserFullCardCollectionOracleCommand(ctx, address)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	ctx.Logger().Error(
+		"quarantined Plasmachain events",
+		"eventCount", len(req.Events),
+		"LastPlasmachainBlockNumber", req.LastPlasmachainBlockNumber,
+		"error", req.Error,
+		"syncedAddressCount", len(syncedAddresses),
+	)
+
+	state.LastPlasmachainBlockNumber = req.LastPlasmachainBlockNumber
+	return saveContractState(ctx, state)
+}
+
+// getOracleEventAddresses returns the addresses whose card collection is changed by the event
+func getOracleEventAddresses(event *orctype.PlasmachainEvent) []*types.Address {
+	switch payload := event.Payload.(type) {
+	case *orctype.PlasmachainEvent_Transfer:
+		return []*types.Address{payload.Transfer.From, payload.Transfer.To}
+	case *orctype.PlasmachainEvent_TransferWithQuantity:
+		return []*types.Address{payload.TransferWithQuantity.From, payload.TransferWithQuantity.To}
+	case *orctype.PlasmachainEvent_BatchTransfer:
+		return []*types.Address{payload.BatchTransfer.From, payload.BatchTransfer.To}
+	default:
+		return nil
+	}
+}
+
+// isZeroLocalAddress is true for the address cards are minted from and burned to
+func isZeroLocalAddress(local loom.LocalAddress) bool {
+	for _, b := range local {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (z *ZombieBattleground) GetQuarantinedOracleEvents(ctx contract.StaticContext, req *orctype.GetQuarantinedOracleEventsRequest) (*orctype.GetQuarantinedOracleEventsResponse, error) {
+	batchList, err := loadQuarantinedOracleEvents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &orctype.GetQuarantinedOracleEventsResponse{
+		Batches: batchList.Batches,
+	}, nil
+}
+
+// RequestFullCardCollectionSyncBatch queues a full card collection sync for each of the addresses,
+// the oracle backfill uses it to resync the collections affected by a range of Plasmachain blocks.
+func (z *ZombieBattleground) RequestFullCardCollectionSyncBatch(ctx contract.Context, req *orctype.RequestFullCardCollectionSyncBatchRequest) error {
+	err := z.validateOracle(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, address := range req.Addresses {
+		if address == nil {
+			return errors.New("address is missing")
+		}
+
+		err = z.addGetUserFullCardCollectionOracleCommand(ctx, loom.UnmarshalAddressPB(address))
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx.Logger().Info("requested full card collection syncs", "addressCount", len(req.Addresses))
+	return nil
+}
+
 func (z *ZombieBattleground) GetContractBuildMetadata(ctx contract.StaticContext, req *zb_calls.GetContractBuildMetadataRequest) (*zb_calls.GetContractBuildMetadataResponse, error) {
 	return &zb_calls.GetContractBuildMetadataResponse{
 		Date:   BuildDate,
@@ -2250,29 +2951,16 @@ func (z *ZombieBattleground) ConfirmPendingMintingTransactionReceipt(ctx contrac
 		return ErrUserNotVerified
 	}
 
-	receiptCollection, err := loadPendingMintingTransactionReceipts(ctx, req.UserId)
+	// expired receipts are only found in the history
+	_, found, err := resolveMintingReceipt(ctx, req.UserId, req.TxId.Value.Int, zb_enums.MintingTransactionReceiptStatus_Redeemed)
 	if err != nil {
 		return err
 	}
 
-	found := false
-	for i, receipt := range receiptCollection.Receipts {
-		if receipt.TxId.Value.Int.Cmp(req.TxId.Value.Int) == 0 {
-			receiptCollection.Receipts = append(receiptCollection.Receipts[:i], receiptCollection.Receipts[i+1:]...)
-			found = true
-			break
-		}
-	}
-
 	if !found {
 		return fmt.Errorf("receipt for txId %s not found", req.TxId.Value.String())
 	}
 
-	err = savePendingMintingTransactionReceipts(ctx, req.UserId, receiptCollection)
-	if err != nil {
-		return err
-	}
-
 	return nil
 }
 
@@ -2340,12 +3028,12 @@ func (z *ZombieBattleground) GetOracleCommandRequestList(ctx contract.StaticCont
 	}
 
 	return &orctype.GetOracleCommandRequestListResponse{
-		CommandRequests: list.Commands,
+		CommandRequests: filterExpiredOracleCommands(ctx, list.Commands),
 	}, nil
 }
 
 func (z *ZombieBattleground) ProcessOracleCommandResponseBatch(ctx contract.Context, req *orctype.ProcessOracleCommandResponseBatchRequest) (*zb_calls.EmptyResponse, error) {
-	err := z.validateOracle(ctx)
+	err := z.validateOracleWithoutOracleSet(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -2377,6 +3065,59 @@ func (z *ZombieBattleground) RequestUserFullCardCollectionSync(ctx contract.Cont
 	return &zb_calls.EmptyResponse{}, nil
 }
 
+func (z *ZombieBattleground) RequestPackBalancesSync(ctx contract.Context, req *zb_calls.RequestPackBalancesSyncRequest) (*zb_calls.EmptyResponse, error) {
+	err := z.isOwnerOrOracle(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	persistentData, err := loadUserPersistentData(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = z.addGetPackBalancesOracleCommand(ctx, loom.UnmarshalAddressPB(persistentData.Address))
+	if err != nil {
+		return nil, err
+	}
+
+	return &zb_calls.EmptyResponse{}, nil
+}
+
+// RequestPendingMintingTransactionReceiptsVerification asks the oracle to check which pending receipts of the user
+// were already used on Plasmachain, those are removed from the pending receipts.
+func (z *ZombieBattleground) RequestPendingMintingTransactionReceiptsVerification(ctx contract.Context, req *zb_calls.RequestPendingMintingTransactionReceiptsVerificationRequest) (*zb_calls.EmptyResponse, error) {
+	err := z.isOwnerOrOracle(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	receiptCollection, err := loadPendingMintingTransactionReceipts(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, receipt := range receiptCollection.Receipts {
+		err = z.addVerifyMintingReceiptConsumedOracleCommand(ctx, req.UserId, receipt.TxId.Value.Int)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &zb_calls.EmptyResponse{}, nil
+}
+
+func (z *ZombieBattleground) GetPackBalances(ctx contract.StaticContext, req *zb_calls.GetPackBalancesRequest) (*zb_calls.GetPackBalancesResponse, error) {
+	packBalanceList, err := loadPackBalances(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zb_calls.GetPackBalancesResponse{
+		PackBalanceList: packBalanceList,
+	}, nil
+}
+
 func (z *ZombieBattleground) DebugGetPendingCardAmountChangeItems(ctx contract.StaticContext, req *zb_calls.DebugGetPendingCardAmountChangeItemsRequest) (*zb_calls.DebugGetPendingCardAmountChangeItemsResponse, error) {
 	container, err := loadPendingCardAmountChangesContainerByAddress(ctx, loom.UnmarshalAddressPB(req.Address))
 	if err != nil {
@@ -2501,10 +3242,15 @@ loop:
 		wipeExecuted = true
 
 		if matchingDataWipeConfiguration.WipeDecks {
+			// revisions of the old decks must be gone before the default decks are saved
+			ctx.Delete(DeckRevisionsKey(userId))
+
 			_, err = z.initializeUserDefaultDecks(ctx, version, userId)
 			if err != nil {
 				return false, errors.Wrap(err, "error wiping user decks")
 			}
+
+			ctx.Delete(DeckStatsKey(userId))
 		}
 
 		if matchingDataWipeConfiguration.WipeOverlordUserInstances {
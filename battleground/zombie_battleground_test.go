@@ -17,6 +17,7 @@ import (
 	"github.com/loomnetwork/go-loom"
 	"github.com/loomnetwork/go-loom/plugin"
 	contract "github.com/loomnetwork/go-loom/plugin/contractpb"
+	"github.com/loomnetwork/go-loom/types"
 	assert "github.com/stretchr/testify/require"
 )
 
@@ -45,6 +46,8 @@ func setup(c *ZombieBattleground, pubKeyHex string, addr *loom.Address, ctx *con
 		Version:           updateInitRequest.InitData.Version,
 		Oracle:            updateInitRequest.InitData.Oracle,
 		OverlordLeveling:  updateInitRequest.InitData.OverlordLeveling,
+		Crafting:          updateInitRequest.InitData.Crafting,
+		Packs:             updateInitRequest.InitData.Packs,
 	}
 
 	c = &ZombieBattleground{}
@@ -217,7 +220,7 @@ func TestUserDataWipe(t *testing.T) {
 
 		deckResponse.Decks[0].Name = "RenamedDefaultDeck"
 
-		err = c.EditDeck(ctx, &zb_calls.EditDeckRequest{
+		_, err = c.EditDeck(ctx, &zb_calls.EditDeckRequest{
 			UserId:  "DeckUser",
 			Deck:    deckResponse.Decks[0],
 			Version: "v1",
@@ -426,7 +429,7 @@ func TestDeckOperations(t *testing.T) {
 	})
 
 	t.Run("EditDeck", func(t *testing.T) {
-		err := c.EditDeck(ctx, &zb_calls.EditDeckRequest{
+		_, err := c.EditDeck(ctx, &zb_calls.EditDeckRequest{
 			UserId: "DeckUser",
 			Deck: &zb_data.Deck{
 				Id:         2,
@@ -459,7 +462,7 @@ func TestDeckOperations(t *testing.T) {
 
 	t.Run("EditDeck (attempt to set more number of cards)", func(t *testing.T) {
 		t.Skip("Edit deck skips checking the number of cards")
-		err := c.EditDeck(ctx, &zb_calls.EditDeckRequest{
+		_, err := c.EditDeck(ctx, &zb_calls.EditDeckRequest{
 			UserId: "DeckUser",
 			Deck: &zb_data.Deck{
 				Id:         2,
@@ -482,7 +485,7 @@ func TestDeckOperations(t *testing.T) {
 	})
 
 	t.Run("EditDeck (same name while editing is allowed)", func(t *testing.T) {
-		err := c.EditDeck(ctx, &zb_calls.EditDeckRequest{
+		_, err := c.EditDeck(ctx, &zb_calls.EditDeckRequest{
 			UserId: "DeckUser",
 			Deck: &zb_data.Deck{
 				Id:         2,
@@ -506,7 +509,7 @@ func TestDeckOperations(t *testing.T) {
 	})
 
 	t.Run("EditDeck (attempt to set duplicate name with different case)", func(t *testing.T) {
-		err := c.EditDeck(ctx, &zb_calls.EditDeckRequest{
+		_, err := c.EditDeck(ctx, &zb_calls.EditDeckRequest{
 			UserId: "DeckUser",
 			Deck: &zb_data.Deck{
 				Id:         2,
@@ -551,6 +554,136 @@ func TestDeckOperations(t *testing.T) {
 	})
 }
 
+func TestDeckFormatOperations(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "7796b813617b283f81ea1747fbddbe73fe4b5fce0eac0728e47de51d8e506701"
+	var addr loom.Address
+	var ctx contract.Context
+
+	setup(c, pubKeyHexString, &addr, &ctx, t)
+	setupAccount(c, ctx, &zb_calls.UpsertAccountRequest{
+		UserId:  "DeckUser",
+		Version: "v1",
+	}, t)
+
+	t.Run("SetFormat without name should fail", func(t *testing.T) {
+		err := c.UpdateContractConfiguration(ctx, &zb_calls.UpdateContractConfigurationRequest{
+			SetFormat: true,
+			Format:    &zb_data.DeckRules{},
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("SetFormat", func(t *testing.T) {
+		for _, format := range []*zb_data.DeckRules{
+			{Name: "wild"},
+			{Name: "season2", AllowedSets: []zb_enums.CardSet_Enum{zb_enums.CardSet_Season2}},
+			{Name: "restricted", RestrictedCards: []battleground_proto.CardKey{{MouldId: 4}}},
+		} {
+			err := c.UpdateContractConfiguration(ctx, &zb_calls.UpdateContractConfigurationRequest{
+				SetFormat: true,
+				Format:    format,
+			})
+			assert.Nil(t, err)
+		}
+	})
+
+	t.Run("ListDecks should report the legal formats", func(t *testing.T) {
+		// default deck has 4 copies of card 4
+		deckResponse, err := c.ListDecks(ctx, &zb_calls.ListDecksRequest{
+			UserId:  "DeckUser",
+			Version: "v1",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"wild"}, deckResponse.Decks[0].LegalFormats)
+	})
+
+	t.Run("CreateDeck and EditDeck should report the legal formats", func(t *testing.T) {
+		deck := &zb_data.Deck{
+			Name:       "NewDeck",
+			OverlordId: 1,
+			Cards: []*zb_data.DeckCard{
+				{Amount: 1, CardKey: battleground_proto.CardKey{MouldId: 4}},
+				{Amount: 1, CardKey: battleground_proto.CardKey{MouldId: 43}},
+			},
+		}
+		createDeckResponse, err := c.CreateDeck(ctx, &zb_calls.CreateDeckRequest{
+			UserId:  "DeckUser",
+			Deck:    deck,
+			Version: "v1",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"wild", "restricted"}, createDeckResponse.LegalFormats)
+
+		deck.Cards[0].Amount = 2
+		editDeckResponse, err := c.EditDeck(ctx, &zb_calls.EditDeckRequest{
+			UserId:  "DeckUser",
+			Deck:    deck,
+			Version: "v1",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"wild"}, editDeckResponse.LegalFormats)
+	})
+
+	t.Run("ValidateDeck should check formats", func(t *testing.T) {
+		response, err := c.ValidateDeck(ctx, &zb_calls.ValidateDeckRequest{
+			UserId:        "DeckUser",
+			Deck:          &zb_data.Deck{Cards: []*zb_data.DeckCard{{Amount: 1, CardKey: battleground_proto.CardKey{MouldId: 4}}}},
+			Version:       "v1",
+			DeckRulesName: "season2",
+		})
+		assert.Nil(t, err)
+		assert.False(t, response.Legal)
+		assert.Equal(t, zb_data.DeckLegalityIssue_CardSetNotAllowed, response.Issues[0].Reason)
+	})
+
+	t.Run("RegisterPlayerPool should require the queue format", func(t *testing.T) {
+		err := c.UpdateContractConfiguration(ctx, &zb_calls.UpdateContractConfigurationRequest{
+			SetMatchMakingQueueConfiguration: true,
+			MatchMakingQueueConfiguration: &zb_data.MatchMakingQueueConfiguration{
+				Name:           MatchMakingQueueRanked,
+				RequiredFormat: "season2",
+			},
+		})
+		assert.Nil(t, err)
+
+		registrationData := &zb_data.PlayerProfileRegistrationData{
+			DeckId:  1,
+			UserId:  "DeckUser",
+			Version: "v1",
+			Queues:  []string{MatchMakingQueueRanked},
+		}
+		_, err = c.RegisterPlayerPool(ctx, &zb_calls.RegisterPlayerPoolRequest{RegistrationData: registrationData})
+		assert.NotNil(t, err)
+
+		err = c.UpdateContractConfiguration(ctx, &zb_calls.UpdateContractConfigurationRequest{
+			SetMatchMakingQueueConfiguration: true,
+			MatchMakingQueueConfiguration: &zb_data.MatchMakingQueueConfiguration{
+				Name:           MatchMakingQueueRanked,
+				RequiredFormat: "wild",
+			},
+		})
+		assert.Nil(t, err)
+
+		_, err = c.RegisterPlayerPool(ctx, &zb_calls.RegisterPlayerPoolRequest{RegistrationData: registrationData})
+		assert.Nil(t, err)
+	})
+
+	t.Run("DeleteFormat", func(t *testing.T) {
+		err := c.UpdateContractConfiguration(ctx, &zb_calls.UpdateContractConfigurationRequest{
+			DeleteFormat:     true,
+			DeleteFormatName: "restricted",
+		})
+		assert.Nil(t, err)
+
+		err = c.UpdateContractConfiguration(ctx, &zb_calls.UpdateContractConfigurationRequest{
+			DeleteFormat:     true,
+			DeleteFormatName: "restricted",
+		})
+		assert.NotNil(t, err)
+	})
+}
+
 func TestCardOperations(t *testing.T) {
 	c := &ZombieBattleground{}
 	var pubKeyHexString = "3866f776276246e4f9998aa90632931d89b0d3a5930e804e02299533f55b39e1"
@@ -824,6 +957,16 @@ func TestFindMatchOperations(t *testing.T) {
 		assert.Nil(t, err)
 	})
 
+	t.Run("EndMatch of ended match should fail", func(t *testing.T) {
+		_, err := c.EndMatch(ctx, &zb_calls.EndMatchRequest{
+			MatchId:          matchID,
+			UserId:           "player-1",
+			WinnerId:         "player-2",
+			MatchExperiences: []int64{123, 350},
+		})
+		assert.NotNil(t, err)
+	})
+
 	t.Run("Check level and experience after match", func(t *testing.T) {
 		getOverlordResponse1, err := c.GetOverlordUserInstance(ctx, &zb_calls.GetOverlordUserInstanceRequest{
 			UserId:     "player-1",
@@ -1454,6 +1597,22 @@ func TestFindMatchWithTagGroupOperations(t *testing.T) {
 		assert.Equal(t, false, response.MatchFound)
 	})
 
+	t.Run("GetTaggedPlayerPool should list the players of every tags queue", func(t *testing.T) {
+		err := ctx.Set(taggedPlayerPoolKey, &zb_data.PlayerPool{
+			PlayerProfiles: []*zb_data.PlayerProfile{
+				{RegistrationData: &zb_data.PlayerProfileRegistrationData{UserId: "legacy-player"}},
+			},
+		})
+		assert.Nil(t, err)
+
+		response, err := c.GetTaggedPlayerPool(ctx, &zb_calls.PlayerPoolRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, 4, len(response.Pool.PlayerProfiles))
+		assert.Equal(t, "legacy-player", response.Pool.PlayerProfiles[0].RegistrationData.UserId)
+
+		ctx.Delete(taggedPlayerPoolKey)
+	})
+
 	t.Run("RegisterPlayerPool", func(t *testing.T) {
 		_, err := c.RegisterPlayerPool(ctx, &zb_calls.RegisterPlayerPoolRequest{
 			RegistrationData: &zb_data.PlayerProfileRegistrationData{
@@ -1530,6 +1689,365 @@ func TestFindMatchWithTagGroupOperations(t *testing.T) {
 	})
 }
 
+func TestFindMatchWithQueuesOperations(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "3866f776276246e4f9998aa90632931d89b0d3a5930e804e02299533f55b39e1"
+	var addr loom.Address
+	var ctx contract.Context
+
+	setup(c, pubKeyHexString, &addr, &ctx, t)
+	setupAccount(c, ctx, &zb_calls.UpsertAccountRequest{
+		UserId:  "player-1",
+		Version: "v1",
+	}, t)
+	setupAccount(c, ctx, &zb_calls.UpsertAccountRequest{
+		UserId:  "player-2",
+		Version: "v1",
+	}, t)
+
+	t.Run("RegisterPlayerPool with unknown queue should fail", func(t *testing.T) {
+		_, err := c.RegisterPlayerPool(ctx, &zb_calls.RegisterPlayerPoolRequest{
+			RegistrationData: &zb_data.PlayerProfileRegistrationData{
+				DeckId:  1,
+				UserId:  "player-1",
+				Version: "v1",
+				Queues:  []string{"unknown"},
+			},
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("RegisterPlayerPool with debug cheats in ranked queue should fail", func(t *testing.T) {
+		_, err := c.RegisterPlayerPool(ctx, &zb_calls.RegisterPlayerPoolRequest{
+			RegistrationData: &zb_data.PlayerProfileRegistrationData{
+				DeckId:  1,
+				UserId:  "player-1",
+				Version: "v1",
+				Queues:  []string{MatchMakingQueueRanked},
+				DebugCheats: zb_data.DebugCheatsConfiguration{
+					Enabled: true,
+				},
+			},
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("RegisterPlayerPool with not allowed version should fail", func(t *testing.T) {
+		err := c.UpdateContractConfiguration(ctx, &zb_calls.UpdateContractConfigurationRequest{
+			SetMatchMakingQueueConfiguration: true,
+			MatchMakingQueueConfiguration: &zb_data.MatchMakingQueueConfiguration{
+				Name:            MatchMakingQueueTournament,
+				AllowedVersions: []string{"v2"},
+			},
+		})
+		assert.Nil(t, err)
+
+		_, err = c.RegisterPlayerPool(ctx, &zb_calls.RegisterPlayerPoolRequest{
+			RegistrationData: &zb_data.PlayerProfileRegistrationData{
+				DeckId:              1,
+				UserId:              "player-1",
+				Version:             "v1",
+				Queues:              []string{MatchMakingQueueTournament},
+				UseBackendGameLogic: true,
+			},
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Configured queue should keep the built-in rules", func(t *testing.T) {
+		configuration, err := loadContractConfiguration(ctx)
+		assert.Nil(t, err)
+
+		queue, err := getMatchMakingQueue(configuration, MatchMakingQueueTournament)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"v2"}, queue.Rules.AllowedVersions)
+		assert.True(t, queue.Rules.RequireBackendGameLogic)
+		assert.True(t, queue.Rules.ForbidDebugCheats)
+	})
+
+	t.Run("RegisterPlayerPool in multiple queues", func(t *testing.T) {
+		_, err := c.RegisterPlayerPool(ctx, &zb_calls.RegisterPlayerPoolRequest{
+			RegistrationData: &zb_data.PlayerProfileRegistrationData{
+				DeckId:  1,
+				UserId:  "player-1",
+				Version: "v1",
+				Queues:  []string{MatchMakingQueueCasual, MatchMakingQueueRanked},
+			},
+		})
+		assert.Nil(t, err)
+
+		_, err = c.RegisterPlayerPool(ctx, &zb_calls.RegisterPlayerPoolRequest{
+			RegistrationData: &zb_data.PlayerProfileRegistrationData{
+				DeckId:  1,
+				UserId:  "player-2",
+				Version: "v1",
+				Queues:  []string{MatchMakingQueueRanked},
+			},
+		})
+		assert.Nil(t, err)
+
+		response, err := c.GetPlayerPool(ctx, &zb_calls.PlayerPoolRequest{Queue: MatchMakingQueueCasual})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(response.Pool.PlayerProfiles))
+
+		response, err = c.GetPlayerPool(ctx, &zb_calls.PlayerPoolRequest{Queue: MatchMakingQueueRanked})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(response.Pool.PlayerProfiles))
+	})
+
+	t.Run("Findmatch should search all registered queues", func(t *testing.T) {
+		response, err := c.FindMatch(ctx, &zb_calls.FindMatchRequest{
+			UserId: "player-1",
+		})
+		assert.Nil(t, err)
+		assert.True(t, response.MatchFound)
+		assert.Equal(t, "player-1", response.Match.PlayerStates[0].Id)
+		assert.Equal(t, "player-2", response.Match.PlayerStates[1].Id)
+	})
+
+	t.Run("Matched players should be removed from all queues", func(t *testing.T) {
+		response, err := c.GetPlayerPool(ctx, &zb_calls.PlayerPoolRequest{Queue: MatchMakingQueueCasual})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(response.Pool.PlayerProfiles))
+
+		response, err = c.GetPlayerPool(ctx, &zb_calls.PlayerPoolRequest{Queue: MatchMakingQueueRanked})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(response.Pool.PlayerProfiles))
+	})
+
+	t.Run("Findmatch should search the casual queue for registrations without queues", func(t *testing.T) {
+		setupAccount(c, ctx, &zb_calls.UpsertAccountRequest{
+			UserId:  "player-3",
+			Version: "v1",
+		}, t)
+		_, err := c.RegisterPlayerPool(ctx, &zb_calls.RegisterPlayerPoolRequest{
+			RegistrationData: &zb_data.PlayerProfileRegistrationData{
+				DeckId:  1,
+				UserId:  "player-3",
+				Version: "v1",
+			},
+		})
+		assert.Nil(t, err)
+
+		// registrations made before the queues were introduced have no queue list
+		err = saveUserMatchMakingQueues(ctx, "player-3", nil)
+		assert.Nil(t, err)
+
+		response, err := c.FindMatch(ctx, &zb_calls.FindMatchRequest{
+			UserId: "player-3",
+		})
+		assert.Nil(t, err)
+		assert.False(t, response.MatchFound)
+	})
+}
+
+func TestFindMatchWithCompatibleVersionsOperations(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "3866f776276246e4f9998aa90632931d89b0d3a5930e804e02299533f55b39e1"
+	var addr loom.Address
+	var ctx contract.Context
+
+	setup(c, pubKeyHexString, &addr, &ctx, t)
+
+	updateInitRequestV2 := proto.Clone(&updateInitRequest).(*zb_calls.UpdateInitRequest)
+	updateInitRequestV2.InitData.Version = "v2"
+	err := c.UpdateInit(ctx, updateInitRequestV2)
+	assert.Nil(t, err)
+
+	setupAccount(c, ctx, &zb_calls.UpsertAccountRequest{
+		UserId:  "player-1",
+		Version: "v1",
+	}, t)
+	setupAccount(c, ctx, &zb_calls.UpsertAccountRequest{
+		UserId:  "player-2",
+		Version: "v2",
+	}, t)
+
+	_, err = c.RegisterPlayerPool(ctx, &zb_calls.RegisterPlayerPoolRequest{
+		RegistrationData: &zb_data.PlayerProfileRegistrationData{
+			DeckId:  1,
+			UserId:  "player-1",
+			Version: "v1",
+		},
+	})
+	assert.Nil(t, err)
+
+	_, err = c.RegisterPlayerPool(ctx, &zb_calls.RegisterPlayerPoolRequest{
+		RegistrationData: &zb_data.PlayerProfileRegistrationData{
+			DeckId:  1,
+			UserId:  "player-2",
+			Version: "v2",
+		},
+	})
+	assert.Nil(t, err)
+
+	t.Run("Findmatch should not match incompatible versions", func(t *testing.T) {
+		response, err := c.FindMatch(ctx, &zb_calls.FindMatchRequest{
+			UserId: "player-1",
+		})
+		assert.Nil(t, err)
+		assert.False(t, response.MatchFound)
+	})
+
+	t.Run("Findmatch should match compatible versions on the match version", func(t *testing.T) {
+		err := c.UpdateContractConfiguration(ctx, &zb_calls.UpdateContractConfigurationRequest{
+			SetMatchMakingVersionCompatibility: true,
+			MatchMakingVersionCompatibility: &zb_data.MatchMakingVersionCompatibility{
+				MatchVersion: "v2",
+				Versions:     []string{"v1", "v2"},
+			},
+		})
+		assert.Nil(t, err)
+
+		response, err := c.FindMatch(ctx, &zb_calls.FindMatchRequest{
+			UserId: "player-1",
+		})
+		assert.Nil(t, err)
+		assert.True(t, response.MatchFound)
+		assert.Equal(t, "v2", response.Match.Version)
+		assert.Equal(t, "player-2", response.Match.PlayerStates[1].Id)
+	})
+}
+
+func TestFindMatchWithCustomGameOperations(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "3866f776276246e4f9998aa90632931d89b0d3a5930e804e02299533f55b39e1"
+	var addr loom.Address
+	var ctx contract.Context
+
+	setup(c, pubKeyHexString, &addr, &ctx, t)
+	for i := 1; i <= 3; i++ {
+		setupAccount(c, ctx, &zb_calls.UpsertAccountRequest{
+			UserId:  fmt.Sprintf("player-%d", i),
+			Version: "v1",
+		}, t)
+	}
+
+	gameMode, err := c.AddGameMode(ctx, &zb_calls.GameModeRequest{
+		Name:        "Test game mode",
+		Description: "Just a test",
+		Version:     "0.1",
+		Address:     "0xf16a25a1b4e6434bacf9d037d69d675dcf852691",
+	})
+	assert.Nil(t, err)
+
+	unknownGameModeAddress, err := loom.LocalAddressFromHexString("0xf16a25a1b4e6434bacf9d037d69d675dcf852692")
+	assert.Nil(t, err)
+
+	t.Run("RegisterPlayerPool with unknown custom game should fail", func(t *testing.T) {
+		_, err := c.RegisterPlayerPool(ctx, &zb_calls.RegisterPlayerPoolRequest{
+			RegistrationData: &zb_data.PlayerProfileRegistrationData{
+				DeckId:     1,
+				UserId:     "player-1",
+				Version:    "v1",
+				CustomGame: &types.Address{ChainId: gameMode.Address.ChainId, Local: unknownGameModeAddress},
+			},
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("RegisterPlayerPool with custom game in another queue should fail", func(t *testing.T) {
+		_, err := c.RegisterPlayerPool(ctx, &zb_calls.RegisterPlayerPoolRequest{
+			RegistrationData: &zb_data.PlayerProfileRegistrationData{
+				DeckId:     1,
+				UserId:     "player-1",
+				Version:    "v1",
+				CustomGame: gameMode.Address,
+				Queues:     []string{MatchMakingQueueCasual},
+			},
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("RegisterPlayerPool with custom game and tags should succeed", func(t *testing.T) {
+		_, err := c.RegisterPlayerPool(ctx, &zb_calls.RegisterPlayerPoolRequest{
+			RegistrationData: &zb_data.PlayerProfileRegistrationData{
+				DeckId:     1,
+				UserId:     "player-1",
+				Version:    "v1",
+				CustomGame: gameMode.Address,
+				Tags:       []string{"custom-game-tag"},
+			},
+		})
+		assert.Nil(t, err)
+
+		response, err := c.GetPlayerPool(ctx, &zb_calls.PlayerPoolRequest{Queue: tagsMatchMakingQueueName([]string{"custom-game-tag"})})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(response.Pool.PlayerProfiles))
+
+		_, err = c.CancelFindMatch(ctx, &zb_calls.CancelFindMatchRequest{UserId: "player-1"})
+		assert.Nil(t, err)
+	})
+
+	t.Run("RegisterPlayerPool with deleted custom game should fail", func(t *testing.T) {
+		deletedGameMode, err := c.AddGameMode(ctx, &zb_calls.GameModeRequest{
+			Name:        "Deleted game mode",
+			Description: "Just a test",
+			Version:     "0.1",
+			Address:     "0xf16a25a1b4e6434bacf9d037d69d675dcf852693",
+		})
+		assert.Nil(t, err)
+
+		err = c.DeleteGameMode(ctx, &zb_calls.DeleteGameModeRequest{ID: deletedGameMode.ID})
+		assert.Nil(t, err)
+
+		_, err = c.RegisterPlayerPool(ctx, &zb_calls.RegisterPlayerPoolRequest{
+			RegistrationData: &zb_data.PlayerProfileRegistrationData{
+				DeckId:     1,
+				UserId:     "player-1",
+				Version:    "v1",
+				CustomGame: deletedGameMode.Address,
+			},
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Findmatch should only match players of the same custom game", func(t *testing.T) {
+		_, err := c.RegisterPlayerPool(ctx, &zb_calls.RegisterPlayerPoolRequest{
+			RegistrationData: &zb_data.PlayerProfileRegistrationData{
+				DeckId:     1,
+				UserId:     "player-1",
+				Version:    "v1",
+				CustomGame: gameMode.Address,
+			},
+		})
+		assert.Nil(t, err)
+
+		_, err = c.RegisterPlayerPool(ctx, &zb_calls.RegisterPlayerPoolRequest{
+			RegistrationData: &zb_data.PlayerProfileRegistrationData{
+				DeckId:  1,
+				UserId:  "player-2",
+				Version: "v1",
+			},
+		})
+		assert.Nil(t, err)
+
+		response, err := c.FindMatch(ctx, &zb_calls.FindMatchRequest{
+			UserId: "player-1",
+		})
+		assert.Nil(t, err)
+		assert.False(t, response.MatchFound)
+
+		_, err = c.RegisterPlayerPool(ctx, &zb_calls.RegisterPlayerPoolRequest{
+			RegistrationData: &zb_data.PlayerProfileRegistrationData{
+				DeckId:     1,
+				UserId:     "player-3",
+				Version:    "v1",
+				CustomGame: gameMode.Address,
+			},
+		})
+		assert.Nil(t, err)
+
+		response, err = c.FindMatch(ctx, &zb_calls.FindMatchRequest{
+			UserId: "player-1",
+		})
+		assert.Nil(t, err)
+		assert.True(t, response.MatchFound)
+		assert.Equal(t, "player-3", response.Match.PlayerStates[1].Id)
+		assert.Equal(t, gameMode.Address.Local, response.Match.CustomGameAddr.Local)
+	})
+}
+
 func TestMatchMakingPlayerPool(t *testing.T) {
 	c := &ZombieBattleground{}
 	var pubKeyHexString = "3866f776276246e4f9998aa90632931d89b0d3a5930e804e02299533f55b39e1"
@@ -1655,6 +2173,93 @@ func TestMatchMakingTimeout(t *testing.T) {
 	})
 }
 
+func TestPlayerPoolEviction(t *testing.T) {
+	c := &ZombieBattleground{}
+	var pubKeyHexString = "3866f776276246e4f9998aa90632931d89b0d3a5930e804e02299533f55b39e1"
+	var addr loom.Address
+	var ctx contract.Context
+
+	// setup ctx
+	fc := setup(c, pubKeyHexString, &addr, &ctx, t)
+	now := time.Now()
+	fc.SetTime(now)
+
+	for i := 1; i <= 4; i++ {
+		setupAccount(c, ctx, &zb_calls.UpsertAccountRequest{
+			UserId:  fmt.Sprintf("player-%d", i),
+			Version: "v1",
+		}, t)
+	}
+
+	err := c.UpdateContractConfiguration(ctx, &zb_calls.UpdateContractConfigurationRequest{
+		SetPlayerPoolEntryTimeToLive: true,
+		PlayerPoolEntryTimeToLive:    60,
+	})
+	assert.Nil(t, err)
+
+	register := func(userID string, queues []string) {
+		_, err := c.RegisterPlayerPool(ctx, &zb_calls.RegisterPlayerPoolRequest{
+			RegistrationData: &zb_data.PlayerProfileRegistrationData{
+				DeckId:  1,
+				UserId:  userID,
+				Version: "v1",
+				Queues:  queues,
+			},
+		})
+		assert.Nil(t, err)
+	}
+
+	getPoolSize := func(queue string) int {
+		response, err := c.GetPlayerPool(ctx, &zb_calls.PlayerPoolRequest{Queue: queue})
+		assert.Nil(t, err)
+		return len(response.Pool.PlayerProfiles)
+	}
+
+	t.Run("RegisterPlayerPool should evict stale entries from all their queues", func(t *testing.T) {
+		register("player-1", []string{MatchMakingQueueCasual, MatchMakingQueueRanked})
+		register("player-2", []string{MatchMakingQueueRanked})
+
+		fc.SetTime(now.Add(2 * time.Minute))
+		register("player-3", nil)
+
+		// player-1 is stale in the casual queue, so it is removed from the ranked queue too.
+		// the ranked queue is not swept when registering in the casual queue, so player-2 is still there
+		assert.Equal(t, 1, getPoolSize(MatchMakingQueueCasual))
+		assert.Equal(t, 1, getPoolSize(MatchMakingQueueRanked))
+
+		response, err := c.GetContractState(ctx, &zb_calls.EmptyRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1), response.State.PlayerPoolEvictedEntryCount)
+	})
+
+	t.Run("FindMatch should not match stale entries", func(t *testing.T) {
+		fc.SetTime(now.Add(4 * time.Minute))
+		register("player-4", nil)
+
+		response, err := c.FindMatch(ctx, &zb_calls.FindMatchRequest{
+			UserId: "player-4",
+		})
+		assert.Nil(t, err)
+		assert.False(t, response.MatchFound)
+		assert.Equal(t, 1, getPoolSize(MatchMakingQueueCasual))
+	})
+
+	t.Run("SweepPlayerPools should evict stale entries of every queue", func(t *testing.T) {
+		register("player-1", []string{MatchMakingQueueRanked})
+		register("player-2", []string{"tags:test"})
+
+		fc.SetTime(now.Add(6 * time.Minute))
+		response, err := c.SweepPlayerPools(ctx, &zb_calls.SweepPlayerPoolsRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), response.EvictedEntryCount)
+		assert.Equal(t, 3, len(response.QueueEvictionCounts))
+
+		assert.Equal(t, 0, getPoolSize(MatchMakingQueueCasual))
+		assert.Equal(t, 0, getPoolSize(MatchMakingQueueRanked))
+		assert.Equal(t, 0, getPoolSize("tags:test"))
+	})
+}
+
 func TestGameStateOperations(t *testing.T) {
 	c := &ZombieBattleground{}
 	var pubKeyHexString = "3866f776276246e4f9998aa90632931d89b0d3a5930e804e02299533f55b39e1"
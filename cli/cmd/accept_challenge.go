@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"strings"
+
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var acceptChallengeCmdArgs struct {
+	userID        string
+	challengeCode string
+	deckID        int64
+	version       string
+}
+
+var acceptChallengeCmd = &cobra.Command{
+	Use:   "accept_challenge",
+	Short: "accepts a challenge and starts the match",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+		req := zb_calls.AcceptChallengeRequest{
+			UserId:        acceptChallengeCmdArgs.userID,
+			ChallengeCode: acceptChallengeCmdArgs.challengeCode,
+			DeckId:        acceptChallengeCmdArgs.deckID,
+			Version:       acceptChallengeCmdArgs.version,
+		}
+		var resp zb_calls.AcceptChallengeResponse
+
+		_, err := commonTxObjs.contract.Call("AcceptChallenge", &req, signer, &resp)
+		if err != nil {
+			return err
+		}
+
+		match := resp.Match
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			err := battleground_utility.PrintProtoMessageAsJsonToStdout(match)
+			if err != nil {
+				return err
+			}
+		default:
+			fmt.Printf("MatchID: %d\n", match.Id)
+			fmt.Printf("Status: %s\n", match.Status)
+			fmt.Printf("Topic: %v\n", match.Topics)
+			fmt.Printf("Players:\n")
+			for _, player := range match.PlayerStates {
+				fmt.Printf("\tPlayerID: %s\n", player.Id)
+			}
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(acceptChallengeCmd)
+
+	acceptChallengeCmd.Flags().StringVarP(&acceptChallengeCmdArgs.userID, "userId", "u", "loom", "UserId of account")
+	acceptChallengeCmd.Flags().StringVarP(&acceptChallengeCmdArgs.challengeCode, "code", "", "", "Challenge code")
+	acceptChallengeCmd.Flags().Int64VarP(&acceptChallengeCmdArgs.deckID, "deckId", "d", 1, "Deck id")
+	acceptChallengeCmd.Flags().StringVarP(&acceptChallengeCmdArgs.version, "version", "v", "", "Version")
+
+	_ = acceptChallengeCmd.MarkFlagRequired("code")
+	_ = acceptChallengeCmd.MarkFlagRequired("version")
+}
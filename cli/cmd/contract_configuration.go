@@ -18,6 +18,8 @@ var configurationCmdArgs struct {
 	initialFiatPurchaseTxId        string
 	useCardLibraryAsUserCollection bool
 	cardCollectionSyncDataVersion  string
+	playerPoolEntryTimeToLive      int64
+	mintingReceiptTimeToLive       int64
 }
 
 var configuration_setDataWipeConfigurationCmdArgs struct {
@@ -26,6 +28,31 @@ var configuration_setDataWipeConfigurationCmdArgs struct {
 	wipeOverlordsUserInstances bool
 }
 
+var configuration_setMatchMakingQueueConfigurationCmdArgs struct {
+	name                    string
+	allowedVersions         []string
+	requireBackendGameLogic bool
+	forbidDebugCheats       bool
+	requiredFormat          string
+}
+
+var configuration_setMatchMakingVersionCompatibilityCmdArgs struct {
+	matchVersion string
+	versions     []string
+}
+
+var configuration_setDeckRulesCmdArgs struct {
+	data string
+}
+
+var configuration_setFormatCmdArgs struct {
+	data string
+}
+
+var configuration_deleteFormatCmdArgs struct {
+	name string
+}
+
 var configuration_get = &cobra.Command{
 	Use:   "get",
 	Short: "get contract configuration",
@@ -133,6 +160,109 @@ var configuration_setDataWipeConfigurationCmd = &cobra.Command{
 	},
 }
 
+var configuration_setMatchMakingQueueConfigurationCmd = &cobra.Command{
+	Use:   "set_matchmaking_queue_configuration",
+	Short: "sets the rules of a matchmaking queue",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := &zb_calls.UpdateContractConfigurationRequest{
+			SetMatchMakingQueueConfiguration: true,
+			MatchMakingQueueConfiguration: &zb_data.MatchMakingQueueConfiguration{
+				Name:                    configuration_setMatchMakingQueueConfigurationCmdArgs.name,
+				AllowedVersions:         configuration_setMatchMakingQueueConfigurationCmdArgs.allowedVersions,
+				RequireBackendGameLogic: configuration_setMatchMakingQueueConfigurationCmdArgs.requireBackendGameLogic,
+				ForbidDebugCheats:       configuration_setMatchMakingQueueConfigurationCmdArgs.forbidDebugCheats,
+				RequiredFormat:          configuration_setMatchMakingQueueConfigurationCmdArgs.requiredFormat,
+			},
+		}
+		return configurationSetMain(request)
+	},
+}
+
+var configuration_setMatchMakingVersionCompatibilityCmd = &cobra.Command{
+	Use:   "set_matchmaking_version_compatibility",
+	Short: "sets which data versions can be matched together and the card library version the match is played on",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := &zb_calls.UpdateContractConfigurationRequest{
+			SetMatchMakingVersionCompatibility: true,
+			MatchMakingVersionCompatibility: &zb_data.MatchMakingVersionCompatibility{
+				MatchVersion: configuration_setMatchMakingVersionCompatibilityCmdArgs.matchVersion,
+				Versions:     configuration_setMatchMakingVersionCompatibilityCmdArgs.versions,
+			},
+		}
+		return configurationSetMain(request)
+	},
+}
+
+var configuration_setPlayerPoolEntryTimeToLiveCmd = &cobra.Command{
+	Use:   "set_player_pool_entry_ttl",
+	Short: "sets after how many seconds a player pool entry is evicted",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := &zb_calls.UpdateContractConfigurationRequest{
+			SetPlayerPoolEntryTimeToLive: true,
+			PlayerPoolEntryTimeToLive:    configurationCmdArgs.playerPoolEntryTimeToLive,
+		}
+		return configurationSetMain(request)
+	},
+}
+
+var configuration_setMintingReceiptTimeToLiveCmd = &cobra.Command{
+	Use:   "set_minting_receipt_ttl",
+	Short: "sets after how many seconds a pending minting receipt expires",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := &zb_calls.UpdateContractConfigurationRequest{
+			SetMintingReceiptTimeToLive: true,
+			MintingReceiptTimeToLive:    configurationCmdArgs.mintingReceiptTimeToLive,
+		}
+		return configurationSetMain(request)
+	},
+}
+
+var configuration_setDeckRulesCmd = &cobra.Command{
+	Use:   "set_deck_rules",
+	Short: "sets deck legality rules, rules with the same name are replaced",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var deckRules zb_data.DeckRules
+		if err := battleground_utility.ReadJsonStringToProtoMessage(configuration_setDeckRulesCmdArgs.data, &deckRules); err != nil {
+			return fmt.Errorf("invalid JSON passed in data field. Error: %s", err.Error())
+		}
+
+		request := &zb_calls.UpdateContractConfigurationRequest{
+			SetDeckRules: true,
+			DeckRules:    &deckRules,
+		}
+		return configurationSetMain(request)
+	},
+}
+
+var configuration_setFormatCmd = &cobra.Command{
+	Use:   "set_format",
+	Short: "sets a deck format, formats with the same name are replaced",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var format zb_data.DeckRules
+		if err := battleground_utility.ReadJsonStringToProtoMessage(configuration_setFormatCmdArgs.data, &format); err != nil {
+			return fmt.Errorf("invalid JSON passed in data field. Error: %s", err.Error())
+		}
+
+		request := &zb_calls.UpdateContractConfigurationRequest{
+			SetFormat: true,
+			Format:    &format,
+		}
+		return configurationSetMain(request)
+	},
+}
+
+var configuration_deleteFormatCmd = &cobra.Command{
+	Use:   "delete_format",
+	Short: "deletes a deck format",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request := &zb_calls.UpdateContractConfigurationRequest{
+			DeleteFormat:     true,
+			DeleteFormatName: configuration_deleteFormatCmdArgs.name,
+		}
+		return configurationSetMain(request)
+	},
+}
+
 func configurationSetMain(configurationRequest *zb_calls.UpdateContractConfigurationRequest) error {
 	signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
 	var result zb_calls.EmptyResponse
@@ -161,15 +291,34 @@ func init() {
 	configuration_setInitialFiatPurchaseTxIdCmd.Flags().StringVarP(&configurationCmdArgs.initialFiatPurchaseTxId, "value", "v", "0", "Starting txId used for transaction receipt created by the contract")
 	configuration_useCardLibraryAsUserCollectionCmd.Flags().BoolVarP(&configurationCmdArgs.useCardLibraryAsUserCollection, "value", "v", false, "If false, user personal collection is used, if true, card library is used to make a full fake collection")
 	configuration_cardCollectionSyncDataVersionCmd.Flags().StringVarP(&configurationCmdArgs.cardCollectionSyncDataVersion, "value", "v", "", "")
+	configuration_setPlayerPoolEntryTimeToLiveCmd.Flags().Int64VarP(&configurationCmdArgs.playerPoolEntryTimeToLive, "value", "v", 0, "Time to live in seconds, default matchmaking timeout is used if 0")
+	configuration_setMintingReceiptTimeToLiveCmd.Flags().Int64VarP(&configurationCmdArgs.mintingReceiptTimeToLive, "value", "v", 0, "Time to live in seconds, 30 days if 0")
 
 	configuration_setDataWipeConfigurationCmd.Flags().StringVarP(&configuration_setDataWipeConfigurationCmdArgs.version, "version", "v", "v1", "Data version to wipe on")
 	configuration_setDataWipeConfigurationCmd.Flags().BoolVarP(&configuration_setDataWipeConfigurationCmdArgs.wipeDecks, "wipeDecks", "d", false, "Whether to wipe user decks")
 	configuration_setDataWipeConfigurationCmd.Flags().BoolVarP(&configuration_setDataWipeConfigurationCmdArgs.wipeOverlordsUserInstances, "wipeOverlords", "o", false, "Whether to wipe user overlords progress")
+	configuration_setMatchMakingQueueConfigurationCmd.Flags().StringVarP(&configuration_setMatchMakingQueueConfigurationCmdArgs.name, "name", "n", "", "Matchmaking queue name")
+	configuration_setMatchMakingQueueConfigurationCmd.Flags().StringArrayVarP(&configuration_setMatchMakingQueueConfigurationCmdArgs.allowedVersions, "allowedVersion", "a", nil, "Data version allowed in the queue, any version is allowed if not set")
+	configuration_setMatchMakingQueueConfigurationCmd.Flags().BoolVarP(&configuration_setMatchMakingQueueConfigurationCmdArgs.requireBackendGameLogic, "requireBackendGameLogic", "b", false, "Whether the queue requires backend game logic")
+	configuration_setMatchMakingQueueConfigurationCmd.Flags().BoolVarP(&configuration_setMatchMakingQueueConfigurationCmdArgs.forbidDebugCheats, "forbidDebugCheats", "d", false, "Whether debug cheats are forbidden in the queue")
+	configuration_setMatchMakingVersionCompatibilityCmd.Flags().StringVarP(&configuration_setMatchMakingVersionCompatibilityCmdArgs.matchVersion, "matchVersion", "m", "", "Card library version the match is played on")
+	configuration_setMatchMakingVersionCompatibilityCmd.Flags().StringArrayVarP(&configuration_setMatchMakingVersionCompatibilityCmdArgs.versions, "version", "v", nil, "Data version that can play on the match version, compatibility is removed if not set")
+	configuration_setMatchMakingQueueConfigurationCmd.Flags().StringVarP(&configuration_setMatchMakingQueueConfigurationCmdArgs.requiredFormat, "requiredFormat", "f", "", "Format the decks must be legal in, any deck is allowed if not set")
+	configuration_setDeckRulesCmd.Flags().StringVarP(&configuration_setDeckRulesCmdArgs.data, "data", "d", "", "Deck rules in JSON format")
+	configuration_setFormatCmd.Flags().StringVarP(&configuration_setFormatCmdArgs.data, "data", "d", "", "Format deck rules in JSON format")
+	configuration_deleteFormatCmd.Flags().StringVarP(&configuration_deleteFormatCmdArgs.name, "name", "n", "", "Format name")
 	_ = configuration_setFiatPurchaseContractVersionCmd.MarkFlagRequired("value")
 	_ = configuration_setInitialFiatPurchaseTxIdCmd.MarkFlagRequired("value")
 	_ = configuration_useCardLibraryAsUserCollectionCmd.MarkFlagRequired("value")
 	_ = configuration_cardCollectionSyncDataVersionCmd.MarkFlagRequired("value")
+	_ = configuration_setPlayerPoolEntryTimeToLiveCmd.MarkFlagRequired("value")
+	_ = configuration_setMintingReceiptTimeToLiveCmd.MarkFlagRequired("value")
 	_ = configuration_setDataWipeConfigurationCmd.MarkFlagRequired("version")
+	_ = configuration_setMatchMakingQueueConfigurationCmd.MarkFlagRequired("name")
+	_ = configuration_setMatchMakingVersionCompatibilityCmd.MarkFlagRequired("matchVersion")
+	_ = configuration_setDeckRulesCmd.MarkFlagRequired("data")
+	_ = configuration_setFormatCmd.MarkFlagRequired("data")
+	_ = configuration_deleteFormatCmd.MarkFlagRequired("name")
 
 	configurationCmd.AddCommand(
 		configuration_get,
@@ -178,6 +327,13 @@ func init() {
 		configuration_useCardLibraryAsUserCollectionCmd,
 		configuration_cardCollectionSyncDataVersionCmd,
 		configuration_setDataWipeConfigurationCmd,
+		configuration_setMatchMakingQueueConfigurationCmd,
+		configuration_setMatchMakingVersionCompatibilityCmd,
+		configuration_setPlayerPoolEntryTimeToLiveCmd,
+		configuration_setMintingReceiptTimeToLiveCmd,
+		configuration_setDeckRulesCmd,
+		configuration_setFormatCmd,
+		configuration_deleteFormatCmd,
 	)
 
 	rootCmd.AddCommand(configurationCmd)
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	"strings"
+
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var craftCardCmdArgs struct {
+	userID  string
+	version string
+	mouldID int64
+	variant string
+	amount  int64
+}
+
+var craftCardCmd = &cobra.Command{
+	Use:   "craft_card",
+	Short: "crafts cards with dust",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+
+		variant, exists := zb_enums.CardVariant_Enum_value[craftCardCmdArgs.variant]
+		if !exists {
+			return fmt.Errorf("unknown card variant %s", craftCardCmdArgs.variant)
+		}
+
+		req := &zb_calls.CraftCardRequest{
+			UserId:  craftCardCmdArgs.userID,
+			Version: craftCardCmdArgs.version,
+			CardKey: battleground_proto.CardKey{
+				MouldId: craftCardCmdArgs.mouldID,
+				Variant: zb_enums.CardVariant_Enum(variant),
+			},
+			Amount: craftCardCmdArgs.amount,
+		}
+		var result zb_calls.CraftCardResponse
+		_, err := commonTxObjs.contract.Call("CraftCard", req, signer, &result)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			output, err := json.Marshal(map[string]interface{}{"dust": result.Dust})
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+		default:
+			fmt.Printf("card crafted successfully, dust: %d\n", result.Dust)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(craftCardCmd)
+
+	craftCardCmd.Flags().StringVarP(&craftCardCmdArgs.userID, "userId", "u", "loom", "UserId of account")
+	craftCardCmd.Flags().StringVarP(&craftCardCmdArgs.version, "version", "v", "v1", "Version")
+	craftCardCmd.Flags().Int64VarP(&craftCardCmdArgs.mouldID, "mouldId", "m", 0, "Mould id of the card")
+	craftCardCmd.Flags().StringVarP(&craftCardCmdArgs.variant, "variant", "", "Standard", "Variant of the card")
+	craftCardCmd.Flags().Int64VarP(&craftCardCmdArgs.amount, "amount", "a", 1, "Amount of cards")
+
+	_ = craftCardCmd.MarkFlagRequired("mouldId")
+}
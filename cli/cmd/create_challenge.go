@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"strings"
+	"time"
+
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var createChallengeCmdArgs struct {
+	userID       string
+	targetUserID string
+	deckID       int64
+	gameModeID   string
+	version      string
+}
+
+var createChallengeCmd = &cobra.Command{
+	Use:   "create_challenge",
+	Short: "challenges another player directly",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+		req := zb_calls.CreateChallengeRequest{
+			UserId:       createChallengeCmdArgs.userID,
+			TargetUserId: createChallengeCmdArgs.targetUserID,
+			DeckId:       createChallengeCmdArgs.deckID,
+			GameModeId:   createChallengeCmdArgs.gameModeID,
+			Version:      createChallengeCmdArgs.version,
+		}
+		var resp zb_calls.CreateChallengeResponse
+
+		_, err := commonTxObjs.contract.Call("CreateChallenge", &req, signer, &resp)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			err := battleground_utility.PrintProtoMessageAsJsonToStdout(resp.Challenge)
+			if err != nil {
+				return err
+			}
+		default:
+			fmt.Printf("Challenge code: %s\n", resp.Challenge.Code)
+			fmt.Printf("Expires at: %s\n", time.Unix(resp.Challenge.ExpiresAt, 0).String())
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(createChallengeCmd)
+
+	createChallengeCmd.Flags().StringVarP(&createChallengeCmdArgs.userID, "userId", "u", "loom", "UserId of account")
+	createChallengeCmd.Flags().StringVarP(&createChallengeCmdArgs.targetUserID, "targetUserId", "t", "", "UserId of the challenged player")
+	createChallengeCmd.Flags().Int64VarP(&createChallengeCmdArgs.deckID, "deckId", "d", 1, "Deck id")
+	createChallengeCmd.Flags().StringVarP(&createChallengeCmdArgs.gameModeID, "gameModeId", "g", "", "Game mode id, default game if not set")
+	createChallengeCmd.Flags().StringVarP(&createChallengeCmdArgs.version, "version", "v", "", "Version")
+
+	_ = createChallengeCmd.MarkFlagRequired("targetUserId")
+	_ = createChallengeCmd.MarkFlagRequired("version")
+}
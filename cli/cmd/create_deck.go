@@ -49,6 +49,7 @@ var createDeckCmd = &cobra.Command{
 			fmt.Println(string(output))
 		default:
 			fmt.Printf("deck created successfully with id %d\n", result.DeckId)
+			fmt.Printf("legal formats: %s\n", strings.Join(result.LegalFormats, ", "))
 		}
 
 		return nil
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"strings"
+
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var declineChallengeCmdArgs struct {
+	userID        string
+	challengeCode string
+}
+
+var declineChallengeCmd = &cobra.Command{
+	Use:   "decline_challenge",
+	Short: "declines a challenge",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+		req := &zb_calls.DeclineChallengeRequest{
+			UserId:        declineChallengeCmdArgs.userID,
+			ChallengeCode: declineChallengeCmdArgs.challengeCode,
+		}
+
+		_, err := commonTxObjs.contract.Call("DeclineChallenge", req, signer, nil)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			output, err := json.Marshal(map[string]interface{}{"success": true})
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+		default:
+			fmt.Println("challenge declined successfully")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(declineChallengeCmd)
+
+	declineChallengeCmd.Flags().StringVarP(&declineChallengeCmdArgs.userID, "userId", "u", "loom", "UserId of account")
+	declineChallengeCmd.Flags().StringVarP(&declineChallengeCmdArgs.challengeCode, "code", "", "", "Challenge code")
+
+	_ = declineChallengeCmd.MarkFlagRequired("code")
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/loomnetwork/gamechain/battleground"
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"strings"
+
+	"github.com/loomnetwork/go-loom"
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var diffCardLibraryCmdArgs struct {
+	fromVersion string
+	toVersion   string
+}
+
+var diffCardLibraryCmd = &cobra.Command{
+	Use:   "diff_card_library",
+	Short: "shows added, removed and changed cards between two card library versions",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+		callerAddr := loom.Address{
+			ChainID: commonTxObjs.rpcClient.GetChainID(),
+			Local:   loom.LocalAddressFromPublicKey(signer.PublicKey()),
+		}
+
+		getInitData := func(version string) (*zb_data.InitData, error) {
+			req := zb_calls.GetInitRequest{
+				Version: version,
+			}
+			result := zb_calls.GetInitResponse{}
+
+			_, err := commonTxObjs.contract.StaticCall("GetInit", &req, callerAddr, &result)
+			if err != nil {
+				return nil, err
+			}
+
+			return result.InitData, nil
+		}
+
+		fromInitData, err := getInitData(diffCardLibraryCmdArgs.fromVersion)
+		if err != nil {
+			return err
+		}
+
+		toInitData, err := getInitData(diffCardLibraryCmdArgs.toVersion)
+		if err != nil {
+			return err
+		}
+
+		diff, err := battleground.DiffCardLibraries(fromInitData.Cards, toInitData.Cards)
+		if err != nil {
+			return err
+		}
+
+		cardKeyToMigration := make(map[battleground_proto.CardKey]*zb_data.CardKeyMigration)
+		for _, migration := range toInitData.CardKeyMigrations {
+			cardKeyToMigration[migration.From] = migration
+		}
+
+		getMigrationDescription := func(cardKey battleground_proto.CardKey) string {
+			migration, exists := cardKeyToMigration[cardKey]
+			if !exists {
+				return "no migration, dropped from decks"
+			}
+
+			if migration.Retired {
+				return "retired"
+			}
+
+			return "remapped to [" + migration.To.String() + "]"
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			added := make([]map[string]interface{}, 0)
+			for _, card := range diff.Added {
+				added = append(added, map[string]interface{}{
+					"cardKey": card.CardKey,
+					"name":    card.Name,
+				})
+			}
+
+			removed := make([]map[string]interface{}, 0)
+			for _, card := range diff.Removed {
+				removed = append(removed, map[string]interface{}{
+					"cardKey":   card.CardKey,
+					"name":      card.Name,
+					"migration": getMigrationDescription(card.CardKey),
+				})
+			}
+
+			changed := make([]map[string]interface{}, 0)
+			for _, cardDiff := range diff.Changed {
+				fields := make([]map[string]interface{}, 0)
+				for _, field := range cardDiff.Fields {
+					fields = append(fields, map[string]interface{}{
+						"path": field.Path,
+						"from": json.RawMessage(field.From),
+						"to":   json.RawMessage(field.To),
+					})
+				}
+
+				changed = append(changed, map[string]interface{}{
+					"cardKey": cardDiff.CardKey,
+					"name":    cardDiff.Name,
+					"fields":  fields,
+				})
+			}
+
+			output, err := json.Marshal(map[string]interface{}{
+				"added":   added,
+				"removed": removed,
+				"changed": changed,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+		default:
+			if diff.IsEmpty() {
+				fmt.Println("card libraries are identical")
+				return nil
+			}
+
+			for _, card := range diff.Added {
+				fmt.Printf("+ [%s] %s\n", card.CardKey.String(), card.Name)
+			}
+
+			for _, card := range diff.Removed {
+				fmt.Printf("- [%s] %s (%s)\n", card.CardKey.String(), card.Name, getMigrationDescription(card.CardKey))
+			}
+
+			for _, cardDiff := range diff.Changed {
+				fmt.Printf("~ [%s] %s\n", cardDiff.CardKey.String(), cardDiff.Name)
+				for _, field := range cardDiff.Fields {
+					fmt.Printf("    %s: %s -> %s\n", field.Path, field.From, field.To)
+				}
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCardLibraryCmd)
+
+	diffCardLibraryCmd.Flags().StringVarP(&diffCardLibraryCmdArgs.fromVersion, "from", "", "", "Version to compare from")
+	diffCardLibraryCmd.Flags().StringVarP(&diffCardLibraryCmdArgs.toVersion, "to", "", "", "Version to compare to")
+
+	_ = diffCardLibraryCmd.MarkFlagRequired("from")
+	_ = diffCardLibraryCmd.MarkFlagRequired("to")
+}
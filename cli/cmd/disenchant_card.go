@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	"strings"
+
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var disenchantCardCmdArgs struct {
+	userID  string
+	version string
+	mouldID int64
+	variant string
+	amount  int64
+}
+
+var disenchantCardCmd = &cobra.Command{
+	Use:   "disenchant_card",
+	Short: "disenchants duplicate cards into dust",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+
+		variant, exists := zb_enums.CardVariant_Enum_value[disenchantCardCmdArgs.variant]
+		if !exists {
+			return fmt.Errorf("unknown card variant %s", disenchantCardCmdArgs.variant)
+		}
+
+		req := &zb_calls.DisenchantCardRequest{
+			UserId:  disenchantCardCmdArgs.userID,
+			Version: disenchantCardCmdArgs.version,
+			CardKey: battleground_proto.CardKey{
+				MouldId: disenchantCardCmdArgs.mouldID,
+				Variant: zb_enums.CardVariant_Enum(variant),
+			},
+			Amount: disenchantCardCmdArgs.amount,
+		}
+		var result zb_calls.DisenchantCardResponse
+		_, err := commonTxObjs.contract.Call("DisenchantCard", req, signer, &result)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			output, err := json.Marshal(map[string]interface{}{"dust": result.Dust})
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+		default:
+			fmt.Printf("card disenchanted successfully, dust: %d\n", result.Dust)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(disenchantCardCmd)
+
+	disenchantCardCmd.Flags().StringVarP(&disenchantCardCmdArgs.userID, "userId", "u", "loom", "UserId of account")
+	disenchantCardCmd.Flags().StringVarP(&disenchantCardCmdArgs.version, "version", "v", "v1", "Version")
+	disenchantCardCmd.Flags().Int64VarP(&disenchantCardCmdArgs.mouldID, "mouldId", "m", 0, "Mould id of the card")
+	disenchantCardCmd.Flags().StringVarP(&disenchantCardCmdArgs.variant, "variant", "", "Standard", "Variant of the card")
+	disenchantCardCmd.Flags().Int64VarP(&disenchantCardCmdArgs.amount, "amount", "a", 1, "Amount of cards")
+
+	_ = disenchantCardCmd.MarkFlagRequired("mouldId")
+}
@@ -34,7 +34,8 @@ var editDeckCmd = &cobra.Command{
 			Version: editDeckCmdArgs.version,
 		}
 
-		_, err := commonTxObjs.contract.Call("EditDeck", req, signer, nil)
+		var result zb_calls.EditDeckResponse
+		_, err := commonTxObjs.contract.Call("EditDeck", req, signer, &result)
 		if err != nil {
 			return fmt.Errorf("error encountered while calling EditDeck: %s", err.Error())
 		}
@@ -48,6 +49,7 @@ var editDeckCmd = &cobra.Command{
 			fmt.Println(string(output))
 		default:
 			fmt.Printf("deck edited successfully\n")
+			fmt.Printf("legal formats: %s\n", strings.Join(result.LegalFormats, ", "))
 		}
 
 		return nil
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"strings"
+
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var exportDeckCmdArgs struct {
+	userID  string
+	deckID  int64
+	version string
+}
+
+var exportDeckCmd = &cobra.Command{
+	Use:   "export_deck",
+	Short: "gets the shareable code of a deck",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+
+		req := &zb_calls.ExportDeckRequest{
+			UserId:  exportDeckCmdArgs.userID,
+			DeckId:  exportDeckCmdArgs.deckID,
+			Version: exportDeckCmdArgs.version,
+		}
+		var result zb_calls.ExportDeckResponse
+		_, err := commonTxObjs.contract.Call("ExportDeck", req, signer, &result)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			output, err := json.Marshal(map[string]interface{}{"deckCode": result.DeckCode})
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+		default:
+			fmt.Println(result.DeckCode)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportDeckCmd)
+
+	exportDeckCmd.Flags().StringVarP(&exportDeckCmdArgs.userID, "userId", "u", "loom", "UserId of account")
+	exportDeckCmd.Flags().Int64VarP(&exportDeckCmdArgs.deckID, "deckId", "", 0, "DeckId of account")
+	exportDeckCmd.Flags().StringVarP(&exportDeckCmdArgs.version, "version", "v", "v1", "Version")
+
+	_ = exportDeckCmd.MarkFlagRequired("deckId")
+}
@@ -13,6 +13,7 @@ import (
 var findMatchCmdArgs struct {
 	userID string
 	tags   []string
+	queues []string
 }
 
 var findMatchCmd = &cobra.Command{
@@ -23,6 +24,7 @@ var findMatchCmd = &cobra.Command{
 		var req = zb_calls.FindMatchRequest{
 			UserId: findMatchCmdArgs.userID,
 			Tags:   findMatchCmdArgs.tags,
+			Queues: findMatchCmdArgs.queues,
 		}
 		var resp zb_calls.FindMatchResponse
 
@@ -61,4 +63,5 @@ func init() {
 
 	findMatchCmd.Flags().StringVarP(&findMatchCmdArgs.userID, "userId", "u", "loom", "UserId of account")
 	findMatchCmd.Flags().StringArrayVarP(&findMatchCmdArgs.tags, "tags", "t", nil, "tags")
+	findMatchCmd.Flags().StringArrayVarP(&findMatchCmdArgs.queues, "queues", "q", nil, "matchmaking queues")
 }
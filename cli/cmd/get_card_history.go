@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	"strings"
+
+	"github.com/loomnetwork/go-loom"
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var getCardHistoryCmdArgs struct {
+	mouldID int64
+	variant string
+}
+
+var getCardHistoryCmd = &cobra.Command{
+	Use:   "get_card_history",
+	Short: "gets the changes made to a card by card library updates",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+		callerAddr := loom.Address{
+			ChainID: commonTxObjs.rpcClient.GetChainID(),
+			Local:   loom.LocalAddressFromPublicKey(signer.PublicKey()),
+		}
+
+		variant, exists := zb_enums.CardVariant_Enum_value[getCardHistoryCmdArgs.variant]
+		if !exists {
+			return fmt.Errorf("unknown card variant %s", getCardHistoryCmdArgs.variant)
+		}
+
+		req := &zb_calls.GetCardHistoryRequest{
+			CardKey: battleground_proto.CardKey{
+				MouldId: getCardHistoryCmdArgs.mouldID,
+				Variant: zb_enums.CardVariant_Enum(variant),
+			},
+		}
+		var result zb_calls.GetCardHistoryResponse
+		_, err := commonTxObjs.contract.StaticCall("GetCardHistory", req, callerAddr, &result)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			err := battleground_utility.PrintProtoMessageAsJsonToStdout(&result)
+			if err != nil {
+				return err
+			}
+		default:
+			for _, entry := range result.Entries {
+				fmt.Printf(
+					"%s in version %s, block height: %d, changed by: %s, time: %d\n",
+					entry.Kind.String(),
+					entry.Version,
+					entry.BlockHeight,
+					entry.ChangedBy,
+					entry.CreatedAt,
+				)
+				for _, change := range entry.Changes {
+					fmt.Printf("    %s: %s -> %s\n", change.Field, change.OldValue, change.NewValue)
+				}
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(getCardHistoryCmd)
+
+	getCardHistoryCmd.Flags().Int64VarP(&getCardHistoryCmdArgs.mouldID, "mouldId", "m", 0, "Mould id of the card")
+	getCardHistoryCmd.Flags().StringVarP(&getCardHistoryCmdArgs.variant, "variant", "", "Standard", "Variant of the card")
+
+	_ = getCardHistoryCmd.MarkFlagRequired("mouldId")
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"strings"
+
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var getCraftingAuditLogCmdArgs struct {
+	userID string
+}
+
+var getCraftingAuditLogCmd = &cobra.Command{
+	Use:   "get_crafting_audit_log",
+	Short: "gets the dust balance and the crafting history of a user",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+
+		req := &zb_calls.GetCraftingAuditLogRequest{
+			UserId: getCraftingAuditLogCmdArgs.userID,
+		}
+		var result zb_calls.GetCraftingAuditLogResponse
+		_, err := commonTxObjs.contract.Call("GetCraftingAuditLog", req, signer, &result)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			err := battleground_utility.PrintProtoMessageAsJsonToStdout(&result)
+			if err != nil {
+				return err
+			}
+		default:
+			fmt.Printf("dust: %d\n", result.Dust)
+			for _, entry := range result.Entries {
+				fmt.Printf(
+					"%s card: [%v], amount: %d, dust change: %d, dust balance: %d, time: %d\n",
+					entry.Action.String(),
+					entry.CardKey.String(),
+					entry.Amount,
+					entry.DustChange,
+					entry.DustBalance,
+					entry.CreatedAt,
+				)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(getCraftingAuditLogCmd)
+
+	getCraftingAuditLogCmd.Flags().StringVarP(&getCraftingAuditLogCmdArgs.userID, "userId", "u", "loom", "UserId of account")
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"strings"
+
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var getDeckStatsCmdArgs struct {
+	userID              string
+	deckID              int64
+	version             string
+	mostPlayedCardCount int32
+}
+
+var getDeckStatsCmd = &cobra.Command{
+	Use:   "get_deck_stats",
+	Short: "gets the statistics of a deck",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+
+		req := &zb_calls.GetDeckStatsRequest{
+			UserId:              getDeckStatsCmdArgs.userID,
+			DeckId:              getDeckStatsCmdArgs.deckID,
+			Version:             getDeckStatsCmdArgs.version,
+			MostPlayedCardCount: getDeckStatsCmdArgs.mostPlayedCardCount,
+		}
+		var result zb_calls.GetDeckStatsResponse
+		_, err := commonTxObjs.contract.Call("GetDeckStats", req, signer, &result)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			err := battleground_utility.PrintProtoMessageAsJsonToStdout(&result)
+			if err != nil {
+				return err
+			}
+		default:
+			fmt.Printf("deck id: %v\n", result.DeckId)
+			fmt.Printf("games played: %d, wins: %d, losses: %d\n", result.GamesPlayed, result.Wins, result.Losses)
+			fmt.Printf("win rate: %.2f%%\n", result.WinRate*100)
+			fmt.Printf("average match duration: %ds\n", result.AverageMatchDuration)
+			for _, cardPlayCount := range result.MostPlayedCards {
+				fmt.Printf("card: [%v], played: %d\n", cardPlayCount.CardKey.String(), cardPlayCount.PlayCount)
+			}
+			for _, entry := range result.GooCurve {
+				fmt.Printf("goo: %d, cards: %d\n", entry.Goo, entry.CardCount)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(getDeckStatsCmd)
+
+	getDeckStatsCmd.Flags().StringVarP(&getDeckStatsCmdArgs.userID, "userId", "u", "loom", "UserId of account")
+	getDeckStatsCmd.Flags().Int64VarP(&getDeckStatsCmdArgs.deckID, "deckId", "", 0, "DeckId of account")
+	getDeckStatsCmd.Flags().StringVarP(&getDeckStatsCmdArgs.version, "version", "v", "v1", "Version")
+	getDeckStatsCmd.Flags().Int32VarP(&getDeckStatsCmdArgs.mostPlayedCardCount, "mostPlayedCardCount", "", 0, "Number of most played cards to return")
+
+	_ = getDeckStatsCmd.MarkFlagRequired("deckId")
+}
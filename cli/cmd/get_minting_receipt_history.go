@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"strings"
+
+	"github.com/loomnetwork/go-loom"
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var getMintingReceiptHistoryCmdArgs struct {
+	userID string
+}
+
+var getMintingReceiptHistoryCmd = &cobra.Command{
+	Use:   "get_minting_receipt_history",
+	Short: "lists the minting receipts of a user with their status (pending, redeemed, expired)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+		callerAddr := loom.Address{
+			ChainID: commonTxObjs.rpcClient.GetChainID(),
+			Local:   loom.LocalAddressFromPublicKey(signer.PublicKey()),
+		}
+
+		req := &zb_calls.GetMintingTransactionReceiptHistoryRequest{
+			UserId: getMintingReceiptHistoryCmdArgs.userID,
+		}
+		var result zb_calls.GetMintingTransactionReceiptHistoryResponse
+		_, err := commonTxObjs.contract.StaticCall("GetMintingTransactionReceiptHistory", req, callerAddr, &result)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			err := battleground_utility.PrintProtoMessageAsJsonToStdout(&result)
+			if err != nil {
+				return err
+			}
+		default:
+			for _, entry := range result.History.Entries {
+				fmt.Printf(
+					"txId: %s, status: %s, created: %d, updated: %d\n",
+					entry.Receipt.TxId.Value.String(),
+					entry.Status.String(),
+					entry.CreatedAt,
+					entry.UpdatedAt,
+				)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(getMintingReceiptHistoryCmd)
+
+	getMintingReceiptHistoryCmd.Flags().StringVarP(&getMintingReceiptHistoryCmdArgs.userID, "userId", "u", "loom", "UserId of account")
+}
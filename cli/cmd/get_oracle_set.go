@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	"github.com/loomnetwork/gamechain/types/oracle"
+	"strings"
+
+	"github.com/loomnetwork/go-loom"
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var getOracleSetCmd = &cobra.Command{
+	Use:   "get_oracle_set",
+	Short: "shows the oracle set members, threshold and the calls waiting for votes",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+		callerAddr := loom.Address{
+			ChainID: commonTxObjs.rpcClient.GetChainID(),
+			Local:   loom.LocalAddressFromPublicKey(signer.PublicKey()),
+		}
+
+		req := &oracle.GetOracleSetRequest{}
+		var result oracle.GetOracleSetResponse
+		_, err := commonTxObjs.contract.StaticCall("GetOracleSet", req, callerAddr, &result)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			err := battleground_utility.PrintProtoMessageAsJsonToStdout(&result)
+			if err != nil {
+				return err
+			}
+		default:
+			fmt.Printf("threshold: %d\n", result.OracleSet.GetThreshold())
+			for _, member := range result.OracleSet.GetMembers() {
+				fmt.Printf("member: %s\n", loom.UnmarshalAddressPB(member).String())
+			}
+			for _, vote := range result.Votes {
+				fmt.Printf("vote: %s, votes: %d, created: %d\n", vote.Method, len(vote.Voters), vote.CreatedAt)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(getOracleSetCmd)
+}
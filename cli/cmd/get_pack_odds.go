@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	"strings"
+
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var getPackOddsCmdArgs struct {
+	version  string
+	packType string
+}
+
+var getPackOddsCmd = &cobra.Command{
+	Use:   "get_pack_odds",
+	Short: "gets the odds of every card in each slot of a pack",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+
+		packType, exists := zb_enums.PackType_Enum_value[getPackOddsCmdArgs.packType]
+		if !exists {
+			return fmt.Errorf("unknown pack type %s", getPackOddsCmdArgs.packType)
+		}
+
+		req := &zb_calls.GetPackOddsRequest{
+			Version: getPackOddsCmdArgs.version,
+			Type:    zb_enums.PackType_Enum(packType),
+		}
+		var result zb_calls.GetPackOddsResponse
+		_, err := commonTxObjs.contract.Call("GetPackOdds", req, signer, &result)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			err := battleground_utility.PrintProtoMessageAsJsonToStdout(&result)
+			if err != nil {
+				return err
+			}
+		default:
+			for index, slot := range result.Slots {
+				fmt.Printf("slot %d:\n", index+1)
+				for _, cardOdds := range slot.Cards {
+					fmt.Printf("  [%s]: %.4f%%\n", cardOdds.CardKey.String(), cardOdds.Probability*100)
+				}
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(getPackOddsCmd)
+
+	getPackOddsCmd.Flags().StringVarP(&getPackOddsCmdArgs.version, "version", "v", "v1", "Version")
+	getPackOddsCmd.Flags().StringVarP(&getPackOddsCmdArgs.packType, "type", "t", "Booster", "Type of the pack")
+}
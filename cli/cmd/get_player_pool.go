@@ -11,8 +11,8 @@ import (
 )
 
 var getPlayerPoolCmdArgs struct {
-	MatchID            int64
-	isTaggedPlayerPool bool
+	MatchID int64
+	queue   string
 }
 
 var getPlayerPoolCmd = &cobra.Command{
@@ -21,19 +21,14 @@ var getPlayerPoolCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
 
-		var req = zb_calls.PlayerPoolRequest{}
+		var req = zb_calls.PlayerPoolRequest{
+			Queue: getPlayerPoolCmdArgs.queue,
+		}
 		var resp zb_calls.PlayerPoolResponse
 
-		if getPlayerPoolCmdArgs.isTaggedPlayerPool {
-			_, err := commonTxObjs.contract.Call("GetTaggedPlayerPool", &req, signer, &resp)
-			if err != nil {
-				return err
-			}
-		} else {
-			_, err := commonTxObjs.contract.Call("GetPlayerPool", &req, signer, &resp)
-			if err != nil {
-				return err
-			}
+		_, err := commonTxObjs.contract.Call("GetPlayerPool", &req, signer, &resp)
+		if err != nil {
+			return err
 		}
 
 		pool := resp.Pool
@@ -59,5 +54,5 @@ var getPlayerPoolCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(getPlayerPoolCmd)
 
-	getPlayerPoolCmd.Flags().BoolVarP(&getPlayerPoolCmdArgs.isTaggedPlayerPool, "tagged", "t", false, "Tagged Player Pool")
+	getPlayerPoolCmd.Flags().StringVarP(&getPlayerPoolCmdArgs.queue, "queue", "q", "", "Matchmaking queue name, casual queue if empty")
 }
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	"github.com/loomnetwork/gamechain/types/oracle"
+	"strings"
+
+	"github.com/loomnetwork/go-loom"
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var getQuarantinedOracleEventsCmd = &cobra.Command{
+	Use:   "get_quarantined_oracle_events",
+	Short: "lists the Plasmachain events the oracle quarantined because they kept failing",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+		callerAddr := loom.Address{
+			ChainID: commonTxObjs.rpcClient.GetChainID(),
+			Local:   loom.LocalAddressFromPublicKey(signer.PublicKey()),
+		}
+
+		req := &oracle.GetQuarantinedOracleEventsRequest{}
+		var result oracle.GetQuarantinedOracleEventsResponse
+		_, err := commonTxObjs.contract.StaticCall("GetQuarantinedOracleEvents", req, callerAddr, &result)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			err := battleground_utility.PrintProtoMessageAsJsonToStdout(&result)
+			if err != nil {
+				return err
+			}
+		default:
+			for _, batch := range result.Batches {
+				fmt.Printf(
+					"last block: %d, events: %d, quarantined: %d, error: %s\n",
+					batch.LastPlasmachainBlockNumber,
+					len(batch.Events),
+					batch.QuarantinedAt,
+					batch.Error,
+				)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(getQuarantinedOracleEventsCmd)
+}
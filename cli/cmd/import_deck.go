@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"strings"
+
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var importDeckCmdArgs struct {
+	userID   string
+	deckCode string
+	name     string
+	version  string
+}
+
+var importDeckCmd = &cobra.Command{
+	Use:   "import_deck",
+	Short: "creates a deck from a deck code",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+
+		req := &zb_calls.ImportDeckRequest{
+			UserId:   importDeckCmdArgs.userID,
+			DeckCode: importDeckCmdArgs.deckCode,
+			Name:     importDeckCmdArgs.name,
+			Version:  importDeckCmdArgs.version,
+		}
+		var result zb_calls.ImportDeckResponse
+		_, err := commonTxObjs.contract.Call("ImportDeck", req, signer, &result)
+		if err != nil {
+			return fmt.Errorf("error encountered while calling ImportDeck: %s", err.Error())
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			output, err := json.Marshal(map[string]interface{}{"success": true, "deckId": result.DeckId})
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+		default:
+			fmt.Printf("deck imported successfully with id %d\n", result.DeckId)
+			fmt.Printf("legal formats: %s\n", strings.Join(result.LegalFormats, ", "))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importDeckCmd)
+
+	importDeckCmd.Flags().StringVarP(&importDeckCmdArgs.userID, "userId", "u", "loom", "UserId of account")
+	importDeckCmd.Flags().StringVarP(&importDeckCmdArgs.deckCode, "deckCode", "d", "", "Deck code")
+	importDeckCmd.Flags().StringVarP(&importDeckCmdArgs.name, "name", "n", "", "Name of the new deck")
+	importDeckCmd.Flags().StringVarP(&importDeckCmdArgs.version, "version", "v", "v1", "Version")
+
+	_ = importDeckCmd.MarkFlagRequired("deckCode")
+	_ = importDeckCmd.MarkFlagRequired("name")
+}
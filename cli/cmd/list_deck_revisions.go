@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"strings"
+
+	"github.com/loomnetwork/go-loom"
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var listDeckRevisionsCmdArgs struct {
+	userID string
+	deckID int64
+}
+
+var listDeckRevisionsCmd = &cobra.Command{
+	Use:   "list_deck_revisions",
+	Short: "lists the kept revisions of a deck",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+		callerAddr := loom.Address{
+			ChainID: commonTxObjs.rpcClient.GetChainID(),
+			Local:   loom.LocalAddressFromPublicKey(signer.PublicKey()),
+		}
+
+		req := &zb_calls.ListDeckRevisionsRequest{
+			UserId: listDeckRevisionsCmdArgs.userID,
+			DeckId: listDeckRevisionsCmdArgs.deckID,
+		}
+		var result zb_calls.ListDeckRevisionsResponse
+		_, err := commonTxObjs.contract.StaticCall("ListDeckRevisions", req, callerAddr, &result)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			err := battleground_utility.PrintProtoMessageAsJsonToStdout(&result)
+			if err != nil {
+				return err
+			}
+		default:
+			for _, revision := range result.Revisions {
+				fmt.Printf("revision %d: %s, time: %d", revision.Revision, revision.Reason.String(), revision.CreatedAt)
+				if revision.RestoredRevision != 0 {
+					fmt.Printf(", restored revision: %d", revision.RestoredRevision)
+				}
+				fmt.Println()
+				for _, change := range revision.Changes {
+					fmt.Printf("    card: [%v], %d -> %d\n", change.CardKey.String(), change.OldAmount, change.NewAmount)
+				}
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listDeckRevisionsCmd)
+
+	listDeckRevisionsCmd.Flags().StringVarP(&listDeckRevisionsCmdArgs.userID, "userId", "u", "loom", "UserId of account")
+	listDeckRevisionsCmd.Flags().Int64VarP(&listDeckRevisionsCmdArgs.deckID, "deckId", "", 0, "DeckId of account")
+
+	_ = listDeckRevisionsCmd.MarkFlagRequired("deckId")
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"strings"
+
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var listPackOpeningsCmdArgs struct {
+	userID string
+}
+
+var listPackOpeningsCmd = &cobra.Command{
+	Use:   "list_pack_openings",
+	Short: "lists the pack openings of a user",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+
+		req := &zb_calls.ListPackOpeningsRequest{
+			UserId: listPackOpeningsCmdArgs.userID,
+		}
+		var result zb_calls.ListPackOpeningsResponse
+		_, err := commonTxObjs.contract.Call("ListPackOpenings", req, signer, &result)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			err := battleground_utility.PrintProtoMessageAsJsonToStdout(&result)
+			if err != nil {
+				return err
+			}
+		default:
+			for _, opening := range result.Openings {
+				fmt.Printf(
+					"index: %d, burn: %d/%d/%d, first pack: %d, seed: %x, block height: %d, packs: %d, time: %d\n",
+					opening.Index,
+					opening.BurnEthBlock,
+					opening.BurnTxIndex,
+					opening.BurnLogIndex,
+					opening.FirstPackIndex,
+					opening.Seed,
+					opening.BlockHeight,
+					len(opening.Packs),
+					opening.CreatedAt,
+				)
+			}
+			for _, unopenedPack := range result.UnopenedPacks {
+				fmt.Printf("unopened %s packs: %d\n", unopenedPack.Type.String(), unopenedPack.Amount)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listPackOpeningsCmd)
+
+	listPackOpeningsCmd.Flags().StringVarP(&listPackOpeningsCmdArgs.userID, "userId", "u", "loom", "UserId of account")
+}
@@ -18,6 +18,9 @@ var mergeJsonToInitCmdArgs struct {
 	overlordsFile string
 	aiDecksFile string
 	overlordLevelingFile string
+	craftingFile string
+	packsFile string
+	cardKeyMigrationsFile string
 
 	outputFile string
 }
@@ -34,6 +37,9 @@ var mergeJsonToInitCmd = &cobra.Command{
 		var overlordsPrototypesData zb_data.OverlordPrototypesDataContainer
 		var aiDecksData zb_data.AIDecksDataContainer
 		var overlordLevelingData zb_data.OverlordLevelingDataContainer
+		var craftingData zb_data.CraftingDataContainer
+		var packsData zb_data.PackDefinitionsDataContainer
+		var cardKeyMigrationsData zb_data.CardKeyMigrationsDataContainer
 
 		// Read pieces
 		err = battleground_utility.ReadJsonFileToProtoMessage(mergeJsonToInitCmdArgs.initJsonTemplateFile, &initData)
@@ -71,6 +77,30 @@ var mergeJsonToInitCmd = &cobra.Command{
 			return errors.Wrap(err, "error parsing " + mergeJsonToInitCmdArgs.overlordLevelingFile)
 		}
 
+		// crafting rates are optional
+		if mergeJsonToInitCmdArgs.craftingFile != "" {
+			err = battleground_utility.ReadJsonFileToProtoMessage(mergeJsonToInitCmdArgs.craftingFile, &craftingData)
+			if err != nil {
+				return errors.Wrap(err, "error parsing " + mergeJsonToInitCmdArgs.craftingFile)
+			}
+		}
+
+		// pack definitions are optional
+		if mergeJsonToInitCmdArgs.packsFile != "" {
+			err = battleground_utility.ReadJsonFileToProtoMessage(mergeJsonToInitCmdArgs.packsFile, &packsData)
+			if err != nil {
+				return errors.Wrap(err, "error parsing " + mergeJsonToInitCmdArgs.packsFile)
+			}
+		}
+
+		// card key migrations are optional
+		if mergeJsonToInitCmdArgs.cardKeyMigrationsFile != "" {
+			err = battleground_utility.ReadJsonFileToProtoMessage(mergeJsonToInitCmdArgs.cardKeyMigrationsFile, &cardKeyMigrationsData)
+			if err != nil {
+				return errors.Wrap(err, "error parsing " + mergeJsonToInitCmdArgs.cardKeyMigrationsFile)
+			}
+		}
+
 		// Merge
 		initData.DefaultDecks = defaultDecksData.DefaultDecks
 		initData.DefaultCollection = defaultCollectionData.DefaultCollection
@@ -78,6 +108,15 @@ var mergeJsonToInitCmd = &cobra.Command{
 		initData.Overlords = overlordsPrototypesData.Overlords
 		initData.AiDecks = aiDecksData.AiDecks
 		initData.OverlordLeveling = overlordLevelingData.OverlordLeveling
+		if craftingData.Crafting != nil {
+			initData.Crafting = craftingData.Crafting
+		}
+		if packsData.Packs != nil {
+			initData.Packs = packsData.Packs
+		}
+		if cardKeyMigrationsData.CardKeyMigrations != nil {
+			initData.CardKeyMigrations = cardKeyMigrationsData.CardKeyMigrations
+		}
 
 		// Write merged file
 		mergedFile, err := os.Create(mergeJsonToInitCmdArgs.outputFile)
@@ -113,6 +152,9 @@ func init() {
 	mergeJsonToInitCmd.Flags().StringVarP(&mergeJsonToInitCmdArgs.overlordsFile, "overlords", "", "overlords.json", "overlord prototypes JSON data file")
 	mergeJsonToInitCmd.Flags().StringVarP(&mergeJsonToInitCmdArgs.aiDecksFile, "aiDecks", "", "ai_decks.json", "AI decks JSON data file")
 	mergeJsonToInitCmd.Flags().StringVarP(&mergeJsonToInitCmdArgs.overlordLevelingFile, "overlordLeveling", "", "overlord_leveling.json", "Overlord leveling JSON data file")
+	mergeJsonToInitCmd.Flags().StringVarP(&mergeJsonToInitCmdArgs.craftingFile, "crafting", "", "", "crafting rates JSON data file")
+	mergeJsonToInitCmd.Flags().StringVarP(&mergeJsonToInitCmdArgs.packsFile, "packs", "", "", "pack definitions JSON data file")
+	mergeJsonToInitCmd.Flags().StringVarP(&mergeJsonToInitCmdArgs.cardKeyMigrationsFile, "cardKeyMigrations", "", "", "card key migrations JSON data file")
 
 	mergeJsonToInitCmd.Flags().StringVarP(&mergeJsonToInitCmdArgs.outputFile, "outputFile", "o", "update_init_merged.json", "path to the merged output file")
 }
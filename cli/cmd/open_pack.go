@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	"strings"
+
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var openPackCmdArgs struct {
+	userID   string
+	version  string
+	packType string
+	amount   uint64
+}
+
+var openPackCmd = &cobra.Command{
+	Use:   "open_pack",
+	Short: "opens packs burned on plasmachain",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+
+		packType, exists := zb_enums.PackType_Enum_value[openPackCmdArgs.packType]
+		if !exists {
+			return fmt.Errorf("unknown pack type %s", openPackCmdArgs.packType)
+		}
+
+		req := &zb_calls.OpenPackRequest{
+			UserId:  openPackCmdArgs.userID,
+			Version: openPackCmdArgs.version,
+			Type:    zb_enums.PackType_Enum(packType),
+			Amount:  openPackCmdArgs.amount,
+		}
+		var result zb_calls.OpenPackResponse
+		_, err := commonTxObjs.contract.Call("OpenPack", req, signer, &result)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			err := battleground_utility.PrintProtoMessageAsJsonToStdout(&result)
+			if err != nil {
+				return err
+			}
+		default:
+			for _, opening := range result.Openings {
+				fmt.Printf(
+					"burn: %d/%d/%d, seed: %x, block height: %d\n",
+					opening.BurnEthBlock,
+					opening.BurnTxIndex,
+					opening.BurnLogIndex,
+					opening.Seed,
+					opening.BlockHeight,
+				)
+				for _, openedPack := range opening.Packs {
+					fmt.Printf("%s pack:\n", openedPack.Type.String())
+					for _, cardKey := range openedPack.Cards {
+						fmt.Printf("  [%s]\n", cardKey.String())
+					}
+				}
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(openPackCmd)
+
+	openPackCmd.Flags().StringVarP(&openPackCmdArgs.userID, "userId", "u", "loom", "UserId of account")
+	openPackCmd.Flags().StringVarP(&openPackCmdArgs.version, "version", "v", "v1", "Version")
+	openPackCmd.Flags().StringVarP(&openPackCmdArgs.packType, "type", "t", "Booster", "Type of the packs")
+	openPackCmd.Flags().Uint64VarP(&openPackCmdArgs.amount, "amount", "a", 1, "Number of packs to open")
+}
@@ -14,6 +14,7 @@ var registerPlayerPoolCmdArgs struct {
 	deckID              int64
 	version             string
 	tags                []string
+	queues              []string
 	useBackendGameLogic bool
 
 	enableDebugCheats    bool
@@ -33,6 +34,7 @@ var registerPlayerPoolCmd = &cobra.Command{
 				DeckId:              registerPlayerPoolCmdArgs.deckID,
 				Version:             registerPlayerPoolCmdArgs.version,
 				Tags:                registerPlayerPoolCmdArgs.tags,
+				Queues:              registerPlayerPoolCmdArgs.queues,
 				UseBackendGameLogic: registerPlayerPoolCmdArgs.useBackendGameLogic,
 			},
 		}
@@ -71,5 +73,6 @@ func init() {
 	registerPlayerPoolCmd.Flags().StringVarP(&registerPlayerPoolCmdArgs.forceFirstTurnUserId, "forceFirstTurnUserId", "", "", "Cheat - UserId of the player who will have the first turn")
 	registerPlayerPoolCmd.Flags().Int64VarP(&registerPlayerPoolCmdArgs.randomSeed, "randomSeed", "", 0, "Cheat - Random Seed")
 	registerPlayerPoolCmd.Flags().StringArrayVarP(&registerPlayerPoolCmdArgs.tags, "tags", "t", nil, "tags")
+	registerPlayerPoolCmd.Flags().StringArrayVarP(&registerPlayerPoolCmdArgs.queues, "queues", "q", nil, "matchmaking queues")
 	registerPlayerPoolCmd.Flags().BoolVarP(&registerPlayerPoolCmdArgs.useBackendGameLogic, "useBackendGameLogic", "b", false, "useBackendGameLogic")
 }
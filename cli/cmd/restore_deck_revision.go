@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"strings"
+
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var restoreDeckRevisionCmdArgs struct {
+	userID   string
+	version  string
+	deckID   int64
+	revision int64
+}
+
+var restoreDeckRevisionCmd = &cobra.Command{
+	Use:   "restore_deck_revision",
+	Short: "restores a deck to an earlier revision",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+
+		req := &zb_calls.RestoreDeckRevisionRequest{
+			UserId:   restoreDeckRevisionCmdArgs.userID,
+			Version:  restoreDeckRevisionCmdArgs.version,
+			DeckId:   restoreDeckRevisionCmdArgs.deckID,
+			Revision: restoreDeckRevisionCmdArgs.revision,
+		}
+		var result zb_calls.RestoreDeckRevisionResponse
+		_, err := commonTxObjs.contract.Call("RestoreDeckRevision", req, signer, &result)
+		if err != nil {
+			return fmt.Errorf("error encountered while calling RestoreDeckRevision: %s", err.Error())
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			err := battleground_utility.PrintProtoMessageAsJsonToStdout(result.Deck)
+			if err != nil {
+				return err
+			}
+		default:
+			fmt.Printf("deck %d restored to revision %d\n", result.Deck.Id, restoreDeckRevisionCmdArgs.revision)
+			fmt.Printf("legal formats: %s\n", strings.Join(result.Deck.LegalFormats, ", "))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreDeckRevisionCmd)
+
+	restoreDeckRevisionCmd.Flags().StringVarP(&restoreDeckRevisionCmdArgs.userID, "userId", "u", "loom", "UserId of account")
+	restoreDeckRevisionCmd.Flags().StringVarP(&restoreDeckRevisionCmdArgs.version, "version", "v", "v1", "Version")
+	restoreDeckRevisionCmd.Flags().Int64VarP(&restoreDeckRevisionCmdArgs.deckID, "deckId", "", 0, "DeckId of account")
+	restoreDeckRevisionCmd.Flags().Int64VarP(&restoreDeckRevisionCmdArgs.revision, "revision", "", 0, "Revision to restore")
+
+	_ = restoreDeckRevisionCmd.MarkFlagRequired("deckId")
+	_ = restoreDeckRevisionCmd.MarkFlagRequired("revision")
+}
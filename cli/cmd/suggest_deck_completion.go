@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
+	"strings"
+
+	"github.com/loomnetwork/go-loom"
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var suggestDeckCompletionCmdArgs struct {
+	userID     string
+	data       string
+	version    string
+	overlordID int64
+}
+
+var suggestDeckCompletionCmd = &cobra.Command{
+	Use:   "suggest_deck_completion",
+	Short: "fills a partial deck to legal size with cards from the collection",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+		callerAddr := loom.Address{
+			ChainID: commonTxObjs.rpcClient.GetChainID(),
+			Local:   loom.LocalAddressFromPublicKey(signer.PublicKey()),
+		}
+
+		var deck zb_data.Deck
+		if err := json.Unmarshal([]byte(suggestDeckCompletionCmdArgs.data), &deck); err != nil {
+			return fmt.Errorf("invalid JSON passed in data field. Error: %s", err.Error())
+		}
+
+		req := &zb_calls.SuggestDeckCompletionRequest{
+			UserId:     suggestDeckCompletionCmdArgs.userID,
+			Version:    suggestDeckCompletionCmdArgs.version,
+			Deck:       &deck,
+			OverlordId: suggestDeckCompletionCmdArgs.overlordID,
+		}
+		var result zb_calls.SuggestDeckCompletionResponse
+		_, err := commonTxObjs.contract.StaticCall("SuggestDeckCompletion", req, callerAddr, &result)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			err := battleground_utility.PrintProtoMessageAsJsonToStdout(&result)
+			if err != nil {
+				return err
+			}
+		default:
+			for _, deckCard := range result.Deck.Cards {
+				fmt.Printf("card: [%v], amount: %d\n", deckCard.CardKey.String(), deckCard.Amount)
+			}
+			for _, deckCard := range result.AddedCards {
+				fmt.Printf("added card: [%v], amount: %d\n", deckCard.CardKey.String(), deckCard.Amount)
+			}
+			if !result.Complete {
+				fmt.Println("not enough cards in collection to complete the deck")
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(suggestDeckCompletionCmd)
+
+	suggestDeckCompletionCmd.Flags().StringVarP(&suggestDeckCompletionCmdArgs.userID, "userId", "u", "loom", "UserId of account")
+	suggestDeckCompletionCmd.Flags().StringVarP(&suggestDeckCompletionCmdArgs.data, "data", "d", "{\"name\": \"NewDeck\", \"cards\": []}", "Partial deck data in serialized json format")
+	suggestDeckCompletionCmd.Flags().StringVarP(&suggestDeckCompletionCmdArgs.version, "version", "v", "v1", "Version")
+	suggestDeckCompletionCmd.Flags().Int64VarP(&suggestDeckCompletionCmdArgs.overlordID, "overlordId", "", 0, "Overlord the deck is built for")
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/loomnetwork/gamechain/types/oracle"
+	"strings"
+
+	loom "github.com/loomnetwork/go-loom"
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/loomnetwork/go-loom/types"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var updateOracleSetCmdArgs struct {
+	addMembers    []string
+	removeMembers []string
+	threshold     uint32
+}
+
+func parseOracleSetMembers(addressStrings []string) ([]*types.Address, error) {
+	members := make([]*types.Address, len(addressStrings))
+	for i, addressString := range addressStrings {
+		address, err := loom.ParseAddress(addressString)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse oracle address %s", addressString)
+		}
+		members[i] = address.MarshalPB()
+	}
+	return members, nil
+}
+
+var updateOracleSetCmd = &cobra.Command{
+	Use:   "update_oracle_set",
+	Short: "adds and removes members of the oracle set and sets the number of members that have to agree, 0 disables the set",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+
+		addMembers, err := parseOracleSetMembers(updateOracleSetCmdArgs.addMembers)
+		if err != nil {
+			return err
+		}
+
+		removeMembers, err := parseOracleSetMembers(updateOracleSetCmdArgs.removeMembers)
+		if err != nil {
+			return err
+		}
+
+		_, err = commonTxObjs.contract.Call("UpdateOracleSet", &oracle.UpdateOracleSetRequest{
+			AddMembers:    addMembers,
+			RemoveMembers: removeMembers,
+			Threshold:     updateOracleSetCmdArgs.threshold,
+		}, signer, nil)
+		if err != nil {
+			return errors.Wrap(err, "error when calling UpdateOracleSet")
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			output, err := json.Marshal(map[string]interface{}{"success": true})
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+		default:
+			fmt.Println("oracle set updated")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(updateOracleSetCmd)
+
+	updateOracleSetCmd.Flags().StringSliceVar(&updateOracleSetCmdArgs.addMembers, "addMembers", nil, "Addresses of the oracles to add to the set")
+	updateOracleSetCmd.Flags().StringSliceVar(&updateOracleSetCmdArgs.removeMembers, "removeMembers", nil, "Addresses of the oracles to remove from the set")
+	updateOracleSetCmd.Flags().Uint32VarP(&updateOracleSetCmdArgs.threshold, "threshold", "t", 0, "Number of members that have to submit identical event batches, 0 disables the set")
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/go-loom"
+	"strings"
+
+	"github.com/loomnetwork/go-loom/auth"
+	"github.com/spf13/cobra"
+)
+
+var validateDeckCmdArgs struct {
+	userID        string
+	deckID        int64
+	version       string
+	deckRulesName string
+}
+
+var validateDeckCmd = &cobra.Command{
+	Use:   "validate_deck",
+	Short: "checks a deck against the deck legality rules",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signer := auth.NewEd25519Signer(commonTxObjs.privateKey)
+		callerAddr := loom.Address{
+			ChainID: commonTxObjs.rpcClient.GetChainID(),
+			Local:   loom.LocalAddressFromPublicKey(signer.PublicKey()),
+		}
+
+		getDeckRequest := &zb_calls.GetDeckRequest{
+			UserId:  validateDeckCmdArgs.userID,
+			DeckId:  validateDeckCmdArgs.deckID,
+			Version: validateDeckCmdArgs.version,
+		}
+		var getDeckResult zb_calls.GetDeckResponse
+		_, err := commonTxObjs.contract.Call("GetDeck", getDeckRequest, signer, &getDeckResult)
+		if err != nil {
+			return err
+		}
+
+		req := &zb_calls.ValidateDeckRequest{
+			UserId:        validateDeckCmdArgs.userID,
+			Deck:          getDeckResult.Deck,
+			Version:       validateDeckCmdArgs.version,
+			DeckRulesName: validateDeckCmdArgs.deckRulesName,
+		}
+		var result zb_calls.ValidateDeckResponse
+		_, err = commonTxObjs.contract.StaticCall("ValidateDeck", req, callerAddr, &result)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(rootCmdArgs.outputFormat) {
+		case "json":
+			err := battleground_utility.PrintProtoMessageAsJsonToStdout(&result)
+			if err != nil {
+				return err
+			}
+		default:
+			fmt.Printf("legal: %v\n", result.Legal)
+			for _, issue := range result.Issues {
+				fmt.Printf("%s: %s\n", issue.Reason.String(), issue.Message)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateDeckCmd)
+
+	validateDeckCmd.Flags().StringVarP(&validateDeckCmdArgs.userID, "userId", "u", "loom", "UserId of account")
+	validateDeckCmd.Flags().Int64VarP(&validateDeckCmdArgs.deckID, "deckId", "", 0, "DeckId of account")
+	validateDeckCmd.Flags().StringVarP(&validateDeckCmdArgs.version, "version", "v", "v1", "Version")
+	validateDeckCmd.Flags().StringVarP(&validateDeckCmdArgs.deckRulesName, "rules", "", "", "Name of the deck rules, default rules are used if not set")
+
+	_ = validateDeckCmd.MarkFlagRequired("deckId")
+}
@@ -0,0 +1,347 @@
+package oracle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	orctype "github.com/loomnetwork/gamechain/types/oracle"
+	"github.com/loomnetwork/go-loom"
+	ltypes "github.com/loomnetwork/go-loom/types"
+	"github.com/pkg/errors"
+)
+
+// Maximum number of addresses sent in a single RequestFullCardCollectionSyncBatch call
+const backfillSyncBatchSize = 100
+
+type BackfillConfig struct {
+	// Plasmachain blocks to backfill, inclusive
+	FromBlock uint64
+	ToBlock   uint64
+	// Number of blocks fetched from Plasmachain at once
+	BlockRange uint64
+	// File that keeps the progress, so an interrupted backfill resumes where it stopped. Not kept if empty.
+	CheckpointPath string
+	// Queue a full card collection sync for every address in the events instead of replaying the events
+	ResyncCollections bool
+	// Replay the events even if Gamechain already processed blocks after the start of the range,
+	// moving its last processed block back. The events older than the window of applied events are applied again.
+	Force bool
+	// Called after each backfilled block range, optional
+	OnProgress func(progress BackfillProgress)
+}
+
+type BackfillProgress struct {
+	FromBlock uint64
+	ToBlock   uint64
+	// Last block that was backfilled
+	LastBlock uint64
+	// Number of events found by this run
+	EventCount int
+	Elapsed    time.Duration
+}
+
+// backfillCheckpoint is saved after every step, the backfill resumes from NextBlock
+type backfillCheckpoint struct {
+	FromBlock         uint64 `json:"fromBlock"`
+	ToBlock           uint64 `json:"toBlock"`
+	ResyncCollections bool   `json:"resyncCollections"`
+	NextBlock         uint64 `json:"nextBlock"`
+}
+
+// loadBackfillCheckpoint returns nil if there's no checkpoint
+func loadBackfillCheckpoint(path string) (*backfillCheckpoint, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to read backfill checkpoint")
+	}
+
+	var checkpoint backfillCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, errors.Wrap(err, "failed to parse backfill checkpoint")
+	}
+	return &checkpoint, nil
+}
+
+// save writes a temporary file first, so the previous checkpoint stays intact if writing fails
+func (c *backfillCheckpoint) save(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode backfill checkpoint")
+	}
+
+	tmpPath := path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, data, 0644); err != nil {
+		return errors.Wrap(err, "failed to write backfill checkpoint")
+	}
+	return errors.Wrap(os.Rename(tmpPath, path), "failed to replace backfill checkpoint")
+}
+
+// Backfill replays the Plasmachain events of a block range to Gamechain as fast as possible, or queues
+// full card collection syncs for the addresses in the events. A backfill that fails or is cancelled
+// can be run again with the same range to resume from its checkpoint.
+func (orc *Oracle) Backfill(ctx context.Context, cfg BackfillConfig) error {
+	if cfg.FromBlock == 0 || cfg.FromBlock > cfg.ToBlock {
+		return errors.Errorf("invalid block range %d-%d", cfg.FromBlock, cfg.ToBlock)
+	}
+	if cfg.BlockRange == 0 {
+		return errors.New("block range must be > 0")
+	}
+	// in an oracle set the batches are only votes, so the backfill couldn't tell whether they were applied
+	if orc.oracleSetMode {
+		return errors.New("backfill isn't supported in oracle set mode")
+	}
+
+	if err := orc.connect(); err != nil {
+		return err
+	}
+
+	latestBlock, err := orc.getLatestEthBlockNumber()
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain latest Plasmachain block number")
+	}
+	if latestBlock < orc.confirmations || cfg.ToBlock > latestBlock-orc.confirmations {
+		return errors.Errorf("block %d doesn't have %d confirmations yet, the latest Plasmachain block is %d",
+			cfg.ToBlock, orc.confirmations, latestBlock)
+	}
+
+	checkpoint, err := loadBackfillCheckpoint(cfg.CheckpointPath)
+	if err != nil {
+		return err
+	}
+
+	if checkpoint == nil {
+		checkpoint = &backfillCheckpoint{
+			FromBlock:         cfg.FromBlock,
+			ToBlock:           cfg.ToBlock,
+			ResyncCollections: cfg.ResyncCollections,
+			NextBlock:         cfg.FromBlock,
+		}
+
+		if err := orc.prepareBackfill(cfg, checkpoint.NextBlock); err != nil {
+			return err
+		}
+
+		// The journal may still list the events applied before a reset, they have to be submitted again.
+		if !cfg.ResyncCollections {
+			if err := orc.journal.removeAfter(cfg.FromBlock - 1); err != nil {
+				return errors.Wrap(err, "failed to remove the backfilled events from the journal")
+			}
+		}
+
+		if err := checkpoint.save(cfg.CheckpointPath); err != nil {
+			return err
+		}
+	} else if checkpoint.FromBlock != cfg.FromBlock ||
+		checkpoint.ToBlock != cfg.ToBlock ||
+		checkpoint.ResyncCollections != cfg.ResyncCollections {
+		return errors.Errorf("checkpoint %s belongs to another backfill of blocks %d-%d, remove it to start a new backfill",
+			cfg.CheckpointPath, checkpoint.FromBlock, checkpoint.ToBlock)
+	} else {
+		orc.logger.Info("resuming backfill", "nextBlock", checkpoint.NextBlock, "toBlock", checkpoint.ToBlock)
+		if checkpoint.NextBlock <= cfg.ToBlock {
+			if err := orc.prepareBackfill(cfg, checkpoint.NextBlock); err != nil {
+				return err
+			}
+		}
+	}
+
+	begin := time.Now()
+	eventCount := 0
+	syncedAddresses := make(map[string]bool)
+	for checkpoint.NextBlock <= cfg.ToBlock {
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "backfill stopped before block %d", checkpoint.NextBlock)
+		}
+
+		startBlock := checkpoint.NextBlock
+		endBlock := startBlock + cfg.BlockRange - 1
+		if endBlock > cfg.ToBlock {
+			endBlock = cfg.ToBlock
+		}
+
+		eventInfos, err := orc.fetchEvents(startBlock, endBlock)
+		if err != nil {
+			return err
+		}
+		eventCount += len(eventInfos)
+		orc.numPlasmachainEventsFetched = orc.numPlasmachainEventsFetched + uint64(len(eventInfos))
+		orc.updateStatus()
+
+		if cfg.ResyncCollections {
+			// burned packs aren't part of the card collections, they would be lost
+			for _, eventInfo := range eventInfos {
+				if eventInfo.Event.GetPackBurn() != nil {
+					return errors.Errorf("block %d burns packs, backfill it without resyncing collections", eventInfo.BlockNum)
+				}
+			}
+
+			if err := orc.requestFullCardCollectionSyncs(eventInfos, syncedAddresses); err != nil {
+				return err
+			}
+
+			checkpoint.NextBlock = endBlock + 1
+			if err := checkpoint.save(cfg.CheckpointPath); err != nil {
+				return err
+			}
+		} else {
+			for _, batch := range splitEventBatches(eventInfos, startBlock, endBlock, orc.maxBatchEventCount, orc.maxBatchSize) {
+				if _, err := orc.submitEventBatch(batch); err != nil {
+					return errors.Wrapf(err, "failed to submit blocks %d-%d", batch.StartBlock, batch.EndBlock)
+				}
+
+				checkpoint.NextBlock = batch.EndBlock + 1
+				if err := checkpoint.save(cfg.CheckpointPath); err != nil {
+					return err
+				}
+			}
+		}
+
+		orc.logger.Info("backfilled blocks",
+			"startBlock", startBlock,
+			"endBlock", endBlock,
+			"toBlock", cfg.ToBlock,
+			"eventCount", len(eventInfos),
+		)
+		if cfg.OnProgress != nil {
+			cfg.OnProgress(BackfillProgress{
+				FromBlock:  cfg.FromBlock,
+				ToBlock:    cfg.ToBlock,
+				LastBlock:  endBlock,
+				EventCount: eventCount,
+				Elapsed:    time.Since(begin),
+			})
+		}
+	}
+
+	// the synced collections already contain the changes of the range, so the events aren't processed again
+	if cfg.ResyncCollections {
+		lastPlasmachainBlockNumber, err := orc.gcGateway.GetLastPlasmaBlockNumber()
+		if err != nil {
+			return errors.Wrap(err, "failed to obtain last Plasmachain block number from Gamechain")
+		}
+
+		if lastPlasmachainBlockNumber+1 < cfg.FromBlock {
+			return errors.Errorf("last processed Plasmachain block on Gamechain moved back to %d, blocks before %d weren't processed",
+				lastPlasmachainBlockNumber, cfg.FromBlock)
+		}
+
+		if lastPlasmachainBlockNumber < cfg.ToBlock {
+			if err := orc.gcGateway.SetLastPlasmaBlockNumber(cfg.ToBlock); err != nil {
+				return errors.Wrap(err, "failed to set the last processed Plasmachain block")
+			}
+		}
+	}
+
+	orc.logger.Info("backfill finished", "fromBlock", cfg.FromBlock, "toBlock", cfg.ToBlock, "eventCount", eventCount)
+	return nil
+}
+
+// prepareBackfill checks that Gamechain processed the blocks before nextBlock, so the backfill doesn't skip any block.
+// The events are replayed on top of the last processed block, which has to be the block before nextBlock,
+// unless cfg.Force moves it back. Resyncing collections only needs the blocks before the range to be processed.
+func (orc *Oracle) prepareBackfill(cfg BackfillConfig, nextBlock uint64) error {
+	lastPlasmachainBlockNumber, err := orc.gcGateway.GetLastPlasmaBlockNumber()
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain last Plasmachain block number from Gamechain")
+	}
+
+	if cfg.ResyncCollections {
+		if lastPlasmachainBlockNumber+1 < cfg.FromBlock {
+			return errors.Errorf("last processed Plasmachain block on Gamechain is %d, blocks %d-%d would be skipped",
+				lastPlasmachainBlockNumber, lastPlasmachainBlockNumber+1, cfg.FromBlock-1)
+		}
+		return nil
+	}
+
+	if lastPlasmachainBlockNumber+1 == nextBlock {
+		return nil
+	}
+
+	if lastPlasmachainBlockNumber+1 < nextBlock {
+		return errors.Errorf("last processed Plasmachain block on Gamechain is %d, blocks %d-%d would be skipped",
+			lastPlasmachainBlockNumber, lastPlasmachainBlockNumber+1, nextBlock-1)
+	}
+
+	if !cfg.Force {
+		return errors.Errorf("last processed Plasmachain block on Gamechain is %d, the backfill would apply blocks %d-%d again, use --force to replay them anyway",
+			lastPlasmachainBlockNumber, nextBlock, lastPlasmachainBlockNumber)
+	}
+
+	orc.logger.Warn("moving the last processed Plasmachain block back",
+		"lastPlasmachainBlockNumber", lastPlasmachainBlockNumber,
+		"nextBlock", nextBlock,
+	)
+	if err := orc.gcGateway.SetLastPlasmaBlockNumber(nextBlock - 1); err != nil {
+		return errors.Wrap(err, "failed to set the last processed Plasmachain block")
+	}
+	return nil
+}
+
+// requestFullCardCollectionSyncs queues a sync for the addresses in the events that weren't synced yet
+func (orc *Oracle) requestFullCardCollectionSyncs(eventInfos []*plasmachainEventInfo, syncedAddresses map[string]bool) error {
+	var addresses []loom.Address
+	for _, eventInfo := range eventInfos {
+		for _, addressPB := range getPlasmachainEventAddresses(eventInfo.Event) {
+			if addressPB == nil || isZeroLocalAddress(addressPB.Local) || bytes.Equal(addressPB.Local, orc.pcZbgCardContractAddress.Local) {
+				continue
+			}
+
+			address := loom.UnmarshalAddressPB(addressPB)
+			if !syncedAddresses[address.String()] {
+				syncedAddresses[address.String()] = true
+				addresses = append(addresses, address)
+			}
+		}
+	}
+
+	for start := 0; start < len(addresses); start += backfillSyncBatchSize {
+		end := start + backfillSyncBatchSize
+		if end > len(addresses) {
+			end = len(addresses)
+		}
+
+		if err := orc.gcGateway.RequestFullCardCollectionSyncBatch(addresses[start:end]); err != nil {
+			return errors.Wrap(err, "failed to request full card collection syncs")
+		}
+	}
+
+	return nil
+}
+
+func getPlasmachainEventAddresses(event *orctype.PlasmachainEvent) []*ltypes.Address {
+	switch payload := event.Payload.(type) {
+	case *orctype.PlasmachainEvent_Transfer:
+		return []*ltypes.Address{payload.Transfer.From, payload.Transfer.To}
+	case *orctype.PlasmachainEvent_TransferWithQuantity:
+		return []*ltypes.Address{payload.TransferWithQuantity.From, payload.TransferWithQuantity.To}
+	case *orctype.PlasmachainEvent_BatchTransfer:
+		return []*ltypes.Address{payload.BatchTransfer.From, payload.BatchTransfer.To}
+	default:
+		return nil
+	}
+}
+
+// isZeroLocalAddress is true for the address cards are minted from and burned to
+func isZeroLocalAddress(local loom.LocalAddress) bool {
+	for _, b := range local {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package oracle
+
+import (
+	"github.com/gogo/protobuf/proto"
+	orctype "github.com/loomnetwork/gamechain/types/oracle"
+)
+
+// eventBatch is a range of Plasmachain blocks whose events are submitted to Gamechain in a single tx
+type eventBatch struct {
+	StartBlock uint64
+	EndBlock   uint64
+	EventInfos []*plasmachainEventInfo
+}
+
+func (b *eventBatch) events() []*orctype.PlasmachainEvent {
+	events := make([]*orctype.PlasmachainEvent, len(b.EventInfos))
+	for i, eventInfo := range b.EventInfos {
+		events[i] = eventInfo.Event
+	}
+	return events
+}
+
+// splitEventBatches splits the events from startBlock to endBlock, sorted by block, into batches of at most
+// maxEventCount events and maxSize bytes (no limit if 0). Batches end at block boundaries, so a block that exceeds
+// the limits on its own gets a batch of its own. The batches cover the whole block range, even without events.
+func splitEventBatches(eventInfos []*plasmachainEventInfo, startBlock, endBlock uint64, maxEventCount int, maxSize int) []*eventBatch {
+	var batches []*eventBatch
+	batch := &eventBatch{StartBlock: startBlock}
+	batchSize := 0
+
+	for i := 0; i < len(eventInfos); {
+		// the events of a block are never split between batches
+		blockNumber := eventInfos[i].BlockNum
+		blockEnd := i
+		blockSize := 0
+		for ; blockEnd < len(eventInfos) && eventInfos[blockEnd].BlockNum == blockNumber; blockEnd++ {
+			blockSize += proto.Size(eventInfos[blockEnd].Event)
+		}
+		blockEventInfos := eventInfos[i:blockEnd]
+		i = blockEnd
+
+		exceedsEventCount := maxEventCount > 0 && len(batch.EventInfos)+len(blockEventInfos) > maxEventCount
+		exceedsSize := maxSize > 0 && batchSize+blockSize > maxSize
+		if len(batch.EventInfos) > 0 && (exceedsEventCount || exceedsSize) {
+			batch.EndBlock = blockNumber - 1
+			batches = append(batches, batch)
+			batch = &eventBatch{StartBlock: blockNumber}
+			batchSize = 0
+		}
+
+		batch.EventInfos = append(batch.EventInfos, blockEventInfos...)
+		batchSize += blockSize
+	}
+
+	batch.EndBlock = endBlock
+	return append(batches, batch)
+}
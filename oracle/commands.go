@@ -0,0 +1,167 @@
+package oracle
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	orctype "github.com/loomnetwork/gamechain/types/oracle"
+	"github.com/loomnetwork/go-loom"
+	"github.com/pkg/errors"
+)
+
+// commandExecutor executes a single Gamechain command and returns the response command
+type commandExecutor func(orc *Oracle, commandRequest *orctype.OracleCommandRequest, latestPlasmaBlock uint64) (*orctype.OracleCommandResponse, error)
+
+// permanentCommandError is returned by the executors when retrying the command won't help
+type permanentCommandError struct {
+	error
+}
+
+func newPermanentCommandError(format string, args ...interface{}) error {
+	return permanentCommandError{fmt.Errorf(format, args...)}
+}
+
+func isPermanentCommandError(err error) bool {
+	_, ok := errors.Cause(err).(permanentCommandError)
+	return ok
+}
+
+// commandExecutors maps the command request types to their executors
+var commandExecutors = map[reflect.Type]commandExecutor{
+	reflect.TypeOf(&orctype.OracleCommandRequest_GetUserFullCardCollection{}):    executeGetUserFullCardCollectionCommand,
+	reflect.TypeOf(&orctype.OracleCommandRequest_VerifyMintingReceiptConsumed{}): executeVerifyMintingReceiptConsumedCommand,
+	reflect.TypeOf(&orctype.OracleCommandRequest_GetPackBalances{}):              executeGetPackBalancesCommand,
+}
+
+// executeCommand never fails, errors are reported to Gamechain as failure responses
+func (orc *Oracle) executeCommand(commandRequest *orctype.OracleCommandRequest, latestPlasmaBlock uint64) *orctype.OracleCommandResponse {
+	commandType := reflect.TypeOf(commandRequest.GetCommand())
+
+	var err error
+	defer func(begin time.Time) {
+		orc.metrics.CommandExecuted(begin, commandTypeName(commandType), err)
+	}(time.Now())
+
+	executor, exists := commandExecutors[commandType]
+	if exists {
+		var commandResponse *orctype.OracleCommandResponse
+		commandResponse, err = executor(orc, commandRequest, latestPlasmaBlock)
+		if err == nil {
+			commandResponse.CommandId = commandRequest.CommandId
+			return commandResponse
+		}
+	} else {
+		err = newPermanentCommandError("unknown command type %v", commandType)
+	}
+
+	orc.logger.Warn("failed to execute command", "commandId", commandRequest.CommandId, "commandType", fmt.Sprint(commandType), "err", err)
+	return &orctype.OracleCommandResponse{
+		CommandId: commandRequest.CommandId,
+		Command: &orctype.OracleCommandResponse_Failure{
+			Failure: &orctype.OracleCommandResponse_CommandFailure{
+				Error:     err.Error(),
+				Permanent: isPermanentCommandError(err),
+			},
+		},
+	}
+}
+
+// commandTypeName returns the name of the command without the oneof wrapper prefix, e.g. GetPackBalances
+func commandTypeName(commandType reflect.Type) string {
+	if commandType == nil || commandType.Kind() != reflect.Ptr {
+		return "Unknown"
+	}
+	return strings.TrimPrefix(commandType.Elem().Name(), "OracleCommandRequest_")
+}
+
+func executeGetUserFullCardCollectionCommand(orc *Oracle, commandRequest *orctype.OracleCommandRequest, latestPlasmaBlock uint64) (*orctype.OracleCommandResponse, error) {
+	userAddress := commandRequest.GetGetUserFullCardCollection().UserAddress
+	if userAddress == nil {
+		return nil, newPermanentCommandError("user address is missing")
+	}
+
+	tokensOwned, err := orc.pcGateway.GetTokensOwned(loom.UnmarshalAddressPB(userAddress).Local)
+	if err != nil {
+		return nil, err
+	}
+
+	orc.logger.Info("GetUserFullCardCollection", "userAddress", loom.UnmarshalAddressPB(userAddress), "tokensOwned", len(tokensOwned))
+
+	response := &orctype.OracleCommandResponse_GetUserFullCardCollectionCommandResponse{
+		UserAddress: userAddress,
+		BlockHeight: latestPlasmaBlock,
+	}
+
+	for _, tokensOwnedResponseItem := range tokensOwned {
+		response.OwnedCards = append(response.OwnedCards, &orctype.RawCardCollectionCard{
+			CardTokenId: battleground_utility.MarshalBigIntProto(tokensOwnedResponseItem.Index),
+			Amount:      battleground_utility.MarshalBigIntProto(tokensOwnedResponseItem.Balance),
+		})
+	}
+
+	return &orctype.OracleCommandResponse{
+		Command: &orctype.OracleCommandResponse_GetUserFullCardCollection{
+			GetUserFullCardCollection: response,
+		},
+	}, nil
+}
+
+func executeVerifyMintingReceiptConsumedCommand(orc *Oracle, commandRequest *orctype.OracleCommandRequest, latestPlasmaBlock uint64) (*orctype.OracleCommandResponse, error) {
+	request := commandRequest.GetVerifyMintingReceiptConsumed()
+	if request.TxId == nil {
+		return nil, newPermanentCommandError("txId is missing")
+	}
+
+	consumed, err := orc.pcGateway.IsMintingReceiptConsumed(request.TxId.Value.Int)
+	if err != nil {
+		return nil, err
+	}
+
+	orc.logger.Info("VerifyMintingReceiptConsumed", "userId", request.UserId, "txId", request.TxId.Value.String(), "consumed", consumed)
+
+	return &orctype.OracleCommandResponse{
+		Command: &orctype.OracleCommandResponse_VerifyMintingReceiptConsumed{
+			VerifyMintingReceiptConsumed: &orctype.OracleCommandResponse_VerifyMintingReceiptConsumedCommandResponse{
+				UserId:      request.UserId,
+				TxId:        request.TxId,
+				Consumed:    consumed,
+				BlockHeight: latestPlasmaBlock,
+			},
+		},
+	}, nil
+}
+
+func executeGetPackBalancesCommand(orc *Oracle, commandRequest *orctype.OracleCommandRequest, latestPlasmaBlock uint64) (*orctype.OracleCommandResponse, error) {
+	userAddress := commandRequest.GetGetPackBalances().UserAddress
+	if userAddress == nil {
+		return nil, newPermanentCommandError("user address is missing")
+	}
+
+	packBalances, err := orc.pcGateway.GetPackBalances(loom.UnmarshalAddressPB(userAddress).Local)
+	if err != nil {
+		return nil, err
+	}
+
+	orc.logger.Info("GetPackBalances", "userAddress", loom.UnmarshalAddressPB(userAddress), "packBalances", len(packBalances))
+
+	response := &orctype.OracleCommandResponse_GetPackBalancesCommandResponse{
+		UserAddress: userAddress,
+		BlockHeight: latestPlasmaBlock,
+	}
+
+	for _, packBalance := range packBalances {
+		response.PackBalances = append(response.PackBalances, &orctype.RawPackBalance{
+			PackType: packBalance.PackType,
+			Amount:   battleground_utility.MarshalBigIntProto(packBalance.Balance),
+		})
+	}
+
+	return &orctype.OracleCommandResponse{
+		Command: &orctype.OracleCommandResponse_GetPackBalances{
+			GetPackBalances: response,
+		},
+	}, nil
+}
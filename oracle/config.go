@@ -8,8 +8,23 @@ type Config struct {
 	PlasmachainWriteURI                  string
 	PlasmachainEventsURI                 string
 	PlasmachainZbgCardContractHexAddress string
-	PlasmachainPollInterval              int // in second
-	PlasmachainMaxBlockRange             int
+	// Used by the minting receipt verification command, the command fails if empty.
+	PlasmachainFiatPurchaseContractHexAddress string
+	// Pack contract addresses by pack type name (Booster, Super, etc.), used by the pack balances command.
+	PlasmachainPackContractHexAddresses map[string]string
+	PlasmachainPollInterval             int // in second
+	PlasmachainMaxBlockRange            int
+	// Maximum number of events and size in bytes of a single event batch submitted to Gamechain, no limit if 0.
+	// The events of a block are always submitted together.
+	PlasmachainMaxBatchEventCount int
+	PlasmachainMaxBatchSize       int
+	// Number of failed submissions after which a batch is submitted block by block,
+	// and the events of a block are quarantined. Failing batches are retried forever if 0.
+	PlasmachainMaxBatchAttempts int
+	// Number of blocks an event has to be buried under before it's submitted to Gamechain.
+	PlasmachainConfirmations int
+	// Number of last processed Plasmachain blocks whose hashes are checked for reorgs.
+	PlasmachainReorgTrackingDepth int
 	// Gamechain
 	GamechainPrivateKey   string
 	GamechainChainID      string
@@ -17,6 +32,16 @@ type Config struct {
 	GamechainWriteURI     string
 	GamechainEventsURI    string
 	GamechainContractName string
+	// Number of seconds between the sweeps of stale player pool entries, sweeping is disabled if 0.
+	GamechainPlayerPoolSweepInterval int
+	// Number of seconds between the checks of pending minting receipts for redemption and expiry, checking is disabled if 0.
+	GamechainMintingReceiptSweepInterval int
+	// Run as a member of the Gamechain oracle set: block ranges end at multiples of PlasmachainMaxBlockRange,
+	// ranges without events are submitted as empty batches, and the journal isn't used to skip events,
+	// so every member submits identical batches.
+	GamechainOracleSetMode bool
+	// Execute the Gamechain oracle commands, which requires the oracle key.
+	GamechainCommandsEnabled bool
 	// Oracle log verbosity (debug, info, error, etc.)
 	OracleLogLevel       string
 	OracleLogDestination string
@@ -26,6 +51,11 @@ type Config struct {
 	OracleReconnectInterval int32
 	// Address on from which the out-of-process Oracle should expose the status & metrics endpoints.
 	OracleQueryAddress string
+	// File that keeps the event batches applied by Gamechain across restarts, the journal is only kept in memory if empty.
+	OracleJournalPath string
+	// Number of blocks the last processed Plasmachain block can be behind the latest one before the oracle
+	// is reported as unhealthy, the lag is ignored if 0.
+	OracleMaxPlasmachainBlockLag int
 }
 
 func DefaultConfig() *Config {
@@ -37,11 +67,21 @@ func DefaultConfig() *Config {
 		PlasmachainZbgCardContractHexAddress: "0xC5dFc9282BF68DFAd041a04a0c09bE927b093992",
 		PlasmachainPollInterval:              10,
 		PlasmachainMaxBlockRange:             20,
+		PlasmachainMaxBatchEventCount:        200,
+		PlasmachainMaxBatchSize:              256 * 1024,
+		PlasmachainMaxBatchAttempts:          5,
+		PlasmachainConfirmations:             6,
+		PlasmachainReorgTrackingDepth:        50,
 		GamechainChainID:                     "default",
 		GamechainReadURI:                     "http://127.0.0.1:46658/query",
 		GamechainWriteURI:                    "http://127.0.0.1:46658/rpc",
 		GamechainEventsURI:                   "ws://127.0.0.1:%d/queryws",
 		GamechainContractName:                "zombiebattleground",
+		GamechainPlayerPoolSweepInterval:     60,
+		GamechainMintingReceiptSweepInterval: 300,
+		GamechainCommandsEnabled:             true,
 		OracleReconnectInterval:              5,
+		OracleJournalPath:                    "oracle-journal.jsonl",
+		OracleMaxPlasmachainBlockLag:         100,
 	}
 }
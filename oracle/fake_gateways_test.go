@@ -0,0 +1,476 @@
+package oracle
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	orctype "github.com/loomnetwork/gamechain/types/oracle"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	"github.com/loomnetwork/go-loom"
+)
+
+var (
+	_ plasmachainSource = &fakePlasmachain{}
+	_ gamechainSink     = &fakeGamechain{}
+)
+
+// fakeFailures counts the calls of the fake gateway methods and fails the ones that were scripted to fail
+type fakeFailures struct {
+	methodToFailureCount map[string]int
+	methodToCallCount    map[string]int
+}
+
+// failNext makes the next count calls of the method fail
+func (f *fakeFailures) failNext(method string, count int) {
+	if f.methodToFailureCount == nil {
+		f.methodToFailureCount = make(map[string]int)
+	}
+
+	f.methodToFailureCount[method] += count
+}
+
+func (f *fakeFailures) callCount(method string) int {
+	return f.methodToCallCount[method]
+}
+
+func (f *fakeFailures) call(method string) error {
+	if f.methodToCallCount == nil {
+		f.methodToCallCount = make(map[string]int)
+	}
+	f.methodToCallCount[method]++
+
+	if f.methodToFailureCount[method] > 0 {
+		f.methodToFailureCount[method]--
+		return fmt.Errorf("scripted %s failure", method)
+	}
+
+	return nil
+}
+
+type fakePlasmachainBlock struct {
+	hash   []byte
+	events []*orctype.PlasmachainEvent
+}
+
+// fakePlasmachain is an in-memory Plasmachain, blocks are only produced when the test mines them
+type fakePlasmachain struct {
+	fakeFailures
+
+	address  loom.Address
+	lastSeen time.Time
+	// blocks[0] is block 1
+	blocks []*fakePlasmachainBlock
+	// changes the hashes of blocks mined after a reorg
+	reorgCount          int
+	ownerToTokensOwned  map[string][]tokensOwnedResponseItem
+	ownerToPackBalances map[string][]packBalance
+	consumedTxIds       map[string]bool
+}
+
+func newFakePlasmachain(address loom.Address) *fakePlasmachain {
+	return &fakePlasmachain{
+		address:             address,
+		ownerToTokensOwned:  make(map[string][]tokensOwnedResponseItem),
+		ownerToPackBalances: make(map[string][]packBalance),
+		consumedTxIds:       make(map[string]bool),
+	}
+}
+
+// mineBlock adds a block with the events and returns its number
+func (f *fakePlasmachain) mineBlock(events ...*orctype.PlasmachainEvent) uint64 {
+	blockNumber := uint64(len(f.blocks) + 1)
+	for _, event := range events {
+		event.EthBlock = blockNumber
+	}
+
+	f.blocks = append(f.blocks, &fakePlasmachainBlock{
+		hash:   []byte(fmt.Sprintf("block-%d-%d", blockNumber, f.reorgCount)),
+		events: events,
+	})
+	return blockNumber
+}
+
+func (f *fakePlasmachain) mineBlocks(count int) {
+	for i := 0; i < count; i++ {
+		f.mineBlock()
+	}
+}
+
+// reorg drops the blocks starting at blockNumber, the blocks mined afterwards get different hashes
+func (f *fakePlasmachain) reorg(blockNumber uint64) {
+	f.blocks = f.blocks[:blockNumber-1]
+	f.reorgCount++
+}
+
+func (f *fakePlasmachain) setTokensOwned(owner loom.Address, tokensOwned []tokensOwnedResponseItem) {
+	f.ownerToTokensOwned[hex.EncodeToString(owner.Local)] = tokensOwned
+}
+
+func (f *fakePlasmachain) setPackBalances(owner loom.Address, packBalances []packBalance) {
+	f.ownerToPackBalances[hex.EncodeToString(owner.Local)] = packBalances
+}
+
+func (f *fakePlasmachain) consumeMintingReceipt(txId *big.Int) {
+	f.consumedTxIds[txId.String()] = true
+}
+
+func (f *fakePlasmachain) GatewayAddress() loom.Address {
+	return f.address
+}
+
+func (f *fakePlasmachain) LastSeen() time.Time {
+	return f.lastSeen
+}
+
+func (f *fakePlasmachain) LastBlockNumber() (uint64, error) {
+	if err := f.call("LastBlockNumber"); err != nil {
+		return 0, err
+	}
+
+	f.lastSeen = time.Now()
+	return uint64(len(f.blocks)), nil
+}
+
+func (f *fakePlasmachain) BlockHash(blockNumber uint64) ([]byte, error) {
+	if err := f.call("BlockHash"); err != nil {
+		return nil, err
+	}
+
+	if blockNumber == 0 || blockNumber > uint64(len(f.blocks)) {
+		return nil, fmt.Errorf("block %d not found", blockNumber)
+	}
+
+	f.lastSeen = time.Now()
+	return f.blocks[blockNumber-1].hash, nil
+}
+
+func (f *fakePlasmachain) FetchEvents(startBlock, endBlock uint64) ([]*plasmachainEventInfo, error) {
+	if err := f.call("FetchEvents"); err != nil {
+		return nil, err
+	}
+
+	var events []*plasmachainEventInfo
+	for blockNumber := startBlock; blockNumber <= endBlock && blockNumber <= uint64(len(f.blocks)); blockNumber++ {
+		block := f.blocks[blockNumber-1]
+		for txIndex, event := range block.events {
+			event.TxIndex = uint64(txIndex)
+			event.LogIndex = uint64(txIndex)
+			events = append(events, &plasmachainEventInfo{
+				BlockNum:  blockNumber,
+				BlockHash: block.hash,
+				TxIdx:     uint(txIndex),
+				LogIdx:    uint(txIndex),
+				Kind:      getFakePlasmachainEventKind(event),
+				Event:     event,
+			})
+		}
+	}
+
+	f.lastSeen = time.Now()
+	return events, nil
+}
+
+func (f *fakePlasmachain) GetTokensOwned(owner loom.LocalAddress) ([]tokensOwnedResponseItem, error) {
+	if err := f.call("GetTokensOwned"); err != nil {
+		return nil, err
+	}
+
+	f.lastSeen = time.Now()
+	return f.ownerToTokensOwned[hex.EncodeToString(owner)], nil
+}
+
+func (f *fakePlasmachain) IsMintingReceiptConsumed(txId *big.Int) (bool, error) {
+	if err := f.call("IsMintingReceiptConsumed"); err != nil {
+		return false, err
+	}
+
+	f.lastSeen = time.Now()
+	return f.consumedTxIds[txId.String()], nil
+}
+
+func (f *fakePlasmachain) GetPackBalances(owner loom.LocalAddress) ([]packBalance, error) {
+	if err := f.call("GetPackBalances"); err != nil {
+		return nil, err
+	}
+
+	f.lastSeen = time.Now()
+	return f.ownerToPackBalances[hex.EncodeToString(owner)], nil
+}
+
+func getFakePlasmachainEventKind(event *orctype.PlasmachainEvent) string {
+	switch event.Payload.(type) {
+	case *orctype.PlasmachainEvent_Transfer:
+		return "Transfer"
+	case *orctype.PlasmachainEvent_TransferWithQuantity:
+		return "TransferWithQuantity"
+	case *orctype.PlasmachainEvent_BatchTransfer:
+		return "BatchTransfer"
+	case *orctype.PlasmachainEvent_PackBurn:
+		return "PackBurn"
+	default:
+		return ""
+	}
+}
+
+// fakeGamechain is an in-memory ZombieBattleground contract that only keeps track of what the oracle changes
+type fakeGamechain struct {
+	fakeFailures
+
+	address                    loom.Address
+	lastSeen                   time.Time
+	lastPlasmachainBlockNumber uint64
+	// card amounts by address and card token id
+	cardAmounts      map[string]int64
+	commandRequests  []*orctype.OracleCommandRequest
+	commandResponses []*orctype.OracleCommandResponse
+	evictedCount     int64
+	// burned pack amounts by address and pack type
+	burnedPackAmounts map[string]int64
+	// commands queued by SweepMintingReceipts
+	mintingReceiptCommandRequests []*orctype.OracleCommandRequest
+	// batches with events from these blocks always fail
+	poisonedBlocks    map[uint64]bool
+	quarantinedEvents []*orctype.PlasmachainEvent
+	// number of events and first and last block of each processed batch
+	batchEventCounts []int
+	batchBlockRanges [][2]uint64
+	// addresses passed to RequestFullCardCollectionSyncBatch
+	fullCardCollectionSyncAddresses []loom.Address
+	// number of the next batches and rollbacks that only count as an oracle set vote, without being processed
+	pendingVoteCount int
+}
+
+func newFakeGamechain(address loom.Address, lastPlasmachainBlockNumber uint64) *fakeGamechain {
+	return &fakeGamechain{
+		address:                    address,
+		lastPlasmachainBlockNumber: lastPlasmachainBlockNumber,
+		cardAmounts:                make(map[string]int64),
+		burnedPackAmounts:          make(map[string]int64),
+		poisonedBlocks:             make(map[uint64]bool),
+	}
+}
+
+func (f *fakeGamechain) cardAmount(address loom.Address, cardTokenId int64) int64 {
+	return f.cardAmounts[fmt.Sprintf("%s:%d", hex.EncodeToString(address.Local), cardTokenId)]
+}
+
+func (f *fakeGamechain) applyCardAmountChanges(cardAmountChanges []*orctype.PlasmachainCardAmountChange, sign int64) {
+	for _, change := range cardAmountChanges {
+		key := fmt.Sprintf("%s:%s", hex.EncodeToString(change.Address.Local), change.CardTokenId.Value.String())
+		f.cardAmounts[key] += sign * change.AmountChange
+	}
+}
+
+func (f *fakeGamechain) burnedPackAmount(address loom.Address, packType zb_enums.PackType_Enum) int64 {
+	return f.burnedPackAmounts[fmt.Sprintf("%s:%s", hex.EncodeToString(address.Local), packType.String())]
+}
+
+func (f *fakeGamechain) applyPackAmountChanges(packAmountChanges []*orctype.PlasmachainPackAmountChange, sign int64) {
+	for _, change := range packAmountChanges {
+		key := fmt.Sprintf("%s:%s", hex.EncodeToString(change.Address.Local), change.PackType.String())
+		f.burnedPackAmounts[key] += sign * change.AmountChange
+	}
+}
+
+func (f *fakeGamechain) GatewayAddress() loom.Address {
+	return f.address
+}
+
+func (f *fakeGamechain) LastSeen() time.Time {
+	return f.lastSeen
+}
+
+func (f *fakeGamechain) GetLastPlasmaBlockNumber() (uint64, error) {
+	if err := f.call("GetLastPlasmaBlockNumber"); err != nil {
+		return 0, err
+	}
+
+	f.lastSeen = time.Now()
+	return f.lastPlasmachainBlockNumber, nil
+}
+
+func (f *fakeGamechain) SetLastPlasmaBlockNumber(lastBlock uint64) error {
+	if err := f.call("SetLastPlasmaBlockNumber"); err != nil {
+		return err
+	}
+
+	f.lastPlasmachainBlockNumber = lastBlock
+	f.lastSeen = time.Now()
+	return nil
+}
+
+// ProcessOracleEventBatch skips the already processed blocks like the contract does
+func (f *fakeGamechain) ProcessOracleEventBatch(events []*orctype.PlasmachainEvent, startBlock uint64, endBlock uint64, zbgCardContractAddress loom.Address) error {
+	if err := f.call("ProcessOracleEventBatch"); err != nil {
+		return err
+	}
+
+	if f.pendingVoteCount > 0 {
+		f.pendingVoteCount--
+		f.lastSeen = time.Now()
+		return nil
+	}
+
+	var newEvents []*orctype.PlasmachainEvent
+	for _, event := range events {
+		if f.poisonedBlocks[event.EthBlock] {
+			return fmt.Errorf("poisoned event in block %d", event.EthBlock)
+		}
+
+		if event.EthBlock > f.lastPlasmachainBlockNumber {
+			newEvents = append(newEvents, event)
+		}
+	}
+
+	// the compensating changes undo the events, so they are applied negated
+	f.applyCardAmountChanges(getCompensatingCardAmountChanges(newEvents, zbgCardContractAddress), -1)
+	f.applyPackAmountChanges(getCompensatingPackAmountChanges(newEvents), -1)
+	f.batchEventCounts = append(f.batchEventCounts, len(events))
+	f.batchBlockRanges = append(f.batchBlockRanges, [2]uint64{startBlock, endBlock})
+	f.lastPlasmachainBlockNumber = endBlock
+	f.lastSeen = time.Now()
+	return nil
+}
+
+func (f *fakeGamechain) RollbackOracleEventBatch(
+	lastBlock uint64,
+	cardAmountChanges []*orctype.PlasmachainCardAmountChange,
+	packAmountChanges []*orctype.PlasmachainPackAmountChange,
+) (bool, error) {
+	if err := f.call("RollbackOracleEventBatch"); err != nil {
+		return false, err
+	}
+
+	if lastBlock >= f.lastPlasmachainBlockNumber {
+		return false, fmt.Errorf("can't roll back to block %d, last processed block is %d", lastBlock, f.lastPlasmachainBlockNumber)
+	}
+
+	if f.pendingVoteCount > 0 {
+		f.pendingVoteCount--
+		f.lastSeen = time.Now()
+		return false, nil
+	}
+
+	f.applyCardAmountChanges(cardAmountChanges, 1)
+	f.applyPackAmountChanges(packAmountChanges, 1)
+	f.lastPlasmachainBlockNumber = lastBlock
+	f.lastSeen = time.Now()
+	return true, nil
+}
+
+// QuarantineOracleEvents skips the blocks of the events without applying them like the contract does
+func (f *fakeGamechain) QuarantineOracleEvents(events []*orctype.PlasmachainEvent, lastBlock uint64, errorMessage string, zbgCardContractAddress loom.Address) error {
+	if err := f.call("QuarantineOracleEvents"); err != nil {
+		return err
+	}
+
+	if lastBlock <= f.lastPlasmachainBlockNumber {
+		return fmt.Errorf("can't quarantine up to block %d, last processed block is %d", lastBlock, f.lastPlasmachainBlockNumber)
+	}
+
+	f.quarantinedEvents = append(f.quarantinedEvents, events...)
+	f.lastPlasmachainBlockNumber = lastBlock
+	f.lastSeen = time.Now()
+	return nil
+}
+
+func (f *fakeGamechain) GetOracleCommandRequestList() ([]*orctype.OracleCommandRequest, error) {
+	if err := f.call("GetOracleCommandRequestList"); err != nil {
+		return nil, err
+	}
+
+	f.lastSeen = time.Now()
+	return f.commandRequests, nil
+}
+
+// ProcessOracleCommandResponseBatch removes the answered command requests like the contract does
+func (f *fakeGamechain) ProcessOracleCommandResponseBatch(commandResponses []*orctype.OracleCommandResponse) error {
+	if err := f.call("ProcessOracleCommandResponseBatch"); err != nil {
+		return err
+	}
+
+	answeredCommandIds := make(map[uint64]bool)
+	for _, commandResponse := range commandResponses {
+		answeredCommandIds[commandResponse.CommandId] = true
+	}
+
+	var commandRequests []*orctype.OracleCommandRequest
+	for _, commandRequest := range f.commandRequests {
+		if !answeredCommandIds[commandRequest.CommandId] {
+			commandRequests = append(commandRequests, commandRequest)
+		}
+	}
+
+	f.commandRequests = commandRequests
+	f.commandResponses = append(f.commandResponses, commandResponses...)
+	f.lastSeen = time.Now()
+	return nil
+}
+
+func (f *fakeGamechain) SweepPlayerPools() (*zb_calls.SweepPlayerPoolsResponse, error) {
+	if err := f.call("SweepPlayerPools"); err != nil {
+		return nil, err
+	}
+
+	f.lastSeen = time.Now()
+	return &zb_calls.SweepPlayerPoolsResponse{
+		EvictedEntryCount: f.evictedCount,
+	}, nil
+}
+
+// SweepMintingReceipts queues the prepared minting receipt commands
+func (f *fakeGamechain) SweepMintingReceipts() (*zb_calls.SweepMintingReceiptsResponse, error) {
+	if err := f.call("SweepMintingReceipts"); err != nil {
+		return nil, err
+	}
+
+	f.commandRequests = append(f.commandRequests, f.mintingReceiptCommandRequests...)
+	response := &zb_calls.SweepMintingReceiptsResponse{
+		VerificationRequestCount: int64(len(f.mintingReceiptCommandRequests)),
+	}
+	f.mintingReceiptCommandRequests = nil
+	f.lastSeen = time.Now()
+	return response, nil
+}
+
+func (f *fakeGamechain) RequestFullCardCollectionSyncBatch(addresses []loom.Address) error {
+	if err := f.call("RequestFullCardCollectionSyncBatch"); err != nil {
+		return err
+	}
+
+	f.fullCardCollectionSyncAddresses = append(f.fullCardCollectionSyncAddresses, addresses...)
+	f.lastSeen = time.Now()
+	return nil
+}
+
+var testOracleCount int32
+
+// newTestOracle creates an oracle that talks to the fake chains
+func newTestOracle(plasmachain *fakePlasmachain, gamechain *fakeGamechain, confirmations uint64) *Oracle {
+	// metrics are registered globally, so each oracle needs its own subsystem
+	metricSubsystem := fmt.Sprintf("oracle_test_%d", atomic.AddInt32(&testOracleCount, 1))
+
+	return &Oracle{
+		pcZbgCardContractAddress: plasmachain.address,
+		pcGateway:                plasmachain,
+		pcPollInterval:           10 * time.Millisecond,
+		gcGateway:                gamechain,
+		logger:                   loom.NewLoomLogger("error", ""),
+		reconnectInterval:        10 * time.Millisecond,
+		status: Status{
+			Version: "1.0.0",
+		},
+		metrics:            NewMetrics(metricSubsystem),
+		journal:            newSubmittedBatchJournal(""),
+		maxBlockRange:      20,
+		maxBatchEventCount: 200,
+		maxBatchAttempts:   3,
+		gcCommandsEnabled:  true,
+		confirmations:      confirmations,
+		processedBlocks:    newProcessedBlockTracker(10),
+	}
+}
@@ -8,9 +8,12 @@ import (
 	"github.com/loomnetwork/go-loom"
 	"github.com/loomnetwork/go-loom/auth"
 	"github.com/loomnetwork/go-loom/client"
+	ltypes "github.com/loomnetwork/go-loom/types"
 	"github.com/pkg/errors"
 )
 
+var _ gamechainSink = &GamechainGateway{}
+
 type GamechainGateway struct {
 	Address loom.Address
 	// Timestamp of the last successful response from the DAppChain
@@ -40,6 +43,14 @@ func ConnectToGamechainGateway(
 	}, nil
 }
 
+func (gw *GamechainGateway) GatewayAddress() loom.Address {
+	return gw.Address
+}
+
+func (gw *GamechainGateway) LastSeen() time.Time {
+	return gw.LastResponseTime
+}
+
 func (gw *GamechainGateway) GetLastPlasmaBlockNumber() (uint64, error) {
 	var req zb_calls.EmptyRequest
 	var resp zb_calls.GetContractStateResponse
@@ -94,13 +105,79 @@ func (gw *GamechainGateway) ProcessOracleCommandResponseBatch(commandResponses [
 	return nil
 }
 
-func (gw *GamechainGateway) ProcessOracleEventBatch(events []*orctype.PlasmachainEvent, endBlock uint64, zbgCardContractAddress loom.Address) error {
-	req := orctype.ProcessOracleEventBatchRequest{
+func (gw *GamechainGateway) SweepPlayerPools() (*zb_calls.SweepPlayerPoolsResponse, error) {
+	var req zb_calls.SweepPlayerPoolsRequest
+	var resp zb_calls.SweepPlayerPoolsResponse
+
+	if _, err := gw.contract.Call("SweepPlayerPools", &req, gw.signer, &resp); err != nil {
+		err = errors.Wrap(err, "failed to call SweepPlayerPools")
+		gw.logger.Error(err.Error())
+		return nil, err
+	}
+	gw.LastResponseTime = time.Now()
+	return &resp, nil
+}
+
+func (gw *GamechainGateway) QuarantineOracleEvents(
+	events []*orctype.PlasmachainEvent,
+	lastBlock uint64,
+	errorMessage string,
+	zbgCardContractAddress loom.Address,
+) error {
+	req := orctype.QuarantineOracleEventsRequest{
 		Events:                     events,
-		LastPlasmachainBlockNumber: endBlock,
+		LastPlasmachainBlockNumber: lastBlock,
+		Error:                      errorMessage,
 		ZbgCardContractAddress:     zbgCardContractAddress.MarshalPB(),
 	}
 
+	if _, err := gw.contract.Call("QuarantineOracleEvents", &req, gw.signer, nil); err != nil {
+		err = errors.Wrap(err, "failed to call QuarantineOracleEvents")
+		gw.logger.Error(err.Error())
+		return err
+	}
+	gw.LastResponseTime = time.Now()
+	return nil
+}
+
+func (gw *GamechainGateway) SweepMintingReceipts() (*zb_calls.SweepMintingReceiptsResponse, error) {
+	var req zb_calls.SweepMintingReceiptsRequest
+	var resp zb_calls.SweepMintingReceiptsResponse
+
+	if _, err := gw.contract.Call("SweepMintingReceipts", &req, gw.signer, &resp); err != nil {
+		err = errors.Wrap(err, "failed to call SweepMintingReceipts")
+		gw.logger.Error(err.Error())
+		return nil, err
+	}
+	gw.LastResponseTime = time.Now()
+	return &resp, nil
+}
+
+func (gw *GamechainGateway) RequestFullCardCollectionSyncBatch(addresses []loom.Address) error {
+	req := orctype.RequestFullCardCollectionSyncBatchRequest{
+		Addresses: make([]*ltypes.Address, len(addresses)),
+	}
+	for i, address := range addresses {
+		req.Addresses[i] = address.MarshalPB()
+	}
+
+	if _, err := gw.contract.Call("RequestFullCardCollectionSyncBatch", &req, gw.signer, nil); err != nil {
+		err = errors.Wrap(err, "failed to call RequestFullCardCollectionSyncBatch")
+		gw.logger.Error(err.Error())
+		return err
+	}
+	gw.LastResponseTime = time.Now()
+	return nil
+}
+
+func (gw *GamechainGateway) ProcessOracleEventBatch(events []*orctype.PlasmachainEvent, startBlock uint64, endBlock uint64, zbgCardContractAddress loom.Address) error {
+	req := orctype.ProcessOracleEventBatchRequest{
+		Events:                      events,
+		FirstPlasmachainBlockNumber: startBlock,
+		LastPlasmachainBlockNumber:  endBlock,
+		ZbgCardContractAddress:      zbgCardContractAddress.MarshalPB(),
+	}
+
 	if _, err := gw.contract.Call("ProcessOracleEventBatch", &req, gw.signer, nil); err != nil {
 		err = errors.Wrap(err, "failed to call ProcessOracleEventBatch")
 		gw.logger.Error(err.Error())
@@ -109,3 +186,24 @@ func (gw *GamechainGateway) ProcessOracleEventBatch(events []*orctype.Plasmachai
 	gw.LastResponseTime = time.Now()
 	return nil
 }
+
+func (gw *GamechainGateway) RollbackOracleEventBatch(
+	lastBlock uint64,
+	cardAmountChanges []*orctype.PlasmachainCardAmountChange,
+	packAmountChanges []*orctype.PlasmachainPackAmountChange,
+) (bool, error) {
+	req := orctype.RollbackOracleEventBatchRequest{
+		LastPlasmachainBlockNumber: lastBlock,
+		CardAmountChanges:          cardAmountChanges,
+		PackAmountChanges:          packAmountChanges,
+	}
+	var resp orctype.RollbackOracleEventBatchResponse
+
+	if _, err := gw.contract.Call("RollbackOracleEventBatch", &req, gw.signer, &resp); err != nil {
+		err = errors.Wrap(err, "failed to call RollbackOracleEventBatch")
+		gw.logger.Error(err.Error())
+		return false, err
+	}
+	gw.LastResponseTime = time.Now()
+	return resp.Applied, nil
+}
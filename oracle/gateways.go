@@ -0,0 +1,53 @@
+package oracle
+
+import (
+	"math/big"
+	"time"
+
+	orctype "github.com/loomnetwork/gamechain/types/oracle"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/go-loom"
+)
+
+// gateway is the part of a chain connection reported in the oracle status
+type gateway interface {
+	// GatewayAddress returns the address of the contract the gateway talks to
+	GatewayAddress() loom.Address
+	// LastSeen returns the time of the last successful response from the chain
+	LastSeen() time.Time
+}
+
+// plasmachainSource is where the oracle reads card transfers and balances from.
+// PlasmachainGateway implements it on top of the ZBGCard contract.
+type plasmachainSource interface {
+	gateway
+
+	LastBlockNumber() (uint64, error)
+	BlockHash(blockNumber uint64) ([]byte, error)
+	// FetchEvents returns the card transfer and pack burn events from startBlock to endBlock (inclusive), in any order
+	FetchEvents(startBlock, endBlock uint64) ([]*plasmachainEventInfo, error)
+	GetTokensOwned(owner loom.LocalAddress) ([]tokensOwnedResponseItem, error)
+	IsMintingReceiptConsumed(txId *big.Int) (bool, error)
+	GetPackBalances(owner loom.LocalAddress) ([]packBalance, error)
+}
+
+// gamechainSink is where the oracle submits Plasmachain events and command responses to.
+// GamechainGateway implements it on top of the ZombieBattleground contract.
+type gamechainSink interface {
+	gateway
+
+	GetLastPlasmaBlockNumber() (uint64, error)
+	SetLastPlasmaBlockNumber(lastBlock uint64) error
+	ProcessOracleEventBatch(events []*orctype.PlasmachainEvent, startBlock uint64, endBlock uint64, zbgCardContractAddress loom.Address) error
+	RollbackOracleEventBatch(lastBlock uint64, cardAmountChanges []*orctype.PlasmachainCardAmountChange, packAmountChanges []*orctype.PlasmachainPackAmountChange) (applied bool, err error)
+	// QuarantineOracleEvents records events that keep failing without applying them, skips their blocks,
+	// and queues a full card collection sync for the addresses in the events
+	QuarantineOracleEvents(events []*orctype.PlasmachainEvent, lastBlock uint64, errorMessage string, zbgCardContractAddress loom.Address) error
+	GetOracleCommandRequestList() ([]*orctype.OracleCommandRequest, error)
+	ProcessOracleCommandResponseBatch(commandResponses []*orctype.OracleCommandResponse) error
+	SweepPlayerPools() (*zb_calls.SweepPlayerPoolsResponse, error)
+	// SweepMintingReceipts expires the old pending minting receipts and queues redemption checks for the others
+	SweepMintingReceipts() (*zb_calls.SweepMintingReceiptsResponse, error)
+	// RequestFullCardCollectionSyncBatch queues a full card collection sync for each of the addresses
+	RequestFullCardCollectionSyncBatch(addresses []loom.Address) error
+}
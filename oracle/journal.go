@@ -0,0 +1,215 @@
+package oracle
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	orctype "github.com/loomnetwork/gamechain/types/oracle"
+	"github.com/pkg/errors"
+)
+
+// Number of Plasmachain blocks, counting back from the last submitted one, whose events are kept in the journal.
+// Matches the window of processed events kept by the contract.
+const journalWindowBlockCount = 1000
+
+type journalEventId struct {
+	Block    uint64 `json:"block"`
+	TxIndex  uint64 `json:"txIndex"`
+	LogIndex uint64 `json:"logIndex"`
+}
+
+// journalBatch is a batch of events that was applied by Gamechain
+type journalBatch struct {
+	EndBlock uint64           `json:"endBlock"`
+	EventIds []journalEventId `json:"eventIds"`
+}
+
+// submittedBatchJournal is an on-disk log of the event batches submitted to Gamechain, one JSON batch per line.
+// It survives restarts, so events that were already applied are never submitted again.
+// The journal only lives in memory if the path is empty.
+type submittedBatchJournal struct {
+	path     string
+	batches  []*journalBatch
+	eventIds map[journalEventId]bool
+}
+
+func newSubmittedBatchJournal(path string) *submittedBatchJournal {
+	return &submittedBatchJournal{
+		path:     path,
+		eventIds: make(map[journalEventId]bool),
+	}
+}
+
+func newJournalEventId(event *orctype.PlasmachainEvent) journalEventId {
+	return journalEventId{
+		Block:    event.EthBlock,
+		TxIndex:  event.TxIndex,
+		LogIndex: event.LogIndex,
+	}
+}
+
+// load reads the batches written by a previous run, a missing file is an empty journal
+func (j *submittedBatchJournal) load() error {
+	if j.path == "" {
+		return nil
+	}
+
+	file, err := os.Open(j.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrap(err, "failed to open journal")
+	}
+	defer file.Close()
+
+	var batches []*journalBatch
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var batch journalBatch
+		if err := json.Unmarshal(scanner.Bytes(), &batch); err != nil {
+			return errors.Wrapf(err, "failed to parse journal line %d", lineNumber)
+		}
+		batches = append(batches, &batch)
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrap(err, "failed to read journal")
+	}
+
+	j.setBatches(batches)
+	return nil
+}
+
+func (j *submittedBatchJournal) contains(event *orctype.PlasmachainEvent) bool {
+	return j.eventIds[newJournalEventId(event)]
+}
+
+// record appends a batch that was applied by Gamechain, and drops the batches that fell out of the window
+func (j *submittedBatchJournal) record(endBlock uint64, events []*orctype.PlasmachainEvent) error {
+	batch := &journalBatch{
+		EndBlock: endBlock,
+		EventIds: make([]journalEventId, len(events)),
+	}
+	for i, event := range events {
+		batch.EventIds[i] = newJournalEventId(event)
+	}
+
+	batches := append(j.batches, batch)
+	firstBatch := 0
+	if endBlock >= journalWindowBlockCount {
+		for firstBatch < len(batches) && batches[firstBatch].EndBlock <= endBlock-journalWindowBlockCount {
+			firstBatch++
+		}
+	}
+
+	if firstBatch > 0 {
+		j.setBatches(batches[firstBatch:])
+		return j.rewrite()
+	}
+
+	j.setBatches(batches)
+	return j.append(batch)
+}
+
+// removeAfter forgets the events of blocks after blockNumber, so blocks invalidated by a reorg are submitted again
+func (j *submittedBatchJournal) removeAfter(blockNumber uint64) error {
+	var batches []*journalBatch
+	for _, batch := range j.batches {
+		if batch.EndBlock <= blockNumber {
+			batches = append(batches, batch)
+			continue
+		}
+
+		// a batch can end after the reorg while some of its events are still valid
+		keptBatch := &journalBatch{EndBlock: blockNumber}
+		for _, eventId := range batch.EventIds {
+			if eventId.Block <= blockNumber {
+				keptBatch.EventIds = append(keptBatch.EventIds, eventId)
+			}
+		}
+		if len(keptBatch.EventIds) > 0 {
+			batches = append(batches, keptBatch)
+		}
+	}
+
+	j.setBatches(batches)
+	return j.rewrite()
+}
+
+func (j *submittedBatchJournal) setBatches(batches []*journalBatch) {
+	j.batches = batches
+	j.eventIds = make(map[journalEventId]bool)
+	for _, batch := range batches {
+		for _, eventId := range batch.EventIds {
+			j.eventIds[eventId] = true
+		}
+	}
+}
+
+func (j *submittedBatchJournal) append(batch *journalBatch) error {
+	if j.path == "" {
+		return nil
+	}
+
+	line, err := json.Marshal(batch)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode journal batch")
+	}
+
+	file, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrap(err, "failed to open journal")
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return errors.Wrap(err, "failed to write journal")
+	}
+	return file.Sync()
+}
+
+// rewrite replaces the journal file with the batches in memory, the old file stays intact if writing fails
+func (j *submittedBatchJournal) rewrite() error {
+	if j.path == "" {
+		return nil
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(j.path), filepath.Base(j.path)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to create journal")
+	}
+	defer os.Remove(file.Name())
+
+	writer := bufio.NewWriter(file)
+	for _, batch := range j.batches {
+		line, err := json.Marshal(batch)
+		if err != nil {
+			file.Close()
+			return errors.Wrap(err, "failed to encode journal batch")
+		}
+		fmt.Fprintf(writer, "%s\n", line)
+	}
+
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return errors.Wrap(err, "failed to write journal")
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return errors.Wrap(err, "failed to write journal")
+	}
+	if err := file.Close(); err != nil {
+		return errors.Wrap(err, "failed to write journal")
+	}
+
+	return errors.Wrap(os.Rename(file.Name(), j.path), "failed to replace journal")
+}
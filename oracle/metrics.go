@@ -10,10 +10,18 @@ import (
 )
 
 type Metrics struct {
-	methodCallCount                metrics.Counter
-	methodDuration                 metrics.Histogram
-	fetchedPlasmachainEventCount   metrics.Counter
-	submittedPlasmachainEventCount metrics.Counter
+	methodCallCount                  metrics.Counter
+	methodDuration                   metrics.Histogram
+	fetchedPlasmachainEventCount     metrics.Counter
+	submittedPlasmachainEventCount   metrics.Counter
+	quarantinedPlasmachainEventCount metrics.Counter
+	evictedPlayerPoolEntryCount      metrics.Counter
+	plasmachainReorgCount            metrics.Counter
+	plasmachainBlockLag              metrics.Gauge
+	pendingCommandCount              metrics.Gauge
+	oldestPendingCommandAge          metrics.Gauge
+	commandDuration                  metrics.Histogram
+	batchFailureCount                metrics.Counter
 }
 
 func NewMetrics(subsystem string) *Metrics {
@@ -48,6 +56,63 @@ func NewMetrics(subsystem string) *Metrics {
 				Name:      "submitted_plasma_event_count",
 				Help:      "Number of Plasmachain events successfully submitted to the Gamechain.",
 			}, nil),
+		quarantinedPlasmachainEventCount: kitprometheus.NewCounterFrom(
+			stdprometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "quarantined_plasma_event_count",
+				Help:      "Number of Plasmachain events that kept failing and were quarantined on the Gamechain.",
+			}, nil),
+		evictedPlayerPoolEntryCount: kitprometheus.NewCounterFrom(
+			stdprometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "evicted_player_pool_entry_count",
+				Help:      "Number of stale player pool entries evicted from the Gamechain matchmaking queues.",
+			}, []string{"queue"}),
+		plasmachainReorgCount: kitprometheus.NewCounterFrom(
+			stdprometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "plasma_reorg_count",
+				Help:      "Number of Plasmachain reorgs that invalidated blocks already submitted to the Gamechain.",
+			}, nil),
+		plasmachainBlockLag: kitprometheus.NewGaugeFrom(
+			stdprometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "plasma_block_lag",
+				Help:      "Number of Plasmachain blocks between the latest block and the last block processed by the Gamechain.",
+			}, nil),
+		pendingCommandCount: kitprometheus.NewGaugeFrom(
+			stdprometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "pending_command_count",
+				Help:      "Number of Gamechain commands waiting to be executed.",
+			}, nil),
+		oldestPendingCommandAge: kitprometheus.NewGaugeFrom(
+			stdprometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "oldest_pending_command_age",
+				Help:      "How long the oldest Gamechain command has been waiting to be executed (in seconds).",
+			}, nil),
+		commandDuration: kitprometheus.NewHistogramFrom(
+			stdprometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "command_duration",
+				Help:      "How long a Gamechain command took to execute (in seconds).",
+				Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+			}, []string{"command", "error"}),
+		batchFailureCount: kitprometheus.NewCounterFrom(
+			stdprometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "batch_failure_count",
+				Help:      "Number of batches the Gamechain failed to process.",
+			}, []string{"batch"}),
 	}
 }
 
@@ -64,3 +129,32 @@ func (m *Metrics) FetchedPlasmachainEvents(numEvents int, kind string) {
 func (m *Metrics) SubmittedPlasmachainEvents(numEvents int) {
 	m.submittedPlasmachainEventCount.Add(float64(numEvents))
 }
+
+func (m *Metrics) QuarantinedPlasmachainEvents(numEvents int) {
+	m.quarantinedPlasmachainEventCount.Add(float64(numEvents))
+}
+
+func (m *Metrics) EvictedPlayerPoolEntries(numEntries int64, queue string) {
+	m.evictedPlayerPoolEntryCount.With("queue", queue).Add(float64(numEntries))
+}
+
+func (m *Metrics) DetectedPlasmachainReorg() {
+	m.plasmachainReorgCount.Add(1)
+}
+
+func (m *Metrics) PlasmachainBlockLag(lag uint64) {
+	m.plasmachainBlockLag.Set(float64(lag))
+}
+
+func (m *Metrics) PendingCommands(numCommands int, oldestAge time.Duration) {
+	m.pendingCommandCount.Set(float64(numCommands))
+	m.oldestPendingCommandAge.Set(oldestAge.Seconds())
+}
+
+func (m *Metrics) CommandExecuted(begin time.Time, command string, err error) {
+	m.commandDuration.With("command", command, "error", fmt.Sprint(err != nil)).Observe(time.Since(begin).Seconds())
+}
+
+func (m *Metrics) BatchFailed(batch string) {
+	m.batchFailureCount.With("batch", batch).Add(1)
+}
@@ -1,6 +1,7 @@
 package oracle
 
 import (
+	"context"
 	"encoding/base64"
 	"io/ioutil"
 	"reflect"
@@ -10,16 +11,12 @@ import (
 	"sync"
 	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
-	ethtypes "github.com/ethereum/go-ethereum/core/types"
-	"github.com/loomnetwork/gamechain/tools/battleground_utility"
 	orctype "github.com/loomnetwork/gamechain/types/oracle"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
 	"github.com/loomnetwork/go-loom"
 	"github.com/loomnetwork/go-loom/auth"
 	"github.com/loomnetwork/go-loom/client"
-	"github.com/loomnetwork/go-loom/common"
-	ptypes "github.com/loomnetwork/go-loom/plugin/types"
 	ltypes "github.com/loomnetwork/go-loom/types"
 	"github.com/pkg/errors"
 )
@@ -36,6 +33,35 @@ type Status struct {
 	PlasmachainEventsFetchedCount uint64 `json:",string"`
 	// Total number of Plamachain events fetched
 	PlasmachainEventsSubmittedCount uint64 `json:",string"`
+	// Total number of stale player pool entries evicted by the sweeps
+	PlayerPoolEntriesEvictedCount uint64 `json:",string"`
+	// Total number of Plasmachain events that kept failing and were quarantined on Gamechain
+	PlasmachainEventsQuarantinedCount uint64 `json:",string"`
+	// Total number of Plasmachain reorgs that invalidated processed blocks
+	PlasmachainReorgCount uint64 `json:",string"`
+	// Latest Plasmachain block and last block processed by Gamechain, as of the last poll
+	PlasmachainLatestBlockNumber    uint64 `json:",string"`
+	PlasmachainProcessedBlockNumber uint64 `json:",string"`
+	// Number of blocks the processed block is behind the latest block
+	PlasmachainBlockLag uint64 `json:",string"`
+	// Number of Gamechain commands waiting to be executed, as of the last fetch
+	PendingCommandCount int
+	// Number of seconds the oldest pending Gamechain command has been waiting
+	OldestPendingCommandAge int64
+	// Total number of event and command response batches Gamechain failed to process
+	EventBatchFailureCount           uint64 `json:",string"`
+	CommandResponseBatchFailureCount uint64 `json:",string"`
+	// Time of the last connection attempt or communication round
+	LastHeartbeatTime time.Time
+	// Time of the last communication round that finished without errors
+	LastSuccessfulRoundTime time.Time
+	Stopped                 bool
+	// The oracle loop is running
+	Live bool
+	// The oracle is connected and communication rounds are succeeding
+	Ready bool
+	// The oracle is ready and the Plasmachain block lag doesn't exceed Config.OracleMaxPlasmachainBlockLag
+	Healthy bool
 }
 
 type Oracle struct {
@@ -45,12 +71,22 @@ type Oracle struct {
 	pcAddress                loom.Address
 	pcSigner                 auth.Signer
 	pcZbgCardContractAddress loom.Address
-	pcGateway                *PlasmachainGateway
-	pcPollInterval           time.Duration
+	// nil if not configured
+	pcFiatPurchaseContractAddress *ethcommon.Address
+	pcPackContractAddresses       map[zb_enums.PackType_Enum]ethcommon.Address
+	pcGateway                     plasmachainSource
+	pcPollInterval                time.Duration
 	// Gamechain
 	gcAddress loom.Address
 	gcSigner  auth.Signer
-	gcGateway *GamechainGateway
+	gcGateway gamechainSink
+	// sweeping of stale player pool entries
+	gcPlayerPoolSweepInterval   time.Duration
+	lastPlayerPoolSweepTime     time.Time
+	numPlayerPoolEntriesEvicted uint64
+	// checking of pending minting receipts
+	gcMintingReceiptSweepInterval time.Duration
+	lastMintingReceiptSweepTime   time.Time
 	// oracle
 	logger            *loom.Logger
 	reconnectInterval time.Duration
@@ -63,10 +99,38 @@ type Oracle struct {
 	startBlock                    uint64
 	numPlasmachainEventsFetched   uint64
 	numPlasmachainEventsSubmitted uint64
-
-	hashPool *recentHashPool
+	numPlasmachainReorgs          uint64
+	lastHeartbeatTime             time.Time
+	lastSuccessfulRoundTime       time.Time
+	stopped                       bool
+	// lag and backlog figures reported in the status
+	latestPlasmachainBlockNumber    uint64
+	processedPlasmachainBlockNumber uint64
+	maxPlasmachainBlockLag          uint64
+	pendingCommandCount             int
+	oldestPendingCommandTime        time.Time
+	numEventBatchFailures           uint64
+	numCommandResponseBatchFailures uint64
+
+	// batches applied by Gamechain, so events aren't submitted again after a restart
+	journal *submittedBatchJournal
+	// submit deterministic batches for the oracle set members to agree on, see Config.GamechainOracleSetMode
+	oracleSetMode     bool
+	gcCommandsEnabled bool
 
 	maxBlockRange uint64
+	// limits of a single ProcessOracleEventBatch call, no limit if 0
+	maxBatchEventCount int
+	maxBatchSize       int
+	// number of failed attempts after which a batch is split into blocks, and a block is quarantined, never if 0
+	maxBatchAttempts                int
+	failingBatchStartBlock          uint64
+	failingBatchAttemptCount        int
+	isolateUntilBlock               uint64
+	numPlasmachainEventsQuarantined uint64
+	// number of blocks an event has to be buried under before it's submitted
+	confirmations   uint64
+	processedBlocks *processedBlockTracker
 }
 
 func CreateOracle(cfg *Config, metricSubsystem string) (*Oracle, error) {
@@ -102,8 +166,25 @@ func CreateOracle(cfg *Config, metricSubsystem string) (*Oracle, error) {
 		Local:   pcZbgCardContractLocalAddress,
 	}
 
-	hashPool := newRecentHashPool(time.Duration(cfg.PlasmachainPollInterval) * time.Second * 4)
-	hashPool.startCleanupRoutine()
+	var pcFiatPurchaseContractAddress *ethcommon.Address
+	if cfg.PlasmachainFiatPurchaseContractHexAddress != "" {
+		if !ethcommon.IsHexAddress(cfg.PlasmachainFiatPurchaseContractHexAddress) {
+			return nil, errors.Errorf("invalid fiat purchase contract address %s", cfg.PlasmachainFiatPurchaseContractHexAddress)
+		}
+
+		address := ethcommon.HexToAddress(cfg.PlasmachainFiatPurchaseContractHexAddress)
+		pcFiatPurchaseContractAddress = &address
+	}
+
+	pcPackContractAddresses, err := parsePackContractHexAddresses(cfg.PlasmachainPackContractHexAddresses)
+	if err != nil {
+		return nil, err
+	}
+
+	journal := newSubmittedBatchJournal(cfg.OracleJournalPath)
+	if err := journal.load(); err != nil {
+		return nil, errors.Wrapf(err, "failed to load journal %s", cfg.OracleJournalPath)
+	}
 
 	logger.Info("Gamechain address " + gcAddress.String())
 	logger.Info("Plasmachain address " + pcAddress.String())
@@ -112,22 +193,34 @@ func CreateOracle(cfg *Config, metricSubsystem string) (*Oracle, error) {
 	maxBlockRange := uint64(cfg.PlasmachainMaxBlockRange)
 
 	return &Oracle{
-		cfg:                      *cfg,
-		gcAddress:                gcAddress,
-		gcSigner:                 gcSigner,
-		pcAddress:                pcAddress,
-		pcSigner:                 pcSigner,
-		pcZbgCardContractAddress: pcZbgCardContractAddress,
-		metrics:                  NewMetrics(metricSubsystem),
-		logger:                   logger,
-		pcPollInterval:           time.Duration(cfg.PlasmachainPollInterval) * time.Second,
-		startupDelay:             time.Duration(cfg.OracleStartupDelay) * time.Second,
-		reconnectInterval:        time.Duration(cfg.OracleReconnectInterval) * time.Second,
+		cfg:                           *cfg,
+		gcAddress:                     gcAddress,
+		gcSigner:                      gcSigner,
+		pcAddress:                     pcAddress,
+		pcSigner:                      pcSigner,
+		pcZbgCardContractAddress:      pcZbgCardContractAddress,
+		pcFiatPurchaseContractAddress: pcFiatPurchaseContractAddress,
+		pcPackContractAddresses:       pcPackContractAddresses,
+		metrics:                       NewMetrics(metricSubsystem),
+		logger:                        logger,
+		pcPollInterval:                time.Duration(cfg.PlasmachainPollInterval) * time.Second,
+		gcPlayerPoolSweepInterval:     time.Duration(cfg.GamechainPlayerPoolSweepInterval) * time.Second,
+		gcMintingReceiptSweepInterval: time.Duration(cfg.GamechainMintingReceiptSweepInterval) * time.Second,
+		startupDelay:                  time.Duration(cfg.OracleStartupDelay) * time.Second,
+		reconnectInterval:             time.Duration(cfg.OracleReconnectInterval) * time.Second,
+		maxPlasmachainBlockLag:        uint64(cfg.OracleMaxPlasmachainBlockLag),
 		status: Status{
 			Version: "1.0.0",
 		},
-		hashPool:      hashPool,
-		maxBlockRange: maxBlockRange,
+		journal:            journal,
+		oracleSetMode:      cfg.GamechainOracleSetMode,
+		gcCommandsEnabled:  cfg.GamechainCommandsEnabled,
+		maxBlockRange:      maxBlockRange,
+		maxBatchEventCount: cfg.PlasmachainMaxBatchEventCount,
+		maxBatchSize:       cfg.PlasmachainMaxBatchSize,
+		maxBatchAttempts:   cfg.PlasmachainMaxBatchAttempts,
+		confirmations:      uint64(cfg.PlasmachainConfirmations),
+		processedBlocks:    newProcessedBlockTracker(cfg.PlasmachainReorgTrackingDepth),
 	}, nil
 }
 
@@ -138,166 +231,361 @@ func (orc *Oracle) Status() *Status {
 	s := orc.status
 
 	orc.statusMutex.RUnlock()
+
+	livenessTimeout := orc.livenessTimeout()
+	s.Live = !s.Stopped && time.Since(s.LastHeartbeatTime) < livenessTimeout
+	s.Ready = s.Live &&
+		s.GamechainGatewayAddress != "" &&
+		s.PlasmachainGatewayAddress != "" &&
+		!s.LastSuccessfulRoundTime.IsZero() &&
+		time.Since(s.LastSuccessfulRoundTime) < livenessTimeout
+	s.Healthy = s.Ready && (orc.maxPlasmachainBlockLag == 0 || s.PlasmachainBlockLag <= orc.maxPlasmachainBlockLag)
 	return &s
 }
 
+// livenessTimeout is how long the oracle can go without a heartbeat before it's considered stuck
+func (orc *Oracle) livenessTimeout() time.Duration {
+	const minLivenessTimeout = time.Minute
+
+	timeout := 5 * orc.pcPollInterval
+	if 5*orc.reconnectInterval > timeout {
+		timeout = 5 * orc.reconnectInterval
+	}
+	if timeout < minLivenessTimeout {
+		timeout = minLivenessTimeout
+	}
+
+	return orc.startupDelay + timeout
+}
+
+func (orc *Oracle) heartbeat() {
+	orc.lastHeartbeatTime = time.Now()
+	orc.updateStatus()
+}
+
+func (orc *Oracle) finishRound(err error) {
+	orc.lastHeartbeatTime = time.Now()
+	if err == nil {
+		orc.lastSuccessfulRoundTime = orc.lastHeartbeatTime
+	}
+	orc.updateStatus()
+}
+
+// setPlasmachainBlockNumbers updates the figures the Plasmachain block lag is computed from
+func (orc *Oracle) setPlasmachainBlockNumbers(latestBlock uint64, processedBlock uint64) {
+	orc.latestPlasmachainBlockNumber = latestBlock
+	orc.processedPlasmachainBlockNumber = processedBlock
+	orc.metrics.PlasmachainBlockLag(orc.plasmachainBlockLag())
+}
+
+func (orc *Oracle) plasmachainBlockLag() uint64 {
+	if orc.latestPlasmachainBlockNumber <= orc.processedPlasmachainBlockNumber {
+		return 0
+	}
+	return orc.latestPlasmachainBlockNumber - orc.processedPlasmachainBlockNumber
+}
+
+// setPendingCommands updates the backlog figures from the commands fetched from Gamechain
+func (orc *Oracle) setPendingCommands(commandRequests []*orctype.OracleCommandRequest) {
+	var oldestCreatedAt int64
+	for _, commandRequest := range commandRequests {
+		if commandRequest.CreatedAt > 0 && (oldestCreatedAt == 0 || commandRequest.CreatedAt < oldestCreatedAt) {
+			oldestCreatedAt = commandRequest.CreatedAt
+		}
+	}
+
+	orc.pendingCommandCount = len(commandRequests)
+	orc.oldestPendingCommandTime = time.Time{}
+	var oldestAge time.Duration
+	if oldestCreatedAt > 0 {
+		orc.oldestPendingCommandTime = time.Unix(oldestCreatedAt, 0)
+		oldestAge = time.Since(orc.oldestPendingCommandTime)
+	}
+
+	orc.metrics.PendingCommands(len(commandRequests), oldestAge)
+	orc.updateStatus()
+}
+
+func (orc *Oracle) setStopped() {
+	orc.stopped = true
+	orc.updateStatus()
+}
+
 func (orc *Oracle) updateStatus() {
 	orc.statusMutex.Lock()
 
 	orc.status.NextPlasmachainBlockNumber = orc.startBlock
 	orc.status.PlasmachainEventsFetchedCount = orc.numPlasmachainEventsFetched
 	orc.status.PlasmachainEventsSubmittedCount = orc.numPlasmachainEventsSubmitted
+	orc.status.PlayerPoolEntriesEvictedCount = orc.numPlayerPoolEntriesEvicted
+	orc.status.PlasmachainReorgCount = orc.numPlasmachainReorgs
+	orc.status.PlasmachainEventsQuarantinedCount = orc.numPlasmachainEventsQuarantined
+	orc.status.PlasmachainLatestBlockNumber = orc.latestPlasmachainBlockNumber
+	orc.status.PlasmachainProcessedBlockNumber = orc.processedPlasmachainBlockNumber
+	orc.status.PlasmachainBlockLag = orc.plasmachainBlockLag()
+	orc.status.PendingCommandCount = orc.pendingCommandCount
+	orc.status.OldestPendingCommandAge = 0
+	if !orc.oldestPendingCommandTime.IsZero() {
+		orc.status.OldestPendingCommandAge = int64(time.Since(orc.oldestPendingCommandTime).Seconds())
+	}
+	orc.status.EventBatchFailureCount = orc.numEventBatchFailures
+	orc.status.CommandResponseBatchFailureCount = orc.numCommandResponseBatchFailures
+	orc.status.LastHeartbeatTime = orc.lastHeartbeatTime
+	orc.status.LastSuccessfulRoundTime = orc.lastSuccessfulRoundTime
+	orc.status.Stopped = orc.stopped
 
 	if orc.gcGateway != nil {
-		orc.status.GamechainGatewayAddress = orc.gcGateway.Address.String()
-		orc.status.GamechainGatewayLastSeen = orc.gcGateway.LastResponseTime
+		orc.status.GamechainGatewayAddress = orc.gcGateway.GatewayAddress().String()
+		orc.status.GamechainGatewayLastSeen = orc.gcGateway.LastSeen()
 	}
 	if orc.pcGateway != nil {
-		orc.status.PlasmachainGatewayAddress = orc.pcGateway.Address.String()
-		orc.status.PlasmachainGatewayLastSeen = orc.pcGateway.LastResponseTime
+		orc.status.PlasmachainGatewayAddress = orc.pcGateway.GatewayAddress().String()
+		orc.status.PlasmachainGatewayLastSeen = orc.pcGateway.LastSeen()
 	}
 
 	orc.statusMutex.Unlock()
 }
 
 // Status returns some basic info about the current state of the Oracle.
+// Gateways that are already set, for example in tests, are kept.
 func (orc *Oracle) connect() error {
-	var err error
 	if orc.pcGateway == nil {
 		dappClient := client.NewDAppChainRPCClient(orc.cfg.PlasmachainChainID, orc.cfg.PlasmachainWriteURI, orc.cfg.PlasmachainReadURI)
-		orc.pcGateway, err = ConnectToPlasmachainGateway(dappClient, orc.pcAddress, orc.pcZbgCardContractAddress, orc.pcSigner, orc.logger)
+		pcGateway, err := ConnectToPlasmachainGateway(
+			dappClient,
+			orc.pcAddress,
+			orc.pcZbgCardContractAddress,
+			orc.pcFiatPurchaseContractAddress,
+			orc.pcPackContractAddresses,
+			orc.pcSigner,
+			orc.logger,
+		)
 		if err != nil {
 			return errors.Wrap(err, "failed to create plasmachain gateway")
 		}
+		orc.pcGateway = pcGateway
 		orc.logger.Info("connected to Plasmachain")
 	}
 
 	if orc.gcGateway == nil {
 		dappClient := client.NewDAppChainRPCClient(orc.cfg.GamechainChainID, orc.cfg.GamechainWriteURI, orc.cfg.GamechainReadURI)
-		orc.gcGateway, err = ConnectToGamechainGateway(dappClient, orc.gcAddress, orc.cfg.GamechainContractName, orc.gcSigner, orc.logger)
+		gcGateway, err := ConnectToGamechainGateway(dappClient, orc.gcAddress, orc.cfg.GamechainContractName, orc.gcSigner, orc.logger)
 		if err != nil {
 			return errors.Wrap(err, "failed to create gamechain gateway")
 		}
+		orc.gcGateway = gcGateway
 		orc.logger.Info("connected to Gamechain")
 	}
 
 	return nil
 }
 
-// RunWithRecovery should run in a goroutine, it will ensure the oracle keeps on running as long
-// as it doesn't panic due to a runtime error.
-func (orc *Oracle) RunWithRecovery() {
+// RunWithRecovery should run in a goroutine, it keeps the oracle running until the context is cancelled,
+// and restarts it after a panic unless the panic was caused by a runtime error.
+func (orc *Oracle) RunWithRecovery(ctx context.Context) {
 	orc.logger.Info("Running Oracle...")
+	defer orc.setStopped()
+
+	// When running in-process give the node a bit of time to spin up.
+	orc.heartbeat()
+	if !sleepWithContext(ctx, orc.startupDelay) {
+		return
+	}
+
+	for {
+		if !orc.runAndRecover(ctx) || !sleepWithContext(ctx, 30*time.Second) {
+			return
+		}
+		orc.logger.Info("Restarting Oracle...")
+	}
+}
+
+// runAndRecover runs the oracle and returns whether it should be restarted
+func (orc *Oracle) runAndRecover(ctx context.Context) (restart bool) {
 	defer func() {
 		if r := recover(); r != nil {
 			orc.logger.Error("recovered from panic in Oracle", "r", r, "stacktrace", string(debug.Stack()))
-			// Unless it's a runtime error restart the goroutine
-			if _, ok := r.(runtime.Error); !ok {
-				time.Sleep(30 * time.Second)
-				orc.logger.Info("Restarting Oracle...")
-				go orc.RunWithRecovery()
-			}
+			// Unless it's a runtime error restart the oracle
+			_, isRuntimeError := r.(runtime.Error)
+			restart = !isRuntimeError
 		}
 	}()
 
-	// When running in-process give the node a bit of time to spin up.
-	if orc.startupDelay > 0 {
-		time.Sleep(orc.startupDelay)
-	}
-
-	orc.Run()
+	orc.Run(ctx)
+	return false
 }
 
-// TODO: Graceful shutdown
-func (orc *Oracle) Run() {
+// Run polls Plasmachain and Gamechain until the context is cancelled. A communication round that is
+// in progress when the context is cancelled either finishes its current step or is abandoned between steps,
+// each step leaves Gamechain in a consistent state.
+func (orc *Oracle) Run(ctx context.Context) {
 	for {
 		err := orc.connect()
 		if err == nil {
 			break
 		}
 		orc.logger.Info(err.Error())
-		orc.updateStatus()
-		time.Sleep(orc.reconnectInterval)
+		orc.heartbeat()
+		if !sleepWithContext(ctx, orc.reconnectInterval) {
+			orc.logger.Info("Oracle stopped")
+			return
+		}
 	}
 
 	skipSleep := true
 	for {
 		if !skipSleep {
-			time.Sleep(orc.pcPollInterval)
+			if !sleepWithContext(ctx, orc.pcPollInterval) {
+				orc.logger.Info("Oracle stopped")
+				return
+			}
 		} else {
 			skipSleep = false
 		}
-		err := orc.doCommunicationRound()
+		err := orc.doCommunicationRound(ctx)
 		if err != nil {
 			orc.logger.Error(err.Error())
 		}
+		orc.finishRound(err)
 	}
 }
 
-func (orc *Oracle) doCommunicationRound() error {
+func (orc *Oracle) doCommunicationRound(ctx context.Context) error {
 	latestPlasmaBlock, err := orc.pollPlasmachainForEvents()
 	if err != nil {
 		return errors.Wrap(err, "failed to poll Plasmachain for events")
 	}
 
+	if ctx.Err() != nil {
+		orc.logger.Info("Oracle is stopping, abandoning the rest of the communication round")
+		return nil
+	}
+
+	// the redemption checks queued by the sweep are executed with the other commands
+	if err := orc.sweepMintingReceipts(); err != nil {
+		return errors.Wrap(err, "failed to sweep Gamechain minting receipts")
+	}
+
 	if err := orc.executeGamechainCommands(latestPlasmaBlock); err != nil {
 		return errors.Wrap(err, "failed to execute Gamechain commands")
 	}
 
+	if ctx.Err() != nil {
+		orc.logger.Info("Oracle is stopping, abandoning the rest of the communication round")
+		return nil
+	}
+
+	if err := orc.sweepPlayerPools(); err != nil {
+		return errors.Wrap(err, "failed to sweep Gamechain player pools")
+	}
+
+	return nil
+}
+
+// sleepWithContext waits for the duration, and returns false if the context was cancelled before
+func sleepWithContext(ctx context.Context, duration time.Duration) bool {
+	if duration <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (orc *Oracle) sweepPlayerPools() (err error) {
+	if orc.gcPlayerPoolSweepInterval <= 0 || time.Since(orc.lastPlayerPoolSweepTime) < orc.gcPlayerPoolSweepInterval {
+		return nil
+	}
+
+	begin := time.Now()
+	defer func() {
+		orc.metrics.MethodCalled(begin, "sweepPlayerPools", err)
+	}()
+
+	orc.logger.Debug("Sweeping Gamechain player pools")
+	response, err := orc.gcGateway.SweepPlayerPools()
+	if err != nil {
+		return err
+	}
+	orc.lastPlayerPoolSweepTime = time.Now()
+
+	for _, queueEvictionCount := range response.QueueEvictionCounts {
+		orc.metrics.EvictedPlayerPoolEntries(queueEvictionCount.EvictedEntryCount, queueEvictionCount.Queue)
+	}
+
+	orc.numPlayerPoolEntriesEvicted += uint64(response.EvictedEntryCount)
+	orc.updateStatus()
+
+	if response.EvictedEntryCount > 0 {
+		orc.logger.Info("Evicted stale player pool entries", "count", response.EvictedEntryCount)
+	}
+
+	return nil
+}
+
+func (orc *Oracle) sweepMintingReceipts() (err error) {
+	if orc.gcMintingReceiptSweepInterval <= 0 || time.Since(orc.lastMintingReceiptSweepTime) < orc.gcMintingReceiptSweepInterval {
+		return nil
+	}
+
+	begin := time.Now()
+	defer func() {
+		orc.metrics.MethodCalled(begin, "sweepMintingReceipts", err)
+	}()
+
+	orc.logger.Debug("Sweeping Gamechain minting receipts")
+	response, err := orc.gcGateway.SweepMintingReceipts()
+	if err != nil {
+		return err
+	}
+	orc.lastMintingReceiptSweepTime = time.Now()
+
+	if response.ExpiredReceiptCount > 0 || response.VerificationRequestCount > 0 {
+		orc.logger.Info(
+			"Swept minting receipts",
+			"expired", response.ExpiredReceiptCount,
+			"verificationRequests", response.VerificationRequestCount,
+		)
+	}
+
 	return nil
 }
 
 func (orc *Oracle) executeGamechainCommands(latestPlasmaBlock uint64) error {
+	if !orc.gcCommandsEnabled {
+		return nil
+	}
+
 	orc.logger.Debug("Fetching Gamechain commands")
 
 	commandRequests, err := orc.gcGateway.GetOracleCommandRequestList()
 	if err != nil {
 		return err
 	}
+	orc.setPendingCommands(commandRequests)
 
 	commandResponses := make([]*orctype.OracleCommandResponse, 0, len(commandRequests))
 
 	orc.logger.Debug("Executing Gamechain commands", "len(commandRequests)", len(commandRequests))
-	for _, commandRequestWrapper := range commandRequests {
-		orc.logger.Info("Executing command", "commandId", commandRequestWrapper.CommandId, "commandType", reflect.TypeOf(commandRequestWrapper.GetCommand()).String())
-		switch commandRequest := commandRequestWrapper.Command.(type) {
-		case *orctype.OracleCommandRequest_GetUserFullCardCollection:
-			userAddress := commandRequest.GetUserFullCardCollection.UserAddress
-			tokensOwned, err := orc.pcGateway.GetTokensOwned(loom.UnmarshalAddressPB(userAddress).Local)
-			if err != nil {
-				return err
-			}
-
-			orc.logger.Info("GetUserFullCardCollection", "userAddress", loom.UnmarshalAddressPB(userAddress), "tokensOwned", len(tokensOwned))
-
-			response := &orctype.OracleCommandResponse_GetUserFullCardCollectionCommandResponse{
-				UserAddress: userAddress,
-				BlockHeight: latestPlasmaBlock,
-			}
-
-			for _, tokensOwnedResponseItem := range tokensOwned {
-				response.OwnedCards = append(response.OwnedCards, &orctype.RawCardCollectionCard{
-					CardTokenId: battleground_utility.MarshalBigIntProto(tokensOwnedResponseItem.Index),
-					Amount:      battleground_utility.MarshalBigIntProto(tokensOwnedResponseItem.Balance),
-				})
-			}
-
-			responseWrapper := &orctype.OracleCommandResponse{
-				CommandId: commandRequestWrapper.CommandId,
-				Command: &orctype.OracleCommandResponse_GetUserFullCardCollection{
-					GetUserFullCardCollection: response,
-				},
-			}
-			commandResponses = append(commandResponses, responseWrapper)
-		default:
-			orc.logger.Warn("unknown command type", "commandType", reflect.TypeOf(commandRequestWrapper.GetCommand()).String())
-		}
+	for _, commandRequest := range commandRequests {
+		orc.logger.Info("Executing command", "commandId", commandRequest.CommandId, "commandType", reflect.TypeOf(commandRequest.GetCommand()).String())
+		commandResponses = append(commandResponses, orc.executeCommand(commandRequest, latestPlasmaBlock))
 	}
 
 	if len(commandResponses) > 0 {
 		orc.logger.Debug("Sending executed command responses to Gamechain", "len(commandResponses)", len(commandResponses))
 		err := orc.gcGateway.ProcessOracleCommandResponseBatch(commandResponses)
 		if err != nil {
+			orc.numCommandResponseBatchFailures++
+			orc.metrics.BatchFailed("command_response")
+			orc.updateStatus()
 			return err
 		}
 	}
@@ -308,6 +596,17 @@ func (orc *Oracle) executeGamechainCommands(latestPlasmaBlock uint64) error {
 
 func (orc *Oracle) pollPlasmachainForEvents() (latestPlasmaBlock uint64, err error) {
 	orc.logger.Info("Start polling Plasmachain")
+	rolledBack, err := orc.rollbackReorganisedBlocks()
+	if err != nil {
+		orc.logger.Error("failed to roll back reorganised Plasmachain blocks", "err", err)
+		return 0, err
+	}
+
+	// the reorganised blocks stay tracked, the rollback is submitted again until it's applied
+	if !rolledBack {
+		return 0, nil
+	}
+
 	lastPlasmachainBlockNumber, err := orc.gcGateway.GetLastPlasmaBlockNumber()
 	if err != nil {
 		orc.logger.Error("failed to obtain last Plasmachain block number from Gamechain", "err", err)
@@ -332,78 +631,313 @@ func (orc *Oracle) pollPlasmachainForEvents() (latestPlasmaBlock uint64, err err
 	}
 
 	orc.logger.Debug("current latest Plasmachain block number", "latestBlock", latestBlock)
+	orc.setPlasmachainBlockNumbers(latestBlock, lastPlasmachainBlockNumber)
+	orc.updateStatus()
+
+	// Only process blocks that are unlikely to be reorganised
+	if latestBlock < orc.confirmations {
+		return 0, nil
+	}
+	latestBlock = latestBlock - orc.confirmations
 
 	if latestBlock < startBlock {
 		// Wait for Plasmachain to produce a new block...
 		return 0, nil
 	}
 
-	if latestBlock-startBlock > orc.maxBlockRange {
+	if orc.oracleSetMode {
+		// every member has to submit the same block ranges, so the ranges end at multiples of the max block range
+		rangeEndBlock := ((startBlock-1)/orc.maxBlockRange + 1) * orc.maxBlockRange
+		if latestBlock < rangeEndBlock {
+			return 0, nil
+		}
+		latestBlock = rangeEndBlock
+	} else if latestBlock-startBlock > orc.maxBlockRange {
 		latestBlock = startBlock + orc.maxBlockRange
 		orc.logger.Info("adjust latestBlock due to range limit", "startBlock", startBlock, "latestBlock", latestBlock)
 	}
 
+	// the blocks of a batch that kept failing are submitted one by one, so the failing events can be quarantined
+	maxBatchEventCount := orc.maxBatchEventCount
+	if startBlock <= orc.isolateUntilBlock {
+		maxBatchEventCount = 1
+		if latestBlock > orc.isolateUntilBlock {
+			latestBlock = orc.isolateUntilBlock
+		}
+	}
+
 	orc.logger.Info("fetching events", "startBlock", startBlock, "latestBlock", latestBlock)
-	events, err := orc.fetchEvents(startBlock, latestBlock)
+	eventInfos, err := orc.fetchEvents(startBlock, latestBlock)
 	if err != nil {
 		orc.logger.Error("failed to fetch events from Plasmachain", "err", err)
 		return 0, err
 	}
 
-	orc.logger.Debug("finished fetching events", "len(events)", len(events))
-
-	if len(events) > 0 {
-		orc.numPlasmachainEventsFetched = orc.numPlasmachainEventsFetched + uint64(len(events))
-		orc.updateStatus()
+	orc.logger.Debug("finished fetching events", "len(events)", len(eventInfos))
+	orc.numPlasmachainEventsFetched = orc.numPlasmachainEventsFetched + uint64(len(eventInfos))
+	orc.updateStatus()
 
-		orc.logger.Debug("calling ProcessOracleEventBatch")
-		if err := orc.gcGateway.ProcessOracleEventBatch(events, latestBlock, orc.pcZbgCardContractAddress); err != nil {
+	// Each batch moves the last processed block on Gamechain, so a failure only loses the progress of one batch
+	for _, batch := range splitEventBatches(eventInfos, startBlock, latestBlock, maxBatchEventCount, orc.maxBatchSize) {
+		quarantined, err := orc.submitEventBatch(batch)
+		if err != nil {
 			return 0, err
 		}
-		orc.logger.Debug("finished calling ProcessOracleEventBatch")
 
-		orc.numPlasmachainEventsSubmitted = orc.numPlasmachainEventsSubmitted + uint64(len(events))
-		orc.metrics.SubmittedPlasmachainEvents(len(events))
+		// quarantined events weren't applied, so there's nothing to undo if their blocks are reorganised
+		trackedEventInfos := batch.EventInfos
+		if quarantined {
+			trackedEventInfos = nil
+		}
+
+		// Every member of an oracle set tracks the blocks it voted for, so they all agree on the rollback of a reorg.
+		// A batch waiting for votes is submitted again, so its blocks are replaced.
+		// Failing to track the blocks only weakens reorg detection, the events were already submitted
+		orc.processedBlocks.removeAfter(batch.StartBlock - 1)
+		if err := orc.trackProcessedBlocks(trackedEventInfos, batch.EndBlock); err != nil {
+			orc.logger.Error("failed to track processed Plasmachain blocks", "err", err)
+		}
+
+		// in an oracle set the batch is only a vote, its blocks are processed once enough members submitted it
+		if orc.oracleSetMode {
+			lastPlasmachainBlockNumber, err := orc.gcGateway.GetLastPlasmaBlockNumber()
+			if err != nil {
+				return 0, err
+			}
+
+			if lastPlasmachainBlockNumber < batch.EndBlock {
+				orc.logger.Info("event batch is waiting for the votes of other oracle set members",
+					"startBlock", batch.StartBlock,
+					"endBlock", batch.EndBlock,
+					"lastPlasmachainBlockNumber", lastPlasmachainBlockNumber,
+				)
+				orc.startBlock = lastPlasmachainBlockNumber + 1
+				orc.setPlasmachainBlockNumbers(orc.latestPlasmachainBlockNumber, lastPlasmachainBlockNumber)
+				orc.updateStatus()
+				return latestBlock, nil
+			}
+		}
+
+		orc.startBlock = batch.EndBlock + 1
+		orc.setPlasmachainBlockNumbers(orc.latestPlasmachainBlockNumber, batch.EndBlock)
 		orc.updateStatus()
-	} else {
-		// If there were no events, just update the latest Plasmachain block number
+	}
+
+	return latestBlock, nil
+}
+
+// submitEventBatch submits the events of the batch, or just moves the last processed block if there are none.
+// A batch that keeps failing is split into single blocks, and the events of a single block that keeps failing
+// are quarantined on Gamechain so the following blocks can be processed. In an oracle set it's retried as is.
+func (orc *Oracle) submitEventBatch(batch *eventBatch) (quarantined bool, err error) {
+	// events recorded in the journal were applied before a restart or a manual reset of the last processed block.
+	// The members of an oracle set don't skip them, their batches have to be identical.
+	var events []*orctype.PlasmachainEvent
+	for _, event := range batch.events() {
+		if !orc.oracleSetMode && orc.journal.contains(event) {
+			orc.logger.Warn("skipping event that was already submitted",
+				"block", event.EthBlock, "txIndex", event.TxIndex, "logIndex", event.LogIndex)
+			continue
+		}
+		events = append(events, event)
+	}
+
+	if len(events) == 0 && !orc.oracleSetMode {
+		// If there were no new events, just update the latest Plasmachain block number
 		// so that we won't process same events again.
 		orc.logger.Info("calling SetLastPlasmaBlockNumber")
-		if err := orc.gcGateway.SetLastPlasmaBlockNumber(latestBlock); err != nil {
+		if err := orc.gcGateway.SetLastPlasmaBlockNumber(batch.EndBlock); err != nil {
 			orc.logger.Warn(err.Error())
-			return 0, err
+			return false, err
 		}
+		return false, nil
 	}
 
-	orc.startBlock = latestBlock + 1
-	return latestBlock, nil
+	orc.logger.Debug("calling ProcessOracleEventBatch", "startBlock", batch.StartBlock, "endBlock", batch.EndBlock, "len(events)", len(events))
+	err = orc.gcGateway.ProcessOracleEventBatch(events, batch.StartBlock, batch.EndBlock, orc.pcZbgCardContractAddress)
+	if err == nil {
+		orc.logger.Debug("finished calling ProcessOracleEventBatch")
+		// the events were applied, the contract rejects them if they are submitted again anyway
+		if !orc.oracleSetMode {
+			if err := orc.journal.record(batch.EndBlock, events); err != nil {
+				orc.logger.Error("failed to record submitted events in the journal", "err", err)
+			}
+		}
+		orc.resetFailingBatch()
+		orc.numPlasmachainEventsSubmitted = orc.numPlasmachainEventsSubmitted + uint64(len(events))
+		orc.metrics.SubmittedPlasmachainEvents(len(events))
+		orc.updateStatus()
+		return false, nil
+	}
+
+	orc.numEventBatchFailures++
+	orc.metrics.BatchFailed("event")
+	orc.updateStatus()
+
+	if orc.failingBatchStartBlock != batch.StartBlock {
+		orc.failingBatchStartBlock = batch.StartBlock
+		orc.failingBatchAttemptCount = 0
+	}
+	orc.failingBatchAttemptCount++
+	// the members of an oracle set can't isolate or quarantine a failing batch, their requests would stop matching:
+	// only the member casting the deciding vote sees the error
+	if orc.oracleSetMode || orc.maxBatchAttempts <= 0 || orc.failingBatchAttemptCount < orc.maxBatchAttempts || len(events) == 0 {
+		return false, err
+	}
+
+	firstBlock := events[0].EthBlock
+	lastBlock := events[len(events)-1].EthBlock
+	if firstBlock != lastBlock {
+		orc.logger.Warn("event batch keeps failing, submitting its blocks one by one",
+			"startBlock", batch.StartBlock,
+			"endBlock", batch.EndBlock,
+			"attempts", orc.failingBatchAttemptCount,
+			"err", err,
+		)
+		orc.isolateUntilBlock = batch.EndBlock
+		orc.resetFailingBatch()
+		return false, err
+	}
+
+	orc.logger.Error("events keep failing, quarantining them",
+		"block", firstBlock,
+		"eventCount", len(events),
+		"attempts", orc.failingBatchAttemptCount,
+		"err", err,
+	)
+	if err := orc.gcGateway.QuarantineOracleEvents(events, batch.EndBlock, err.Error(), orc.pcZbgCardContractAddress); err != nil {
+		return false, errors.Wrap(err, "failed to quarantine events")
+	}
+
+	orc.resetFailingBatch()
+	orc.numPlasmachainEventsQuarantined = orc.numPlasmachainEventsQuarantined + uint64(len(events))
+	orc.metrics.QuarantinedPlasmachainEvents(len(events))
+	orc.updateStatus()
+	return true, nil
 }
 
-func (orc *Oracle) getLatestEthBlockNumber() (uint64, error) {
-	return orc.pcGateway.LastBlockNumber()
+func (orc *Oracle) resetFailingBatch() {
+	orc.failingBatchStartBlock = 0
+	orc.failingBatchAttemptCount = 0
 }
 
-// Fetches all relevent events from an Plasmachain node from startBlock to endBlock (inclusive)
-func (orc *Oracle) fetchEvents(startBlock, endBlock uint64) ([]*orctype.PlasmachainEvent, error) {
-	// NOTE: Currently either all blocks from w.StartBlock are processed successfully or none are.
-	filterOpts := &bind.FilterOpts{
-		Start: startBlock,
-		End:   &endBlock,
+// trackProcessedBlocks remembers the hashes of the blocks with events and of the last processed block
+func (orc *Oracle) trackProcessedBlocks(eventInfos []*plasmachainEventInfo, endBlock uint64) error {
+	var block *processedBlock
+	for _, eventInfo := range eventInfos {
+		if block == nil || block.Number != eventInfo.BlockNum {
+			block = &processedBlock{
+				Number: eventInfo.BlockNum,
+				Hash:   eventInfo.BlockHash,
+			}
+			orc.processedBlocks.add(block)
+		}
+
+		block.Events = append(block.Events, eventInfo.Event)
+	}
+
+	if block != nil && block.Number == endBlock {
+		return nil
+	}
+
+	hash, err := orc.pcGateway.BlockHash(endBlock)
+	if err != nil {
+		return err
 	}
 
-	var rawEvents []*plasmachainEventInfo
-	var err error
+	orc.processedBlocks.add(&processedBlock{
+		Number: endBlock,
+		Hash:   hash,
+	})
+	return nil
+}
 
-	rawEvents, err = orc.fetchTransferEvents(filterOpts)
+// rollbackReorganisedBlocks checks whether processed blocks were reorganised, and if so, undoes their events
+// on Gamechain so the blocks are processed again. In an oracle set the rollback is only a vote,
+// rolledBack is false until enough members submitted it.
+func (orc *Oracle) rollbackReorganisedBlocks() (rolledBack bool, err error) {
+	lastValidBlockNumber, invalidatedBlocks, err := orc.processedBlocks.findInvalidatedBlocks(orc.pcGateway.BlockHash)
+	if err != nil {
+		return false, err
+	}
+
+	if len(invalidatedBlocks) == 0 {
+		return true, nil
+	}
+
+	begin := time.Now()
+	defer func() {
+		orc.metrics.MethodCalled(begin, "rollbackReorganisedBlocks", err)
+	}()
+
+	var events []*orctype.PlasmachainEvent
+	for _, block := range invalidatedBlocks {
+		events = append(events, block.Events...)
+	}
+
+	lastPlasmachainBlockNumber, err := orc.gcGateway.GetLastPlasmaBlockNumber()
+	if err != nil {
+		return false, err
+	}
+
+	// blocks after this one are dropped from the tracked blocks and processed again
+	resumeAfterBlockNumber := lastValidBlockNumber
+	if lastValidBlockNumber >= lastPlasmachainBlockNumber {
+		// the invalidated blocks weren't processed on Gamechain, e.g. their oracle set vote didn't pass,
+		// so there's nothing to undo and the contract would reject the rollback
+		resumeAfterBlockNumber = lastPlasmachainBlockNumber
+		orc.logger.Warn("Plasmachain reorg detected in blocks Gamechain hasn't processed, dropping them",
+			"lastValidBlock", lastValidBlockNumber,
+			"lastPlasmachainBlockNumber", lastPlasmachainBlockNumber,
+			"invalidatedBlockCount", len(invalidatedBlocks),
+		)
+	} else {
+		cardAmountChanges := getCompensatingCardAmountChanges(events, orc.pcZbgCardContractAddress)
+		packAmountChanges := getCompensatingPackAmountChanges(events)
+		orc.logger.Warn("Plasmachain reorg detected, rolling back processed blocks",
+			"lastValidBlock", lastValidBlockNumber,
+			"invalidatedBlockCount", len(invalidatedBlocks),
+			"eventCount", len(events),
+			"cardAmountChangeCount", len(cardAmountChanges),
+			"packAmountChangeCount", len(packAmountChanges),
+		)
+
+		applied, err := orc.gcGateway.RollbackOracleEventBatch(lastValidBlockNumber, cardAmountChanges, packAmountChanges)
+		if err != nil {
+			return false, err
+		}
+
+		if !applied {
+			orc.logger.Info("rollback is waiting for the votes of other oracle set members", "lastValidBlock", lastValidBlockNumber)
+			return false, nil
+		}
+	}
+
+	orc.processedBlocks.removeAfter(resumeAfterBlockNumber)
+	if err := orc.journal.removeAfter(lastValidBlockNumber); err != nil {
+		orc.logger.Error("failed to remove rolled back events from the journal", "err", err)
+	}
+	orc.startBlock = resumeAfterBlockNumber + 1
+	orc.setPlasmachainBlockNumbers(orc.latestPlasmachainBlockNumber, resumeAfterBlockNumber)
+	orc.numPlasmachainReorgs++
+	orc.metrics.DetectedPlasmachainReorg()
+	orc.updateStatus()
+
+	return true, nil
+}
+
+func (orc *Oracle) getLatestEthBlockNumber() (uint64, error) {
+	return orc.pcGateway.LastBlockNumber()
+}
+
+// Fetches all relevent events from an Plasmachain node from startBlock to endBlock (inclusive), sorted by block
+func (orc *Oracle) fetchEvents(startBlock, endBlock uint64) ([]*plasmachainEventInfo, error) {
+	rawEvents, err := orc.fetchTransferEvents(startBlock, endBlock)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to fetch transfer events")
 	}
 
 	sortPlasmachainEvents(rawEvents)
-	events := make([]*orctype.PlasmachainEvent, len(rawEvents))
-	for i, event := range rawEvents {
-		events[i] = event.Event
-	}
 
 	if len(rawEvents) > 0 {
 		orc.logger.Debug("fetched Plasmachain events",
@@ -413,6 +947,33 @@ func (orc *Oracle) fetchEvents(startBlock, endBlock uint64) ([]*orctype.Plasmach
 		)
 	}
 
+	return rawEvents, nil
+}
+
+func (orc *Oracle) fetchTransferEvents(startBlock, endBlock uint64) (events []*plasmachainEventInfo, err error) {
+	kindToEventCount := map[string]int{
+		"Transfer":             0,
+		"TransferToken":        0,
+		"TransferWithQuantity": 0,
+		"BatchTransfer":        0,
+	}
+	defer func(begin time.Time) {
+		orc.metrics.MethodCalled(begin, "fetchTransferEvents", err)
+		for kind, eventCount := range kindToEventCount {
+			orc.metrics.FetchedPlasmachainEvents(eventCount, kind)
+		}
+		orc.updateStatus()
+	}(time.Now())
+
+	events, err = orc.pcGateway.FetchEvents(startBlock, endBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		kindToEventCount[event.Kind]++
+	}
+
 	return events, nil
 }
 
@@ -421,6 +982,9 @@ func sortPlasmachainEvents(events []*plasmachainEventInfo) {
 	// Need to check if plasmachain event contains TxIdx
 	sort.SliceStable(events, func(i, j int) bool {
 		if events[i].BlockNum == events[j].BlockNum {
+			if events[i].TxIdx == events[j].TxIdx {
+				return events[i].LogIdx < events[j].LogIdx
+			}
 			return events[i].TxIdx < events[j].TxIdx
 		}
 		return events[i].BlockNum < events[j].BlockNum
@@ -450,24 +1014,6 @@ func LoadDappChainPrivateKey(privKeyB64 string) ([]byte, error) {
 	return privKey, nil
 }
 
-func (orc *Oracle) processSingleRawEvent(rawEvent ethtypes.Log) (eventInfo *plasmachainEventInfo, receipt *ptypes.EvmTxReceipt, err error) {
-	receiptRaw, err := orc.pcGateway.client.GetEvmTxReceipt(rawEvent.TxHash.Bytes())
-	if err != nil {
-		orc.logger.Error(err.Error(), "txHash", rawEvent.TxHash.Hex())
-		return nil, nil, err
-	}
-
-	receipt = &receiptRaw
-
-	return &plasmachainEventInfo{
-		BlockNum: rawEvent.BlockNumber,
-		TxIdx:    rawEvent.TxIndex,
-		Event: &orctype.PlasmachainEvent{
-			EthBlock: rawEvent.BlockNumber,
-		},
-	}, receipt, nil
-}
-
 func parseBasicEventData(ethFromAddress *ethcommon.Address, ethToAddress *ethcommon.Address, chainId string) (fromAddress *ltypes.Address, toAddress *ltypes.Address, err error) {
 	fromLocal, err := loom.LocalAddressFromHexString(ethFromAddress.Hex())
 	if err != nil {
@@ -490,194 +1036,3 @@ func parseBasicEventData(ethFromAddress *ethcommon.Address, ethToAddress *ethcom
 
 	return fromAddress, toAddress, nil
 }
-
-func (orc *Oracle) fetchTransferEvents(filterOpts *bind.FilterOpts) ([]*plasmachainEventInfo, error) {
-	var err error
-	numTransferEvents := 0
-	numTransferTokenEvents := 0
-	numTransferWithQuantityEvents := 0
-	numBatchTransferEvents := 0
-	defer func(begin time.Time) {
-		orc.metrics.MethodCalled(begin, "fetchTransferEvents", err)
-		orc.metrics.FetchedPlasmachainEvents(numTransferEvents, "Transfer")
-		orc.metrics.FetchedPlasmachainEvents(numTransferTokenEvents, "TransferToken")
-		orc.metrics.FetchedPlasmachainEvents(numTransferWithQuantityEvents, "TransferWithQuantity")
-		orc.metrics.FetchedPlasmachainEvents(numBatchTransferEvents, "BatchTransfer")
-		orc.updateStatus()
-	}(time.Now())
-
-	var chainID = orc.pcGateway.client.GetChainID()
-	events := make([]*plasmachainEventInfo, 0)
-
-	// Transfer
-	transferIterator, err := orc.pcGateway.zbgCard.FilterTransfer(filterOpts, nil, nil, nil)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get logs for Transfer")
-	}
-	for {
-		ok := transferIterator.Next()
-		if ok {
-			event := transferIterator.Event
-			eventInfo, _, err := orc.processSingleRawEvent(event.Raw)
-			if err != nil {
-				return nil, err
-			}
-
-			fromAddress, toAddress, err := parseBasicEventData(&event.From, &event.To, chainID)
-			if err != nil {
-				return nil, errors.Wrap(err, "error parsing basic event data")
-			}
-
-			eventInfo.Event.Payload = &orctype.PlasmachainEvent_Transfer{
-				Transfer: &orctype.PlasmachainEventTransfer{
-					From:    fromAddress,
-					To:      toAddress,
-					TokenId: &ltypes.BigUInt{Value: common.BigUInt{Int: event.TokenId}},
-				},
-			}
-
-			events = append(events, eventInfo)
-			numTransferEvents++
-		} else {
-			err = transferIterator.Error()
-			if err != nil {
-				return nil, errors.Wrap(err, "Failed to get event data for Transfer")
-			}
-			transferIterator.Close()
-			break
-		}
-	}
-
-	// TransferToken
-	transferTokenIterator, err := orc.pcGateway.zbgCard.FilterTransferToken(filterOpts, nil, nil, nil)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get logs for TransferToken")
-	}
-	for {
-		ok := transferTokenIterator.Next()
-		if ok {
-			event := transferTokenIterator.Event
-			eventInfo, _, err := orc.processSingleRawEvent(event.Raw)
-			if err != nil {
-				return nil, err
-			}
-
-			fromAddress, toAddress, err := parseBasicEventData(&event.From, &event.To, chainID)
-			if err != nil {
-				return nil, errors.Wrap(err, "error parsing basic event data")
-			}
-
-			// TransferToken is just an old name for TransferWithQuantity, we can use the same event type
-			eventInfo.Event.Payload = &orctype.PlasmachainEvent_TransferWithQuantity{
-				TransferWithQuantity: &orctype.PlasmachainEventTransferWithQuantity{
-					From:    fromAddress,
-					To:      toAddress,
-					TokenId: &ltypes.BigUInt{Value: common.BigUInt{Int: event.TokenId}},
-					Amount:  &ltypes.BigUInt{Value: common.BigUInt{Int: event.Quantity}},
-				},
-			}
-
-			events = append(events, eventInfo)
-			numTransferTokenEvents++
-		} else {
-			err = transferTokenIterator.Error()
-			if err != nil {
-				return nil, errors.Wrap(err, "Failed to get event data for TransferToken")
-			}
-			transferTokenIterator.Close()
-			break
-		}
-	}
-
-	// TransferWithQuantity
-	transferWithQuantityIterator, err := orc.pcGateway.zbgCard.FilterTransferWithQuantity(filterOpts, nil)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get logs for TransferWithQuantity")
-	}
-	for {
-		ok := transferWithQuantityIterator.Next()
-		if ok {
-			event := transferWithQuantityIterator.Event
-			eventInfo, _, err := orc.processSingleRawEvent(event.Raw)
-			if err != nil {
-				return nil, err
-			}
-
-			fromAddress, toAddress, err := parseBasicEventData(&event.From, &event.To, chainID)
-			if err != nil {
-				return nil, errors.Wrap(err, "error parsing basic event data")
-			}
-
-			eventInfo.Event.Payload = &orctype.PlasmachainEvent_TransferWithQuantity{
-				TransferWithQuantity: &orctype.PlasmachainEventTransferWithQuantity{
-					From:    fromAddress,
-					To:      toAddress,
-					TokenId: &ltypes.BigUInt{Value: common.BigUInt{Int: event.TokenId}},
-					Amount:  &ltypes.BigUInt{Value: common.BigUInt{Int: event.Amount}},
-				},
-			}
-
-			events = append(events, eventInfo)
-			numTransferWithQuantityEvents++
-		} else {
-			err = transferWithQuantityIterator.Error()
-			if err != nil {
-				return nil, errors.Wrap(err, "Failed to get event data for TransferWithQuantity")
-			}
-			transferWithQuantityIterator.Close()
-			break
-		}
-	}
-
-	// BatchTransfer
-	batchTransferIterator, err := orc.pcGateway.zbgCard.FilterBatchTransfer(filterOpts)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get logs for BatchTransfer")
-	}
-	for {
-		ok := batchTransferIterator.Next()
-		if ok {
-			event := batchTransferIterator.Event
-			eventInfo, _, err := orc.processSingleRawEvent(event.Raw)
-			if err != nil {
-				return nil, err
-			}
-
-			fromAddress, toAddress, err := parseBasicEventData(&event.From, &event.To, chainID)
-			if err != nil {
-				return nil, errors.Wrap(err, "error parsing basic event data")
-			}
-
-			tokenIds := make([]*ltypes.BigUInt, len(event.TokenTypes))
-			amounts := make([]*ltypes.BigUInt, len(event.Amounts))
-
-			for index, tokenType := range event.TokenTypes {
-				amount := event.Amounts[index]
-
-				tokenIds[index] = &ltypes.BigUInt{Value: common.BigUInt{Int: tokenType}}
-				amounts[index] = &ltypes.BigUInt{Value: common.BigUInt{Int: amount}}
-			}
-
-			eventInfo.Event.Payload = &orctype.PlasmachainEvent_BatchTransfer{
-				BatchTransfer: &orctype.PlasmachainEventBatchTransfer{
-					From:     fromAddress,
-					To:       toAddress,
-					TokenIds: tokenIds,
-					Amounts:  amounts,
-				},
-			}
-
-			events = append(events, eventInfo)
-			numBatchTransferEvents++
-		} else {
-			err = batchTransferIterator.Error()
-			if err != nil {
-				return nil, errors.Wrap(err, "Failed to get event data for BatchTransfer")
-			}
-			batchTransferIterator.Close()
-			break
-		}
-	}
-
-	return events, nil
-}
@@ -1,23 +1,59 @@
 package oracle
 
 import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/gogo/protobuf/proto"
+	"github.com/loomnetwork/gamechain/tools/battleground_utility"
+	orctype "github.com/loomnetwork/gamechain/types/oracle"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	"github.com/loomnetwork/go-loom"
+	ltypes "github.com/loomnetwork/go-loom/types"
 	"github.com/stretchr/testify/require"
 )
 
-func TestRecentHashPool(t *testing.T) {
-	recentHashPool := newRecentHashPool(4 * time.Second)
-	recentHashPool.startCleanupRoutine()
+func TestSubmittedBatchJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oracle-journal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "journal.jsonl")
 
-	require.True(t, recentHashPool.addHash([]byte{1, 2, 3}), "adding hash for first time should succed")
+	journal := newSubmittedBatchJournal(path)
+	require.NoError(t, journal.load(), "missing journal file is an empty journal")
+	require.NoError(t, journal.record(10, []*orctype.PlasmachainEvent{
+		{EthBlock: 9, TxIndex: 1, LogIndex: 2},
+		{EthBlock: 10, TxIndex: 0, LogIndex: 0},
+	}))
+	require.NoError(t, journal.record(12, []*orctype.PlasmachainEvent{
+		{EthBlock: 12, TxIndex: 3, LogIndex: 4},
+	}))
 
-	require.False(t, recentHashPool.addHash([]byte{1, 2, 3}), "adding duplicate hash shouldnt be allowed")
+	journal = newSubmittedBatchJournal(path)
+	require.NoError(t, journal.load())
+	require.True(t, journal.contains(&orctype.PlasmachainEvent{EthBlock: 9, TxIndex: 1, LogIndex: 2}), "journal should survive a restart")
+	require.True(t, journal.contains(&orctype.PlasmachainEvent{EthBlock: 12, TxIndex: 3, LogIndex: 4}))
+	require.False(t, journal.contains(&orctype.PlasmachainEvent{EthBlock: 9, TxIndex: 1, LogIndex: 3}))
 
-	time.Sleep(5 * time.Second)
+	require.NoError(t, journal.removeAfter(9))
+	journal = newSubmittedBatchJournal(path)
+	require.NoError(t, journal.load())
+	require.True(t, journal.contains(&orctype.PlasmachainEvent{EthBlock: 9, TxIndex: 1, LogIndex: 2}))
+	require.False(t, journal.contains(&orctype.PlasmachainEvent{EthBlock: 10}), "rolled back events should be forgotten")
+	require.False(t, journal.contains(&orctype.PlasmachainEvent{EthBlock: 12, TxIndex: 3, LogIndex: 4}))
 
-	require.True(t, recentHashPool.addHash([]byte{1, 2, 3}), "after timeout, hash should be allowed")
+	require.NoError(t, journal.record(9+journalWindowBlockCount, []*orctype.PlasmachainEvent{
+		{EthBlock: 9 + journalWindowBlockCount},
+	}))
+	journal = newSubmittedBatchJournal(path)
+	require.NoError(t, journal.load())
+	require.False(t, journal.contains(&orctype.PlasmachainEvent{EthBlock: 9, TxIndex: 1, LogIndex: 2}), "old batches should be dropped")
+	require.True(t, journal.contains(&orctype.PlasmachainEvent{EthBlock: 9 + journalWindowBlockCount}))
 }
 
 func TestTransferGatewayOraclePlasmachainEventSort(t *testing.T) {
@@ -50,3 +86,1014 @@ func TestTransferGatewayOraclePlasmachainEventSort(t *testing.T) {
 	sortPlasmachainEvents(events)
 	require.EqualValues(t, sortedEvents, events, "wrong sort order")
 }
+
+func TestProcessedBlockTracker(t *testing.T) {
+	tracker := newProcessedBlockTracker(3)
+	for number := uint64(1); number <= 4; number++ {
+		tracker.add(&processedBlock{Number: number, Hash: []byte{byte(number)}})
+	}
+	require.Equal(t, 3, len(tracker.blocks), "oldest blocks should be dropped")
+	require.Equal(t, uint64(2), tracker.blocks[0].Number)
+
+	chainHashes := map[uint64][]byte{2: {2}, 3: {3}, 4: {4}}
+	getBlockHash := func(blockNumber uint64) ([]byte, error) {
+		return chainHashes[blockNumber], nil
+	}
+
+	_, invalidated, err := tracker.findInvalidatedBlocks(getBlockHash)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(invalidated), "no reorg expected")
+
+	chainHashes[3] = []byte{30}
+	chainHashes[4] = []byte{40}
+	lastValidBlockNumber, invalidated, err := tracker.findInvalidatedBlocks(getBlockHash)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), lastValidBlockNumber)
+	require.Equal(t, 2, len(invalidated))
+	require.Equal(t, uint64(3), invalidated[0].Number)
+
+	chainHashes[2] = []byte{20}
+	lastValidBlockNumber, invalidated, err = tracker.findInvalidatedBlocks(getBlockHash)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), lastValidBlockNumber, "reorg deeper than the tracked blocks")
+	require.Equal(t, 3, len(invalidated))
+
+	tracker.removeAfter(2)
+	require.Equal(t, 1, len(tracker.blocks))
+	require.Equal(t, uint64(2), tracker.blocks[0].Number)
+}
+
+func TestSplitEventBatches(t *testing.T) {
+	eventInfos := []*plasmachainEventInfo{
+		{BlockNum: 3, Event: &orctype.PlasmachainEvent{EthBlock: 3}},
+		{BlockNum: 3, Event: &orctype.PlasmachainEvent{EthBlock: 3}},
+		{BlockNum: 4, Event: &orctype.PlasmachainEvent{EthBlock: 4}},
+		{BlockNum: 6, Event: &orctype.PlasmachainEvent{EthBlock: 6}},
+		{BlockNum: 6, Event: &orctype.PlasmachainEvent{EthBlock: 6}},
+		{BlockNum: 6, Event: &orctype.PlasmachainEvent{EthBlock: 6}},
+		{BlockNum: 7, Event: &orctype.PlasmachainEvent{EthBlock: 7}},
+	}
+
+	batches := splitEventBatches(eventInfos, 2, 9, 0, 0)
+	require.Equal(t, 1, len(batches), "no limits")
+	require.Equal(t, uint64(2), batches[0].StartBlock)
+	require.Equal(t, uint64(9), batches[0].EndBlock)
+	require.Equal(t, 7, len(batches[0].EventInfos))
+
+	batches = splitEventBatches(eventInfos, 2, 9, 2, 0)
+	require.Equal(t, 4, len(batches))
+	require.Equal(t, []uint64{2, 4, 6, 7}, []uint64{batches[0].StartBlock, batches[1].StartBlock, batches[2].StartBlock, batches[3].StartBlock})
+	require.Equal(t, []uint64{3, 5, 6, 9}, []uint64{batches[0].EndBlock, batches[1].EndBlock, batches[2].EndBlock, batches[3].EndBlock})
+	require.Equal(t, 3, len(batches[2].EventInfos), "events of a block are never split")
+
+	eventSize := proto.Size(eventInfos[0].Event)
+	batches = splitEventBatches(eventInfos, 2, 9, 0, 3*eventSize)
+	require.Equal(t, 3, len(batches))
+	require.Equal(t, 3, len(batches[0].EventInfos))
+	require.Equal(t, 3, len(batches[1].EventInfos))
+	require.Equal(t, 1, len(batches[2].EventInfos))
+
+	batches = splitEventBatches(nil, 2, 9, 2, 0)
+	require.Equal(t, 1, len(batches), "empty block range still needs a batch")
+	require.Equal(t, uint64(9), batches[0].EndBlock)
+	require.Equal(t, 0, len(batches[0].EventInfos))
+}
+
+func TestCompensatingCardAmountChanges(t *testing.T) {
+	zbgCardContractAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000099")
+	user1Address := loom.MustParseAddress("default:0x0000000000000000000000000000000000000001")
+	user2Address := loom.MustParseAddress("default:0x0000000000000000000000000000000000000002")
+	bigUInt := func(value int64) *ltypes.BigUInt {
+		return battleground_utility.MarshalBigIntProto(big.NewInt(value))
+	}
+
+	events := []*orctype.PlasmachainEvent{
+		{
+			EthBlock: 10,
+			Payload: &orctype.PlasmachainEvent_TransferWithQuantity{
+				TransferWithQuantity: &orctype.PlasmachainEventTransferWithQuantity{
+					From:    zbgCardContractAddress.MarshalPB(),
+					To:      user1Address.MarshalPB(),
+					TokenId: bigUInt(100),
+					Amount:  bigUInt(5),
+				},
+			},
+		},
+		{
+			EthBlock: 11,
+			Payload: &orctype.PlasmachainEvent_BatchTransfer{
+				BatchTransfer: &orctype.PlasmachainEventBatchTransfer{
+					From:     user1Address.MarshalPB(),
+					To:       user2Address.MarshalPB(),
+					TokenIds: []*ltypes.BigUInt{bigUInt(100), bigUInt(200)},
+					Amounts:  []*ltypes.BigUInt{bigUInt(2), bigUInt(1)},
+				},
+			},
+		},
+		{
+			EthBlock: 11,
+			Payload: &orctype.PlasmachainEvent_Transfer{
+				Transfer: &orctype.PlasmachainEventTransfer{
+					From:    user2Address.MarshalPB(),
+					To:      user1Address.MarshalPB(),
+					TokenId: bigUInt(200),
+				},
+			},
+		},
+	}
+
+	changes := getCompensatingCardAmountChanges(events, zbgCardContractAddress)
+	require.Equal(t, 2, len(changes))
+	require.Equal(t, user1Address.Local, changes[0].Address.Local)
+	require.Equal(t, int64(100), changes[0].CardTokenId.Value.Int64())
+	require.Equal(t, int64(-3), changes[0].AmountChange)
+	require.Equal(t, user2Address.Local, changes[1].Address.Local)
+	require.Equal(t, int64(100), changes[1].CardTokenId.Value.Int64())
+	require.Equal(t, int64(-2), changes[1].AmountChange)
+}
+
+func TestCompensatingPackAmountChanges(t *testing.T) {
+	userAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000001")
+	newPackBurnEvent := func(packType zb_enums.PackType_Enum, amount int64) *orctype.PlasmachainEvent {
+		return &orctype.PlasmachainEvent{
+			Payload: &orctype.PlasmachainEvent_PackBurn{
+				PackBurn: &orctype.PlasmachainEventPackBurn{
+					From:     userAddress.MarshalPB(),
+					PackType: packType,
+					Amount:   battleground_utility.MarshalBigIntProto(big.NewInt(amount)),
+				},
+			},
+		}
+	}
+
+	changes := getCompensatingPackAmountChanges([]*orctype.PlasmachainEvent{
+		newPackBurnEvent(zb_enums.PackType_Super, 1),
+		newPackBurnEvent(zb_enums.PackType_Booster, 2),
+		newPackBurnEvent(zb_enums.PackType_Booster, 3),
+	})
+	require.Equal(t, []*orctype.PlasmachainPackAmountChange{
+		{Address: userAddress.MarshalPB(), PackType: zb_enums.PackType_Booster, AmountChange: -5},
+		{Address: userAddress.MarshalPB(), PackType: zb_enums.PackType_Super, AmountChange: -1},
+	}, changes)
+}
+
+func TestOracleCommunicationRound(t *testing.T) {
+	zbgCardContractAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000099")
+	gamechainAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000098")
+	user1Address := loom.MustParseAddress("default:0x0000000000000000000000000000000000000001")
+	user2Address := loom.MustParseAddress("default:0x0000000000000000000000000000000000000002")
+	newTransferEvent := func(from loom.Address, to loom.Address, cardTokenId int64, amount int64) *orctype.PlasmachainEvent {
+		return &orctype.PlasmachainEvent{
+			Payload: &orctype.PlasmachainEvent_TransferWithQuantity{
+				TransferWithQuantity: &orctype.PlasmachainEventTransferWithQuantity{
+					From:    from.MarshalPB(),
+					To:      to.MarshalPB(),
+					TokenId: battleground_utility.MarshalBigIntProto(big.NewInt(cardTokenId)),
+					Amount:  battleground_utility.MarshalBigIntProto(big.NewInt(amount)),
+				},
+			},
+		}
+	}
+
+	plasmachain := newFakePlasmachain(zbgCardContractAddress)
+	plasmachain.mineBlock()
+	gamechain := newFakeGamechain(gamechainAddress, 1)
+	orc := newTestOracle(plasmachain, gamechain, 2)
+	ctx := context.Background()
+
+	t.Run("Unconfirmed events are not submitted", func(t *testing.T) {
+		plasmachain.mineBlock(newTransferEvent(zbgCardContractAddress, user1Address, 100, 5))
+		plasmachain.mineBlock()
+
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, uint64(1), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, int64(0), gamechain.cardAmount(user1Address, 100))
+	})
+
+	t.Run("Confirmed events are submitted", func(t *testing.T) {
+		plasmachain.mineBlocks(2)
+
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, uint64(3), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, int64(5), gamechain.cardAmount(user1Address, 100))
+		require.Equal(t, uint64(1), orc.Status().PlasmachainEventsSubmittedCount)
+	})
+
+	t.Run("Failed submission is retried", func(t *testing.T) {
+		plasmachain.mineBlock(newTransferEvent(user1Address, user2Address, 100, 2))
+		plasmachain.mineBlocks(2)
+
+		gamechain.failNext("ProcessOracleEventBatch", 1)
+		require.Error(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, uint64(3), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, int64(0), gamechain.cardAmount(user2Address, 100))
+
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, uint64(6), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, int64(3), gamechain.cardAmount(user1Address, 100))
+		require.Equal(t, int64(2), gamechain.cardAmount(user2Address, 100))
+		require.Equal(t, uint64(2), orc.Status().PlasmachainEventsSubmittedCount)
+	})
+
+	t.Run("Events are not submitted twice after a partial failure", func(t *testing.T) {
+		plasmachain.mineBlock(newTransferEvent(user2Address, user1Address, 100, 1))
+		plasmachain.mineBlocks(2)
+		plasmachain.setTokensOwned(user1Address, []tokensOwnedResponseItem{
+			{Index: big.NewInt(100), Balance: big.NewInt(4)},
+		})
+		gamechain.commandRequests = []*orctype.OracleCommandRequest{
+			{
+				CommandId: 1,
+				Command: &orctype.OracleCommandRequest_GetUserFullCardCollection{
+					GetUserFullCardCollection: &orctype.OracleCommandRequest_GetUserFullCardCollectionCommandRequest{
+						UserAddress: user1Address.MarshalPB(),
+					},
+				},
+			},
+		}
+
+		gamechain.failNext("GetOracleCommandRequestList", 1)
+		require.Error(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, uint64(9), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, int64(4), gamechain.cardAmount(user1Address, 100))
+		require.Equal(t, 1, len(gamechain.commandRequests))
+		submittedBatchCount := gamechain.callCount("ProcessOracleEventBatch")
+
+		plasmachain.mineBlock()
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, uint64(10), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, int64(4), gamechain.cardAmount(user1Address, 100))
+		require.Equal(t, submittedBatchCount, gamechain.callCount("ProcessOracleEventBatch"))
+
+		require.Equal(t, 0, len(gamechain.commandRequests))
+		require.Equal(t, 1, len(gamechain.commandResponses))
+		response := gamechain.commandResponses[0].GetGetUserFullCardCollection()
+		require.Equal(t, uint64(10), response.BlockHeight)
+		require.Equal(t, 1, len(response.OwnedCards))
+		require.Equal(t, int64(4), response.OwnedCards[0].Amount.Value.Int64())
+	})
+
+	t.Run("Reorganised blocks are rolled back and processed again", func(t *testing.T) {
+		plasmachain.reorg(9)
+		plasmachain.mineBlocks(4)
+
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, 1, gamechain.callCount("RollbackOracleEventBatch"))
+		require.Equal(t, uint64(10), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, int64(3), gamechain.cardAmount(user1Address, 100))
+		require.Equal(t, int64(2), gamechain.cardAmount(user2Address, 100))
+		require.Equal(t, uint64(1), orc.Status().PlasmachainReorgCount)
+	})
+}
+
+func TestOraclePackBurns(t *testing.T) {
+	zbgCardContractAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000099")
+	gamechainAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000098")
+	userAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000001")
+
+	plasmachain := newFakePlasmachain(zbgCardContractAddress)
+	plasmachain.mineBlock()
+	gamechain := newFakeGamechain(gamechainAddress, 1)
+	orc := newTestOracle(plasmachain, gamechain, 0)
+	ctx := context.Background()
+
+	t.Run("Pack burns are submitted", func(t *testing.T) {
+		plasmachain.mineBlock(&orctype.PlasmachainEvent{
+			Payload: &orctype.PlasmachainEvent_PackBurn{
+				PackBurn: &orctype.PlasmachainEventPackBurn{
+					From:     userAddress.MarshalPB(),
+					PackType: zb_enums.PackType_Booster,
+					Amount:   battleground_utility.MarshalBigIntProto(big.NewInt(3)),
+				},
+			},
+		})
+
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, uint64(2), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, int64(3), gamechain.burnedPackAmount(userAddress, zb_enums.PackType_Booster))
+	})
+
+	t.Run("Reorganised pack burns are rolled back", func(t *testing.T) {
+		plasmachain.reorg(2)
+		plasmachain.mineBlocks(2)
+
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, 1, gamechain.callCount("RollbackOracleEventBatch"))
+		require.Equal(t, uint64(3), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, int64(0), gamechain.burnedPackAmount(userAddress, zb_enums.PackType_Booster))
+	})
+}
+
+func TestOracleEventBatching(t *testing.T) {
+	zbgCardContractAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000099")
+	gamechainAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000098")
+	userAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000001")
+	newMintEvent := func() *orctype.PlasmachainEvent {
+		return &orctype.PlasmachainEvent{
+			Payload: &orctype.PlasmachainEvent_TransferWithQuantity{
+				TransferWithQuantity: &orctype.PlasmachainEventTransferWithQuantity{
+					From:    zbgCardContractAddress.MarshalPB(),
+					To:      userAddress.MarshalPB(),
+					TokenId: battleground_utility.MarshalBigIntProto(big.NewInt(100)),
+					Amount:  battleground_utility.MarshalBigIntProto(big.NewInt(1)),
+				},
+			},
+		}
+	}
+
+	plasmachain := newFakePlasmachain(zbgCardContractAddress)
+	plasmachain.mineBlock()
+	gamechain := newFakeGamechain(gamechainAddress, 1)
+	orc := newTestOracle(plasmachain, gamechain, 0)
+	orc.maxBatchEventCount = 2
+	ctx := context.Background()
+
+	t.Run("Events are submitted in limited batches", func(t *testing.T) {
+		plasmachain.mineBlock(newMintEvent(), newMintEvent())
+		plasmachain.mineBlock(newMintEvent())
+		plasmachain.mineBlock(newMintEvent())
+		plasmachain.mineBlock()
+
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, []int{2, 2}, gamechain.batchEventCounts)
+		require.Equal(t, uint64(5), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, int64(4), gamechain.cardAmount(userAddress, 100))
+	})
+
+	t.Run("Successful batches are kept when a later batch fails", func(t *testing.T) {
+		plasmachain.mineBlock(newMintEvent())
+		plasmachain.mineBlock(newMintEvent())
+		poisonedBlock := plasmachain.mineBlock(newMintEvent())
+		gamechain.poisonedBlocks[poisonedBlock] = true
+
+		require.Error(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, uint64(7), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, int64(6), gamechain.cardAmount(userAddress, 100))
+	})
+
+	t.Run("Events of a block that keeps failing are quarantined", func(t *testing.T) {
+		require.Error(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, 0, gamechain.callCount("QuarantineOracleEvents"))
+
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, uint64(8), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, 1, len(gamechain.quarantinedEvents))
+		require.Equal(t, uint64(8), gamechain.quarantinedEvents[0].EthBlock)
+		require.Equal(t, int64(6), gamechain.cardAmount(userAddress, 100))
+		require.Equal(t, uint64(1), orc.Status().PlasmachainEventsQuarantinedCount)
+	})
+
+	t.Run("Batch that keeps failing is submitted block by block", func(t *testing.T) {
+		plasmachain.mineBlock(newMintEvent())
+		poisonedBlock := plasmachain.mineBlock(newMintEvent())
+		gamechain.poisonedBlocks[poisonedBlock] = true
+
+		for i := 0; i < 3; i++ {
+			require.Error(t, orc.doCommunicationRound(ctx))
+		}
+		require.Equal(t, uint64(8), gamechain.lastPlasmachainBlockNumber)
+
+		// the healthy block goes through, the poisoned one is retried on its own
+		require.Error(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, uint64(9), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, int64(7), gamechain.cardAmount(userAddress, 100))
+
+		require.Error(t, orc.doCommunicationRound(ctx))
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, uint64(10), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, 2, len(gamechain.quarantinedEvents))
+		require.Equal(t, uint64(2), orc.Status().PlasmachainEventsQuarantinedCount)
+	})
+
+	t.Run("Batches are limited again after the quarantine", func(t *testing.T) {
+		gamechain.batchEventCounts = nil
+		plasmachain.mineBlock(newMintEvent())
+		plasmachain.mineBlock(newMintEvent())
+
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, []int{2}, gamechain.batchEventCounts)
+		require.Equal(t, uint64(12), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, int64(9), gamechain.cardAmount(userAddress, 100))
+	})
+}
+
+func TestOracleJournal(t *testing.T) {
+	zbgCardContractAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000099")
+	gamechainAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000098")
+	userAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000001")
+	newMintEvent := func() *orctype.PlasmachainEvent {
+		return &orctype.PlasmachainEvent{
+			Payload: &orctype.PlasmachainEvent_TransferWithQuantity{
+				TransferWithQuantity: &orctype.PlasmachainEventTransferWithQuantity{
+					From:    zbgCardContractAddress.MarshalPB(),
+					To:      userAddress.MarshalPB(),
+					TokenId: battleground_utility.MarshalBigIntProto(big.NewInt(100)),
+					Amount:  battleground_utility.MarshalBigIntProto(big.NewInt(1)),
+				},
+			},
+		}
+	}
+
+	plasmachain := newFakePlasmachain(zbgCardContractAddress)
+	plasmachain.mineBlock()
+	plasmachain.mineBlock(newMintEvent(), newMintEvent())
+	gamechain := newFakeGamechain(gamechainAddress, 1)
+	orc := newTestOracle(plasmachain, gamechain, 0)
+	ctx := context.Background()
+
+	require.NoError(t, orc.doCommunicationRound(ctx))
+	require.Equal(t, int64(2), gamechain.cardAmount(userAddress, 100))
+
+	t.Run("Restarted oracle doesn't submit journaled events again", func(t *testing.T) {
+		// the last processed block was moved back, e.g. to recover from a Gamechain state problem
+		gamechain.lastPlasmachainBlockNumber = 1
+		plasmachain.mineBlock(newMintEvent())
+		restartedOrc := newTestOracle(plasmachain, gamechain, 0)
+		restartedOrc.journal = orc.journal
+
+		require.NoError(t, restartedOrc.doCommunicationRound(ctx))
+		require.Equal(t, uint64(3), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, int64(3), gamechain.cardAmount(userAddress, 100))
+		require.Equal(t, uint64(1), restartedOrc.Status().PlasmachainEventsSubmittedCount)
+	})
+}
+
+func TestOracleSetMode(t *testing.T) {
+	zbgCardContractAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000099")
+	gamechainAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000098")
+	userAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000001")
+
+	plasmachain := newFakePlasmachain(zbgCardContractAddress)
+	plasmachain.mineBlock()
+	gamechain := newFakeGamechain(gamechainAddress, 1)
+	orc := newTestOracle(plasmachain, gamechain, 0)
+	orc.oracleSetMode = true
+	orc.maxBlockRange = 5
+	ctx := context.Background()
+
+	t.Run("Incomplete block ranges are not submitted", func(t *testing.T) {
+		plasmachain.mineBlock()
+		plasmachain.mineBlock(&orctype.PlasmachainEvent{
+			Payload: &orctype.PlasmachainEvent_TransferWithQuantity{
+				TransferWithQuantity: &orctype.PlasmachainEventTransferWithQuantity{
+					From:    zbgCardContractAddress.MarshalPB(),
+					To:      userAddress.MarshalPB(),
+					TokenId: battleground_utility.MarshalBigIntProto(big.NewInt(100)),
+					Amount:  battleground_utility.MarshalBigIntProto(big.NewInt(2)),
+				},
+			},
+		})
+		plasmachain.mineBlock()
+
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, 0, gamechain.callCount("ProcessOracleEventBatch"))
+		require.Equal(t, uint64(1), gamechain.lastPlasmachainBlockNumber)
+	})
+
+	t.Run("Block ranges end at multiples of the max block range", func(t *testing.T) {
+		plasmachain.mineBlocks(3)
+
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, [][2]uint64{{2, 5}}, gamechain.batchBlockRanges)
+		require.Equal(t, uint64(5), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, int64(2), gamechain.cardAmount(userAddress, 100))
+	})
+
+	t.Run("Ranges without events are submitted as empty batches", func(t *testing.T) {
+		plasmachain.mineBlocks(5)
+
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, [][2]uint64{{2, 5}, {6, 10}}, gamechain.batchBlockRanges)
+		require.Equal(t, []int{1, 0}, gamechain.batchEventCounts)
+		require.Equal(t, 0, gamechain.callCount("SetLastPlasmaBlockNumber"))
+		require.Equal(t, uint64(10), gamechain.lastPlasmachainBlockNumber)
+	})
+
+	t.Run("Batches waiting for votes are submitted again", func(t *testing.T) {
+		gamechain.pendingVoteCount = 1
+		plasmachain.mineBlocks(5)
+
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, 3, gamechain.callCount("ProcessOracleEventBatch"))
+		require.Equal(t, uint64(10), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, uint64(11), orc.startBlock)
+
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, [][2]uint64{{2, 5}, {6, 10}, {11, 15}}, gamechain.batchBlockRanges)
+		require.Equal(t, uint64(15), gamechain.lastPlasmachainBlockNumber)
+	})
+
+	t.Run("Reorgs of blocks Gamechain hasn't processed are dropped locally", func(t *testing.T) {
+		gamechain.pendingVoteCount = 1
+		plasmachain.mineBlocks(5)
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, uint64(15), gamechain.lastPlasmachainBlockNumber)
+
+		plasmachain.reorg(18)
+		plasmachain.mineBlocks(5)
+
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, 0, gamechain.callCount("RollbackOracleEventBatch"))
+		require.Equal(t, uint64(1), orc.Status().PlasmachainReorgCount)
+		require.Equal(t, uint64(20), gamechain.lastPlasmachainBlockNumber)
+	})
+
+	t.Run("Rollbacks waiting for votes are submitted again", func(t *testing.T) {
+		plasmachain.mineBlocks(3)
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, uint64(25), gamechain.lastPlasmachainBlockNumber)
+
+		gamechain.pendingVoteCount = 1
+		plasmachain.reorg(24)
+		plasmachain.mineBlocks(5)
+
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, 1, gamechain.callCount("RollbackOracleEventBatch"))
+		require.Equal(t, uint64(25), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, uint64(1), orc.Status().PlasmachainReorgCount)
+
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, 2, gamechain.callCount("RollbackOracleEventBatch"))
+		require.Equal(t, uint64(2), orc.Status().PlasmachainReorgCount)
+		require.Equal(t, uint64(25), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, [2]uint64{21, 25}, gamechain.batchBlockRanges[len(gamechain.batchBlockRanges)-1])
+	})
+
+	t.Run("Failing batches are neither isolated nor quarantined", func(t *testing.T) {
+		orc.maxBatchAttempts = 1
+		block := plasmachain.mineBlock(&orctype.PlasmachainEvent{
+			Payload: &orctype.PlasmachainEvent_TransferWithQuantity{
+				TransferWithQuantity: &orctype.PlasmachainEventTransferWithQuantity{
+					From:    zbgCardContractAddress.MarshalPB(),
+					To:      userAddress.MarshalPB(),
+					TokenId: battleground_utility.MarshalBigIntProto(big.NewInt(100)),
+					Amount:  battleground_utility.MarshalBigIntProto(big.NewInt(1)),
+				},
+			},
+		})
+		plasmachain.mineBlock()
+		gamechain.poisonedBlocks[block] = true
+
+		for i := 0; i < 3; i++ {
+			require.Error(t, orc.doCommunicationRound(ctx))
+		}
+		require.Equal(t, 0, gamechain.callCount("QuarantineOracleEvents"))
+		require.Equal(t, uint64(0), orc.isolateUntilBlock)
+
+		delete(gamechain.poisonedBlocks, block)
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, [2]uint64{26, 30}, gamechain.batchBlockRanges[len(gamechain.batchBlockRanges)-1])
+	})
+}
+
+func TestOracleCommands(t *testing.T) {
+	zbgCardContractAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000099")
+	gamechainAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000098")
+	userAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000001")
+
+	plasmachain := newFakePlasmachain(zbgCardContractAddress)
+	plasmachain.mineBlocks(5)
+	gamechain := newFakeGamechain(gamechainAddress, 5)
+	orc := newTestOracle(plasmachain, gamechain, 0)
+	ctx := context.Background()
+
+	// executes a single command and returns its response
+	executeCommand := func(t *testing.T, commandRequest *orctype.OracleCommandRequest) *orctype.OracleCommandResponse {
+		gamechain.commandRequests = []*orctype.OracleCommandRequest{commandRequest}
+		gamechain.commandResponses = nil
+
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, 0, len(gamechain.commandRequests))
+		require.Equal(t, 1, len(gamechain.commandResponses))
+		require.Equal(t, commandRequest.CommandId, gamechain.commandResponses[0].CommandId)
+		return gamechain.commandResponses[0]
+	}
+
+	newGetPackBalancesCommand := func(commandId uint64) *orctype.OracleCommandRequest {
+		return &orctype.OracleCommandRequest{
+			CommandId: commandId,
+			Command: &orctype.OracleCommandRequest_GetPackBalances{
+				GetPackBalances: &orctype.OracleCommandRequest_GetPackBalancesCommandRequest{
+					UserAddress: userAddress.MarshalPB(),
+				},
+			},
+		}
+	}
+
+	newVerifyMintingReceiptConsumedCommand := func(commandId uint64, txId int64) *orctype.OracleCommandRequest {
+		return &orctype.OracleCommandRequest{
+			CommandId: commandId,
+			Command: &orctype.OracleCommandRequest_VerifyMintingReceiptConsumed{
+				VerifyMintingReceiptConsumed: &orctype.OracleCommandRequest_VerifyMintingReceiptConsumedCommandRequest{
+					UserId: "user",
+					TxId:   battleground_utility.MarshalBigIntProto(big.NewInt(txId)),
+				},
+			},
+		}
+	}
+
+	t.Run("GetUserFullCardCollection", func(t *testing.T) {
+		plasmachain.setTokensOwned(userAddress, []tokensOwnedResponseItem{
+			{Index: big.NewInt(100), Balance: big.NewInt(2)},
+			{Index: big.NewInt(101), Balance: big.NewInt(1)},
+		})
+
+		response := executeCommand(t, &orctype.OracleCommandRequest{
+			CommandId: 1,
+			Command: &orctype.OracleCommandRequest_GetUserFullCardCollection{
+				GetUserFullCardCollection: &orctype.OracleCommandRequest_GetUserFullCardCollectionCommandRequest{
+					UserAddress: userAddress.MarshalPB(),
+				},
+			},
+		}).GetGetUserFullCardCollection()
+		require.NotNil(t, response)
+		require.Equal(t, uint64(5), response.BlockHeight)
+		require.Equal(t, 2, len(response.OwnedCards))
+		require.Equal(t, int64(101), response.OwnedCards[1].CardTokenId.Value.Int64())
+		require.Equal(t, int64(1), response.OwnedCards[1].Amount.Value.Int64())
+	})
+
+	t.Run("VerifyMintingReceiptConsumed", func(t *testing.T) {
+		plasmachain.consumeMintingReceipt(big.NewInt(7))
+
+		response := executeCommand(t, newVerifyMintingReceiptConsumedCommand(2, 7)).GetVerifyMintingReceiptConsumed()
+		require.NotNil(t, response)
+		require.True(t, response.Consumed)
+		require.Equal(t, "user", response.UserId)
+		require.Equal(t, int64(7), response.TxId.Value.Int64())
+		require.Equal(t, uint64(5), response.BlockHeight)
+
+		response = executeCommand(t, newVerifyMintingReceiptConsumedCommand(3, 8)).GetVerifyMintingReceiptConsumed()
+		require.NotNil(t, response)
+		require.False(t, response.Consumed)
+	})
+
+	t.Run("GetPackBalances", func(t *testing.T) {
+		plasmachain.setPackBalances(userAddress, []packBalance{
+			{PackType: zb_enums.PackType_Booster, Balance: big.NewInt(3)},
+			{PackType: zb_enums.PackType_Air, Balance: big.NewInt(1)},
+		})
+
+		response := executeCommand(t, newGetPackBalancesCommand(4)).GetGetPackBalances()
+		require.NotNil(t, response)
+		require.Equal(t, uint64(5), response.BlockHeight)
+		require.Equal(t, 2, len(response.PackBalances))
+		require.Equal(t, zb_enums.PackType_Air, response.PackBalances[1].PackType)
+		require.Equal(t, int64(1), response.PackBalances[1].Amount.Value.Int64())
+	})
+
+	t.Run("Plasmachain errors are reported as retryable failures", func(t *testing.T) {
+		plasmachain.failNext("GetPackBalances", 1)
+
+		failure := executeCommand(t, newGetPackBalancesCommand(5)).GetFailure()
+		require.NotNil(t, failure)
+		require.False(t, failure.Permanent)
+		require.Contains(t, failure.Error, "GetPackBalances")
+	})
+
+	t.Run("Unknown commands are reported as permanent failures", func(t *testing.T) {
+		failure := executeCommand(t, &orctype.OracleCommandRequest{CommandId: 6}).GetFailure()
+		require.NotNil(t, failure)
+		require.True(t, failure.Permanent)
+	})
+
+	t.Run("Invalid commands are reported as permanent failures", func(t *testing.T) {
+		failure := executeCommand(t, &orctype.OracleCommandRequest{
+			CommandId: 7,
+			Command: &orctype.OracleCommandRequest_GetPackBalances{
+				GetPackBalances: &orctype.OracleCommandRequest_GetPackBalancesCommandRequest{},
+			},
+		}).GetFailure()
+		require.NotNil(t, failure)
+		require.True(t, failure.Permanent)
+	})
+}
+
+func TestOracleMintingReceiptSweep(t *testing.T) {
+	zbgCardContractAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000099")
+	gamechainAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000098")
+
+	plasmachain := newFakePlasmachain(zbgCardContractAddress)
+	plasmachain.mineBlock()
+	plasmachain.consumeMintingReceipt(big.NewInt(1))
+	gamechain := newFakeGamechain(gamechainAddress, 1)
+	orc := newTestOracle(plasmachain, gamechain, 0)
+	orc.gcMintingReceiptSweepInterval = time.Hour
+	ctx := context.Background()
+
+	for txId := int64(1); txId <= 2; txId++ {
+		gamechain.mintingReceiptCommandRequests = append(gamechain.mintingReceiptCommandRequests, &orctype.OracleCommandRequest{
+			CommandId: uint64(txId),
+			Command: &orctype.OracleCommandRequest_VerifyMintingReceiptConsumed{
+				VerifyMintingReceiptConsumed: &orctype.OracleCommandRequest_VerifyMintingReceiptConsumedCommandRequest{
+					UserId: "user",
+					TxId:   battleground_utility.MarshalBigIntProto(big.NewInt(txId)),
+				},
+			},
+		})
+	}
+
+	t.Run("Queued receipt checks are executed in the same round", func(t *testing.T) {
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, 1, gamechain.callCount("SweepMintingReceipts"))
+		require.Equal(t, 2, len(gamechain.commandResponses))
+		require.True(t, gamechain.commandResponses[0].GetVerifyMintingReceiptConsumed().Consumed)
+		require.False(t, gamechain.commandResponses[1].GetVerifyMintingReceiptConsumed().Consumed)
+	})
+
+	t.Run("Receipts are not swept before the interval", func(t *testing.T) {
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, 1, gamechain.callCount("SweepMintingReceipts"))
+	})
+
+	t.Run("Failed sweep is retried", func(t *testing.T) {
+		orc.lastMintingReceiptSweepTime = time.Time{}
+		gamechain.failNext("SweepMintingReceipts", 1)
+		require.Error(t, orc.doCommunicationRound(ctx))
+		require.NoError(t, orc.doCommunicationRound(ctx))
+		require.Equal(t, 3, gamechain.callCount("SweepMintingReceipts"))
+	})
+}
+
+func TestOracleStatus(t *testing.T) {
+	zbgCardContractAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000099")
+	gamechainAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000098")
+	userAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000001")
+
+	plasmachain := newFakePlasmachain(zbgCardContractAddress)
+	plasmachain.mineBlocks(5)
+	gamechain := newFakeGamechain(gamechainAddress, 1)
+	orc := newTestOracle(plasmachain, gamechain, 2)
+	orc.maxBlockRange = 2
+	orc.maxPlasmachainBlockLag = 2
+	ctx := context.Background()
+
+	doRound := func() error {
+		err := orc.doCommunicationRound(ctx)
+		orc.finishRound(err)
+		return err
+	}
+
+	t.Run("Lag within the limit is healthy", func(t *testing.T) {
+		require.NoError(t, doRound())
+
+		status := orc.Status()
+		require.Equal(t, uint64(5), status.PlasmachainLatestBlockNumber)
+		require.Equal(t, uint64(3), status.PlasmachainProcessedBlockNumber)
+		require.Equal(t, uint64(2), status.PlasmachainBlockLag)
+		require.True(t, status.Healthy)
+	})
+
+	t.Run("Lag over the limit is unhealthy", func(t *testing.T) {
+		plasmachain.mineBlock(&orctype.PlasmachainEvent{
+			Payload: &orctype.PlasmachainEvent_Transfer{
+				Transfer: &orctype.PlasmachainEventTransfer{
+					From:    zbgCardContractAddress.MarshalPB(),
+					To:      userAddress.MarshalPB(),
+					TokenId: battleground_utility.MarshalBigIntProto(big.NewInt(100)),
+				},
+			},
+		})
+		plasmachain.mineBlocks(4)
+		gamechain.failNext("ProcessOracleEventBatch", 1)
+		require.Error(t, doRound())
+
+		status := orc.Status()
+		require.Equal(t, uint64(10), status.PlasmachainLatestBlockNumber)
+		require.Equal(t, uint64(3), status.PlasmachainProcessedBlockNumber)
+		require.Equal(t, uint64(7), status.PlasmachainBlockLag)
+		require.Equal(t, uint64(1), status.EventBatchFailureCount)
+		require.True(t, status.Ready)
+		require.False(t, status.Healthy)
+
+		for i := 0; i < 2; i++ {
+			require.NoError(t, doRound())
+		}
+		status = orc.Status()
+		require.Equal(t, uint64(2), status.PlasmachainBlockLag)
+		require.True(t, status.Healthy)
+	})
+
+	t.Run("Command backlog is reported", func(t *testing.T) {
+		now := time.Now().Unix()
+		gamechain.commandRequests = []*orctype.OracleCommandRequest{
+			{
+				CommandId: 1,
+				CreatedAt: now - 10,
+				Command: &orctype.OracleCommandRequest_GetPackBalances{
+					GetPackBalances: &orctype.OracleCommandRequest_GetPackBalancesCommandRequest{
+						UserAddress: userAddress.MarshalPB(),
+					},
+				},
+			},
+			{
+				CommandId: 2,
+				CreatedAt: now - 60,
+				Command: &orctype.OracleCommandRequest_GetPackBalances{
+					GetPackBalances: &orctype.OracleCommandRequest_GetPackBalancesCommandRequest{
+						UserAddress: userAddress.MarshalPB(),
+					},
+				},
+			},
+		}
+		gamechain.failNext("ProcessOracleCommandResponseBatch", 1)
+		require.Error(t, doRound())
+
+		status := orc.Status()
+		require.Equal(t, 2, status.PendingCommandCount)
+		require.True(t, status.OldestPendingCommandAge >= 60)
+		require.Equal(t, uint64(1), status.CommandResponseBatchFailureCount)
+
+		require.NoError(t, doRound())
+		require.NoError(t, doRound())
+		status = orc.Status()
+		require.Equal(t, 0, status.PendingCommandCount)
+		require.Equal(t, int64(0), status.OldestPendingCommandAge)
+	})
+}
+
+func TestOracleBackfill(t *testing.T) {
+	zbgCardContractAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000099")
+	gamechainAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000098")
+	user1Address := loom.MustParseAddress("default:0x0000000000000000000000000000000000000001")
+	user2Address := loom.MustParseAddress("default:0x0000000000000000000000000000000000000002")
+
+	dir, err := ioutil.TempDir("", "oracle-backfill")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	plasmachain := newFakePlasmachain(zbgCardContractAddress)
+	plasmachain.mineBlocks(2)
+	plasmachain.mineBlock(&orctype.PlasmachainEvent{
+		Payload: &orctype.PlasmachainEvent_Transfer{
+			Transfer: &orctype.PlasmachainEventTransfer{
+				From:    zbgCardContractAddress.MarshalPB(),
+				To:      user1Address.MarshalPB(),
+				TokenId: battleground_utility.MarshalBigIntProto(big.NewInt(100)),
+			},
+		},
+	})
+	plasmachain.mineBlocks(3)
+	plasmachain.mineBlock(&orctype.PlasmachainEvent{
+		Payload: &orctype.PlasmachainEvent_TransferWithQuantity{
+			TransferWithQuantity: &orctype.PlasmachainEventTransferWithQuantity{
+				From:    zbgCardContractAddress.MarshalPB(),
+				To:      user2Address.MarshalPB(),
+				TokenId: battleground_utility.MarshalBigIntProto(big.NewInt(101)),
+				Amount:  battleground_utility.MarshalBigIntProto(big.NewInt(2)),
+			},
+		},
+	})
+	plasmachain.mineBlocks(3)
+	ctx := context.Background()
+
+	t.Run("Events are replayed and the backfill resumes from the checkpoint", func(t *testing.T) {
+		gamechain := newFakeGamechain(gamechainAddress, 9)
+		gamechain.poisonedBlocks[7] = true
+		orc := newTestOracle(plasmachain, gamechain, 0)
+
+		var progress []BackfillProgress
+		cfg := BackfillConfig{
+			FromBlock:      2,
+			ToBlock:        8,
+			BlockRange:     3,
+			CheckpointPath: filepath.Join(dir, "replay.json"),
+			OnProgress: func(p BackfillProgress) {
+				progress = append(progress, p)
+			},
+		}
+		require.Error(t, orc.Backfill(ctx, cfg), "processed blocks shouldn't be replayed without force")
+		require.Equal(t, uint64(9), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, 0, gamechain.callCount("ProcessOracleEventBatch"))
+
+		cfg.Force = true
+		require.Error(t, orc.Backfill(ctx, cfg))
+		require.Equal(t, uint64(4), gamechain.lastPlasmachainBlockNumber, "the range should be replayed from its start")
+		require.Equal(t, int64(1), gamechain.cardAmount(user1Address, 100))
+		require.Equal(t, 1, len(progress))
+
+		delete(gamechain.poisonedBlocks, 7)
+		require.NoError(t, orc.Backfill(ctx, cfg))
+		require.Equal(t, [][2]uint64{{2, 4}, {5, 7}}, gamechain.batchBlockRanges)
+		require.Equal(t, uint64(8), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, int64(1), gamechain.cardAmount(user1Address, 100))
+		require.Equal(t, int64(2), gamechain.cardAmount(user2Address, 101))
+		require.Equal(t, 3, len(progress))
+		require.Equal(t, uint64(8), progress[2].LastBlock)
+
+		require.NoError(t, orc.Backfill(ctx, cfg), "finished backfill should do nothing")
+		require.Equal(t, 2, len(gamechain.batchBlockRanges))
+
+		cfg.ToBlock = 9
+		require.Error(t, orc.Backfill(ctx, cfg), "checkpoint of another range should be rejected")
+	})
+
+	t.Run("Collections are resynced instead of replaying the events", func(t *testing.T) {
+		gamechain := newFakeGamechain(gamechainAddress, 1)
+		orc := newTestOracle(plasmachain, gamechain, 0)
+
+		require.NoError(t, orc.Backfill(ctx, BackfillConfig{
+			FromBlock:         2,
+			ToBlock:           8,
+			BlockRange:        3,
+			CheckpointPath:    filepath.Join(dir, "resync.json"),
+			ResyncCollections: true,
+		}))
+		require.Equal(t, []loom.Address{user1Address, user2Address}, gamechain.fullCardCollectionSyncAddresses)
+		require.Equal(t, 0, gamechain.callCount("ProcessOracleEventBatch"))
+		require.Equal(t, uint64(8), gamechain.lastPlasmachainBlockNumber)
+	})
+
+	t.Run("Blocks Gamechain never processed can't be skipped", func(t *testing.T) {
+		gamechain := newFakeGamechain(gamechainAddress, 1)
+		orc := newTestOracle(plasmachain, gamechain, 0)
+
+		require.Error(t, orc.Backfill(ctx, BackfillConfig{FromBlock: 4, ToBlock: 8, BlockRange: 3, Force: true}))
+		require.Error(t, orc.Backfill(ctx, BackfillConfig{FromBlock: 4, ToBlock: 8, BlockRange: 3, ResyncCollections: true}))
+		require.Equal(t, uint64(1), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, 0, gamechain.callCount("ProcessOracleEventBatch"))
+		require.Equal(t, 0, len(gamechain.fullCardCollectionSyncAddresses))
+	})
+
+	t.Run("Blocks with pack burns can't be resynced", func(t *testing.T) {
+		packBurnPlasmachain := newFakePlasmachain(zbgCardContractAddress)
+		packBurnPlasmachain.mineBlocks(2)
+		packBurnPlasmachain.mineBlock(&orctype.PlasmachainEvent{
+			Payload: &orctype.PlasmachainEvent_PackBurn{
+				PackBurn: &orctype.PlasmachainEventPackBurn{
+					From:     user1Address.MarshalPB(),
+					PackType: zb_enums.PackType_Booster,
+					Amount:   battleground_utility.MarshalBigIntProto(big.NewInt(1)),
+				},
+			},
+		})
+		gamechain := newFakeGamechain(gamechainAddress, 1)
+		orc := newTestOracle(packBurnPlasmachain, gamechain, 0)
+
+		require.Error(t, orc.Backfill(ctx, BackfillConfig{FromBlock: 2, ToBlock: 3, BlockRange: 3, ResyncCollections: true}))
+		require.Equal(t, uint64(1), gamechain.lastPlasmachainBlockNumber)
+		require.Equal(t, 0, len(gamechain.fullCardCollectionSyncAddresses))
+	})
+
+	t.Run("Backfill is rejected in oracle set mode", func(t *testing.T) {
+		gamechain := newFakeGamechain(gamechainAddress, 1)
+		orc := newTestOracle(plasmachain, gamechain, 0)
+		orc.oracleSetMode = true
+
+		require.Error(t, orc.Backfill(ctx, BackfillConfig{FromBlock: 2, ToBlock: 8, BlockRange: 3}))
+		require.Equal(t, 0, gamechain.callCount("ProcessOracleEventBatch"))
+	})
+
+	t.Run("Blocks without enough confirmations can't be backfilled", func(t *testing.T) {
+		gamechain := newFakeGamechain(gamechainAddress, 1)
+		orc := newTestOracle(plasmachain, gamechain, 5)
+
+		require.Error(t, orc.Backfill(ctx, BackfillConfig{FromBlock: 2, ToBlock: 8, BlockRange: 3}))
+		require.Equal(t, uint64(1), gamechain.lastPlasmachainBlockNumber)
+	})
+}
+
+func TestOracleRun(t *testing.T) {
+	zbgCardContractAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000099")
+	gamechainAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000098")
+	userAddress := loom.MustParseAddress("default:0x0000000000000000000000000000000000000001")
+
+	plasmachain := newFakePlasmachain(zbgCardContractAddress)
+	plasmachain.mineBlock()
+	plasmachain.mineBlock(&orctype.PlasmachainEvent{
+		Payload: &orctype.PlasmachainEvent_Transfer{
+			Transfer: &orctype.PlasmachainEventTransfer{
+				From:    zbgCardContractAddress.MarshalPB(),
+				To:      userAddress.MarshalPB(),
+				TokenId: battleground_utility.MarshalBigIntProto(big.NewInt(100)),
+			},
+		},
+	})
+	gamechain := newFakeGamechain(gamechainAddress, 1)
+	gamechain.failNext("GetLastPlasmaBlockNumber", 2)
+	orc := newTestOracle(plasmachain, gamechain, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		orc.Run(ctx)
+		close(stopped)
+	}()
+
+	for i := 0; i < 100 && orc.Status().PlasmachainEventsSubmittedCount == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("oracle didn't stop after the context was cancelled")
+	}
+
+	status := orc.Status()
+	require.Equal(t, uint64(1), status.PlasmachainEventsSubmittedCount, "events should be submitted after the failed rounds")
+	require.False(t, status.LastSuccessfulRoundTime.IsZero())
+	require.Equal(t, gamechainAddress.String(), status.GamechainGatewayAddress)
+	require.Equal(t, int64(1), gamechain.cardAmount(userAddress, 100))
+}
@@ -0,0 +1,61 @@
+package oracle
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	"github.com/pkg/errors"
+)
+
+// Only the read-only methods the oracle commands need are included,
+// so there's no point in generating full bindings for these contracts.
+
+const fiatPurchaseABI = `[{"constant":true,"inputs":[{"name":"txID","type":"uint256"}],"name":"txIdUsed","outputs":[{"name":"","type":"bool"}],"payable":false,"stateMutability":"view","type":"function"}]`
+
+const packABI = `[{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"}]`
+
+// Pack contracts burn packs by transferring them to the zero address
+var packTransferEventId = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+type packBalance struct {
+	PackType zb_enums.PackType_Enum
+	Balance  *big.Int
+}
+
+type packContract struct {
+	packType zb_enums.PackType_Enum
+	address  common.Address
+	contract *bind.BoundContract
+}
+
+func bindReadOnlyContract(contractABI string, address common.Address, backend bind.ContractBackend) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(contractABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, backend, backend, backend), nil
+}
+
+// parsePackContractHexAddresses converts the pack type names in the config to pack types
+func parsePackContractHexAddresses(packTypeToHexAddress map[string]string) (map[zb_enums.PackType_Enum]common.Address, error) {
+	packContractAddresses := make(map[zb_enums.PackType_Enum]common.Address)
+	for packTypeName, hexAddress := range packTypeToHexAddress {
+		packTypeValue, exists := zb_enums.PackType_Enum_value[packTypeName]
+		if !exists {
+			return nil, errors.Errorf("unknown pack type %s", packTypeName)
+		}
+
+		if !common.IsHexAddress(hexAddress) {
+			return nil, errors.Errorf("invalid %s pack contract address %s", packTypeName, hexAddress)
+		}
+
+		packContractAddresses[zb_enums.PackType_Enum(packTypeValue)] = common.HexToAddress(hexAddress)
+	}
+
+	return packContractAddresses, nil
+}
@@ -1,22 +1,37 @@
 package oracle
 
 import (
+	"context"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"math/big"
+	"sort"
 	"time"
 
 	"github.com/loomnetwork/gamechain/oracle/ethcontract"
 	orctype "github.com/loomnetwork/gamechain/types/oracle"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
 	loom "github.com/loomnetwork/go-loom"
 	"github.com/loomnetwork/go-loom/auth"
 	"github.com/loomnetwork/go-loom/client"
+	lcommon "github.com/loomnetwork/go-loom/common"
+	ptypes "github.com/loomnetwork/go-loom/plugin/types"
+	ltypes "github.com/loomnetwork/go-loom/types"
+	"github.com/pkg/errors"
 )
 
 type plasmachainEventInfo struct {
-	BlockNum uint64
-	TxIdx    uint
-	Event    *orctype.PlasmachainEvent
+	BlockNum  uint64
+	BlockHash []byte
+	TxIdx     uint
+	LogIdx    uint
+	// Name of the ZBGCard event, TransferToken events have the same payload as TransferWithQuantity.
+	// PackBurn for transfers of pack tokens to the zero address.
+	Kind  string
+	Event *orctype.PlasmachainEvent
 }
 
 type tokensOwnedResponseItem struct{
@@ -24,6 +39,8 @@ type tokensOwnedResponseItem struct{
 	Balance *big.Int
 }
 
+var _ plasmachainSource = &PlasmachainGateway{}
+
 type PlasmachainGateway struct {
 	Address loom.Address
 	// Timestamp of the last successful response from the DAppChain
@@ -37,12 +54,19 @@ type PlasmachainGateway struct {
 	client *client.DAppChainRPCClient
 	// zbgCard
 	zbgCard *ethcontract.ZBGCard
+	backend bind.ContractBackend
+	// nil if the fiat purchase contract address isn't configured
+	fiatPurchase *bind.BoundContract
+	// sorted by pack type
+	packContracts []packContract
 }
 
 func ConnectToPlasmachainGateway(
 	loomClient *client.DAppChainRPCClient,
 	caller loom.Address,
 	zbgCardContractAddress loom.Address,
+	fiatPurchaseContractAddress *common.Address,
+	packContractAddresses map[zb_enums.PackType_Enum]common.Address,
 	signer auth.Signer,
 	logger *loom.Logger,
 ) (*PlasmachainGateway, error) {
@@ -54,6 +78,31 @@ func ConnectToPlasmachainGateway(
 		return nil, err
 	}
 
+	var fiatPurchase *bind.BoundContract
+	if fiatPurchaseContractAddress != nil {
+		fiatPurchase, err = bindReadOnlyContract(fiatPurchaseABI, *fiatPurchaseContractAddress, backend)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to bind fiat purchase contract")
+		}
+	}
+
+	packContracts := make([]packContract, 0, len(packContractAddresses))
+	for packType, packContractAddress := range packContractAddresses {
+		contract, err := bindReadOnlyContract(packABI, packContractAddress, backend)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to bind %s pack contract", packType.String())
+		}
+
+		packContracts = append(packContracts, packContract{
+			packType: packType,
+			address:  packContractAddress,
+			contract: contract,
+		})
+	}
+	sort.Slice(packContracts, func(i, j int) bool {
+		return packContracts[i].packType < packContracts[j].packType
+	})
+
 	return &PlasmachainGateway{
 		Address:          gatewayAddr,
 		LastResponseTime: time.Now(),
@@ -63,9 +112,20 @@ func ConnectToPlasmachainGateway(
 		logger:           logger,
 		client:           loomClient,
 		zbgCard:          zbgCard,
+		backend:          backend,
+		fiatPurchase:     fiatPurchase,
+		packContracts:    packContracts,
 	}, nil
 }
 
+func (gw *PlasmachainGateway) GatewayAddress() loom.Address {
+	return gw.Address
+}
+
+func (gw *PlasmachainGateway) LastSeen() time.Time {
+	return gw.LastResponseTime
+}
+
 func (gw *PlasmachainGateway) LastBlockNumber() (uint64, error) {
 	block, err := gw.client.GetEvmBlockByNumber("latest", false)
 	if err != nil {
@@ -75,6 +135,16 @@ func (gw *PlasmachainGateway) LastBlockNumber() (uint64, error) {
 	return uint64(block.Number), nil
 }
 
+// BlockHash returns the hash of the Plasmachain block with the given number
+func (gw *PlasmachainGateway) BlockHash(blockNumber uint64) ([]byte, error) {
+	block, err := gw.client.GetEvmBlockByNumber(hexutil.EncodeUint64(blockNumber), false)
+	if err != nil {
+		return nil, err
+	}
+	gw.LastResponseTime = time.Now()
+	return block.Hash, nil
+}
+
 func (gw *PlasmachainGateway) GetTokensOwned(owner loom.LocalAddress) ([]tokensOwnedResponseItem, error) {
 	ownerCommonAddress := common.BytesToAddress(owner)
 	opts := &bind.CallOpts{
@@ -96,3 +166,303 @@ func (gw *PlasmachainGateway) GetTokensOwned(owner loom.LocalAddress) ([]tokensO
 	gw.LastResponseTime = time.Now()
 	return tokensOwned, nil
 }
+
+// IsMintingReceiptConsumed checks whether the fiat purchase contract has already used the minting receipt with the txId
+func (gw *PlasmachainGateway) IsMintingReceiptConsumed(txId *big.Int) (bool, error) {
+	if gw.fiatPurchase == nil {
+		return false, errors.New("fiat purchase contract address is not configured")
+	}
+
+	var consumed bool
+	err := gw.fiatPurchase.Call(&bind.CallOpts{}, &consumed, "txIdUsed", txId)
+	if err != nil {
+		return false, err
+	}
+
+	gw.LastResponseTime = time.Now()
+	return consumed, nil
+}
+
+// GetPackBalances returns the balances of the configured pack contracts, sorted by pack type
+func (gw *PlasmachainGateway) GetPackBalances(owner loom.LocalAddress) ([]packBalance, error) {
+	ownerCommonAddress := common.BytesToAddress(owner)
+
+	packBalances := make([]packBalance, 0, len(gw.packContracts))
+	for _, packContract := range gw.packContracts {
+		balance := new(big.Int)
+		err := packContract.contract.Call(&bind.CallOpts{}, &balance, "balanceOf", ownerCommonAddress)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get %s pack balance", packContract.packType.String())
+		}
+
+		packBalances = append(packBalances, packBalance{
+			PackType: packContract.packType,
+			Balance:  balance,
+		})
+	}
+
+	gw.LastResponseTime = time.Now()
+	return packBalances, nil
+}
+
+// FetchEvents returns the card transfer and pack burn events from startBlock to endBlock (inclusive), grouped by event kind
+func (gw *PlasmachainGateway) FetchEvents(startBlock, endBlock uint64) ([]*plasmachainEventInfo, error) {
+	filterOpts := &bind.FilterOpts{
+		Start: startBlock,
+		End:   &endBlock,
+	}
+
+	var chainID = gw.client.GetChainID()
+	events := make([]*plasmachainEventInfo, 0)
+
+	// Transfer
+	transferIterator, err := gw.zbgCard.FilterTransfer(filterOpts, nil, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get logs for Transfer")
+	}
+	for {
+		ok := transferIterator.Next()
+		if ok {
+			event := transferIterator.Event
+			eventInfo, _, err := gw.processSingleRawEvent(event.Raw)
+			if err != nil {
+				return nil, err
+			}
+
+			fromAddress, toAddress, err := parseBasicEventData(&event.From, &event.To, chainID)
+			if err != nil {
+				return nil, errors.Wrap(err, "error parsing basic event data")
+			}
+
+			eventInfo.Event.Payload = &orctype.PlasmachainEvent_Transfer{
+				Transfer: &orctype.PlasmachainEventTransfer{
+					From:    fromAddress,
+					To:      toAddress,
+					TokenId: &ltypes.BigUInt{Value: lcommon.BigUInt{Int: event.TokenId}},
+				},
+			}
+
+			eventInfo.Kind = "Transfer"
+			events = append(events, eventInfo)
+		} else {
+			err = transferIterator.Error()
+			if err != nil {
+				return nil, errors.Wrap(err, "Failed to get event data for Transfer")
+			}
+			transferIterator.Close()
+			break
+		}
+	}
+
+	// TransferToken
+	transferTokenIterator, err := gw.zbgCard.FilterTransferToken(filterOpts, nil, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get logs for TransferToken")
+	}
+	for {
+		ok := transferTokenIterator.Next()
+		if ok {
+			event := transferTokenIterator.Event
+			eventInfo, _, err := gw.processSingleRawEvent(event.Raw)
+			if err != nil {
+				return nil, err
+			}
+
+			fromAddress, toAddress, err := parseBasicEventData(&event.From, &event.To, chainID)
+			if err != nil {
+				return nil, errors.Wrap(err, "error parsing basic event data")
+			}
+
+			// TransferToken is just an old name for TransferWithQuantity, we can use the same event type
+			eventInfo.Event.Payload = &orctype.PlasmachainEvent_TransferWithQuantity{
+				TransferWithQuantity: &orctype.PlasmachainEventTransferWithQuantity{
+					From:    fromAddress,
+					To:      toAddress,
+					TokenId: &ltypes.BigUInt{Value: lcommon.BigUInt{Int: event.TokenId}},
+					Amount:  &ltypes.BigUInt{Value: lcommon.BigUInt{Int: event.Quantity}},
+				},
+			}
+
+			eventInfo.Kind = "TransferToken"
+			events = append(events, eventInfo)
+		} else {
+			err = transferTokenIterator.Error()
+			if err != nil {
+				return nil, errors.Wrap(err, "Failed to get event data for TransferToken")
+			}
+			transferTokenIterator.Close()
+			break
+		}
+	}
+
+	// TransferWithQuantity
+	transferWithQuantityIterator, err := gw.zbgCard.FilterTransferWithQuantity(filterOpts, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get logs for TransferWithQuantity")
+	}
+	for {
+		ok := transferWithQuantityIterator.Next()
+		if ok {
+			event := transferWithQuantityIterator.Event
+			eventInfo, _, err := gw.processSingleRawEvent(event.Raw)
+			if err != nil {
+				return nil, err
+			}
+
+			fromAddress, toAddress, err := parseBasicEventData(&event.From, &event.To, chainID)
+			if err != nil {
+				return nil, errors.Wrap(err, "error parsing basic event data")
+			}
+
+			eventInfo.Event.Payload = &orctype.PlasmachainEvent_TransferWithQuantity{
+				TransferWithQuantity: &orctype.PlasmachainEventTransferWithQuantity{
+					From:    fromAddress,
+					To:      toAddress,
+					TokenId: &ltypes.BigUInt{Value: lcommon.BigUInt{Int: event.TokenId}},
+					Amount:  &ltypes.BigUInt{Value: lcommon.BigUInt{Int: event.Amount}},
+				},
+			}
+
+			eventInfo.Kind = "TransferWithQuantity"
+			events = append(events, eventInfo)
+		} else {
+			err = transferWithQuantityIterator.Error()
+			if err != nil {
+				return nil, errors.Wrap(err, "Failed to get event data for TransferWithQuantity")
+			}
+			transferWithQuantityIterator.Close()
+			break
+		}
+	}
+
+	// BatchTransfer
+	batchTransferIterator, err := gw.zbgCard.FilterBatchTransfer(filterOpts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get logs for BatchTransfer")
+	}
+	for {
+		ok := batchTransferIterator.Next()
+		if ok {
+			event := batchTransferIterator.Event
+			eventInfo, _, err := gw.processSingleRawEvent(event.Raw)
+			if err != nil {
+				return nil, err
+			}
+
+			fromAddress, toAddress, err := parseBasicEventData(&event.From, &event.To, chainID)
+			if err != nil {
+				return nil, errors.Wrap(err, "error parsing basic event data")
+			}
+
+			tokenIds := make([]*ltypes.BigUInt, len(event.TokenTypes))
+			amounts := make([]*ltypes.BigUInt, len(event.Amounts))
+
+			for index, tokenType := range event.TokenTypes {
+				amount := event.Amounts[index]
+
+				tokenIds[index] = &ltypes.BigUInt{Value: lcommon.BigUInt{Int: tokenType}}
+				amounts[index] = &ltypes.BigUInt{Value: lcommon.BigUInt{Int: amount}}
+			}
+
+			eventInfo.Event.Payload = &orctype.PlasmachainEvent_BatchTransfer{
+				BatchTransfer: &orctype.PlasmachainEventBatchTransfer{
+					From:     fromAddress,
+					To:       toAddress,
+					TokenIds: tokenIds,
+					Amounts:  amounts,
+				},
+			}
+
+			eventInfo.Kind = "BatchTransfer"
+			events = append(events, eventInfo)
+		} else {
+			err = batchTransferIterator.Error()
+			if err != nil {
+				return nil, errors.Wrap(err, "Failed to get event data for BatchTransfer")
+			}
+			batchTransferIterator.Close()
+			break
+		}
+	}
+
+	packBurnEvents, err := gw.fetchPackBurnEvents(startBlock, endBlock)
+	if err != nil {
+		return nil, err
+	}
+	events = append(events, packBurnEvents...)
+
+	gw.LastResponseTime = time.Now()
+	return events, nil
+}
+
+// fetchPackBurnEvents returns the transfers of pack tokens to the zero address
+func (gw *PlasmachainGateway) fetchPackBurnEvents(startBlock, endBlock uint64) ([]*plasmachainEventInfo, error) {
+	var chainID = gw.client.GetChainID()
+	events := make([]*plasmachainEventInfo, 0)
+
+	for _, packContract := range gw.packContracts {
+		logs, err := gw.backend.FilterLogs(context.Background(), ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(startBlock),
+			ToBlock:   new(big.Int).SetUint64(endBlock),
+			Addresses: []common.Address{packContract.address},
+			Topics:    [][]common.Hash{{packTransferEventId}},
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get logs for %s pack Transfer", packContract.packType.String())
+		}
+
+		for _, log := range logs {
+			// the backend only filters by event, the receiver is checked here
+			if len(log.Topics) != 3 || common.BytesToAddress(log.Topics[2].Bytes()) != (common.Address{}) {
+				continue
+			}
+
+			eventInfo, _, err := gw.processSingleRawEvent(log)
+			if err != nil {
+				return nil, err
+			}
+
+			from := common.BytesToAddress(log.Topics[1].Bytes())
+			to := common.Address{}
+			fromAddress, _, err := parseBasicEventData(&from, &to, chainID)
+			if err != nil {
+				return nil, errors.Wrap(err, "error parsing basic event data")
+			}
+
+			eventInfo.Event.Payload = &orctype.PlasmachainEvent_PackBurn{
+				PackBurn: &orctype.PlasmachainEventPackBurn{
+					From:     fromAddress,
+					PackType: packContract.packType,
+					Amount:   &ltypes.BigUInt{Value: lcommon.BigUInt{Int: new(big.Int).SetBytes(log.Data)}},
+				},
+			}
+
+			eventInfo.Kind = "PackBurn"
+			events = append(events, eventInfo)
+		}
+	}
+
+	return events, nil
+}
+
+func (gw *PlasmachainGateway) processSingleRawEvent(rawEvent ethtypes.Log) (eventInfo *plasmachainEventInfo, receipt *ptypes.EvmTxReceipt, err error) {
+	receiptRaw, err := gw.client.GetEvmTxReceipt(rawEvent.TxHash.Bytes())
+	if err != nil {
+		gw.logger.Error(err.Error(), "txHash", rawEvent.TxHash.Hex())
+		return nil, nil, err
+	}
+
+	receipt = &receiptRaw
+
+	return &plasmachainEventInfo{
+		BlockNum:  rawEvent.BlockNumber,
+		BlockHash: rawEvent.BlockHash.Bytes(),
+		TxIdx:     rawEvent.TxIndex,
+		LogIdx:    rawEvent.Index,
+		Event: &orctype.PlasmachainEvent{
+			EthBlock: rawEvent.BlockNumber,
+			TxIndex:  uint64(rawEvent.TxIndex),
+			LogIndex: uint64(rawEvent.Index),
+		},
+	}, receipt, nil
+}
@@ -0,0 +1,214 @@
+package oracle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"sort"
+
+	orctype "github.com/loomnetwork/gamechain/types/oracle"
+	"github.com/loomnetwork/gamechain/types/zb/zb_enums"
+	"github.com/loomnetwork/go-loom"
+	ltypes "github.com/loomnetwork/go-loom/types"
+)
+
+// processedBlock is a Plasmachain block whose events were submitted to Gamechain
+type processedBlock struct {
+	Number uint64
+	Hash   []byte
+	Events []*orctype.PlasmachainEvent
+}
+
+// processedBlockTracker keeps the hashes of the last processed Plasmachain blocks, oldest first,
+// so blocks that were reorganised after being processed can be detected.
+type processedBlockTracker struct {
+	maxBlocks int
+	blocks    []*processedBlock
+}
+
+func newProcessedBlockTracker(maxBlocks int) *processedBlockTracker {
+	return &processedBlockTracker{
+		maxBlocks: maxBlocks,
+	}
+}
+
+func (t *processedBlockTracker) add(block *processedBlock) {
+	if t.maxBlocks <= 0 {
+		return
+	}
+
+	if len(t.blocks) > 0 && t.blocks[len(t.blocks)-1].Number == block.Number {
+		t.blocks = t.blocks[:len(t.blocks)-1]
+	}
+
+	t.blocks = append(t.blocks, block)
+	if len(t.blocks) > t.maxBlocks {
+		t.blocks = t.blocks[len(t.blocks)-t.maxBlocks:]
+	}
+}
+
+// findInvalidatedBlocks compares the tracked hashes with the current ones and returns the tracked blocks
+// that are no longer part of the chain, along with the last block that can be kept.
+// Since each block hash covers its parent, the newest block is checked first.
+func (t *processedBlockTracker) findInvalidatedBlocks(getBlockHash func(blockNumber uint64) ([]byte, error)) (lastValidBlockNumber uint64, invalidated []*processedBlock, err error) {
+	index := len(t.blocks) - 1
+	for ; index >= 0; index-- {
+		block := t.blocks[index]
+		hash, err := getBlockHash(block.Number)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if bytes.Equal(hash, block.Hash) {
+			break
+		}
+	}
+
+	invalidated = t.blocks[index+1:]
+	if len(invalidated) == 0 {
+		return 0, nil, nil
+	}
+
+	if index >= 0 {
+		lastValidBlockNumber = t.blocks[index].Number
+	} else {
+		// the reorg is deeper than the tracked blocks, roll back as far as we know
+		lastValidBlockNumber = invalidated[0].Number - 1
+	}
+
+	return lastValidBlockNumber, invalidated, nil
+}
+
+// removeAfter drops the tracked blocks newer than blockNumber
+func (t *processedBlockTracker) removeAfter(blockNumber uint64) {
+	for index, block := range t.blocks {
+		if block.Number > blockNumber {
+			t.blocks = t.blocks[:index]
+			return
+		}
+	}
+}
+
+// getCompensatingCardAmountChanges returns the card amount changes that undo the events.
+// Transfers from or to the card contract mint or burn cards, so the contract address gets no changes.
+func getCompensatingCardAmountChanges(events []*orctype.PlasmachainEvent, zbgCardContractAddress loom.Address) []*orctype.PlasmachainCardAmountChange {
+	type cardAmountChangeKey struct {
+		address     string
+		cardTokenId string
+	}
+
+	keyToChange := make(map[cardAmountChangeKey]*orctype.PlasmachainCardAmountChange)
+	addChange := func(address *ltypes.Address, cardTokenId *ltypes.BigUInt, amountChange int64) {
+		if address == nil || cardTokenId == nil || bytes.Equal(address.Local, zbgCardContractAddress.Local) {
+			return
+		}
+
+		key := cardAmountChangeKey{
+			address:     hex.EncodeToString(address.Local),
+			cardTokenId: cardTokenId.Value.String(),
+		}
+		change, exists := keyToChange[key]
+		if !exists {
+			change = &orctype.PlasmachainCardAmountChange{
+				Address:     address,
+				CardTokenId: cardTokenId,
+			}
+			keyToChange[key] = change
+		}
+
+		change.AmountChange += amountChange
+	}
+	addTransfer := func(from *ltypes.Address, to *ltypes.Address, cardTokenId *ltypes.BigUInt, amount int64) {
+		addChange(from, cardTokenId, amount)
+		addChange(to, cardTokenId, -amount)
+	}
+
+	for _, event := range events {
+		switch payload := event.Payload.(type) {
+		case *orctype.PlasmachainEvent_Transfer:
+			addTransfer(payload.Transfer.From, payload.Transfer.To, payload.Transfer.TokenId, 1)
+		case *orctype.PlasmachainEvent_TransferWithQuantity:
+			addTransfer(
+				payload.TransferWithQuantity.From,
+				payload.TransferWithQuantity.To,
+				payload.TransferWithQuantity.TokenId,
+				int64(payload.TransferWithQuantity.Amount.Value.Uint64()),
+			)
+		case *orctype.PlasmachainEvent_BatchTransfer:
+			for index, cardTokenId := range payload.BatchTransfer.TokenIds {
+				addTransfer(
+					payload.BatchTransfer.From,
+					payload.BatchTransfer.To,
+					cardTokenId,
+					int64(payload.BatchTransfer.Amounts[index].Value.Uint64()),
+				)
+			}
+		}
+	}
+
+	changes := make([]*orctype.PlasmachainCardAmountChange, 0, len(keyToChange))
+	for _, change := range keyToChange {
+		if change.AmountChange != 0 {
+			changes = append(changes, change)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		addressComparison := bytes.Compare(changes[i].Address.Local, changes[j].Address.Local)
+		if addressComparison != 0 {
+			return addressComparison < 0
+		}
+
+		return changes[i].CardTokenId.Value.Cmp(changes[j].CardTokenId.Value.Int) < 0
+	})
+
+	return changes
+}
+
+// getCompensatingPackAmountChanges returns the burned pack amount changes that undo the pack burn events
+func getCompensatingPackAmountChanges(events []*orctype.PlasmachainEvent) []*orctype.PlasmachainPackAmountChange {
+	type packAmountChangeKey struct {
+		address  string
+		packType zb_enums.PackType_Enum
+	}
+
+	keyToChange := make(map[packAmountChangeKey]*orctype.PlasmachainPackAmountChange)
+	for _, event := range events {
+		packBurn := event.GetPackBurn()
+		if packBurn == nil || packBurn.From == nil || packBurn.Amount == nil {
+			continue
+		}
+
+		key := packAmountChangeKey{
+			address:  hex.EncodeToString(packBurn.From.Local),
+			packType: packBurn.PackType,
+		}
+		change, exists := keyToChange[key]
+		if !exists {
+			change = &orctype.PlasmachainPackAmountChange{
+				Address:  packBurn.From,
+				PackType: packBurn.PackType,
+			}
+			keyToChange[key] = change
+		}
+
+		change.AmountChange -= int64(packBurn.Amount.Value.Uint64())
+	}
+
+	changes := make([]*orctype.PlasmachainPackAmountChange, 0, len(keyToChange))
+	for _, change := range keyToChange {
+		if change.AmountChange != 0 {
+			changes = append(changes, change)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		addressComparison := bytes.Compare(changes[i].Address.Local, changes[j].Address.Local)
+		if addressComparison != 0 {
+			return addressComparison < 0
+		}
+
+		return changes[i].PackType < changes[j].PackType
+	})
+
+	return changes
+}
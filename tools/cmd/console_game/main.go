@@ -34,6 +34,8 @@ func setup(c *battleground.ZombieBattleground, pubKeyHex string, addr *loom.Addr
 		Version:              updateInitRequest.InitData.Version,
 		Oracle:               updateInitRequest.InitData.Oracle,
 		OverlordLeveling:     updateInitRequest.InitData.OverlordLeveling,
+		Crafting:             updateInitRequest.InitData.Crafting,
+		Packs:                updateInitRequest.InitData.Packs,
 	}
 
 	pubKey, _ := hex.DecodeString(pubKeyHex)
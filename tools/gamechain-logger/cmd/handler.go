@@ -185,6 +185,44 @@ func DeleteDeckHandler(eventData *types.EventData, db *gorm.DB) error {
 	return nil
 }
 
+func CardHistoryHandler(eventData *types.EventData, db *gorm.DB) error {
+	var event zb_calls.CardHistoryEvent
+	if err := proto.Unmarshal(eventData.EncodedBody, &event); err != nil {
+		return err
+	}
+
+	for _, entry := range event.Entries {
+		cardChange := models.CardChange{
+			MouldID:     entry.CardKey.MouldId,
+			Variant:     entry.CardKey.Variant.String(),
+			Version:     entry.Version,
+			Kind:        entry.Kind.String(),
+			ChangedBy:   entry.ChangedBy,
+			BlockHeight: eventData.BlockHeight,
+			BlockTime:   time.Unix(eventData.BlockTime, 0),
+		}
+
+		if len(entry.Changes) == 0 {
+			if err := db.Create(&cardChange).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, change := range entry.Changes {
+			fieldChange := cardChange
+			fieldChange.Field = change.Field
+			fieldChange.OldValue = change.OldValue
+			fieldChange.NewValue = change.NewValue
+			if err := db.Create(&fieldChange).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // TODO: seems this is not used anymore at all? can it be removed?
 func EndgameHandler(eventData *types.EventData, db *gorm.DB) error {
 	var event zb_data.PlayerActionEvent
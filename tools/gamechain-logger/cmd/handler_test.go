@@ -7,8 +7,12 @@ import (
 	"testing"
 
 	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
 	"github.com/jinzhu/gorm"
 	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	battleground_proto "github.com/loomnetwork/gamechain/battleground/proto"
+	"github.com/loomnetwork/gamechain/types/zb/zb_calls"
+	"github.com/loomnetwork/gamechain/types/zb/zb_data"
 	"github.com/loomnetwork/go-loom/plugin/types"
 	"github.com/loomnetwork/gamechain/tools/gamechain-logger/models"
 	assert "github.com/stretchr/testify/require"
@@ -80,6 +84,9 @@ func InitDB() *gorm.DB {
 	if err := db.AutoMigrate(&models.ZbHeightCheck{}).Error; err != nil {
 		panic(err)
 	}
+	if err := db.AutoMigrate(&models.CardChange{}).Error; err != nil {
+		panic(err)
+	}
 
 	return db
 }
@@ -154,3 +161,51 @@ func TestEventHandlers(t *testing.T) {
 	db.Close()
 	DropDB()
 }
+
+func TestCardHistoryHandler(t *testing.T) {
+	DropDB()
+	db := InitDB()
+
+	event := zb_calls.CardHistoryEvent{
+		Entries: []*zb_data.CardHistoryEntry{
+			{
+				CardKey:   battleground_proto.CardKey{MouldId: 1},
+				Kind:      zb_data.CardHistoryEntry_Changed,
+				Version:   "v1",
+				ChangedBy: "0x1",
+				Changes: []*zb_data.CardFieldChange{
+					{Field: "damage", OldValue: "1", NewValue: "2"},
+					{Field: "cost", OldValue: "3", NewValue: "2"},
+				},
+			},
+			{
+				CardKey: battleground_proto.CardKey{MouldId: 2},
+				Kind:    zb_data.CardHistoryEntry_Added,
+				Version: "v1",
+			},
+		},
+	}
+	encodedBody, err := proto.Marshal(&event)
+	assert.Nil(t, err)
+
+	err = CardHistoryHandler(&types.EventData{BlockHeight: 10, EncodedBody: encodedBody}, db)
+	assert.Nil(t, err)
+
+	var cardChanges []models.CardChange
+	err = db.Order("id").Find(&cardChanges).Error
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(cardChanges))
+	assert.Equal(t, int64(1), cardChanges[0].MouldID)
+	assert.Equal(t, "damage", cardChanges[0].Field)
+	assert.Equal(t, "1", cardChanges[0].OldValue)
+	assert.Equal(t, "2", cardChanges[0].NewValue)
+	assert.Equal(t, "Changed", cardChanges[0].Kind)
+	assert.Equal(t, uint64(10), cardChanges[0].BlockHeight)
+	assert.Equal(t, "cost", cardChanges[1].Field)
+	assert.Equal(t, int64(2), cardChanges[2].MouldID)
+	assert.Equal(t, "Added", cardChanges[2].Kind)
+	assert.Equal(t, "", cardChanges[2].Field)
+
+	db.Close()
+	DropDB()
+}
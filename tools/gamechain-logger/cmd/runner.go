@@ -136,6 +136,8 @@ func (r *Runner) batchProcessEvents(db *gorm.DB, events []*types.EventData) erro
 				topicHandler = EditDeckHandler
 			case battleground.TopicDeleteDeckEvent:
 				topicHandler = DeleteDeckHandler
+			case battleground.TopicCardHistoryEvent:
+				topicHandler = CardHistoryHandler
 			default:
 				if strings.HasPrefix(topic, "match:") {
 					topicHandler = MatchHandler
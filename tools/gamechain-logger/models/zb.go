@@ -75,6 +75,23 @@ type Card struct {
 	ImageURL    string `json:"image_url"`
 }
 
+// CardChange is a single field change of a card made by a card library update,
+// added and removed cards have a single row with an empty field
+type CardChange struct {
+	ID          int64     `json:"id" gorm:"PRIMARY_KEY"`
+	CreatedAt   time.Time `json:"created_at"`
+	MouldID     int64     `json:"mould_id" gorm:"INDEX"`
+	Variant     string    `json:"variant"`
+	Version     string    `json:"version" gorm:"INDEX"`
+	Kind        string    `json:"kind"`
+	Field       string    `json:"field"`
+	OldValue    string    `json:"old_value" sql:"type:text;"`
+	NewValue    string    `json:"new_value" sql:"type:text;"`
+	ChangedBy   string    `json:"changed_by"`
+	BlockHeight uint64    `json:"block_height" gorm:"INDEX"`
+	BlockTime   time.Time `json:"block_time"`
+}
+
 type ZbHeightCheck struct {
 	Key             int64     `gorm:"not null;UNIQUE_INDEX:height_key;default=1" json:"key"`
 	LastBlockHeight uint64    `json:"last_block_height"`
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/loomnetwork/gamechain/oracle"
+	"github.com/spf13/cobra"
+)
+
+var backfillCmdArgs struct {
+	fromBlock         uint64
+	toBlock           uint64
+	blockRange        uint64
+	checkpointPath    string
+	resyncCollections bool
+	force             bool
+}
+
+var backfillCmd = &cobra.Command{
+	Use:     "backfill",
+	Short:   "Replays the Plasmachain events of a block range to Gamechain",
+	Long:    `Replays the Plasmachain transfer events from --from to --to (inclusive) as fast as possible, or queues a full card collection sync for every address in the events with --resync-collections. An interrupted backfill resumes from its checkpoint when run again with the same range.`,
+	Example: `  gcoracle backfill --from 196492 --to 250000`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		orc, err := oracle.CreateOracle(cfg, "gcoracle")
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+		go func() {
+			sig := <-signals
+			log.Printf("received %s, stopping the backfill", sig)
+			cancel()
+		}()
+
+		err = orc.Backfill(ctx, oracle.BackfillConfig{
+			FromBlock:         backfillCmdArgs.fromBlock,
+			ToBlock:           backfillCmdArgs.toBlock,
+			BlockRange:        backfillCmdArgs.blockRange,
+			CheckpointPath:    backfillCmdArgs.checkpointPath,
+			ResyncCollections: backfillCmdArgs.resyncCollections,
+			Force:             backfillCmdArgs.force,
+			OnProgress: func(progress oracle.BackfillProgress) {
+				blockCount := progress.ToBlock - progress.FromBlock + 1
+				doneBlockCount := progress.LastBlock - progress.FromBlock + 1
+				log.Printf(
+					"backfilled blocks up to %d of %d-%d (%.1f%%), %d events in %s",
+					progress.LastBlock,
+					progress.FromBlock,
+					progress.ToBlock,
+					float64(doneBlockCount)*100/float64(blockCount),
+					progress.EventCount,
+					progress.Elapsed,
+				)
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		log.Printf("backfill of blocks %d-%d finished", backfillCmdArgs.fromBlock, backfillCmdArgs.toBlock)
+		return nil
+	},
+}
+
+func init() {
+	backfillCmd.Flags().Uint64Var(&backfillCmdArgs.fromBlock, "from", 0, "First Plasmachain block to backfill")
+	backfillCmd.Flags().Uint64Var(&backfillCmdArgs.toBlock, "to", 0, "Last Plasmachain block to backfill")
+	backfillCmd.Flags().Uint64Var(&backfillCmdArgs.blockRange, "block-range", 1000, "Number of Plasmachain blocks fetched at once")
+	backfillCmd.Flags().StringVar(&backfillCmdArgs.checkpointPath, "checkpoint-path", "oracle-backfill-checkpoint.json", "File that keeps the backfill progress, empty to not resume")
+	backfillCmd.Flags().BoolVar(&backfillCmdArgs.resyncCollections, "resync-collections", false, "Queue full card collection syncs for the addresses in the events instead of replaying them")
+	backfillCmd.Flags().BoolVar(&backfillCmdArgs.force, "force", false, "Move the last processed Plasmachain block back to the start of the range if Gamechain is past it")
+	_ = backfillCmd.MarkFlagRequired("from")
+	_ = backfillCmd.MarkFlagRequired("to")
+
+	rootCmd.AddCommand(backfillCmd)
+}
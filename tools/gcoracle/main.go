@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/loomnetwork/gamechain/oracle"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -13,6 +18,8 @@ import (
 	"github.com/spf13/viper"
 )
 
+const httpShutdownTimeout = 10 * time.Second
+
 var rootCmd = &cobra.Command{
 	Use:          "gcoracle",
 	Short:        "Gamechain Oracle",
@@ -34,8 +41,15 @@ func init() {
 	rootCmd.PersistentFlags().String("plasmachain-write-uri", "http://localhost:46658/rpc", "Plasmachain Write URI")
 	rootCmd.PersistentFlags().String("plasmachain-event-uri", "ws://localhost:9999/queryws", "Plasmachain Events URI")
 	rootCmd.PersistentFlags().String("plasmachain-zbgcard-contract-hex-address", "0x3fc83db9ad1513c181e9a7345a28f62c0844abbb", "Plasmachain ZBGCard Contract Hex Address")
+	rootCmd.PersistentFlags().String("plasmachain-fiat-purchase-contract-hex-address", "", "Plasmachain Fiat Purchase Contract Hex Address, used to verify minting receipts")
+	rootCmd.PersistentFlags().StringSlice("plasmachain-pack-contract-hex-addresses", nil, "Plasmachain Pack Contract Hex Addresses as PackType=0x..., used to fetch pack balances")
 	rootCmd.PersistentFlags().Int("plasmachain-poll-interval", 10, "Plasmachain Pool Interval in seconds")
 	rootCmd.PersistentFlags().Int("plasmachain-max-block-range", 20, "Plasmachain Max Block Range")
+	rootCmd.PersistentFlags().Int("plasmachain-max-batch-event-count", 200, "Max number of Plasmachain events submitted in one batch, 0 for no limit")
+	rootCmd.PersistentFlags().Int("plasmachain-max-batch-size", 256*1024, "Max size in bytes of Plasmachain events submitted in one batch, 0 for no limit")
+	rootCmd.PersistentFlags().Int("plasmachain-max-batch-attempts", 5, "Number of failed submissions after which failing Plasmachain events are quarantined, 0 to retry forever")
+	rootCmd.PersistentFlags().Int("plasmachain-confirmations", 6, "Number of Plasmachain blocks an event has to be buried under before it's submitted")
+	rootCmd.PersistentFlags().Int("plasmachain-reorg-tracking-depth", 50, "Number of last processed Plasmachain blocks checked for reorgs")
 	// gamechain
 	rootCmd.PersistentFlags().String("gamechain-private-key", "", "Gamechain Private Key")
 	rootCmd.PersistentFlags().String("gamechain-chain-id", "default", "Gamechain Chain ID")
@@ -43,12 +57,18 @@ func init() {
 	rootCmd.PersistentFlags().String("gamechain-write-uri", "http://localhost:46658/rpc", "Gamechain Write URI")
 	rootCmd.PersistentFlags().String("gamechain-event-uri", "ws://localhost:9999/queryws", "Gamechain Events URI")
 	rootCmd.PersistentFlags().String("gamechain-contract-name", "ZombieBattleground", "Gamechain Contract Name")
+	rootCmd.PersistentFlags().Int("gamechain-player-pool-sweep-interval", 60, "Gamechain Player Pool Sweep Interval in seconds, 0 to disable")
+	rootCmd.PersistentFlags().Int("gamechain-minting-receipt-sweep-interval", 300, "Gamechain Minting Receipt Sweep Interval in seconds, 0 to disable")
+	rootCmd.PersistentFlags().Bool("gamechain-oracle-set-mode", false, "Submit Plasmachain events as a member of the Gamechain oracle set")
+	rootCmd.PersistentFlags().Bool("gamechain-commands-enabled", true, "Execute Gamechain oracle commands, requires the oracle key")
 	// oracle
 	rootCmd.PersistentFlags().String("oracle-query-address", ":8888", "Oracle Query Address")
 	rootCmd.PersistentFlags().String("oracle-log-level", "debug", "Oracle Log Level")
 	rootCmd.PersistentFlags().String("oracle-log-destination", "file://oracle.log", "Oracle Log Destination")
 	rootCmd.PersistentFlags().Int("oracle-reconnect-interval", 5, "Oracle Startup Delay in second")
 	rootCmd.PersistentFlags().Int("oracle-startup-delay", 10, "Oracle Reconnect Interval in second")
+	rootCmd.PersistentFlags().String("oracle-journal-path", "oracle-journal.jsonl", "File that keeps the submitted Plasmachain events across restarts, empty to keep them in memory")
+	rootCmd.PersistentFlags().Int("oracle-max-plasmachain-block-lag", 100, "Number of blocks the oracle can fall behind Plasmachain before it's reported as unhealthy, 0 to ignore the lag")
 
 	viper.BindPFlag("plasmachain-private-key", rootCmd.PersistentFlags().Lookup("plasmachain-private-key"))
 	viper.BindPFlag("plasmachain-chain-id", rootCmd.PersistentFlags().Lookup("plasmachain-chain-id"))
@@ -56,8 +76,15 @@ func init() {
 	viper.BindPFlag("plasmachain-write-uri", rootCmd.PersistentFlags().Lookup("plasmachain-write-uri"))
 	viper.BindPFlag("plasmachain-event-uri", rootCmd.PersistentFlags().Lookup("plasmachain-event-uri"))
 	viper.BindPFlag("plasmachain-zbgcard-contract-hex-address", rootCmd.PersistentFlags().Lookup("plasmachain-zbgcard-contract-hex-address"))
+	viper.BindPFlag("plasmachain-fiat-purchase-contract-hex-address", rootCmd.PersistentFlags().Lookup("plasmachain-fiat-purchase-contract-hex-address"))
+	viper.BindPFlag("plasmachain-pack-contract-hex-addresses", rootCmd.PersistentFlags().Lookup("plasmachain-pack-contract-hex-addresses"))
 	viper.BindPFlag("plasmachain-poll-interval", rootCmd.PersistentFlags().Lookup("plasmachain-poll-interval"))
 	viper.BindPFlag("plasmachain-max-block-range", rootCmd.PersistentFlags().Lookup("plasmachain-max-block-range"))
+	viper.BindPFlag("plasmachain-max-batch-event-count", rootCmd.PersistentFlags().Lookup("plasmachain-max-batch-event-count"))
+	viper.BindPFlag("plasmachain-max-batch-size", rootCmd.PersistentFlags().Lookup("plasmachain-max-batch-size"))
+	viper.BindPFlag("plasmachain-max-batch-attempts", rootCmd.PersistentFlags().Lookup("plasmachain-max-batch-attempts"))
+	viper.BindPFlag("plasmachain-confirmations", rootCmd.PersistentFlags().Lookup("plasmachain-confirmations"))
+	viper.BindPFlag("plasmachain-reorg-tracking-depth", rootCmd.PersistentFlags().Lookup("plasmachain-reorg-tracking-depth"))
 
 	viper.BindPFlag("gamechain-private-key", rootCmd.PersistentFlags().Lookup("gamechain-private-key"))
 	viper.BindPFlag("gamechain-chain-id", rootCmd.PersistentFlags().Lookup("gamechain-chain-id"))
@@ -65,12 +92,18 @@ func init() {
 	viper.BindPFlag("gamechain-write-uri", rootCmd.PersistentFlags().Lookup("gamechain-write-uri"))
 	viper.BindPFlag("gamechain-event-uri", rootCmd.PersistentFlags().Lookup("gamechain-event-uri"))
 	viper.BindPFlag("gamechain-contract-name", rootCmd.PersistentFlags().Lookup("gamechain-contract-name"))
+	viper.BindPFlag("gamechain-player-pool-sweep-interval", rootCmd.PersistentFlags().Lookup("gamechain-player-pool-sweep-interval"))
+	viper.BindPFlag("gamechain-minting-receipt-sweep-interval", rootCmd.PersistentFlags().Lookup("gamechain-minting-receipt-sweep-interval"))
+	viper.BindPFlag("gamechain-oracle-set-mode", rootCmd.PersistentFlags().Lookup("gamechain-oracle-set-mode"))
+	viper.BindPFlag("gamechain-commands-enabled", rootCmd.PersistentFlags().Lookup("gamechain-commands-enabled"))
 
 	viper.BindPFlag("oracle-query-address", rootCmd.PersistentFlags().Lookup("oracle-query-address"))
 	viper.BindPFlag("oracle-log-level", rootCmd.PersistentFlags().Lookup("oracle-log-level"))
 	viper.BindPFlag("oracle-log-destination", rootCmd.PersistentFlags().Lookup("oracle-log-destination"))
 	viper.BindPFlag("oracle-reconnect-interval", rootCmd.PersistentFlags().Lookup("oracle-reconnect-interval"))
 	viper.BindPFlag("oracle-startup-delay", rootCmd.PersistentFlags().Lookup("oracle-startup-delay"))
+	viper.BindPFlag("oracle-journal-path", rootCmd.PersistentFlags().Lookup("oracle-journal-path"))
+	viper.BindPFlag("oracle-max-plasmachain-block-lag", rootCmd.PersistentFlags().Lookup("oracle-max-plasmachain-block-lag"))
 }
 
 func initConfig() {
@@ -84,47 +117,143 @@ func main() {
 	}
 }
 
-func run() error {
+// loadConfig builds the oracle config from the flags and environment variables
+func loadConfig() (*oracle.Config, error) {
+	packContractHexAddresses := make(map[string]string)
+	for _, packContract := range viper.GetStringSlice("plasmachain-pack-contract-hex-addresses") {
+		parts := strings.SplitN(packContract, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid pack contract %s, expected PackType=0x...", packContract)
+		}
+		packContractHexAddresses[parts[0]] = parts[1]
+	}
+
 	cfg := &oracle.Config{
-		PlasmachainPrivateKey:                viper.GetString("plasmachain-private-key"),
-		PlasmachainChainID:                   viper.GetString("plasmachain-chain-id"),
-		PlasmachainReadURI:                   viper.GetString("plasmachain-read-uri"),
-		PlasmachainWriteURI:                  viper.GetString("plasmachain-write-uri"),
-		PlasmachainEventsURI:                 viper.GetString("plasmachain-event-uri"),
-		PlasmachainZbgCardContractHexAddress: viper.GetString("plasmachain-zbgcard-contract-hex-address"),
-		PlasmachainPollInterval:              viper.GetInt("plasmachain-poll-interval"),
-		PlasmachainMaxBlockRange:             viper.GetInt("plasmachain-max-block-range"),
-		GamechainPrivateKey:                  viper.GetString("gamechain-private-key"),
-		GamechainChainID:                     viper.GetString("gamechain-chain-id"),
-		GamechainReadURI:                     viper.GetString("gamechain-read-uri"),
-		GamechainWriteURI:                    viper.GetString("gamechain-write-uri"),
-		GamechainEventsURI:                   viper.GetString("gamechain-event-uri"),
-		GamechainContractName:                viper.GetString("gamechain-contract-name"),
-		OracleQueryAddress:                   viper.GetString("oracle-query-address"),
-		OracleLogLevel:                       viper.GetString("oracle-log-level"),
-		OracleLogDestination:                 viper.GetString("oracle-log-destination"),
-		OracleReconnectInterval:              int32(viper.GetInt("oracle-reconnect-interval")),
-		OracleStartupDelay:                   int32(viper.GetInt("oracle-startup-delay")),
+		PlasmachainPrivateKey:                     viper.GetString("plasmachain-private-key"),
+		PlasmachainChainID:                        viper.GetString("plasmachain-chain-id"),
+		PlasmachainReadURI:                        viper.GetString("plasmachain-read-uri"),
+		PlasmachainWriteURI:                       viper.GetString("plasmachain-write-uri"),
+		PlasmachainEventsURI:                      viper.GetString("plasmachain-event-uri"),
+		PlasmachainZbgCardContractHexAddress:      viper.GetString("plasmachain-zbgcard-contract-hex-address"),
+		PlasmachainFiatPurchaseContractHexAddress: viper.GetString("plasmachain-fiat-purchase-contract-hex-address"),
+		PlasmachainPackContractHexAddresses:       packContractHexAddresses,
+		PlasmachainPollInterval:                   viper.GetInt("plasmachain-poll-interval"),
+		PlasmachainMaxBlockRange:                  viper.GetInt("plasmachain-max-block-range"),
+		PlasmachainMaxBatchEventCount:             viper.GetInt("plasmachain-max-batch-event-count"),
+		PlasmachainMaxBatchSize:                   viper.GetInt("plasmachain-max-batch-size"),
+		PlasmachainMaxBatchAttempts:               viper.GetInt("plasmachain-max-batch-attempts"),
+		PlasmachainConfirmations:                  viper.GetInt("plasmachain-confirmations"),
+		PlasmachainReorgTrackingDepth:             viper.GetInt("plasmachain-reorg-tracking-depth"),
+		GamechainPrivateKey:                       viper.GetString("gamechain-private-key"),
+		GamechainChainID:                          viper.GetString("gamechain-chain-id"),
+		GamechainReadURI:                          viper.GetString("gamechain-read-uri"),
+		GamechainWriteURI:                         viper.GetString("gamechain-write-uri"),
+		GamechainEventsURI:                        viper.GetString("gamechain-event-uri"),
+		GamechainContractName:                     viper.GetString("gamechain-contract-name"),
+		GamechainPlayerPoolSweepInterval:          viper.GetInt("gamechain-player-pool-sweep-interval"),
+		GamechainMintingReceiptSweepInterval:      viper.GetInt("gamechain-minting-receipt-sweep-interval"),
+		GamechainOracleSetMode:                    viper.GetBool("gamechain-oracle-set-mode"),
+		GamechainCommandsEnabled:                  viper.GetBool("gamechain-commands-enabled"),
+		OracleQueryAddress:                        viper.GetString("oracle-query-address"),
+		OracleLogLevel:                            viper.GetString("oracle-log-level"),
+		OracleLogDestination:                      viper.GetString("oracle-log-destination"),
+		OracleReconnectInterval:                   int32(viper.GetInt("oracle-reconnect-interval")),
+		OracleStartupDelay:                        int32(viper.GetInt("oracle-startup-delay")),
+		OracleJournalPath:                         viper.GetString("oracle-journal-path"),
+		OracleMaxPlasmachainBlockLag:              viper.GetInt("oracle-max-plasmachain-block-lag"),
 	}
 
 	if cfg.PlasmachainMaxBlockRange <= 0 {
 		panic("max block height must be > 0")
 	}
 
+	if cfg.PlasmachainConfirmations < 0 || cfg.PlasmachainReorgTrackingDepth < 0 {
+		panic("confirmations and reorg tracking depth must be >= 0")
+	}
+
+	if cfg.OracleMaxPlasmachainBlockLag < 0 {
+		panic("max plasmachain block lag must be >= 0")
+	}
+
+	if cfg.PlasmachainMaxBatchEventCount < 0 || cfg.PlasmachainMaxBatchSize < 0 || cfg.PlasmachainMaxBatchAttempts < 0 {
+		panic("max batch event count, size and attempts must be >= 0")
+	}
+
+	return cfg, nil
+}
+
+func run() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
 	orc, err := oracle.CreateOracle(cfg, "gcoracle")
 	if err != nil {
 		panic(err)
 	}
 
-	go orc.RunWithRecovery()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+
+	oracleStopped := make(chan struct{})
+	go func() {
+		orc.RunWithRecovery(ctx)
+		close(oracleStopped)
+	}()
 
 	http.HandleFunc("/status", func(w http.ResponseWriter, req *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(orc.Status())
 	})
+	http.HandleFunc("/status/live", newHealthHandler(orc, func(status *oracle.Status) bool {
+		return status.Live
+	}))
+	http.HandleFunc("/status/ready", newHealthHandler(orc, func(status *oracle.Status) bool {
+		return status.Ready
+	}))
+	http.HandleFunc("/status/healthy", newHealthHandler(orc, func(status *oracle.Status) bool {
+		return status.Healthy
+	}))
 
 	http.Handle("/metrics", promhttp.Handler())
-	log.Fatal(http.ListenAndServe(cfg.OracleQueryAddress, nil))
-	return nil
+	server := &http.Server{Addr: cfg.OracleQueryAddress}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+
+	select {
+	case sig := <-signals:
+		log.Printf("received %s, stopping the oracle", sig)
+	case <-oracleStopped:
+		log.Printf("oracle stopped")
+	}
+
+	cancel()
+	<-oracleStopped
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), httpShutdownTimeout)
+	defer cancelShutdown()
+	return server.Shutdown(shutdownCtx)
+}
+
+// newHealthHandler responds with 200 if the check passes and 503 otherwise, along with the oracle status
+func newHealthHandler(orc *oracle.Oracle, check func(status *oracle.Status) bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		status := orc.Status()
+
+		w.Header().Set("Content-Type", "application/json")
+		if check(status) {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(status)
+	}
 }